@@ -0,0 +1,67 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/server/backup"
+)
+
+var (
+	options = backup.Options{}
+
+	Cmd = &cobra.Command{
+		Use:   "backup",
+		Short: "Backup a namespace",
+		Long:  `Take a consistent backup of the database of each shard of a namespace, with its commit offset`,
+		RunE:  exec,
+	}
+)
+
+func init() {
+	defaultServiceAddress := fmt.Sprintf("localhost:%d", common.DefaultPublicPort)
+	Cmd.Flags().StringVarP(&options.ServiceAddress, "service-address", "a", defaultServiceAddress, "Service address")
+	Cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", oxia.DefaultNamespace, "The namespace to backup")
+	Cmd.Flags().StringVar(&options.Dir, "backup-dir", "", "Directory where to store the backup")
+
+	if err := Cmd.MarkFlagRequired("backup-dir"); err != nil {
+		panic(err)
+	}
+}
+
+func exec(cmd *cobra.Command, _ []string) error {
+	clientPool := common.NewClientPool(nil, nil)
+	defer clientPool.Close()
+
+	manifest, err := backup.Backup(context.Background(), clientPool, options)
+	if err != nil {
+		return err
+	}
+
+	slog.Info(
+		"Backup completed",
+		slog.String("namespace", manifest.Namespace),
+		slog.Int("shards", len(manifest.Shards)),
+		slog.String("backup-dir", options.Dir),
+	)
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulkload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/server/backup"
+)
+
+var (
+	serviceAddress string
+	namespace      string
+	file           string
+	workDir        string
+
+	Cmd = &cobra.Command{
+		Use:   "bulk-load",
+		Short: "Load records in a namespace without going through the write-ahead-log",
+		Long: `Load the records written by the export command by building an sstable for each shard and having
+the replicas of the shard ingest it. The records get new versions and replace the existing ones, without
+producing notifications. Records with secondary indexes are not supported and must be imported instead.`,
+		RunE: exec,
+	}
+)
+
+func init() {
+	defaultServiceAddress := fmt.Sprintf("localhost:%d", common.DefaultPublicPort)
+	defaultWorkDir := filepath.Join(os.TempDir(), "oxia-bulk-load")
+	Cmd.Flags().StringVarP(&serviceAddress, "service-address", "a", defaultServiceAddress, "Service address")
+	Cmd.Flags().StringVarP(&namespace, "namespace", "n", oxia.DefaultNamespace, "The namespace where to load the records")
+	Cmd.Flags().StringVarP(&file, "file", "f", "-", "The file with the records, or - for the standard input")
+	Cmd.Flags().StringVar(&workDir, "work-dir", defaultWorkDir, "The directory where to build the sstables")
+}
+
+func exec(cmd *cobra.Command, _ []string) error {
+	var in io.Reader = cmd.InOrStdin()
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	clientPool := common.NewClientPool(nil, nil)
+	defer clientPool.Close()
+
+	count, err := backup.BulkLoad(context.Background(), clientPool, backup.BulkLoadOptions{
+		ServiceAddress: serviceAddress,
+		Namespace:      namespace,
+		WorkDir:        workDir,
+	}, in)
+	if err != nil {
+		return err
+	}
+
+	slog.Info(
+		"Bulk load completed",
+		slog.String("namespace", namespace),
+		slog.Int64("records", count),
+	)
+	return nil
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/streamnative/oxia/common"
 	"github.com/streamnative/oxia/common/security"
 	"github.com/streamnative/oxia/coordinator"
+	"github.com/streamnative/oxia/coordinator/impl"
 	"github.com/streamnative/oxia/coordinator/model"
 )
 
@@ -50,11 +51,20 @@ var (
 func init() {
 	flag.InternalAddr(Cmd, &conf.InternalServiceAddr)
 	flag.MetricsAddr(Cmd, &conf.MetricsServiceAddr)
-	Cmd.Flags().Var(&conf.MetadataProviderImpl, "metadata", "Metadata provider implementation: file, configmap or memory")
+	Cmd.Flags().Var(&conf.MetadataProviderImpl, "metadata", "Metadata provider implementation: file, configmap, oxia or memory")
 	Cmd.Flags().StringVar(&conf.K8SMetadataNamespace, "k8s-namespace", conf.K8SMetadataNamespace, "Kubernetes namespace for oxia config maps")
 	Cmd.Flags().StringVar(&conf.K8SMetadataConfigMapName, "k8s-configmap-name", conf.K8SMetadataConfigMapName, "ConfigMap name for cluster status configmap")
 	Cmd.Flags().StringVar(&conf.FileMetadataPath, "file-clusters-status-path", "data/cluster-status.json", "The path where the cluster status is stored when using 'file' provider")
+	Cmd.Flags().StringVar(&conf.OxiaMetadataServiceAddress, "oxia-metadata-address", "", "Service address of the Oxia cluster where the cluster status is stored when using 'oxia' provider")
+	Cmd.Flags().StringVar(&conf.OxiaMetadataNamespace, "oxia-metadata-namespace", "", "Namespace where the cluster status is stored when using 'oxia' provider")
+	Cmd.Flags().StringVar(&conf.OxiaMetadataKey, "oxia-metadata-key", impl.DefaultOxiaMetadataKey, "Key of the cluster status record when using 'oxia' provider")
 	Cmd.Flags().StringVarP(&configFile, "conf", "f", "", "Cluster config file")
+	Cmd.Flags().BoolVar(&conf.LeaderElectionEnabled, "leader-election", false, "Allow running multiple coordinators, electing the active one through the metadata provider")
+	Cmd.Flags().StringVar(&conf.LeaderElectionId, "leader-election-id", "", "Unique identifier of this coordinator for the leader election. Defaults to the hostname with a random suffix")
+	Cmd.Flags().DurationVar(&conf.LeaderLeaseDuration, "leader-lease-duration", impl.DefaultLeaderLeaseDuration, "How long a standby coordinator waits for the leader to renew its lease before taking over")
+	Cmd.Flags().StringVar(&conf.AuditOptions.File, "audit-log-file", "", "File where the admin actions of the coordinator are recorded. The audit log is disabled when it's not set")
+	Cmd.Flags().Int64Var(&conf.AuditOptions.MaxFileSize, "audit-log-max-file-size", 0, "Size in bytes after which the audit log file is rotated (default 100MB)")
+	Cmd.Flags().IntVar(&conf.AuditOptions.MaxFiles, "audit-log-max-files", 0, "Max number of rotated audit log files to keep (default 10)")
 
 	// server TLS section
 	Cmd.Flags().StringVar(&serverTLS.CertFile, "tls-cert-file", "", "Tls certificate file")
@@ -84,6 +94,9 @@ func validate(*cobra.Command, []string) error {
 			return errors.New("k8s-configmap-name must be set with metadata=configmap")
 		}
 	}
+	if conf.MetadataProviderImpl == coordinator.Oxia && conf.OxiaMetadataServiceAddress == "" {
+		return errors.New("oxia-metadata-address must be set with metadata=oxia")
+	}
 	return nil
 }
 
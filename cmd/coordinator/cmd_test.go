@@ -184,6 +184,8 @@ func TestCmd(t *testing.T) {
 		{[]string{"--metadata=configmap", "--k8s-namespace=foo", "--k8s-configmap-name=bar"}, false},
 		{[]string{"--metadata=configmap", "--k8s-namespace=foo}"}, true},
 		{[]string{"--metadata=configmap", "--k8s-configmap-name=bar"}, true},
+		{[]string{"--metadata=oxia"}, true},
+		{[]string{"--metadata=oxia", "--oxia-metadata-address=localhost:6648"}, false},
 		{[]string{"--metadata=invalid"}, true},
 	} {
 		t.Run(strings.Join(test.args, "_"), func(t *testing.T) {
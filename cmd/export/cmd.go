@@ -0,0 +1,85 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/server/backup"
+)
+
+var (
+	serviceAddress string
+	namespace      string
+	file           string
+
+	Cmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export the records of a namespace",
+		Long: `Export all the records of a namespace, except for the ephemeral ones, to a file that can be imported
+in any cluster. Each record is a length-delimited ExportedRecord protobuf message.`,
+		RunE: exec,
+	}
+)
+
+func init() {
+	defaultServiceAddress := fmt.Sprintf("localhost:%d", common.DefaultPublicPort)
+	Cmd.Flags().StringVarP(&serviceAddress, "service-address", "a", defaultServiceAddress, "Service address")
+	Cmd.Flags().StringVarP(&namespace, "namespace", "n", oxia.DefaultNamespace, "The namespace to export")
+	Cmd.Flags().StringVarP(&file, "file", "f", "-", "The file where to write the records, or - for the standard output")
+}
+
+func exec(cmd *cobra.Command, _ []string) (err error) {
+	var out io.Writer = cmd.OutOrStdout()
+	if file != "-" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			err = multierr.Append(err, f.Close())
+		}()
+		out = f
+	}
+
+	clientPool := common.NewClientPool(nil, nil)
+	defer clientPool.Close()
+
+	w := bufio.NewWriter(out)
+	count, err := backup.Export(context.Background(), clientPool, serviceAddress, namespace, w)
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	slog.Info(
+		"Export completed",
+		slog.String("namespace", namespace),
+		slog.Int64("records", count),
+	)
+	return nil
+}
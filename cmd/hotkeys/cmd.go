@@ -0,0 +1,93 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hotkeys
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/backup"
+)
+
+var (
+	serviceAddress string
+	namespace      string
+	limit          uint32
+
+	Cmd = &cobra.Command{
+		Use:   "hot-keys",
+		Short: "Show the hot keys of a namespace",
+		Long: `Show the keys and the key prefixes of each shard of a namespace that were accessed the most in the last
+minutes. The number of operations is estimated from the reads and the writes sampled by the leaders.`,
+		RunE: exec,
+	}
+)
+
+func init() {
+	defaultServiceAddress := fmt.Sprintf("localhost:%d", common.DefaultPublicPort)
+	Cmd.Flags().StringVarP(&serviceAddress, "service-address", "a", defaultServiceAddress, "Service address")
+	Cmd.Flags().StringVarP(&namespace, "namespace", "n", oxia.DefaultNamespace, "The namespace to inspect")
+	Cmd.Flags().Uint32Var(&limit, "limit", 10, "The max number of keys and of prefixes to show for each shard")
+}
+
+func exec(cmd *cobra.Command, _ []string) error {
+	clientPool := common.NewClientPool(nil, nil)
+	defer clientPool.Close()
+
+	ctx := context.Background()
+	assignments, err := backup.GetShardAssignments(ctx, clientPool, serviceAddress, namespace)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SHARD\tTYPE\tKEY\tREADS\tWRITES\tSHARE")
+	for _, assignment := range assignments {
+		rpc, err := clientPool.GetAdminRpc(assignment.Leader)
+		if err != nil {
+			return err
+		}
+
+		res, err := rpc.GetHotKeys(ctx, &proto.GetHotKeysRequest{
+			Namespace: namespace,
+			Shard:     assignment.Shard,
+			Limit:     limit,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the hot keys of shard %d", assignment.Shard)
+		}
+
+		printHotKeys(w, assignment.Shard, "key", res.Keys, res.Reads+res.Writes)
+		printHotKeys(w, assignment.Shard, "prefix", res.Prefixes, res.Reads+res.Writes)
+	}
+	return w.Flush()
+}
+
+func printHotKeys(w *tabwriter.Writer, shard int64, keyType string, hotKeys []*proto.HotKey, total int64) {
+	for _, hk := range hotKeys {
+		var share float64
+		if total > 0 {
+			share = 100 * float64(hk.Reads+hk.Writes) / float64(total)
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%.1f%%\n", shard, keyType, hk.Key, hk.Reads, hk.Writes, share)
+	}
+}
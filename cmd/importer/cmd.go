@@ -0,0 +1,82 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/server/backup"
+)
+
+var (
+	serviceAddress string
+	namespace      string
+	file           string
+
+	Cmd = &cobra.Command{
+		Use:   "import",
+		Short: "Import records in a namespace",
+		Long: `Import the records written by the export command. The namespace can have a different number of shards
+than the exported one. The records get new versions, while their partition keys and secondary indexes are kept.`,
+		RunE: exec,
+	}
+)
+
+func init() {
+	defaultServiceAddress := fmt.Sprintf("localhost:%d", common.DefaultPublicPort)
+	Cmd.Flags().StringVarP(&serviceAddress, "service-address", "a", defaultServiceAddress, "Service address")
+	Cmd.Flags().StringVarP(&namespace, "namespace", "n", oxia.DefaultNamespace, "The namespace where to import the records")
+	Cmd.Flags().StringVarP(&file, "file", "f", "-", "The file with the records, or - for the standard input")
+}
+
+func exec(cmd *cobra.Command, _ []string) (err error) {
+	var in io.Reader = cmd.InOrStdin()
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	client, err := oxia.NewAsyncClient(serviceAddress, oxia.WithNamespace(namespace))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Append(err, client.Close())
+	}()
+
+	count, err := backup.Import(client, in)
+	if err != nil {
+		return err
+	}
+
+	slog.Info(
+		"Import completed",
+		slog.String("namespace", namespace),
+		slog.Int64("records", count),
+	)
+	return nil
+}
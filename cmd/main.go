@@ -22,12 +22,20 @@ import (
 	"github.com/spf13/cobra"
 	"go.uber.org/automaxprocs/maxprocs"
 
+	"github.com/streamnative/oxia/cmd/backup"
+	"github.com/streamnative/oxia/cmd/bulkload"
 	"github.com/streamnative/oxia/cmd/client"
 	"github.com/streamnative/oxia/cmd/coordinator"
+	"github.com/streamnative/oxia/cmd/export"
 	"github.com/streamnative/oxia/cmd/health"
+	"github.com/streamnative/oxia/cmd/hotkeys"
+	"github.com/streamnative/oxia/cmd/importer"
 	"github.com/streamnative/oxia/cmd/pebble"
 	"github.com/streamnative/oxia/cmd/perf"
+	"github.com/streamnative/oxia/cmd/replicator"
+	"github.com/streamnative/oxia/cmd/restore"
 	"github.com/streamnative/oxia/cmd/server"
+	"github.com/streamnative/oxia/cmd/slowops"
 	"github.com/streamnative/oxia/cmd/standalone"
 	"github.com/streamnative/oxia/cmd/wal"
 	"github.com/streamnative/oxia/common"
@@ -64,6 +72,14 @@ func init() {
 	rootCmd.AddCommand(standalone.Cmd)
 	rootCmd.AddCommand(pebble.Cmd)
 	rootCmd.AddCommand(wal.Cmd)
+	rootCmd.AddCommand(backup.Cmd)
+	rootCmd.AddCommand(restore.Cmd)
+	rootCmd.AddCommand(export.Cmd)
+	rootCmd.AddCommand(importer.Cmd)
+	rootCmd.AddCommand(bulkload.Cmd)
+	rootCmd.AddCommand(replicator.Cmd)
+	rootCmd.AddCommand(hotkeys.Cmd)
+	rootCmd.AddCommand(slowops.Cmd)
 }
 
 func configureLogLevel(_ *cobra.Command, _ []string) error {
@@ -0,0 +1,62 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replicator
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/streamnative/oxia/cmd/flag"
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/server/replicator"
+)
+
+var (
+	options            = replicator.Options{}
+	conflictResolution string
+
+	Cmd = &cobra.Command{
+		Use:   "replicator",
+		Short: "Replicate a namespace to another cluster",
+		Long: `Asynchronously replicate the records of a namespace to a namespace of another cluster, to keep a warm
+standby. The notifications must be enabled in the source namespace. The offsets of the replicated changes are
+stored in the checkpoint file, from where the replication resumes after a restart.`,
+		Run: exec,
+	}
+)
+
+func init() {
+	defaultServiceAddress := fmt.Sprintf("localhost:%d", common.DefaultPublicPort)
+	Cmd.Flags().StringVar(&options.SourceAddress, "source-address", defaultServiceAddress, "Service address of the source cluster")
+	Cmd.Flags().StringVar(&options.SourceNamespace, "source-namespace", oxia.DefaultNamespace, "The namespace to replicate")
+	Cmd.Flags().StringVar(&options.TargetAddress, "target-address", "", "Service address of the target cluster")
+	Cmd.Flags().StringVar(&options.TargetNamespace, "target-namespace", oxia.DefaultNamespace, "The namespace where to replicate the records")
+	Cmd.Flags().StringVar(&options.CheckpointFile, "checkpoint-file", "./data/replicator-checkpoint.json", "The file where to store the replicated offsets")
+	Cmd.Flags().StringVar(&conflictResolution, "conflict-resolution", string(replicator.LastWriterWins),
+		`How to handle the records modified in the target: "last-writer-wins" or "source-wins"`)
+	Cmd.Flags().StringVar(&options.Identity, "identity", replicator.DefaultIdentity, "The identity recorded in the replicated records")
+	flag.MetricsAddr(Cmd, &options.MetricsServiceAddr)
+	_ = Cmd.MarkFlagRequired("target-address")
+}
+
+func exec(*cobra.Command, []string) {
+	options.ConflictResolution = replicator.ConflictResolution(conflictResolution)
+	common.RunProcess(func() (io.Closer, error) {
+		return replicator.New(options)
+	})
+}
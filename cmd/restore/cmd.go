@@ -0,0 +1,81 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"math"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/server/backup"
+	"github.com/streamnative/oxia/server/encryption"
+	"github.com/streamnative/oxia/server/wal"
+)
+
+var (
+	options = backup.RestoreOptions{}
+
+	toTimestamp       string
+	walOffloadDir     string
+	encryptionKeyFile string
+
+	Cmd = &cobra.Command{
+		Use:   "restore",
+		Short: "Restore a namespace",
+		Long: `Restore the shards of a namespace from a backup, replaying the write-ahead-log entries up to a point in time.
+The storage node that owns the data directory must be stopped.`,
+		RunE: exec,
+	}
+)
+
+func init() {
+	Cmd.Flags().StringVar(&options.BackupDir, "backup-dir", "", "Directory with the backup")
+	Cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", oxia.DefaultNamespace, "The namespace to restore")
+	Cmd.Flags().StringVar(&options.DataDir, "data-dir", "./data/db", "Directory where to restore the data")
+	Cmd.Flags().StringVar(&options.WalDir, "wal-dir", "./data/wal", "Directory of the write-ahead-logs to replay")
+	Cmd.Flags().StringVar(&walOffloadDir, "wal-offload-dir", "", "Directory of the offloaded write-ahead-log segments to replay")
+	Cmd.Flags().Int64Var(&options.ToOffset, "to-offset", math.MaxInt64, "Replay the entries up to this offset")
+	Cmd.Flags().StringVar(&toTimestamp, "to-timestamp", "", "Replay the entries up to this time, in RFC3339 format")
+	Cmd.Flags().StringVar(&encryptionKeyFile, "encryption-key-file", "", "File with the keys of the encrypted data")
+
+	if err := Cmd.MarkFlagRequired("backup-dir"); err != nil {
+		panic(err)
+	}
+}
+
+func exec(*cobra.Command, []string) error {
+	var err error
+	if toTimestamp != "" {
+		if options.ToTimestamp, err = time.Parse(time.RFC3339, toTimestamp); err != nil {
+			return err
+		}
+	}
+
+	if walOffloadDir != "" {
+		if options.WalObjectStore, err = wal.NewFileObjectStore(walOffloadDir); err != nil {
+			return err
+		}
+	}
+
+	if encryptionKeyFile != "" {
+		if options.KeyProvider, err = encryption.NewFileKeyProvider(encryptionKeyFile); err != nil {
+			return err
+		}
+	}
+
+	return backup.Restore(options)
+}
@@ -54,10 +54,30 @@ func init() {
 	Cmd.Flags().DurationVar(&conf.NotificationsRetentionTime, "notifications-retention-time", 1*time.Hour, "Retention time for the db notifications to clients")
 
 	Cmd.Flags().BoolVar(&conf.WalSyncData, "wal-sync-data", true, "Whether to sync data in write-ahead-log")
+	Cmd.Flags().Var(&conf.WalCompression, "wal-compression", `Compression for the write-ahead-log records: "none", "zstd" or "snappy"`)
+	Cmd.Flags().StringVar(&conf.EncryptionKeyFile, "encryption-key-file", "", "File with the keys to encrypt the data at rest, one base64 encoded 256 bits key per line. The first key is the active one")
+	Cmd.Flags().StringVar(&conf.WalOffloadDir, "wal-offload-dir", "", "Directory where the write-ahead-log segments are offloaded before being trimmed, so that they can still be read")
+	Cmd.Flags().Var(&conf.ReplicationCompression, "replication-compression", `Compression for the replication and snapshot streams sent to the followers: "none", "zstd" or "snappy"`)
 	Cmd.Flags().Int64Var(&conf.DbBlockCacheMB, "db-cache-size-mb", kv.DefaultFactoryOptions.CacheSizeMB,
 		"Max size of the shared DB cache")
-	Cmd.Flags().StringVar(&conf.AuthOptions.ProviderName, "auth-provider-name", "", "Authentication provider name. supported: oidc")
-	Cmd.Flags().StringVar(&conf.AuthOptions.ProviderParams, "auth-provider-params", "", "Authentication provider params. \n oidc: "+"{\"allowedIssueURLs\":\"required1,required2\",\"allowedAudiences\":\"required1,required2\",\"userNameClaim\":\"optional(default:sub)\"}")
+	Cmd.Flags().StringVar(&conf.AuthOptions.ProviderName, "auth-provider-name", "", "Authentication provider name. supported: oidc, mtls, jwt, static")
+	Cmd.Flags().StringVar(&conf.AuthOptions.ProviderParams, "auth-provider-params", "", "Authentication provider params. \n oidc: "+"{\"allowedIssueURLs\":\"required1,required2\",\"allowedAudiences\":\"required1,required2\",\"userNameClaim\":\"optional(default:sub)\"}"+
+		"\n mtls: {\"userNameField\":\"optional cn|dns|uri|email (default:cn)\",\"userNamePattern\":\"optional regexp, the first group is the user name\"}"+
+		"\n jwt: {\"publicKeyFiles\":\"file1,file2\",\"jwksFiles\":\"file1,file2\",\"allowedIssuers\":\"optional1,optional2\",\"allowedAudiences\":\"required1,required2\",\"userNameClaim\":\"optional(default:sub)\"}"+
+		"\n static: {\"tokens\":{\"token1\":\"user1\",\"token2\":\"user2\"}}")
+	Cmd.Flags().StringVar(&conf.AuthOptions.PolicyFile, "auth-policy-file", "", "Authorization policy file, with the roles of the authenticated users. It's reloaded when it changes")
+	Cmd.Flags().BoolVar(&conf.AuthOptions.RejectIdentityMismatch, "auth-reject-identity-mismatch", false, "Reject the writes and the sessions whose client identity isn't the authenticated user, instead of replacing it")
+	Cmd.Flags().StringVar(&conf.AuthOptions.Audit.File, "audit-log-file", "", "File where the mutating and the admin operations are recorded, with their principal. The audit log is disabled when it's not set")
+	Cmd.Flags().Int64Var(&conf.AuthOptions.Audit.MaxFileSize, "audit-log-max-file-size", 0, "Size in bytes after which the audit log file is rotated (default 100MB)")
+	Cmd.Flags().IntVar(&conf.AuthOptions.Audit.MaxFiles, "audit-log-max-files", 0, "Max number of rotated audit log files to keep (default 10)")
+	Cmd.Flags().Float64Var(&conf.RateLimit.ReadsPerSecond, "rate-limit-reads", 0, "Max read operations per second of each client on each namespace. Unlimited when it's 0")
+	Cmd.Flags().Float64Var(&conf.RateLimit.WritesPerSecond, "rate-limit-writes", 0, "Max write operations per second of each client on each namespace. Unlimited when it's 0")
+	Cmd.Flags().Float64Var(&conf.RateLimit.NotificationsPerSecond, "rate-limit-notifications", 0, "Max new notifications streams per second of each client on each namespace. Unlimited when it's 0")
+	Cmd.Flags().Int64Var(&conf.WriteBackpressure.MaxPendingWrites, "backpressure-max-pending-writes", 0, "Max writes of a shard appended to the write-ahead-log and not yet completed, beyond which the leader rejects the new writes. Disabled when it's 0")
+	Cmd.Flags().Int64Var(&conf.WriteBackpressure.MaxFollowerLag, "backpressure-max-follower-lag", 0, "Max entries that the followers needed for the quorum can be behind the leader, beyond which the leader rejects the new writes. Disabled when it's 0")
+	Cmd.Flags().DurationVar(&conf.WriteBackpressure.MaxQuorumAckLatency, "backpressure-max-quorum-ack-latency", 0, "Max average time for the writes to be acknowledged by the quorum, beyond which the leader rejects the new writes. Disabled when it's 0")
+	Cmd.Flags().Float64Var(&conf.HotKeysSampleRate, "hot-keys-sample-rate", 0.01, "Fraction of the reads and the writes sampled to find the hot keys of each shard. Disabled when it's 0")
+	Cmd.Flags().DurationVar(&conf.SlowOperationThreshold, "slow-operation-threshold", 1*time.Second, "The reads, writes, lists, range-scans and notification dispatches of the leaders that take longer than this are logged. Disabled when it's 0")
 
 	// server TLS section
 	Cmd.Flags().StringVar(&serverTLS.CertFile, "tls-cert-file", "", "Tls certificate file")
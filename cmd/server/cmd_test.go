@@ -41,6 +41,8 @@ func TestServerCmd(t *testing.T) {
 			WalSyncData:                true,
 			NotificationsRetentionTime: 1 * time.Hour,
 			DbBlockCacheMB:             100,
+			HotKeysSampleRate:          0.01,
+			SlowOperationThreshold:     1 * time.Second,
 		}, false},
 	} {
 		t.Run(strings.Join(test.args, "_"), func(t *testing.T) {
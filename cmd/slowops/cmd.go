@@ -0,0 +1,103 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slowops
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/backup"
+)
+
+var (
+	serviceAddress string
+	namespace      string
+	limit          uint32
+
+	Cmd = &cobra.Command{
+		Use:   "slow-operations",
+		Short: "Show the slow operations of a namespace",
+		Long: `Show the last reads, writes, lists, range-scans and notification dispatches of each shard of a namespace
+that took longer than the slow operation threshold of the leaders, with the time spent by the writes in each stage
+and the error of the failed operations.`,
+		RunE: exec,
+	}
+)
+
+func init() {
+	defaultServiceAddress := fmt.Sprintf("localhost:%d", common.DefaultPublicPort)
+	Cmd.Flags().StringVarP(&serviceAddress, "service-address", "a", defaultServiceAddress, "Service address")
+	Cmd.Flags().StringVarP(&namespace, "namespace", "n", oxia.DefaultNamespace, "The namespace to inspect")
+	Cmd.Flags().Uint32Var(&limit, "limit", 20, "The max number of operations to show for each shard, or all the retained ones when 0")
+}
+
+func exec(cmd *cobra.Command, _ []string) error {
+	clientPool := common.NewClientPool(nil, nil)
+	defer clientPool.Close()
+
+	ctx := context.Background()
+	assignments, err := backup.GetShardAssignments(ctx, clientPool, serviceAddress, namespace)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SHARD\tTIME\tTYPE\tDURATION\tKEY-START\tKEY-END\tRESULTS\tBYTES\tWAL-APPEND\tQUORUM-WAIT\tDB-APPLY\tERROR")
+	for _, assignment := range assignments {
+		rpc, err := clientPool.GetAdminRpc(assignment.Leader)
+		if err != nil {
+			return err
+		}
+
+		res, err := rpc.GetSlowOperations(ctx, &proto.GetSlowOperationsRequest{
+			Namespace: namespace,
+			Shard:     assignment.Shard,
+			Limit:     limit,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the slow operations of shard %d", assignment.Shard)
+		}
+
+		for _, op := range res.Operations {
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%v\t%s\t%s\t%d\t%d\t%v\t%v\t%v\t%s\n",
+				assignment.Shard,
+				time.UnixMilli(int64(op.Timestamp)).Format(time.RFC3339Nano),
+				op.Type,
+				micros(op.DurationMicros),
+				op.KeyStart,
+				op.KeyEnd,
+				op.Results,
+				op.Bytes,
+				micros(op.WalAppendMicros),
+				micros(op.QuorumWaitMicros),
+				micros(op.DbApplyMicros),
+				op.Error,
+			)
+		}
+	}
+	return w.Flush()
+}
+
+func micros(d int64) time.Duration {
+	return time.Duration(d) * time.Microsecond
+}
@@ -45,6 +45,9 @@ func init() {
 	Cmd.Flags().StringVar(&conf.WalDir, "wal-dir", "./data/wal", "Directory for write-ahead-logs")
 	Cmd.Flags().DurationVar(&conf.WalRetentionTime, "wal-retention-time", 1*time.Hour, "Retention time for the entries in the write-ahead-log")
 	Cmd.Flags().BoolVar(&conf.WalSyncData, "wal-sync-data", true, "Whether to sync data in write-ahead-log")
+	Cmd.Flags().Var(&conf.WalCompression, "wal-compression", `Compression for the write-ahead-log records: "none", "zstd" or "snappy"`)
+	Cmd.Flags().StringVar(&conf.EncryptionKeyFile, "encryption-key-file", "", "File with the keys to encrypt the data at rest, one base64 encoded 256 bits key per line. The first key is the active one")
+	Cmd.Flags().StringVar(&conf.WalOffloadDir, "wal-offload-dir", "", "Directory where the write-ahead-log segments are offloaded before being trimmed, so that they can still be read")
 
 	Cmd.Flags().BoolVar(&conf.NotificationsEnabled, "notifications-enabled", true, "Whether notifications are enabled")
 	Cmd.Flags().DurationVar(&conf.NotificationsRetentionTime, "notifications-retention-time", 1*time.Hour, "Retention time for the db notifications to clients")
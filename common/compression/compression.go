@@ -0,0 +1,211 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compression registers the gRPC compressors used on the streams
+// between the servers. Importing it is enough for a server to accept
+// compressed streams.
+package compression
+
+import (
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+)
+
+// Compression is the gRPC compressor used on a stream.
+type Compression string
+
+const (
+	None   Compression = "none"
+	Zstd   Compression = "zstd"
+	Snappy Compression = "snappy"
+)
+
+func (c *Compression) String() string {
+	return string(*c)
+}
+
+func (c *Compression) Set(s string) error {
+	switch Compression(s) {
+	case None, Zstd, Snappy:
+		*c = Compression(s)
+		return nil
+	default:
+		return errors.New(`must be one of "none", "zstd" or "snappy"`)
+	}
+}
+
+func (*Compression) Type() string {
+	return "Compression"
+}
+
+func (c Compression) Enabled() bool {
+	return c == Zstd || c == Snappy
+}
+
+// CallOptions returns the options to compress the messages sent on a call.
+func (c Compression) CallOptions() []grpc.CallOption {
+	if !c.Enabled() {
+		return nil
+	}
+	return []grpc.CallOption{grpc.UseCompressor(string(c))}
+}
+
+// IsNotSupported tells whether the call failed because the peer does not know
+// the compressor, which happens with servers running an older version.
+func IsNotSupported(err error) bool {
+	s, ok := status.FromError(err)
+	return ok && s.Code() == codes.Unimplemented &&
+		strings.Contains(s.Message(), "Decompressor is not installed")
+}
+
+func init() {
+	encoding.RegisterCompressor(&zstdCompressor{})
+	encoding.RegisterCompressor(&snappyCompressor{})
+}
+
+// The encoders and decoders are pooled, since they are expensive to create
+// and there is one per message.
+
+type zstdCompressor struct {
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+func (*zstdCompressor) Name() string {
+	return string(Zstd)
+}
+
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	if e, ok := c.encoders.Get().(*zstd.Encoder); ok {
+		e.Reset(w)
+		return &zstdWriter{Encoder: e, pool: &c.encoders}, nil
+	}
+
+	e, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1), zstd.WithEncoderLevel(zstd.SpeedFastest))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdWriter{Encoder: e, pool: &c.encoders}, nil
+}
+
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	if d, ok := c.decoders.Get().(*zstd.Decoder); ok {
+		if err := d.Reset(r); err != nil {
+			c.decoders.Put(d)
+			return nil, err
+		}
+		return &zstdReader{Decoder: d, pool: &c.decoders}, nil
+	}
+
+	d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdReader{Decoder: d, pool: &c.decoders}, nil
+}
+
+type zstdWriter struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (w *zstdWriter) Close() error {
+	defer w.pool.Put(w.Encoder)
+	return w.Encoder.Close()
+}
+
+type zstdReader struct {
+	*zstd.Decoder
+	pool *sync.Pool
+}
+
+func (r *zstdReader) Read(p []byte) (n int, err error) {
+	if r.Decoder == nil {
+		return 0, io.EOF
+	}
+
+	n, err = r.Decoder.Read(p)
+	if errors.Is(err, io.EOF) {
+		// The message was fully read, the decoder can be reused
+		r.pool.Put(r.Decoder)
+		r.Decoder = nil
+	}
+	return n, err
+}
+
+type snappyCompressor struct {
+	writers sync.Pool
+	readers sync.Pool
+}
+
+func (*snappyCompressor) Name() string {
+	return string(Snappy)
+}
+
+func (c *snappyCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	sw, ok := c.writers.Get().(*s2.Writer)
+	if ok {
+		sw.Reset(w)
+	} else {
+		sw = s2.NewWriter(w, s2.WriterSnappyCompat(), s2.WriterConcurrency(1))
+	}
+	return &snappyWriter{Writer: sw, pool: &c.writers}, nil
+}
+
+func (c *snappyCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	sr, ok := c.readers.Get().(*s2.Reader)
+	if ok {
+		sr.Reset(r)
+	} else {
+		sr = s2.NewReader(r)
+	}
+	return &snappyReader{Reader: sr, pool: &c.readers}, nil
+}
+
+type snappyWriter struct {
+	*s2.Writer
+	pool *sync.Pool
+}
+
+func (w *snappyWriter) Close() error {
+	defer w.pool.Put(w.Writer)
+	return w.Writer.Close()
+}
+
+type snappyReader struct {
+	*s2.Reader
+	pool *sync.Pool
+}
+
+func (r *snappyReader) Read(p []byte) (n int, err error) {
+	if r.Reader == nil {
+		return 0, io.EOF
+	}
+
+	n, err = r.Reader.Read(p)
+	if errors.Is(err, io.EOF) {
+		r.pool.Put(r.Reader)
+		r.Reader = nil
+	}
+	return n, err
+}
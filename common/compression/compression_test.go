@@ -0,0 +1,77 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+)
+
+func TestCompressor(t *testing.T) {
+	payload := bytes.Repeat([]byte("oxia-replication-"), 1000)
+
+	for _, c := range []Compression{Zstd, Snappy} {
+		t.Run(string(c), func(t *testing.T) {
+			compressor := encoding.GetCompressor(string(c))
+			assert.NotNil(t, compressor)
+
+			// The pooled encoders and decoders are reused across messages
+			for i := 0; i < 3; i++ {
+				buf := &bytes.Buffer{}
+				w, err := compressor.Compress(buf)
+				assert.NoError(t, err)
+				_, err = w.Write(payload)
+				assert.NoError(t, err)
+				assert.NoError(t, w.Close())
+				assert.Less(t, buf.Len(), len(payload))
+
+				r, err := compressor.Decompress(buf)
+				assert.NoError(t, err)
+				res, err := io.ReadAll(r)
+				assert.NoError(t, err)
+				assert.Equal(t, payload, res)
+			}
+		})
+	}
+}
+
+func TestCompression_Set(t *testing.T) {
+	var c Compression
+	assert.NoError(t, c.Set("zstd"))
+	assert.Equal(t, Zstd, c)
+	assert.True(t, c.Enabled())
+	assert.Len(t, c.CallOptions(), 1)
+
+	assert.NoError(t, c.Set("none"))
+	assert.False(t, c.Enabled())
+	assert.Empty(t, c.CallOptions())
+
+	assert.Error(t, c.Set("gzip"))
+	assert.Equal(t, None, c)
+}
+
+func TestIsNotSupported(t *testing.T) {
+	assert.True(t, IsNotSupported(status.Error(codes.Unimplemented,
+		`grpc: Decompressor is not installed for grpc-encoding "zstd"`)))
+	assert.False(t, IsNotSupported(status.Error(codes.Unimplemented, "unknown method")))
+	assert.False(t, IsNotSupported(io.EOF))
+	assert.False(t, IsNotSupported(nil))
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	grpcprometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
@@ -61,9 +62,10 @@ func (*defaultProvider) StartGrpcServer(name, bindAddress string, registerFunc f
 
 type defaultGrpcServer struct {
 	io.Closer
-	server *grpc.Server
-	port   int
-	log    *slog.Logger
+	server   *grpc.Server
+	auditLog *auth.AuditLog
+	port     int
+	log      *slog.Logger
 }
 
 func newDefaultGrpcProvider(name, bindAddress string, registerFunc func(grpc.ServiceRegistrar),
@@ -86,6 +88,10 @@ func newDefaultGrpcProvider(name, bindAddress string, registerFunc func(grpc.Ser
 				slog.Any("error", err))
 			return nil, err
 		}
+		if provider.AcceptParamType() == auth.ProviderParamTypeCertificate &&
+			(tlsConf == nil || tlsConf.ClientAuth < tls.VerifyClientCertIfGiven) {
+			return nil, errors.New("the mtls authentication requires TLS with the verification of the client certificates")
+		}
 		delegator, err := auth.NewGrpcAuthenticationDelegator(provider)
 		if err != nil {
 			slog.Error("Failed to init grpc authentication delegator",
@@ -97,7 +103,18 @@ func newDefaultGrpcProvider(name, bindAddress string, registerFunc func(grpc.Ser
 		streamInterceptors = append(streamInterceptors, delegator.GetStreamInterceptor())
 	}
 
+	var auditLog *auth.AuditLog
+	if authOptions != nil && authOptions.Audit.IsEnabled() {
+		var err error
+		if auditLog, err = auth.NewAuditLog(authOptions.Audit); err != nil {
+			return nil, err
+		}
+		unaryInterceptors = append(unaryInterceptors, auditLog.GetAuditUnaryInterceptor())
+		streamInterceptors = append(streamInterceptors, auditLog.GetAuditStreamInterceptor())
+	}
+
 	c := &defaultGrpcServer{
+		auditLog: auditLog,
 		server: grpc.NewServer(
 			grpc.Creds(tcs),
 			grpc.ChainStreamInterceptor(streamInterceptors...),
@@ -114,6 +131,7 @@ func newDefaultGrpcProvider(name, bindAddress string, registerFunc func(grpc.Ser
 
 	listener, err := net.Listen("tcp", bindAddress)
 	if err != nil {
+		_ = auditLog.Close()
 		return nil, err
 	}
 
@@ -153,5 +171,5 @@ func (c *defaultGrpcServer) Port() int {
 func (c *defaultGrpcServer) Close() error {
 	c.server.GracefulStop()
 	c.log.Info("Stopped Grpc server")
-	return nil
+	return c.auditLog.Close()
 }
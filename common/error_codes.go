@@ -20,31 +20,35 @@ import (
 )
 
 const (
-	CodeNotInitialized          codes.Code = 100
-	CodeInvalidTerm             codes.Code = 101
-	CodeInvalidStatus           codes.Code = 102
-	CodeCancelled               codes.Code = 103
-	CodeAlreadyClosed           codes.Code = 104
-	CodeLeaderAlreadyConnected  codes.Code = 105
-	CodeNodeIsNotLeader         codes.Code = 106
-	CodeNodeIsNotFollower       codes.Code = 107
-	CodeSessionNotFound         codes.Code = 108
-	CodeInvalidSessionTimeout   codes.Code = 109
-	CodeNamespaceNotFound       codes.Code = 110
-	CodeNotificationsNotEnabled codes.Code = 111
+	CodeNotInitialized           codes.Code = 100
+	CodeInvalidTerm              codes.Code = 101
+	CodeInvalidStatus            codes.Code = 102
+	CodeCancelled                codes.Code = 103
+	CodeAlreadyClosed            codes.Code = 104
+	CodeLeaderAlreadyConnected   codes.Code = 105
+	CodeNodeIsNotLeader          codes.Code = 106
+	CodeNodeIsNotFollower        codes.Code = 107
+	CodeSessionNotFound          codes.Code = 108
+	CodeInvalidSessionTimeout    codes.Code = 109
+	CodeNamespaceNotFound        codes.Code = 110
+	CodeNotificationsNotEnabled  codes.Code = 111
+	CodeHotKeysNotEnabled        codes.Code = 112
+	CodeSlowOperationsNotEnabled codes.Code = 113
 )
 
 var (
-	ErrorNotInitialized          = status.Error(CodeNotInitialized, "oxia: server not initialized yet")
-	ErrorCancelled               = status.Error(CodeCancelled, "oxia: operation was cancelled")
-	ErrorInvalidTerm             = status.Error(CodeInvalidTerm, "oxia: invalid term")
-	ErrorInvalidStatus           = status.Error(CodeInvalidStatus, "oxia: invalid status")
-	ErrorLeaderAlreadyConnected  = status.Error(CodeLeaderAlreadyConnected, "oxia: leader is already connected")
-	ErrorAlreadyClosed           = status.Error(CodeAlreadyClosed, "oxia: resource is already closed")
-	ErrorNodeIsNotLeader         = status.Error(CodeNodeIsNotLeader, "oxia: node is not leader for shard")
-	ErrorNodeIsNotFollower       = status.Error(CodeNodeIsNotFollower, "oxia: node is not follower for shard")
-	ErrorSessionNotFound         = status.Error(CodeSessionNotFound, "oxia: session not found")
-	ErrorInvalidSessionTimeout   = status.Error(CodeInvalidSessionTimeout, "oxia: invalid session timeout")
-	ErrorNamespaceNotFound       = status.Error(CodeNamespaceNotFound, "oxia: namespace not found")
-	ErrorNotificationsNotEnabled = status.Error(CodeNotificationsNotEnabled, "oxia: notifications not enabled on namespace")
+	ErrorNotInitialized           = status.Error(CodeNotInitialized, "oxia: server not initialized yet")
+	ErrorCancelled                = status.Error(CodeCancelled, "oxia: operation was cancelled")
+	ErrorInvalidTerm              = status.Error(CodeInvalidTerm, "oxia: invalid term")
+	ErrorInvalidStatus            = status.Error(CodeInvalidStatus, "oxia: invalid status")
+	ErrorLeaderAlreadyConnected   = status.Error(CodeLeaderAlreadyConnected, "oxia: leader is already connected")
+	ErrorAlreadyClosed            = status.Error(CodeAlreadyClosed, "oxia: resource is already closed")
+	ErrorNodeIsNotLeader          = status.Error(CodeNodeIsNotLeader, "oxia: node is not leader for shard")
+	ErrorNodeIsNotFollower        = status.Error(CodeNodeIsNotFollower, "oxia: node is not follower for shard")
+	ErrorSessionNotFound          = status.Error(CodeSessionNotFound, "oxia: session not found")
+	ErrorInvalidSessionTimeout    = status.Error(CodeInvalidSessionTimeout, "oxia: invalid session timeout")
+	ErrorNamespaceNotFound        = status.Error(CodeNamespaceNotFound, "oxia: namespace not found")
+	ErrorNotificationsNotEnabled  = status.Error(CodeNotificationsNotEnabled, "oxia: notifications not enabled on namespace")
+	ErrorHotKeysNotEnabled        = status.Error(CodeHotKeysNotEnabled, "oxia: hot keys sampling not enabled on server")
+	ErrorSlowOperationsNotEnabled = status.Error(CodeSlowOperationsNotEnabled, "oxia: slow operation log not enabled on server")
 )
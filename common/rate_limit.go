@@ -0,0 +1,94 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/streamnative/oxia/proto"
+)
+
+// NewRateLimitedError returns the error of a request that was throttled, with
+// the time after which the client can retry it.
+func NewRateLimitedError(operation string, retryAfter time.Duration) error {
+	return newRetryAfterError(fmt.Sprintf("oxia: %s rate limit exceeded", operation), retryAfter)
+}
+
+// NewOverloadedError returns the error of a write that was rejected because
+// the leader can't keep up with the writes, with the time after which the
+// client can retry it.
+func NewOverloadedError(retryAfter time.Duration) error {
+	return newRetryAfterError("oxia: the leader is overloaded", retryAfter)
+}
+
+func newRetryAfterError(message string, retryAfter time.Duration) error {
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("%s, retry after %v", message, retryAfter))
+	return withRetryInfo(st, retryAfter).Err()
+}
+
+func withRetryInfo(st *status.Status, retryAfter time.Duration) *status.Status {
+	if withDetails, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryAfter),
+	}); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// NewWriteRejection returns the response to a request of a write stream that
+// was rejected with the error, while the stream is kept open.
+func NewWriteRejection(err error) *proto.WriteResponse {
+	st := status.Convert(err)
+	rejection := &proto.WriteRejection{
+		Code:    uint32(st.Code()),
+		Message: st.Message(),
+	}
+	if retryAfter, ok := RetryAfter(err); ok {
+		// Rounded up, so that the shortest delays are not lost
+		rejection.RetryAfterMillis = max((retryAfter + time.Millisecond - 1).Milliseconds(), 1)
+	}
+	return &proto.WriteResponse{Rejection: rejection}
+}
+
+// WriteRejectionError returns the error of a rejected request of a write
+// stream, with the retry delay suggested by the server, if any.
+func WriteRejectionError(rejection *proto.WriteRejection) error {
+	st := status.New(codes.Code(rejection.Code), rejection.Message)
+	if rejection.RetryAfterMillis > 0 {
+		st = withRetryInfo(st, time.Duration(rejection.RetryAfterMillis)*time.Millisecond)
+	}
+	return st.Err()
+}
+
+// RetryAfter returns the retry delay suggested by the server in the error, if any.
+func RetryAfter(err error) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.ResourceExhausted {
+		return 0, false
+	}
+
+	for _, detail := range st.Details() {
+		if ri, ok := detail.(*errdetails.RetryInfo); ok && ri.RetryDelay != nil {
+			return ri.RetryDelay.AsDuration(), true
+		}
+	}
+	return 0, false
+}
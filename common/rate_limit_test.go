@@ -0,0 +1,66 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryAfter(t *testing.T) {
+	err := NewRateLimitedError("write", 250*time.Millisecond)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	retryAfter, ok := RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 250*time.Millisecond, retryAfter)
+
+	for _, err := range []error{
+		nil,
+		errors.New("error"),
+		status.Error(codes.ResourceExhausted, "message too large"),
+		status.Error(codes.Unavailable, "unavailable"),
+	} {
+		_, ok = RetryAfter(err)
+		assert.False(t, ok, err)
+	}
+}
+
+func TestWriteRejection(t *testing.T) {
+	response := NewWriteRejection(NewRateLimitedError("write", 1500*time.Millisecond))
+	assert.EqualValues(t, codes.ResourceExhausted, response.Rejection.Code)
+	assert.EqualValues(t, 1500, response.Rejection.RetryAfterMillis)
+
+	err := WriteRejectionError(response.Rejection)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), "write rate limit exceeded")
+	retryAfter, ok := RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, retryAfter)
+
+	response = NewWriteRejection(NewOverloadedError(100 * time.Microsecond))
+	assert.EqualValues(t, 1, response.Rejection.RetryAfterMillis)
+
+	response = NewWriteRejection(errors.New("error"))
+	err = WriteRejectionError(response.Rejection)
+	assert.Equal(t, codes.Unknown, status.Code(err))
+	_, ok = RetryAfter(err)
+	assert.False(t, ok)
+}
@@ -15,17 +15,24 @@
 package coordinator
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/multierr"
 
 	"github.com/streamnative/oxia/common"
 	"github.com/streamnative/oxia/common/metrics"
 	"github.com/streamnative/oxia/coordinator/impl"
 	"github.com/streamnative/oxia/coordinator/model"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/server/auth"
 )
 
 type Config struct {
@@ -38,10 +45,19 @@ type Config struct {
 	K8SMetadataNamespace             string
 	K8SMetadataConfigMapName         string
 	FileMetadataPath                 string
+	OxiaMetadataServiceAddress       string
+	OxiaMetadataNamespace            string
+	OxiaMetadataKey                  string
+	LeaderElectionEnabled            bool
+	LeaderElectionId                 string
+	LeaderLeaseDuration              time.Duration
+	AuditOptions                     auth.AuditOptions
 	ClusterConfigProvider            func() (model.ClusterConfig, error) `json:"-"`
 	ClusterConfigChangeNotifications chan any                            `json:"-"`
 }
 
+const leaderElectionRetryDelay = 5 * time.Second
+
 type MetadataProviderImpl string
 
 func (m *MetadataProviderImpl) String() string {
@@ -50,11 +66,11 @@ func (m *MetadataProviderImpl) String() string {
 
 func (m *MetadataProviderImpl) Set(s string) error {
 	switch s {
-	case "memory", "configmap", "file":
+	case "memory", "configmap", "file", "oxia":
 		*m = MetadataProviderImpl(s)
 		return nil
 	default:
-		return errors.New(`must be one of "memory", "configmap" or "file"`)
+		return errors.New(`must be one of "memory", "configmap", "file" or "oxia"`)
 	}
 }
 
@@ -66,6 +82,7 @@ var (
 	Memory    MetadataProviderImpl = "memory"
 	Configmap MetadataProviderImpl = "configmap"
 	File      MetadataProviderImpl = "file"
+	Oxia      MetadataProviderImpl = "oxia"
 )
 
 func NewConfig() Config {
@@ -77,10 +94,16 @@ func NewConfig() Config {
 }
 
 type Coordinator struct {
+	sync.Mutex
 	coordinator impl.Coordinator
+	election    impl.LeaderElection
 	clientPool  common.ClientPool
 	rpcServer   *rpcServer
 	metrics     *metrics.PrometheusMetrics
+	auditLog    *auth.AuditLog
+
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func New(config Config) (*Coordinator, error) {
@@ -92,6 +115,7 @@ func New(config Config) (*Coordinator, error) {
 	s := &Coordinator{
 		clientPool: common.NewClientPool(config.PeerTLS, nil),
 	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
 
 	var metadataProvider impl.MetadataProvider
 	switch config.MetadataProviderImpl {
@@ -103,12 +127,37 @@ func New(config Config) (*Coordinator, error) {
 		k8sConfig := impl.NewK8SClientConfig()
 		metadataProvider = impl.NewMetadataProviderConfigMap(impl.NewK8SClientset(k8sConfig),
 			config.K8SMetadataNamespace, config.K8SMetadataConfigMapName)
+	case Oxia:
+		client, err := newOxiaMetadataClient(config)
+		if err != nil {
+			return nil, err
+		}
+		metadataProvider = impl.NewMetadataProviderOxia(client, config.OxiaMetadataKey)
 	}
 
 	rpcClient := impl.NewRpcProvider(s.clientPool)
 
 	var err error
-	if s.coordinator, err = impl.NewCoordinator(metadataProvider, config.ClusterConfigProvider, config.ClusterConfigChangeNotifications, rpcClient); err != nil {
+	if s.auditLog, err = auth.NewAuditLog(config.AuditOptions); err != nil {
+		return nil, err
+	}
+
+	if config.LeaderElectionEnabled {
+		id := config.LeaderElectionId
+		if id == "" {
+			id = defaultLeaderElectionId()
+		}
+
+		s.election = impl.NewLeaderElection(metadataProvider, id, config.LeaderLeaseDuration)
+		go common.DoWithLabels(
+			s.ctx,
+			map[string]string{
+				"oxia": "coordinator-leader-election",
+			},
+			func() { s.runLeaderElection(config, rpcClient) },
+		)
+	} else if s.coordinator, err = impl.NewCoordinator(exclusiveMetadataProvider{metadataProvider}, config.ClusterConfigProvider,
+		config.ClusterConfigChangeNotifications, rpcClient, s.auditLog); err != nil {
 		return nil, err
 	}
 
@@ -123,11 +172,146 @@ func New(config Config) (*Coordinator, error) {
 	return s, nil
 }
 
+// Only the elected coordinator is active, while the others wait in standby
+// ready to take over when the leader goes away.
+func (s *Coordinator) runLeaderElection(config Config, rpcClient impl.RpcProvider) {
+	// The config changes are only consumed while being the leader
+	clusterConfigChangeCh := make(chan any, 1)
+	if config.ClusterConfigChangeNotifications != nil {
+		go s.forwardClusterConfigChanges(config.ClusterConfigChangeNotifications, clusterConfigChangeCh)
+	}
+
+	for {
+		if err := s.election.WaitForLeadership(s.ctx); err != nil {
+			return
+		}
+
+		lost := s.election.LeadershipLost()
+		c, err := impl.NewCoordinator(s.election, config.ClusterConfigProvider, clusterConfigChangeCh, rpcClient, s.auditLog)
+		if err != nil {
+			slog.Error(
+				"Failed to start the coordinator after being elected",
+				slog.Any("error", err),
+			)
+			if err := s.election.Resign(); err != nil {
+				slog.Warn("Failed to resign the coordinator leadership", slog.Any("error", err))
+			}
+
+			// Give the other coordinators the chance to take over
+			select {
+			case <-time.After(leaderElectionRetryDelay):
+				continue
+			case <-s.ctx.Done():
+				return
+			}
+		}
+
+		if !s.setCoordinator(c) {
+			_ = c.Close()
+			return
+		}
+
+		select {
+		case <-lost:
+			slog.Info("Stepping down as leader coordinator")
+			if s.setCoordinator(nil) {
+				_ = c.Close()
+			}
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// Without the leader election, the coordinator expects to be the only one
+// updating the metadata. Finding it updated by someone else means that
+// another coordinator is managing the same cluster, and it's not safe to
+// keep going.
+type exclusiveMetadataProvider struct {
+	impl.MetadataProvider
+}
+
+func (m exclusiveMetadataProvider) Store(cs *model.ClusterStatus, expectedVersion impl.Version) (impl.Version, error) {
+	newVersion, err := m.MetadataProvider.Store(cs, expectedVersion)
+	if errors.Is(err, impl.ErrMetadataBadVersion) {
+		panic(err)
+	}
+	return newVersion, err
+}
+
+func (s *Coordinator) setCoordinator(c impl.Coordinator) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.ctx.Err() != nil {
+		return false
+	}
+
+	s.coordinator = c
+	return true
+}
+
+func (s *Coordinator) forwardClusterConfigChanges(in <-chan any, out chan<- any) {
+	for {
+		select {
+		case v := <-in:
+			select {
+			case out <- v:
+			default:
+				// There is already a pending notification
+			}
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func newOxiaMetadataClient(config Config) (oxia.SyncClient, error) {
+	options := []oxia.ClientOption{
+		oxia.WithIdentity("oxia-coordinator"),
+	}
+	if config.OxiaMetadataNamespace != "" {
+		options = append(options, oxia.WithNamespace(config.OxiaMetadataNamespace))
+	}
+	if config.PeerTLS != nil {
+		options = append(options, oxia.WithTLS(config.PeerTLS))
+	}
+
+	client, err := oxia.NewSyncClient(config.OxiaMetadataServiceAddress, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the metadata Oxia cluster: %w", err)
+	}
+	return client, nil
+}
+
+func defaultLeaderElectionId() string {
+	id := uuid.NewString()[:8]
+	if hostname, err := os.Hostname(); err == nil {
+		return fmt.Sprintf("%s-%s", hostname, id)
+	}
+	return id
+}
+
 func (s *Coordinator) Close() error {
+	s.Lock()
+	s.cancel()
+	c := s.coordinator
+	s.coordinator = nil
+	s.Unlock()
+
+	var err error
+	if c != nil {
+		err = c.Close()
+	}
+	if s.election != nil {
+		err = multierr.Append(err, s.election.Close())
+	}
+
 	return multierr.Combine(
-		s.coordinator.Close(),
+		err,
 		s.rpcServer.Close(),
 		s.clientPool.Close(),
 		s.metrics.Close(),
+		s.auditLog.Close(),
 	)
 }
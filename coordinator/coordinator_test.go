@@ -16,9 +16,14 @@ package coordinator
 
 import (
 	"encoding/json"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/streamnative/oxia/coordinator/impl"
+	"github.com/streamnative/oxia/coordinator/model"
 )
 
 func TestCoordinator_MarshalingError(t *testing.T) {
@@ -31,3 +36,60 @@ func TestCoordinator_MarshalingError(t *testing.T) {
 	_, err := json.Marshal(config)
 	assert.Nil(t, err)
 }
+
+func TestCoordinator_LeaderElection(t *testing.T) {
+	metadataPath := filepath.Join(t.TempDir(), "cluster-status.json")
+	newCoordinator := func(id string) *Coordinator {
+		c, err := New(Config{
+			InternalServiceAddr:   "localhost:0",
+			MetricsServiceAddr:    "localhost:0",
+			MetadataProviderImpl:  File,
+			FileMetadataPath:      metadataPath,
+			LeaderElectionEnabled: true,
+			LeaderElectionId:      id,
+			LeaderLeaseDuration:   500 * time.Millisecond,
+			ClusterConfigProvider: func() (model.ClusterConfig, error) {
+				return model.ClusterConfig{}, nil
+			},
+		})
+		assert.NoError(t, err)
+		return c
+	}
+
+	isActive := func(c *Coordinator) func() bool {
+		return func() bool {
+			c.Lock()
+			defer c.Unlock()
+			return c.coordinator != nil
+		}
+	}
+
+	c1 := newCoordinator("c1")
+	assert.Eventually(t, isActive(c1), 10*time.Second, 10*time.Millisecond)
+
+	c2 := newCoordinator("c2")
+	time.Sleep(1 * time.Second)
+	assert.False(t, isActive(c2)())
+
+	// The standby takes over when the leader goes away
+	assert.NoError(t, c1.Close())
+	assert.Eventually(t, isActive(c2), 10*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, c2.Close())
+}
+
+func TestCoordinator_ExclusiveMetadataProvider(t *testing.T) {
+	m := exclusiveMetadataProvider{impl.NewMetadataProviderMemory()}
+
+	version, err := m.Store(&model.ClusterStatus{}, impl.MetadataNotExists)
+	assert.NoError(t, err)
+
+	// Another coordinator is updating the same metadata
+	assert.PanicsWithError(t, impl.ErrMetadataBadVersion.Error(), func() {
+		_, _ = m.Store(&model.ClusterStatus{}, impl.MetadataNotExists)
+	})
+
+	_, err = m.Store(&model.ClusterStatus{}, version)
+	assert.NoError(t, err)
+	assert.NoError(t, m.Close())
+}
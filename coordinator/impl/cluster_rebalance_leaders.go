@@ -0,0 +1,158 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impl
+
+import (
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/coordinator/model"
+)
+
+const (
+	defaultLeaderBalancerInterval = 1 * time.Minute
+	defaultLeaderBalancerMaxMoves = 5
+)
+
+// Transfer the leaderships so that each server leads about its fair share of the
+// shards. Elections pick the most up-to-date node, so after restarts the leaderships
+// tend to pile up on the servers that were available first.
+//
+// A server can only become leader of the shards it has a replica of, therefore the
+// leaderships can only be as balanced as the replicas are. At most maxMoves actions
+// are returned.
+func rebalanceLeaders(servers []model.Server, currentStatus *model.ClusterStatus, maxMoves int) []TransferLeaderAction {
+	actions := make([]TransferLeaderAction, 0)
+
+	lb, ok := newLeaderBalancer(servers, currentStatus)
+	if !ok {
+		return actions
+	}
+
+	for len(actions) < maxMoves {
+		a, found := lb.nextMove()
+		if !found {
+			break
+		}
+
+		slog.Debug(
+			"Transferring leadership to balance the leaders count",
+			slog.Any("transfer-leader-action", a),
+		)
+		actions = append(actions, a)
+	}
+
+	return actions
+}
+
+type leaderBalancer struct {
+	servers     map[string]model.Server
+	shards      []int64
+	leaders     map[int64]string
+	ensembles   map[int64]common.Set[string]
+	movedShards common.Set[int64]
+}
+
+func newLeaderBalancer(servers []model.Server, currentStatus *model.ClusterStatus) (*leaderBalancer, bool) {
+	if len(servers) == 0 {
+		return nil, false
+	}
+
+	lb := &leaderBalancer{
+		servers:     map[string]model.Server{},
+		shards:      make([]int64, 0),
+		leaders:     map[int64]string{},
+		ensembles:   map[int64]common.Set[string]{},
+		movedShards: common.NewSet[int64](),
+	}
+	for _, s := range servers {
+		lb.servers[s.GetIdentifier()] = s
+	}
+
+	for _, nss := range currentStatus.Namespaces {
+		for shard, shardMetadata := range nss.Shards {
+			if shardMetadata.Status != model.ShardStatusSteadyState || shardMetadata.Leader == nil {
+				slog.Debug("Skipping leader balancing, not all the shards are in steady state")
+				return nil, false
+			}
+
+			ensemble := common.NewSet[string]()
+			for _, s := range shardMetadata.Ensemble {
+				if _, ok := lb.servers[s.GetIdentifier()]; !ok {
+					slog.Debug("Skipping leader balancing, some servers are being removed")
+					return nil, false
+				}
+				// The witnesses can't be elected as leaders
+				if !listContains(shardMetadata.Witnesses, s) {
+					ensemble.Add(s.GetIdentifier())
+				}
+			}
+
+			lb.shards = append(lb.shards, shard)
+			lb.leaders[shard] = shardMetadata.Leader.GetIdentifier()
+			lb.ensembles[shard] = ensemble
+		}
+	}
+
+	sort.Slice(lb.shards, func(i, j int) bool { return lb.shards[i] < lb.shards[j] })
+	return lb, true
+}
+
+// Find a shard led by the server with the most leaderships that can be
+// moved to a server in the ensemble with at least two leaderships less. When
+// none of the shards of a server can be moved, the next most loaded server is
+// tried.
+func (lb *leaderBalancer) nextMove() (TransferLeaderAction, bool) {
+	leadersCount := map[string]float64{}
+	for id := range lb.servers {
+		leadersCount[id] = 0
+	}
+	for _, leader := range lb.leaders {
+		leadersCount[leader]++
+	}
+
+	ranking := rankServersByLoad(leadersCount)
+
+	for i := 0; i < len(ranking)-1; i++ {
+		mostLoaded := ranking[i]
+
+		for j := len(ranking) - 1; j > i; j-- {
+			leastLoaded := ranking[j]
+			if leadersCount[mostLoaded]-leadersCount[leastLoaded] <= 1 {
+				// Moving a leadership would only swap the two servers
+				break
+			}
+
+			for _, shard := range lb.shards {
+				if lb.movedShards.Contains(shard) || lb.leaders[shard] != mostLoaded ||
+					!lb.ensembles[shard].Contains(leastLoaded) {
+					continue
+				}
+
+				lb.leaders[shard] = leastLoaded
+				lb.movedShards.Add(shard)
+				return TransferLeaderAction{
+					Shard: shard,
+					From:  lb.servers[mostLoaded],
+					To:    lb.servers[leastLoaded],
+				}, true
+			}
+		}
+	}
+
+	return TransferLeaderAction{}, false
+}
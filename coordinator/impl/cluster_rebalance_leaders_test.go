@@ -0,0 +1,139 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/streamnative/oxia/coordinator/model"
+)
+
+func TestClusterRebalanceLeaders(t *testing.T) {
+	cs := &model.ClusterStatus{
+		Namespaces: map[string]model.NamespaceStatus{
+			"ns-1": {
+				ReplicationFactor: 3,
+				Shards:            map[int64]model.ShardMetadata{},
+			},
+		},
+	}
+
+	for shard := int64(0); shard < 6; shard++ {
+		cs.Namespaces["ns-1"].Shards[shard] = newSteadyStateShard(s1, s1, s2, s3)
+	}
+
+	actions := rebalanceLeaders([]model.Server{s1, s2, s3}, cs, 10)
+	assert.Equal(t, []TransferLeaderAction{
+		{Shard: 0, From: s1, To: s3},
+		{Shard: 1, From: s1, To: s2},
+		{Shard: 2, From: s1, To: s3},
+		{Shard: 3, From: s1, To: s2},
+	}, actions)
+
+	// The number of moves is limited
+	actions = rebalanceLeaders([]model.Server{s1, s2, s3}, cs, 1)
+	assert.Equal(t, []TransferLeaderAction{
+		{Shard: 0, From: s1, To: s3},
+	}, actions)
+}
+
+func TestClusterRebalanceLeaders_Balanced(t *testing.T) {
+	cs := &model.ClusterStatus{
+		Namespaces: map[string]model.NamespaceStatus{
+			"ns-1": {
+				ReplicationFactor: 3,
+				Shards: map[int64]model.ShardMetadata{
+					0: newSteadyStateShard(s1, s1, s2, s3),
+					1: newSteadyStateShard(s2, s1, s2, s3),
+					2: newSteadyStateShard(s3, s1, s2, s3),
+					3: newSteadyStateShard(s1, s1, s2, s3),
+				},
+			},
+		},
+	}
+
+	assert.Empty(t, rebalanceLeaders([]model.Server{s1, s2, s3}, cs, 10))
+}
+
+func TestClusterRebalanceLeaders_Ensembles(t *testing.T) {
+	cs := &model.ClusterStatus{
+		Namespaces: map[string]model.NamespaceStatus{
+			"ns-1": {
+				ReplicationFactor: 2,
+				Shards: map[int64]model.ShardMetadata{
+					0: newSteadyStateShard(s1, s1, s2),
+					1: newSteadyStateShard(s1, s1, s2),
+					2: newSteadyStateShard(s1, s1, s3),
+					3: newSteadyStateShard(s1, s1, s3),
+				},
+			},
+		},
+	}
+
+	// The leadership can only go to a server in the ensemble
+	actions := rebalanceLeaders([]model.Server{s1, s2, s3}, cs, 10)
+	assert.Equal(t, []TransferLeaderAction{
+		{Shard: 2, From: s1, To: s3},
+		{Shard: 0, From: s1, To: s2},
+	}, actions)
+}
+
+func TestClusterRebalanceLeaders_NotSteady(t *testing.T) {
+	election := newSteadyStateShard(s1, s1, s2, s3)
+	election.Status = model.ShardStatusElection
+
+	cs := &model.ClusterStatus{
+		Namespaces: map[string]model.NamespaceStatus{
+			"ns-1": {
+				ReplicationFactor: 3,
+				Shards: map[int64]model.ShardMetadata{
+					0: newSteadyStateShard(s1, s1, s2, s3),
+					1: newSteadyStateShard(s1, s1, s2, s3),
+					2: newSteadyStateShard(s1, s1, s2, s3),
+					3: election,
+				},
+			},
+		},
+	}
+
+	assert.Empty(t, rebalanceLeaders([]model.Server{s1, s2, s3}, cs, 10))
+}
+
+func TestClusterRebalanceLeaders_MostLoadedNotMovable(t *testing.T) {
+	cs := &model.ClusterStatus{
+		Namespaces: map[string]model.NamespaceStatus{
+			"ns-1": {
+				ReplicationFactor: 2,
+				Shards: map[int64]model.ShardMetadata{
+					0: newSteadyStateShard(s1, s1, s2),
+					1: newSteadyStateShard(s1, s1, s2),
+					2: newSteadyStateShard(s1, s1, s2),
+					3: newSteadyStateShard(s2, s2, s4),
+					4: newSteadyStateShard(s2, s2, s4),
+					5: newSteadyStateShard(s2, s2, s4),
+				},
+			},
+		},
+	}
+
+	// None of the shards of s1 can be moved to a less loaded server, the
+	// leaderships of s2 are moved instead
+	actions := rebalanceLeaders([]model.Server{s1, s2, s3, s4}, cs, 10)
+	assert.Equal(t, []TransferLeaderAction{
+		{Shard: 3, From: s2, To: s4},
+	}, actions)
+}
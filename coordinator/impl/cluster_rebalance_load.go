@@ -0,0 +1,320 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impl
+
+import (
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/coordinator/model"
+)
+
+const (
+	defaultLoadBalancerInterval = 5 * time.Minute
+	defaultLoadBalancerMaxMoves = 1
+
+	// Servers are considered balanced when the difference in load between the most
+	// and the least loaded is within this fraction of the most loaded one
+	loadImbalanceThreshold = 0.1
+)
+
+type TransferLeaderAction struct {
+	Shard int64
+	From  model.Server
+	To    model.Server
+}
+
+// ClusterLoad contains the load of each shard replica, indexed by server identifier.
+type ClusterLoad map[string]map[int64]ShardLoad
+
+type shardPlacement struct {
+	leader    string
+	ensemble  common.Set[string]
+	witnesses common.Set[string]
+
+	// Requests served by the leader, per second
+	requestsRate float64
+}
+
+type loadBalancer struct {
+	servers    map[string]model.Server
+	shards     map[int64]*shardPlacement
+	load       ClusterLoad
+	movedShard common.Set[int64]
+}
+
+// Even out the actual load of the servers, based on the metrics reported by the storage
+// nodes. Leaderships are moved first, since the leader serves all the client requests of
+// a shard and moving it doesn't require copying any data. Replicas are moved afterward,
+// to even out the disk usage. At most maxMoves actions are returned.
+func rebalanceClusterLoad(servers []model.Server, currentStatus *model.ClusterStatus, load ClusterLoad,
+	maxMoves int) (leaderActions []TransferLeaderAction, swapActions []SwapNodeAction) {
+	leaderActions = make([]TransferLeaderAction, 0)
+	swapActions = make([]SwapNodeAction, 0)
+
+	lb, ok := newLoadBalancer(servers, currentStatus, load)
+	if !ok {
+		return leaderActions, swapActions
+	}
+
+	for len(leaderActions) < maxMoves {
+		a, found := lb.nextLeaderMove()
+		if !found {
+			break
+		}
+
+		slog.Debug(
+			"Transferring leadership to balance load",
+			slog.Any("transfer-leader-action", a),
+		)
+		leaderActions = append(leaderActions, a)
+	}
+
+	for len(leaderActions)+len(swapActions) < maxMoves {
+		a, found := lb.nextReplicaMove()
+		if !found {
+			break
+		}
+
+		slog.Debug(
+			"Moving replica to balance load",
+			slog.Any("swap-action", a),
+		)
+		swapActions = append(swapActions, a)
+	}
+
+	return leaderActions, swapActions
+}
+
+func newLoadBalancer(servers []model.Server, currentStatus *model.ClusterStatus, load ClusterLoad) (*loadBalancer, bool) {
+	if len(servers) == 0 {
+		return nil, false
+	}
+
+	lb := &loadBalancer{
+		servers:    map[string]model.Server{},
+		shards:     map[int64]*shardPlacement{},
+		load:       ClusterLoad{},
+		movedShard: common.NewSet[int64](),
+	}
+
+	// The load gets updated while simulating the moves, so we need our own copy
+	for _, s := range servers {
+		lb.servers[s.GetIdentifier()] = s
+		lb.load[s.GetIdentifier()] = map[int64]ShardLoad{}
+		for shard, sl := range load[s.GetIdentifier()] {
+			lb.load[s.GetIdentifier()][shard] = sl
+		}
+	}
+
+	for _, nss := range currentStatus.Namespaces {
+		for shard, shardMetadata := range nss.Shards {
+			if shardMetadata.Status != model.ShardStatusSteadyState || shardMetadata.Leader == nil {
+				// The shard is changing, we cannot trust its metrics
+				slog.Debug("Skipping load balancing, not all the shards are in steady state")
+				return nil, false
+			}
+
+			sp := &shardPlacement{
+				leader:    shardMetadata.Leader.GetIdentifier(),
+				ensemble:  common.NewSet[string](),
+				witnesses: common.NewSet[string](),
+			}
+			for _, s := range shardMetadata.Witnesses {
+				sp.witnesses.Add(s.GetIdentifier())
+			}
+			for _, s := range shardMetadata.Ensemble {
+				if _, ok := lb.servers[s.GetIdentifier()]; !ok {
+					// The server is getting removed, the shard will be reassigned
+					// by the count based rebalancing first
+					slog.Debug("Skipping load balancing, some servers are being removed")
+					return nil, false
+				}
+				sp.ensemble.Add(s.GetIdentifier())
+			}
+
+			sl, ok := lb.load[sp.leader][shard]
+			if !ok || !sl.Leader {
+				slog.Debug(
+					"Skipping load balancing, the shard leader has not reported its load yet",
+					slog.Int64("shard", shard),
+				)
+				return nil, false
+			}
+
+			sp.requestsRate = sl.WriteRate + sl.ReadRate
+			lb.shards[shard] = sp
+		}
+	}
+
+	return lb, true
+}
+
+func (lb *loadBalancer) diskUsage(server string, shard int64) float64 {
+	sl := lb.load[server][shard]
+	return float64(sl.DbSize + sl.WalSize)
+}
+
+// Find a shard led by the most loaded server whose leadership can be moved
+// to a less loaded server in the ensemble.
+func (lb *loadBalancer) nextLeaderMove() (TransferLeaderAction, bool) {
+	leadersLoad := map[string]float64{}
+	for id := range lb.servers {
+		leadersLoad[id] = 0
+	}
+	for _, sp := range lb.shards {
+		leadersLoad[sp.leader] += sp.requestsRate
+	}
+
+	ranking := rankServersByLoad(leadersLoad)
+	mostLoaded := ranking[0]
+
+	for j := len(ranking) - 1; j > 0; j-- {
+		leastLoaded := ranking[j]
+		diff := leadersLoad[mostLoaded] - leadersLoad[leastLoaded]
+		if diff <= loadImbalanceThreshold*leadersLoad[mostLoaded] {
+			continue
+		}
+
+		shard, found := lb.bestShardToMove(diff, func(_ int64, sp *shardPlacement) float64 {
+			if sp.leader != mostLoaded || !sp.ensemble.Contains(leastLoaded) || sp.witnesses.Contains(leastLoaded) {
+				return 0
+			}
+			return sp.requestsRate
+		})
+		if !found {
+			continue
+		}
+
+		lb.shards[shard].leader = leastLoaded
+		lb.movedShard.Add(shard)
+		return TransferLeaderAction{
+			Shard: shard,
+			From:  lb.servers[mostLoaded],
+			To:    lb.servers[leastLoaded],
+		}, true
+	}
+
+	return TransferLeaderAction{}, false
+}
+
+// Find a replica on the server with the highest disk usage that can be moved
+// to a server with lower usage, without making the shards count unbalanced.
+func (lb *loadBalancer) nextReplicaMove() (SwapNodeAction, bool) {
+	usage := map[string]float64{}
+	count := map[string]int{}
+	for id := range lb.servers {
+		usage[id] = 0
+	}
+	for shard, sp := range lb.shards {
+		for _, id := range sp.ensemble.GetSorted() {
+			usage[id] += lb.diskUsage(id, shard)
+			count[id]++
+		}
+	}
+
+	ranking := rankServersByLoad(usage)
+	mostLoaded := ranking[0]
+
+	for j := len(ranking) - 1; j > 0; j-- {
+		leastLoaded := ranking[j]
+		diff := usage[mostLoaded] - usage[leastLoaded]
+		if diff <= loadImbalanceThreshold*usage[mostLoaded] || count[mostLoaded] <= count[leastLoaded] {
+			continue
+		}
+
+		shard, found := lb.bestShardToMove(diff, func(shard int64, sp *shardPlacement) float64 {
+			// Leaderships were already balanced, so we're only moving followers
+			if sp.leader == mostLoaded || !sp.ensemble.Contains(mostLoaded) || sp.ensemble.Contains(leastLoaded) {
+				return 0
+			}
+			return lb.diskUsage(mostLoaded, shard)
+		})
+		if !found {
+			continue
+		}
+
+		sp := lb.shards[shard]
+		sp.ensemble.Remove(mostLoaded)
+		sp.ensemble.Add(leastLoaded)
+		if sp.witnesses.Contains(mostLoaded) {
+			sp.witnesses.Remove(mostLoaded)
+			sp.witnesses.Add(leastLoaded)
+		}
+		lb.load[leastLoaded][shard] = lb.load[mostLoaded][shard]
+		lb.movedShard.Add(shard)
+		return SwapNodeAction{
+			Shard: shard,
+			From:  lb.servers[mostLoaded],
+			To:    lb.servers[leastLoaded],
+		}, true
+	}
+
+	return SwapNodeAction{}, false
+}
+
+// Select the shard that, once moved, brings the two servers closest to each other.
+// Moving a shard whose weight is not smaller than the difference would only invert
+// the imbalance, so these shards are not eligible.
+func (lb *loadBalancer) bestShardToMove(diff float64, weight func(shard int64, sp *shardPlacement) float64) (int64, bool) {
+	shards := make([]int64, 0, len(lb.shards))
+	for shard := range lb.shards {
+		shards = append(shards, shard)
+	}
+	sort.Slice(shards, func(i, j int) bool { return shards[i] < shards[j] })
+
+	var (
+		bestShard int64
+		found     bool
+		bestDiff  = diff
+	)
+	for _, shard := range shards {
+		if lb.movedShard.Contains(shard) {
+			continue
+		}
+
+		w := weight(shard, lb.shards[shard])
+		if w <= 0 || w >= diff {
+			continue
+		}
+
+		if newDiff := math.Abs(diff - 2*w); newDiff < bestDiff {
+			bestShard, bestDiff, found = shard, newDiff, true
+		}
+	}
+
+	return bestShard, found
+}
+
+// Rank the servers from the most loaded to the least loaded.
+func rankServersByLoad(load map[string]float64) []string {
+	res := make([]string, 0, len(load))
+	for id := range load {
+		res = append(res, id)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if load[res[i]] != load[res[j]] {
+			return load[res[i]] > load[res[j]]
+		}
+
+		// Ensure predictable sorting
+		return res[i] < res[j]
+	})
+	return res
+}
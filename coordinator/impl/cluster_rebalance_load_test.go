@@ -0,0 +1,134 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/streamnative/oxia/coordinator/model"
+)
+
+func newSteadyStateShard(leader model.Server, ensemble ...model.Server) model.ShardMetadata {
+	return model.ShardMetadata{
+		Status:   model.ShardStatusSteadyState,
+		Term:     1,
+		Leader:   &leader,
+		Ensemble: ensemble,
+	}
+}
+
+func TestClusterRebalanceLoad_Leaders(t *testing.T) {
+	cs := &model.ClusterStatus{
+		Namespaces: map[string]model.NamespaceStatus{
+			"ns-1": {
+				ReplicationFactor: 3,
+				Shards:            map[int64]model.ShardMetadata{},
+			},
+		},
+	}
+
+	load := ClusterLoad{s1.GetIdentifier(): {}, s2.GetIdentifier(): {}, s3.GetIdentifier(): {}}
+	for shard := int64(0); shard < 6; shard++ {
+		cs.Namespaces["ns-1"].Shards[shard] = newSteadyStateShard(s1, s1, s2, s3)
+		load[s1.GetIdentifier()][shard] = ShardLoad{Leader: true, WriteRate: 5, ReadRate: 5}
+		load[s2.GetIdentifier()][shard] = ShardLoad{}
+		load[s3.GetIdentifier()][shard] = ShardLoad{}
+	}
+
+	leaderActions, swapActions := rebalanceClusterLoad([]model.Server{s1, s2, s3}, cs, load, 10)
+	assert.Equal(t, []TransferLeaderAction{
+		{Shard: 0, From: s1, To: s3},
+		{Shard: 1, From: s1, To: s2},
+		{Shard: 2, From: s1, To: s3},
+		{Shard: 3, From: s1, To: s2},
+	}, leaderActions)
+	assert.Empty(t, swapActions)
+
+	// The number of moves is limited
+	leaderActions, swapActions = rebalanceClusterLoad([]model.Server{s1, s2, s3}, cs, load, 2)
+	assert.Equal(t, []TransferLeaderAction{
+		{Shard: 0, From: s1, To: s3},
+		{Shard: 1, From: s1, To: s2},
+	}, leaderActions)
+	assert.Empty(t, swapActions)
+}
+
+func TestClusterRebalanceLoad_Replicas(t *testing.T) {
+	cs := &model.ClusterStatus{
+		Namespaces: map[string]model.NamespaceStatus{
+			"ns-1": {
+				ReplicationFactor: 2,
+				Shards: map[int64]model.ShardMetadata{
+					0: newSteadyStateShard(s2, s1, s2),
+					1: newSteadyStateShard(s3, s1, s3),
+					2: newSteadyStateShard(s1, s1, s2),
+				},
+			},
+		},
+	}
+
+	load := ClusterLoad{
+		s1.GetIdentifier(): {
+			0: {DbSize: 300, WalSize: 100},
+			1: {DbSize: 10},
+			2: {Leader: true, DbSize: 100},
+		},
+		s2.GetIdentifier(): {
+			0: {Leader: true, DbSize: 300, WalSize: 100},
+			2: {DbSize: 100},
+		},
+		s3.GetIdentifier(): {
+			1: {Leader: true, DbSize: 10},
+		},
+	}
+
+	// Only the big shard is moved, after that the servers have
+	// the same shards count and no more moves are possible
+	leaderActions, swapActions := rebalanceClusterLoad([]model.Server{s1, s2, s3}, cs, load, 2)
+	assert.Empty(t, leaderActions)
+	assert.Equal(t, []SwapNodeAction{
+		{Shard: 0, From: s1, To: s3},
+	}, swapActions)
+
+	// The input load is not modified
+	assert.Len(t, load[s3.GetIdentifier()], 1)
+}
+
+func TestClusterRebalanceLoad_MissingMetrics(t *testing.T) {
+	cs := &model.ClusterStatus{
+		Namespaces: map[string]model.NamespaceStatus{
+			"ns-1": {
+				ReplicationFactor: 2,
+				Shards: map[int64]model.ShardMetadata{
+					0: newSteadyStateShard(s1, s1, s2),
+					1: newSteadyStateShard(s1, s1, s2),
+				},
+			},
+		},
+	}
+
+	// The leader of shard 1 has not reported its load yet
+	load := ClusterLoad{
+		s1.GetIdentifier(): {
+			0: {Leader: true, WriteRate: 100},
+		},
+	}
+
+	leaderActions, swapActions := rebalanceClusterLoad([]model.Server{s1, s2}, cs, load, 2)
+	assert.Empty(t, leaderActions)
+	assert.Empty(t, swapActions)
+}
@@ -15,10 +15,20 @@
 package impl
 
 import (
+	"log/slog"
+	"slices"
+	"sort"
+
 	"github.com/streamnative/oxia/common"
 	"github.com/streamnative/oxia/coordinator/model"
 )
 
+type EnsembleChangeAction struct {
+	Shard  int64
+	Add    []model.Server
+	Remove []model.Server
+}
+
 func getServers(servers []model.Server, startIdx uint32, count uint32) []model.Server {
 	n := len(servers)
 	res := make([]model.Server, count)
@@ -28,6 +38,17 @@ func getServers(servers []model.Server, startIdx uint32, count uint32) []model.S
 	return res
 }
 
+// The witnesses are the last members of the ensemble. There must be less
+// witnesses than the majority of the ensemble, so that any majority has
+// at least a full replica.
+func getWitnesses(ensemble []model.Server, witnesses uint32) []model.Server {
+	count := min(int(witnesses), len(ensemble)/2)
+	if count == 0 {
+		return nil
+	}
+	return slices.Clone(ensemble[len(ensemble)-count:])
+}
+
 func findNamespaceConfig(config *model.ClusterConfig, ns string) *model.NamespaceConfig {
 	for _, cns := range config.Namespaces {
 		if cns.Name == ns {
@@ -41,9 +62,11 @@ func findNamespaceConfig(config *model.ClusterConfig, ns string) *model.Namespac
 func applyClusterChanges(config *model.ClusterConfig, currentStatus *model.ClusterStatus) (
 	newStatus *model.ClusterStatus,
 	shardsToAdd map[int64]string,
-	shardsToDelete []int64) {
+	shardsToDelete []int64,
+	namespacesToResize []string) {
 	shardsToAdd = map[int64]string{}
 	shardsToDelete = []int64{}
+	namespacesToResize = []string{}
 
 	newStatus = &model.ClusterStatus{
 		Namespaces:       map[string]model.NamespaceStatus{},
@@ -58,6 +81,14 @@ func applyClusterChanges(config *model.ClusterConfig, currentStatus *model.Clust
 	for _, nc := range config.Namespaces {
 		nss, existing := currentStatus.Namespaces[nc.Name]
 		if existing {
+			if nss.ReplicationFactor != nc.ReplicationFactor {
+				// Record the new replication factor. The ensembles of the shards
+				// will be converging to it through the ensemble changes.
+				nss = nss.Clone()
+				nss.ReplicationFactor = nc.ReplicationFactor
+				newStatus.Namespaces[nc.Name] = nss
+				namespacesToResize = append(namespacesToResize, nc.Name)
+			}
 			continue
 		}
 
@@ -67,15 +98,18 @@ func applyClusterChanges(config *model.ClusterConfig, currentStatus *model.Clust
 			ReplicationFactor: nc.ReplicationFactor,
 		}
 		for _, shard := range common.GenerateShards(newStatus.ShardIdGenerator, nc.InitialShardCount) {
+			ensemble := getServers(config.Servers, newStatus.ServerIdx, nc.ReplicationFactor)
 			shardMetadata := model.ShardMetadata{
 				Status:   model.ShardStatusUnknown,
 				Term:     -1,
 				Leader:   nil,
-				Ensemble: getServers(config.Servers, newStatus.ServerIdx, nc.ReplicationFactor),
+				Ensemble: ensemble,
 				Int32HashRange: model.Int32HashRange{
 					Min: shard.Min,
 					Max: shard.Max,
 				},
+				Observers: slices.Clone(nc.Observers),
+				Witnesses: getWitnesses(ensemble, nc.Witnesses),
 			}
 
 			nss.Shards[shard.Id] = shardMetadata
@@ -105,5 +139,132 @@ func applyClusterChanges(config *model.ClusterConfig, currentStatus *model.Clust
 		newStatus.Namespaces[name] = nss
 	}
 
-	return newStatus, shardsToAdd, shardsToDelete
+	return newStatus, shardsToAdd, shardsToDelete, namespacesToResize
+}
+
+// Compare the ensemble of each shard with the replication factor of its
+// namespace, and output the list of nodes to be added or removed from
+// each ensemble. New members are picked among the least loaded servers,
+// while the members to remove are picked among the most loaded ones,
+// never removing the current leader. Only the shards in steady state are
+// changed, the others are picked up once their election has completed.
+func computeEnsembleChanges(servers []model.Server, currentStatus *model.ClusterStatus) []EnsembleChangeAction {
+	res := make([]EnsembleChangeAction, 0)
+	shardsPerServer, _ := getShardsPerServer(servers, currentStatus)
+
+	namespaces := make([]string, 0, len(currentStatus.Namespaces))
+	for name := range currentStatus.Namespaces {
+		namespaces = append(namespaces, name)
+	}
+	sort.Strings(namespaces)
+
+	for _, name := range namespaces {
+		nss := currentStatus.Namespaces[name]
+		shardIds := make([]int64, 0, len(nss.Shards))
+		for shardId := range nss.Shards {
+			shardIds = append(shardIds, shardId)
+		}
+		sort.Slice(shardIds, func(i, j int) bool { return shardIds[i] < shardIds[j] })
+
+		for _, shardId := range shardIds {
+			shard := nss.Shards[shardId]
+			if shard.Status != model.ShardStatusSteadyState || shard.Leader == nil {
+				continue
+			}
+
+			rf := int(nss.ReplicationFactor)
+			if rf == 0 {
+				continue
+			}
+			if rf > len(servers) {
+				slog.Warn(
+					"Replication factor is bigger than the number of servers, skipping ensemble change",
+					slog.String("namespace", name),
+					slog.Int64("shard", shardId),
+					slog.Int("replication-factor", rf),
+					slog.Int("servers", len(servers)),
+				)
+				continue
+			}
+
+			var action *EnsembleChangeAction
+			switch {
+			case len(shard.Ensemble) < rf:
+				action = growEnsemble(shardId, shard, rf, shardsPerServer)
+			case len(shard.Ensemble) > rf:
+				action = shrinkEnsemble(shardId, shard, rf, shardsPerServer)
+			}
+
+			if action != nil {
+				slog.Debug(
+					"Computed ensemble change",
+					slog.String("namespace", name),
+					slog.Any("ensemble-change-action", action),
+				)
+				res = append(res, *action)
+			}
+		}
+	}
+
+	return res
+}
+
+func growEnsemble(shardId int64, shard model.ShardMetadata, rf int,
+	shardsPerServer map[string]ServerContext) *EnsembleChangeAction {
+	action := &EnsembleChangeAction{Shard: shardId}
+	rankings := getServerRanking(shardsPerServer)
+
+	// Pick from the least loaded servers
+	for i := len(rankings) - 1; i >= 0 && len(shard.Ensemble)+len(action.Add) < rf; i-- {
+		candidate := rankings[i]
+		if listContains(shard.Ensemble, candidate.Server) {
+			continue
+		}
+
+		action.Add = append(action.Add, candidate.Server)
+		candidate.Shards.Add(shardId)
+	}
+
+	if len(action.Add) == 0 {
+		return nil
+	}
+	return action
+}
+
+func shrinkEnsemble(shardId int64, shard model.ShardMetadata, rf int,
+	shardsPerServer map[string]ServerContext) *EnsembleChangeAction {
+	action := &EnsembleChangeAction{Shard: shardId}
+	toRemove := len(shard.Ensemble) - rf
+
+	isLeader := func(server model.Server) bool {
+		return shard.Leader.GetIdentifier() == server.GetIdentifier()
+	}
+
+	// Servers that are being removed from the cluster go first
+	for _, member := range shard.Ensemble {
+		if len(action.Remove) == toRemove {
+			break
+		}
+		if _, ok := shardsPerServer[member.GetIdentifier()]; !ok && !isLeader(member) {
+			action.Remove = append(action.Remove, member)
+		}
+	}
+
+	// Then pick from the most loaded servers
+	for _, candidate := range getServerRanking(shardsPerServer) {
+		if len(action.Remove) == toRemove {
+			break
+		}
+		if !listContains(shard.Ensemble, candidate.Server) || isLeader(candidate.Server) {
+			continue
+		}
+
+		action.Remove = append(action.Remove, candidate.Server)
+		candidate.Shards.Remove(shardId)
+	}
+
+	if len(action.Remove) == 0 {
+		return nil
+	}
+	return action
 }
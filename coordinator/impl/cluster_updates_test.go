@@ -34,7 +34,7 @@ var (
 )
 
 func TestClientUpdates_ClusterInit(t *testing.T) {
-	newStatus, shardsAdded, shardsToRemove := applyClusterChanges(&model.ClusterConfig{
+	newStatus, shardsAdded, shardsToRemove, namespacesToResize := applyClusterChanges(&model.ClusterConfig{
 		Namespaces: []model.NamespaceConfig{{
 			Name:              "ns-1",
 			InitialShardCount: 1,
@@ -95,6 +95,7 @@ func TestClientUpdates_ClusterInit(t *testing.T) {
 	}, newStatus)
 
 	assert.Equal(t, []int64{}, shardsToRemove)
+	assert.Equal(t, []string{}, namespacesToResize)
 	assert.Equal(t, map[int64]string{
 		0: "ns-1",
 		1: "ns-2",
@@ -102,7 +103,7 @@ func TestClientUpdates_ClusterInit(t *testing.T) {
 }
 
 func TestClientUpdates_NamespaceAdded(t *testing.T) {
-	newStatus, shardsAdded, shardsToRemove := applyClusterChanges(&model.ClusterConfig{
+	newStatus, shardsAdded, shardsToRemove, namespacesToResize := applyClusterChanges(&model.ClusterConfig{
 		Namespaces: []model.NamespaceConfig{{
 			Name:              "ns-1",
 			InitialShardCount: 1,
@@ -181,13 +182,14 @@ func TestClientUpdates_NamespaceAdded(t *testing.T) {
 	}, newStatus)
 
 	assert.Equal(t, []int64{}, shardsToRemove)
+	assert.Equal(t, []string{}, namespacesToResize)
 	assert.Equal(t, map[int64]string{
 		1: "ns-2",
 		2: "ns-2"}, shardsAdded)
 }
 
 func TestClientUpdates_NamespaceRemoved(t *testing.T) {
-	newStatus, shardsAdded, shardsToRemove := applyClusterChanges(&model.ClusterConfig{
+	newStatus, shardsAdded, shardsToRemove, namespacesToResize := applyClusterChanges(&model.ClusterConfig{
 		Namespaces: []model.NamespaceConfig{{
 			Name:              "ns-1",
 			InitialShardCount: 1,
@@ -293,4 +295,136 @@ func TestClientUpdates_NamespaceRemoved(t *testing.T) {
 	sort.Slice(shardsToRemove, func(i, j int) bool { return shardsToRemove[i] < shardsToRemove[j] })
 	assert.Equal(t, []int64{1, 2}, shardsToRemove)
 	assert.Equal(t, map[int64]string{}, shardsAdded)
+	assert.Equal(t, []string{}, namespacesToResize)
+}
+
+func TestClientUpdates_ReplicationFactorChanged(t *testing.T) {
+	currentStatus := &model.ClusterStatus{Namespaces: map[string]model.NamespaceStatus{
+		"ns-1": {
+			ReplicationFactor: 3,
+			Shards: map[int64]model.ShardMetadata{
+				0: {
+					Status:   model.ShardStatusSteadyState,
+					Term:     1,
+					Leader:   &s1,
+					Ensemble: []model.Server{s1, s2, s3},
+					Int32HashRange: model.Int32HashRange{
+						Min: 0,
+						Max: math.MaxUint32,
+					},
+				},
+			},
+		},
+	}, ShardIdGenerator: 1,
+		ServerIdx: 3}
+
+	newStatus, shardsAdded, shardsToRemove, namespacesToResize := applyClusterChanges(&model.ClusterConfig{
+		Namespaces: []model.NamespaceConfig{{
+			Name:              "ns-1",
+			InitialShardCount: 1,
+			ReplicationFactor: 5,
+		}},
+		Servers: []model.Server{s1, s2, s3, s4, s5},
+	}, currentStatus)
+
+	assert.Equal(t, []string{"ns-1"}, namespacesToResize)
+	assert.Equal(t, map[int64]string{}, shardsAdded)
+	assert.Equal(t, []int64{}, shardsToRemove)
+
+	// The new replication factor is recorded, though the ensemble is untouched
+	assert.EqualValues(t, 5, newStatus.Namespaces["ns-1"].ReplicationFactor)
+	assert.Equal(t, []model.Server{s1, s2, s3}, newStatus.Namespaces["ns-1"].Shards[0].Ensemble)
+	assert.EqualValues(t, 3, currentStatus.Namespaces["ns-1"].ReplicationFactor)
+}
+
+func TestClientUpdates_Witnesses(t *testing.T) {
+	newStatus, _, _, _ := applyClusterChanges(&model.ClusterConfig{
+		Namespaces: []model.NamespaceConfig{{
+			Name:              "ns-1",
+			InitialShardCount: 1,
+			ReplicationFactor: 3,
+			Witnesses:         1,
+		}, {
+			// The witnesses are capped to half of the ensemble
+			Name:              "ns-2",
+			InitialShardCount: 1,
+			ReplicationFactor: 5,
+			Witnesses:         4,
+		}, {
+			Name:              "ns-3",
+			InitialShardCount: 1,
+			ReplicationFactor: 1,
+			Witnesses:         1,
+		}},
+		Servers: []model.Server{s1, s2, s3, s4, s5},
+	}, model.NewClusterStatus())
+
+	assert.Equal(t, []model.Server{s3}, newStatus.Namespaces["ns-1"].Shards[0].Witnesses)
+	assert.Equal(t, []model.Server{s2, s3}, newStatus.Namespaces["ns-2"].Shards[1].Witnesses)
+	assert.Nil(t, newStatus.Namespaces["ns-3"].Shards[2].Witnesses)
+}
+
+func TestEnsembleChanges_Grow(t *testing.T) {
+	actions := computeEnsembleChanges([]model.Server{s1, s2, s3, s4, s5}, &model.ClusterStatus{
+		Namespaces: map[string]model.NamespaceStatus{
+			"ns-1": {
+				ReplicationFactor: 4,
+				Shards: map[int64]model.ShardMetadata{
+					0: {Status: model.ShardStatusSteadyState, Leader: &s1, Ensemble: []model.Server{s1, s2, s3}},
+				},
+			},
+			"ns-2": {
+				ReplicationFactor: 1,
+				Shards: map[int64]model.ShardMetadata{
+					1: {Status: model.ShardStatusSteadyState, Leader: &s4, Ensemble: []model.Server{s4}},
+				},
+			},
+		},
+	})
+
+	// s5 is the least loaded server
+	assert.Equal(t, []EnsembleChangeAction{{
+		Shard: 0,
+		Add:   []model.Server{s5},
+	}}, actions)
+}
+
+func TestEnsembleChanges_Shrink(t *testing.T) {
+	actions := computeEnsembleChanges([]model.Server{s1, s2, s3, s4, s5}, &model.ClusterStatus{
+		Namespaces: map[string]model.NamespaceStatus{
+			"ns-1": {
+				ReplicationFactor: 1,
+				Shards: map[int64]model.ShardMetadata{
+					0: {Status: model.ShardStatusSteadyState, Leader: &s2, Ensemble: []model.Server{s1, s2, s3}},
+					1: {Status: model.ShardStatusSteadyState, Leader: &s4, Ensemble: []model.Server{s3, s4, s5}},
+					2: {Status: model.ShardStatusDeleting, Leader: &s4, Ensemble: []model.Server{s3, s4, s5}},
+					3: {Status: model.ShardStatusElection, Leader: nil, Ensemble: []model.Server{s3, s4, s5}},
+					4: {Status: model.ShardStatusSteadyState, Leader: nil, Ensemble: []model.Server{s3, s4, s5}},
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, []EnsembleChangeAction{{
+		Shard:  0,
+		Remove: []model.Server{s3, s1},
+	}, {
+		Shard:  1,
+		Remove: []model.Server{s3, s5},
+	}}, actions)
+}
+
+func TestEnsembleChanges_NotEnoughServers(t *testing.T) {
+	actions := computeEnsembleChanges([]model.Server{s1, s2, s3}, &model.ClusterStatus{
+		Namespaces: map[string]model.NamespaceStatus{
+			"ns-1": {
+				ReplicationFactor: 5,
+				Shards: map[int64]model.ShardMetadata{
+					0: {Status: model.ShardStatusSteadyState, Leader: &s1, Ensemble: []model.Server{s1, s2, s3}},
+				},
+			},
+		},
+	})
+
+	assert.Empty(t, actions)
 }
@@ -16,9 +16,12 @@ package impl
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
 	"reflect"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -29,6 +32,7 @@ import (
 	"github.com/streamnative/oxia/common"
 	"github.com/streamnative/oxia/coordinator/model"
 	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/auth"
 )
 
 var (
@@ -37,6 +41,9 @@ var (
 
 type ShardAssignmentsProvider interface {
 	WaitForNextUpdate(ctx context.Context, currentValue *proto.ShardAssignments) (*proto.ShardAssignments, error)
+
+	// ShardsForServer returns all the shards that have a replica on the server
+	ShardsForServer(server model.Server) []int64
 }
 
 type NodeAvailabilityListener interface {
@@ -80,6 +87,8 @@ type coordinator struct {
 	assignments     *proto.ShardAssignments
 	metadataVersion Version
 	rpc             RpcProvider
+	auditLog        *auth.AuditLog
+	namespaceUsage  map[string]*namespaceUsageGauges
 	log             *slog.Logger
 
 	ctx    context.Context
@@ -89,7 +98,8 @@ type coordinator struct {
 func NewCoordinator(metadataProvider MetadataProvider,
 	clusterConfigProvider func() (model.ClusterConfig, error),
 	clusterConfigNotificationsCh chan any,
-	rpc RpcProvider) (Coordinator, error) {
+	rpc RpcProvider,
+	auditLog *auth.AuditLog) (Coordinator, error) {
 	initialClusterConf, err := clusterConfigProvider()
 	if err != nil {
 		return nil, err
@@ -105,6 +115,8 @@ func NewCoordinator(metadataProvider MetadataProvider,
 		drainingNodes:         make(map[string]NodeController),
 		serverIndexes:         sync.Map{},
 		rpc:                   rpc,
+		auditLog:              auditLog,
+		namespaceUsage:        make(map[string]*namespaceUsageGauges),
 		log: slog.With(
 			slog.String("component", "coordinator"),
 		),
@@ -141,6 +153,7 @@ func NewCoordinator(metadataProvider MetadataProvider,
 	}
 
 	c.initialShardController(&initialClusterConf)
+	c.updateNamespaceUsageGauges()
 
 	go common.DoWithLabels(
 		c.ctx,
@@ -204,7 +217,7 @@ func (c *coordinator) initialAssignment() error {
 		slog.Any("clusterConfig", c.ClusterConfig),
 	)
 
-	clusterStatus, _, _ := applyClusterChanges(&c.ClusterConfig, model.NewClusterStatus())
+	clusterStatus, _, _, _ := applyClusterChanges(&c.ClusterConfig, model.NewClusterStatus())
 
 	var err error
 	if c.metadataVersion, err = c.MetadataProvider.Store(clusterStatus, MetadataNotExists); err != nil {
@@ -222,14 +235,16 @@ func (c *coordinator) applyNewClusterConfig() error {
 		slog.Any("metadataVersion", c.metadataVersion),
 	)
 
-	clusterStatus, shardsToAdd, shardsToDelete := applyClusterChanges(&c.ClusterConfig, c.clusterStatus)
+	clusterStatus, shardsToAdd, shardsToDelete, namespacesToResize := applyClusterChanges(&c.ClusterConfig, c.clusterStatus)
 
-	if len(shardsToAdd) > 0 || len(shardsToDelete) > 0 {
+	if len(shardsToAdd) > 0 || len(shardsToDelete) > 0 || len(namespacesToResize) > 0 {
 		var err error
 
 		if c.metadataVersion, err = c.MetadataProvider.Store(clusterStatus, c.metadataVersion); err != nil {
 			return err
 		}
+
+		c.auditReplicationFactorChanges(clusterStatus, namespacesToResize)
 	}
 
 	c.clusterStatus = clusterStatus
@@ -237,6 +252,8 @@ func (c *coordinator) applyNewClusterConfig() error {
 }
 
 func (c *coordinator) Close() error {
+	c.cancel()
+
 	var err error
 
 	for _, sc := range c.shardControllers {
@@ -250,6 +267,13 @@ func (c *coordinator) Close() error {
 	for _, nc := range c.drainingNodes {
 		err = multierr.Append(err, nc.Close())
 	}
+
+	c.Lock()
+	for _, gauges := range c.namespaceUsage {
+		gauges.unregister()
+	}
+	c.namespaceUsage = map[string]*namespaceUsageGauges{}
+	c.Unlock()
 	return err
 }
 
@@ -287,6 +311,21 @@ func (c *coordinator) WaitForNextUpdate(ctx context.Context, currentValue *proto
 	return c.assignments, nil
 }
 
+func (c *coordinator) ShardsForServer(server model.Server) []int64 {
+	c.Lock()
+	defer c.Unlock()
+
+	var res []int64
+	for _, ns := range c.clusterStatus.Namespaces {
+		for shard, shardMetadata := range ns.Shards {
+			if listContains(shardMetadata.Ensemble, server) {
+				res = append(res, shard)
+			}
+		}
+	}
+	return res
+}
+
 func (c *coordinator) InitiateLeaderElection(namespace string, shard int64, metadata model.ShardMetadata) error {
 	c.Lock()
 	defer c.Unlock()
@@ -375,11 +414,16 @@ func (c *coordinator) computeNewAssignments() {
 			if a.Leader != nil {
 				leader = a.Leader.Public
 			}
+			var observers []string
+			for _, o := range a.Observers {
+				observers = append(observers, o.Public)
+			}
 			if a.Status != model.ShardStatusDeleting {
 				nsAssignments.Assignments = append(nsAssignments.Assignments,
 					&proto.ShardAssignment{
-						Shard:  shard,
-						Leader: leader,
+						Shard:     shard,
+						Leader:    leader,
+						Observers: observers,
 						ShardBoundaries: &proto.ShardAssignment_Int32HashRange{
 							Int32HashRange: &proto.Int32HashRange{
 								MinHashInclusive: a.Int32HashRange.Min,
@@ -404,11 +448,29 @@ func (c *coordinator) ClusterStatus() model.ClusterStatus {
 }
 
 func (c *coordinator) waitForExternalEvents() {
+	// Complete any replication factor change that was not
+	// yet applied before the coordinator was restarted
+	c.applyEnsembleChanges()
+
+	loadBalancerTimer := time.NewTimer(c.loadBalancerInterval())
+	defer loadBalancerTimer.Stop()
+
+	leaderBalancerTimer := time.NewTimer(c.leaderBalancerInterval())
+	defer leaderBalancerTimer.Stop()
+
 	for {
 		select {
 		case <-c.ctx.Done():
 			return
 
+		case <-loadBalancerTimer.C:
+			c.rebalanceClusterLoad()
+			loadBalancerTimer.Reset(c.loadBalancerInterval())
+
+		case <-leaderBalancerTimer.C:
+			c.rebalanceLeaders()
+			leaderBalancerTimer.Reset(c.leaderBalancerInterval())
+
 		case <-c.clusterConfigChangeCh:
 			c.log.Info("Received cluster config change event")
 			if err := c.handleClusterConfigUpdated(); err != nil {
@@ -418,6 +480,8 @@ func (c *coordinator) waitForExternalEvents() {
 				)
 			}
 
+			c.applyEnsembleChanges()
+
 			if err := c.rebalanceCluster(); err != nil {
 				c.log.Warn(
 					"Failed to rebalance cluster",
@@ -455,7 +519,20 @@ func (c *coordinator) handleClusterConfigUpdated() error {
 		sc.SyncServerAddress()
 	}
 
-	clusterStatus, shardsToAdd, shardsToDelete := applyClusterChanges(&newClusterConfig, c.clusterStatus)
+	clusterStatus, shardsToAdd, shardsToDelete, namespacesToResize := applyClusterChanges(&newClusterConfig, c.clusterStatus)
+
+	if len(namespacesToResize) > 0 {
+		c.log.Info(
+			"Detected replication factor change",
+			slog.Any("namespaces", namespacesToResize),
+		)
+
+		if c.metadataVersion, err = c.MetadataProvider.Store(clusterStatus, c.metadataVersion); err != nil {
+			return err
+		}
+
+		c.auditReplicationFactorChanges(clusterStatus, namespacesToResize)
+	}
 
 	for shard, namespace := range shardsToAdd {
 		shardMetadata := clusterStatus.Namespaces[namespace].Shards[shard]
@@ -468,22 +545,116 @@ func (c *coordinator) handleClusterConfigUpdated() error {
 			slog.String("namespace", namespace),
 			slog.Any("shard-metadata", shardMetadata),
 		)
+		c.auditLog.Log(&auth.AuditEvent{
+			Principal: auth.AuditPrincipalCoordinator,
+			Operation: auth.AuditOperationCreateShard,
+			Namespace: namespace,
+			Shard:     &shard,
+		}, nil)
 	}
 
 	for _, shard := range shardsToDelete {
 		s, ok := c.shardControllers[shard]
 		if ok {
 			s.DeleteShard()
+			c.auditLog.Log(c.newAuditEvent(auth.AuditOperationDeleteShard, shard), nil)
 		}
 	}
 
+	c.updateObservers(&newClusterConfig)
+	c.updateQuotas(&newClusterConfig)
+
 	c.ClusterConfig = newClusterConfig
 	c.clusterStatus = clusterStatus
+	c.updateNamespaceUsageGauges()
 
 	c.computeNewAssignments()
 	return nil
 }
 
+// Propagate the changes in the quotas of the existing namespaces to their shards.
+// This is called while already holding the lock on the coordinator.
+func (c *coordinator) updateQuotas(newClusterConfig *model.ClusterConfig) {
+	for name, ns := range c.clusterStatus.Namespaces {
+		nc := findNamespaceConfig(newClusterConfig, name)
+		current := findNamespaceConfig(&c.ClusterConfig, name)
+		if nc == nil || current == nil || reflect.DeepEqual(nc.Quota, current.Quota) {
+			continue
+		}
+
+		c.log.Info(
+			"Detected quota change",
+			slog.String("namespace", name),
+			slog.Any("quota", nc.Quota),
+		)
+
+		for shard := range ns.Shards {
+			if sc, ok := c.shardControllers[shard]; ok {
+				sc.SetQuota(nc.Quota)
+			}
+		}
+
+		event := &auth.AuditEvent{
+			Principal: auth.AuditPrincipalCoordinator,
+			Operation: auth.AuditOperationChangeQuota,
+			Namespace: name,
+		}
+		if nc.Quota != nil {
+			event.Attributes = map[string]string{
+				"max-bytes":      fmt.Sprintf("%d", nc.Quota.MaxBytes),
+				"max-keys":       fmt.Sprintf("%d", nc.Quota.MaxKeys),
+				"max-value-size": fmt.Sprintf("%d", nc.Quota.MaxValueSize),
+			}
+		}
+		c.auditLog.Log(event, nil)
+	}
+}
+
+func (c *coordinator) auditReplicationFactorChanges(clusterStatus *model.ClusterStatus, namespaces []string) {
+	for _, namespace := range namespaces {
+		c.auditLog.Log(&auth.AuditEvent{
+			Principal: auth.AuditPrincipalCoordinator,
+			Operation: auth.AuditOperationChangeReplicationFactor,
+			Namespace: namespace,
+			Attributes: map[string]string{
+				"replication-factor": fmt.Sprintf("%d", clusterStatus.Namespaces[namespace].ReplicationFactor),
+			},
+		}, nil)
+	}
+}
+
+// Propagate the changes in the observers of the existing namespaces to their shards.
+// This is called while already holding the lock on the coordinator.
+func (c *coordinator) updateObservers(newClusterConfig *model.ClusterConfig) {
+	for name, ns := range c.clusterStatus.Namespaces {
+		nc := findNamespaceConfig(newClusterConfig, name)
+		if nc == nil {
+			continue
+		}
+
+		for shard, sm := range ns.Shards {
+			if len(removeFromList(sm.Observers, nc.Observers)) == 0 &&
+				len(removeFromList(nc.Observers, sm.Observers)) == 0 {
+				continue
+			}
+
+			if sc, ok := c.shardControllers[shard]; ok {
+				c.log.Info(
+					"Detected observers change",
+					slog.String("namespace", name),
+					slog.Int64("shard", shard),
+					slog.Any("observers", nc.Observers),
+				)
+				sc.SetObservers(slices.Clone(nc.Observers))
+
+				event := c.newAuditEvent(auth.AuditOperationChangeObservers, shard)
+				event.Attributes = map[string]string{"observers": serversList(nc.Observers)}
+				c.auditLog.Log(event, nil)
+			}
+		}
+	}
+}
+
 //nolint:unparam
 func (c *coordinator) rebalanceCluster() error {
 	c.Lock()
@@ -491,32 +662,231 @@ func (c *coordinator) rebalanceCluster() error {
 	c.Unlock()
 
 	for _, swapAction := range actions {
-		c.log.Info(
-			"Applying swap action",
+		c.applySwapAction(swapAction)
+	}
+
+	return nil
+}
+
+func (c *coordinator) applySwapAction(swapAction SwapNodeAction) {
+	c.log.Info(
+		"Applying swap action",
+		slog.Any("swap-action", swapAction),
+	)
+
+	c.Lock()
+	sc, ok := c.shardControllers[swapAction.Shard]
+	event := c.newAuditEvent(auth.AuditOperationSwapNode, swapAction.Shard)
+	c.Unlock()
+	if !ok {
+		c.log.Warn(
+			"Shard controller not found",
+			slog.Int64("shard", swapAction.Shard),
+		)
+		return
+	}
+
+	err := sc.SwapNode(swapAction.From, swapAction.To)
+	if err != nil {
+		c.log.Warn(
+			"Failed to swap node",
+			slog.Any("error", err),
 			slog.Any("swap-action", swapAction),
 		)
+	}
+
+	event.Attributes = map[string]string{
+		"from": swapAction.From.GetIdentifier(),
+		"to":   swapAction.To.GetIdentifier(),
+	}
+	c.auditLog.Log(event, err)
+}
+
+func (c *coordinator) loadBalancerInterval() time.Duration {
+	c.Lock()
+	defer c.Unlock()
+
+	if lbc := c.ClusterConfig.LoadBalancer; lbc != nil && lbc.Interval > 0 {
+		return lbc.Interval
+	}
+	return defaultLoadBalancerInterval
+}
+
+// Move leaderships and replicas based on the load reported by the storage
+// nodes, if the load balancer is enabled.
+func (c *coordinator) rebalanceClusterLoad() {
+	c.Lock()
+	lbc := c.ClusterConfig.LoadBalancer
+	nodeControllers := make(map[string]NodeController)
+	for id, nc := range c.nodeControllers {
+		nodeControllers[id] = nc
+	}
+	c.Unlock()
+
+	if lbc == nil || !lbc.Enabled {
+		return
+	}
+
+	maxMoves := lbc.MaxMovesPerInterval
+	if maxMoves <= 0 {
+		maxMoves = defaultLoadBalancerMaxMoves
+	}
+
+	load := ClusterLoad{}
+	for id, nc := range nodeControllers {
+		load[id] = nc.ShardsLoad()
+	}
+
+	c.Lock()
+	leaderActions, swapActions := rebalanceClusterLoad(c.ClusterConfig.Servers, c.clusterStatus, load, maxMoves)
+	c.Unlock()
+
+	for _, action := range leaderActions {
+		c.applyTransferLeaderAction(action)
+	}
+
+	for _, swapAction := range swapActions {
+		c.applySwapAction(swapAction)
+	}
+}
+
+func (c *coordinator) applyTransferLeaderAction(action TransferLeaderAction) {
+	c.log.Info(
+		"Applying transfer leader action",
+		slog.Any("transfer-leader-action", action),
+	)
+
+	c.Lock()
+	sc, ok := c.shardControllers[action.Shard]
+	event := c.newAuditEvent(auth.AuditOperationTransferLeader, action.Shard)
+	c.Unlock()
+	if !ok {
+		c.log.Warn(
+			"Shard controller not found",
+			slog.Int64("shard", action.Shard),
+		)
+		return
+	}
+
+	err := sc.TransferLeadership(action.To)
+	if err != nil {
+		c.log.Warn(
+			"Failed to transfer leadership",
+			slog.Any("error", err),
+			slog.Any("transfer-leader-action", action),
+		)
+	}
+
+	event.Attributes = map[string]string{
+		"from": action.From.GetIdentifier(),
+		"to":   action.To.GetIdentifier(),
+	}
+	c.auditLog.Log(event, err)
+}
+
+func (c *coordinator) leaderBalancerInterval() time.Duration {
+	c.Lock()
+	defer c.Unlock()
+
+	if lbc := c.ClusterConfig.LeaderBalancer; lbc != nil && lbc.Interval > 0 {
+		return lbc.Interval
+	}
+	return defaultLeaderBalancerInterval
+}
+
+// Spread the leaderships evenly across the servers. When the load balancer is
+// enabled, the leaderships are instead placed based on the actual load.
+func (c *coordinator) rebalanceLeaders() {
+	c.Lock()
+	if lbc := c.ClusterConfig.LoadBalancer; lbc != nil && lbc.Enabled {
+		c.Unlock()
+		return
+	}
+
+	maxMoves := defaultLeaderBalancerMaxMoves
+	if lbc := c.ClusterConfig.LeaderBalancer; lbc != nil {
+		if !lbc.Enabled.Get() {
+			c.Unlock()
+			return
+		}
+		if lbc.MaxMovesPerInterval > 0 {
+			maxMoves = lbc.MaxMovesPerInterval
+		}
+	}
+
+	actions := rebalanceLeaders(c.ClusterConfig.Servers, c.clusterStatus, maxMoves)
+	c.Unlock()
+
+	for _, action := range actions {
+		c.applyTransferLeaderAction(action)
+	}
+}
+
+// Bring the ensemble of each shard in line with the
+// replication factor of its namespace.
+func (c *coordinator) applyEnsembleChanges() {
+	c.Lock()
+	actions := computeEnsembleChanges(c.ClusterConfig.Servers, c.clusterStatus)
+	c.Unlock()
+
+	for _, action := range actions {
+		c.log.Info(
+			"Applying ensemble change action",
+			slog.Any("ensemble-change-action", action),
+		)
 
 		c.Lock()
-		sc, ok := c.shardControllers[swapAction.Shard]
+		sc, ok := c.shardControllers[action.Shard]
+		event := c.newAuditEvent(auth.AuditOperationChangeEnsemble, action.Shard)
 		c.Unlock()
 		if !ok {
 			c.log.Warn(
 				"Shard controller not found",
-				slog.Int64("shard", swapAction.Shard),
+				slog.Int64("shard", action.Shard),
 			)
 			continue
 		}
 
-		if err := sc.SwapNode(swapAction.From, swapAction.To); err != nil {
+		err := sc.ChangeEnsemble(action.Add, action.Remove)
+		if err != nil {
 			c.log.Warn(
-				"Failed to swap node",
+				"Failed to change ensemble",
 				slog.Any("error", err),
-				slog.Any("swap-action", swapAction),
+				slog.Any("ensemble-change-action", action),
 			)
 		}
+
+		event.Attributes = map[string]string{
+			"add":    serversList(action.Add),
+			"remove": serversList(action.Remove),
+		}
+		c.auditLog.Log(event, err)
 	}
+}
 
-	return nil
+// Returns the audit event of an action taken by the coordinator on a shard.
+// This is called while already holding the lock on the coordinator.
+func (c *coordinator) newAuditEvent(operation string, shard int64) *auth.AuditEvent {
+	event := &auth.AuditEvent{
+		Principal: auth.AuditPrincipalCoordinator,
+		Operation: operation,
+		Shard:     &shard,
+	}
+	for name, ns := range c.clusterStatus.Namespaces {
+		if _, ok := ns.Shards[shard]; ok {
+			event.Namespace = name
+			break
+		}
+	}
+	return event
+}
+
+func serversList(servers []model.Server) string {
+	identifiers := make([]string, 0, len(servers))
+	for _, s := range servers {
+		identifiers = append(identifiers, s.GetIdentifier())
+	}
+	return strings.Join(identifiers, ",")
 }
 
 func (c *coordinator) FindServerByIdentifier(identifier string) (*model.Server, bool) {
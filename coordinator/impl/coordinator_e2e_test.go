@@ -15,10 +15,14 @@
 package impl
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -29,9 +33,40 @@ import (
 	"github.com/streamnative/oxia/common"
 	"github.com/streamnative/oxia/coordinator/model"
 	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/proto"
 	"github.com/streamnative/oxia/server"
+	"github.com/streamnative/oxia/server/auth"
+	"github.com/streamnative/oxia/server/backup"
 )
 
+type memoryAuditSink struct {
+	sync.Mutex
+	events []*auth.AuditEvent
+}
+
+func (s *memoryAuditSink) Write(event *auth.AuditEvent) error {
+	s.Lock()
+	defer s.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *memoryAuditSink) operations(operation string) []*auth.AuditEvent {
+	s.Lock()
+	defer s.Unlock()
+	var events []*auth.AuditEvent
+	for _, e := range s.events {
+		if e.Operation == operation {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func (*memoryAuditSink) Close() error {
+	return nil
+}
+
 func newServer(t *testing.T) (s *server.Server, addr model.Server) {
 	t.Helper()
 
@@ -71,7 +106,7 @@ func TestCoordinatorE2E(t *testing.T) {
 	}
 	clientPool := common.NewClientPool(nil, nil)
 
-	coordinator, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool))
+	coordinator, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool), nil)
 
 	assert.NoError(t, err)
 
@@ -109,7 +144,7 @@ func TestCoordinatorE2E_ShardsRanges(t *testing.T) {
 	}
 	clientPool := common.NewClientPool(nil, nil)
 
-	coordinator, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool))
+	coordinator, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 
 	cs := coordinator.ClusterStatus()
@@ -162,7 +197,7 @@ func TestCoordinator_LeaderFailover(t *testing.T) {
 	}
 	clientPool := common.NewClientPool(nil, nil)
 
-	coordinator, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool))
+	coordinator, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 
 	nsStatus := coordinator.ClusterStatus().Namespaces[common.DefaultNamespace]
@@ -266,7 +301,7 @@ func TestCoordinator_MultipleNamespaces(t *testing.T) {
 	}
 	clientPool := common.NewClientPool(nil, nil)
 
-	coordinator, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool))
+	coordinator, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 
 	nsDefaultStatus := coordinator.ClusterStatus().Namespaces[common.DefaultNamespace]
@@ -357,7 +392,7 @@ func TestCoordinator_DeleteNamespace(t *testing.T) {
 	}
 	clientPool := common.NewClientPool(nil, nil)
 
-	coordinator, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool))
+	coordinator, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 
 	ns1Status := coordinator.ClusterStatus().Namespaces["my-ns-1"]
@@ -403,7 +438,7 @@ func TestCoordinator_DeleteNamespace(t *testing.T) {
 	}
 
 	slog.Info("Restarting coordinator")
-	coordinator, err = NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return newClusterConfig, nil }, nil, NewRpcProvider(clientPool))
+	coordinator, err = NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return newClusterConfig, nil }, nil, NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 
 	// Wait for all shards to be deleted
@@ -445,7 +480,7 @@ func TestCoordinator_DynamicallAddNamespace(t *testing.T) {
 		return clusterConfig, nil
 	}
 
-	coordinator, err := NewCoordinator(metadataProvider, configProvider, configChangesCh, NewRpcProvider(clientPool))
+	coordinator, err := NewCoordinator(metadataProvider, configProvider, configChangesCh, NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 
 	ns1Status := coordinator.ClusterStatus().Namespaces["my-ns-1"]
@@ -536,7 +571,7 @@ func TestCoordinator_RebalanceCluster(t *testing.T) {
 	}
 
 	configChangesCh := make(chan any)
-	coordinator, err := NewCoordinator(metadataProvider, configProvider, configChangesCh, NewRpcProvider(clientPool))
+	coordinator, err := NewCoordinator(metadataProvider, configProvider, configChangesCh, NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 
 	ns1Status := coordinator.ClusterStatus().Namespaces["my-ns-1"]
@@ -601,6 +636,378 @@ func TestCoordinator_RebalanceCluster(t *testing.T) {
 	}
 }
 
+func TestCoordinator_ChangeReplicationFactor(t *testing.T) {
+	s1, sa1 := newServer(t)
+	s2, sa2 := newServer(t)
+	s3, sa3 := newServer(t)
+	servers := map[model.Server]*server.Server{
+		sa1: s1,
+		sa2: s2,
+		sa3: s3,
+	}
+
+	metadataProvider := NewMetadataProviderMemory()
+	clusterConfig := model.ClusterConfig{
+		Namespaces: []model.NamespaceConfig{{
+			Name:              common.DefaultNamespace,
+			ReplicationFactor: 1,
+			InitialShardCount: 1,
+		}},
+		Servers: []model.Server{sa1, sa2, sa3},
+	}
+	clientPool := common.NewClientPool(nil, nil)
+	mutex := &sync.Mutex{}
+
+	configProvider := func() (model.ClusterConfig, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return clusterConfig, nil
+	}
+
+	sink := &memoryAuditSink{}
+	auditLog, err := auth.NewAuditLog(auth.AuditOptions{Sink: sink})
+	assert.NoError(t, err)
+
+	configChangesCh := make(chan any)
+	c, err := NewCoordinator(metadataProvider, configProvider, configChangesCh, NewRpcProvider(clientPool), auditLog)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		shard := c.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+		return shard.Status == model.ShardStatusSteadyState
+	}, 10*time.Second, 10*time.Millisecond)
+
+	client, err := oxia.NewSyncClient(sa1.Public)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	_, version1, err := client.Put(ctx, "my-key", []byte("my-value"))
+	assert.NoError(t, err)
+	assert.NoError(t, client.Close())
+
+	// Grow the replication factor
+	mutex.Lock()
+	clusterConfig.Namespaces = []model.NamespaceConfig{{
+		Name:              common.DefaultNamespace,
+		ReplicationFactor: 3,
+		InitialShardCount: 1,
+	}}
+	mutex.Unlock()
+
+	configChangesCh <- nil
+
+	assert.Eventually(t, func() bool {
+		ns := c.ClusterStatus().Namespaces[common.DefaultNamespace]
+		shard := ns.Shards[0]
+		return ns.ReplicationFactor == 3 && len(shard.Ensemble) == 3 &&
+			shard.Status == model.ShardStatusSteadyState
+	}, 30*time.Second, 10*time.Millisecond)
+
+	ns := c.ClusterStatus().Namespaces[common.DefaultNamespace]
+	checkServerLists(t, []model.Server{sa1, sa2, sa3}, ns.Shards[0].Ensemble)
+
+	events := sink.operations(auth.AuditOperationChangeReplicationFactor)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, common.DefaultNamespace, events[0].Namespace)
+		assert.Equal(t, "3", events[0].Attributes["replication-factor"])
+		assert.Equal(t, auth.AuditPrincipalCoordinator, events[0].Principal)
+	}
+
+	// Wait for the new members to catch up with the leader
+	rpc := c.(*coordinator).rpc
+	assert.Eventually(t, func() bool {
+		ls, err := rpc.GetStatus(ctx, *ns.Shards[0].Leader, &proto.GetStatusRequest{Shard: 0})
+		if err != nil {
+			return false
+		}
+		for _, member := range ns.Shards[0].Ensemble {
+			fs, err := rpc.GetStatus(ctx, member, &proto.GetStatusRequest{Shard: 0})
+			if err != nil || fs.HeadOffset < ls.HeadOffset {
+				return false
+			}
+		}
+		return true
+	}, 30*time.Second, 10*time.Millisecond)
+
+	// Stop the leader, the data must have been replicated to the new members
+	leader := *ns.Shards[0].Leader
+	assert.NoError(t, servers[leader].Close())
+	delete(servers, leader)
+
+	var newLeader model.Server
+	assert.Eventually(t, func() bool {
+		shard := c.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+		if shard.Status != model.ShardStatusSteadyState || shard.Leader.GetIdentifier() == leader.GetIdentifier() {
+			return false
+		}
+		newLeader = *shard.Leader
+		return true
+	}, 10*time.Second, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		client, _ = oxia.NewSyncClient(newLeader.Public)
+		_, _, _, err := client.Get(ctx, "my-key")
+		return err == nil
+	}, 10*time.Second, 10*time.Millisecond)
+
+	_, res, version2, err := client.Get(ctx, "my-key")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("my-value"), res)
+	assert.Equal(t, version1, version2)
+	assert.NoError(t, client.Close())
+
+	assert.NoError(t, c.Close())
+	assert.NoError(t, clientPool.Close())
+
+	for _, serverObj := range servers {
+		assert.NoError(t, serverObj.Close())
+	}
+}
+
+func TestCoordinator_ChangeQuota(t *testing.T) {
+	s1, sa1 := newServer(t)
+
+	metadataProvider := NewMetadataProviderMemory()
+	clusterConfig := model.ClusterConfig{
+		Namespaces: []model.NamespaceConfig{{
+			Name:              common.DefaultNamespace,
+			ReplicationFactor: 1,
+			InitialShardCount: 1,
+		}},
+		Servers: []model.Server{sa1},
+	}
+	clientPool := common.NewClientPool(nil, nil)
+	mutex := &sync.Mutex{}
+
+	configProvider := func() (model.ClusterConfig, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return clusterConfig, nil
+	}
+
+	sink := &memoryAuditSink{}
+	auditLog, err := auth.NewAuditLog(auth.AuditOptions{Sink: sink})
+	assert.NoError(t, err)
+
+	configChangesCh := make(chan any)
+	c, err := NewCoordinator(metadataProvider, configProvider, configChangesCh, NewRpcProvider(clientPool), auditLog)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		shard := c.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+		return shard.Status == model.ShardStatusSteadyState
+	}, 10*time.Second, 10*time.Millisecond)
+	term := c.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0].Term
+
+	client, err := oxia.NewSyncClient(sa1.Public)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, _, err = client.Put(ctx, fmt.Sprintf("key-%d", i), []byte("value"))
+		assert.NoError(t, err)
+	}
+
+	// Set a quota on the running cluster
+	mutex.Lock()
+	clusterConfig.Namespaces = []model.NamespaceConfig{{
+		Name:              common.DefaultNamespace,
+		ReplicationFactor: 1,
+		InitialShardCount: 1,
+		Quota:             &model.QuotaConfig{MaxKeys: 3},
+	}}
+	mutex.Unlock()
+
+	configChangesCh <- nil
+
+	assert.Eventually(t, func() bool {
+		shard := c.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+		return shard.Term > term && shard.Status == model.ShardStatusSteadyState
+	}, 10*time.Second, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		_, _, err = client.Put(ctx, "key-3", []byte("value"))
+		return errors.Is(err, oxia.ErrQuotaExceeded)
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// The existing records can still be updated
+	_, _, err = client.Put(ctx, "key-0", []byte("new-value"))
+	assert.NoError(t, err)
+
+	events := sink.operations(auth.AuditOperationChangeQuota)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, common.DefaultNamespace, events[0].Namespace)
+		assert.Equal(t, "3", events[0].Attributes["max-keys"])
+	}
+
+	assert.NoError(t, client.Close())
+	assert.NoError(t, c.Close())
+	assert.NoError(t, clientPool.Close())
+	assert.NoError(t, s1.Close())
+}
+
+func TestCoordinator_TransferLeadership(t *testing.T) {
+	s1, sa1 := newServer(t)
+	s2, sa2 := newServer(t)
+	s3, sa3 := newServer(t)
+
+	metadataProvider := NewMetadataProviderMemory()
+	clusterConfig := model.ClusterConfig{
+		Namespaces: []model.NamespaceConfig{{
+			Name:              common.DefaultNamespace,
+			ReplicationFactor: 3,
+			InitialShardCount: 1,
+		}},
+		Servers: []model.Server{sa1, sa2, sa3},
+	}
+	clientPool := common.NewClientPool(nil, nil)
+
+	c, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool), nil)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		shard := c.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+		return shard.Status == model.ShardStatusSteadyState
+	}, 10*time.Second, 10*time.Millisecond)
+
+	client, err := oxia.NewSyncClient(sa1.Public)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	_, version1, err := client.Put(ctx, "my-key", []byte("my-value"))
+	assert.NoError(t, err)
+
+	shard := c.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+	var newLeader model.Server
+	for _, member := range shard.Ensemble {
+		if member.GetIdentifier() != shard.Leader.GetIdentifier() {
+			newLeader = member
+			break
+		}
+	}
+
+	// Keep writing while the leadership is transferred, the
+	// handover must not make any of the writes fail
+	stopWriting := make(chan struct{})
+	writeErrors := make(chan error, 1000)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stopWriting:
+				return
+			default:
+			}
+
+			if _, _, err := client.Put(ctx, fmt.Sprintf("key-%d", i), []byte("value")); err != nil {
+				writeErrors <- err
+			}
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	c.(*coordinator).Lock()
+	sc := c.(*coordinator).shardControllers[0]
+	c.(*coordinator).Unlock()
+	assert.NoError(t, sc.TransferLeadership(newLeader))
+
+	shard = c.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+	assert.Equal(t, model.ShardStatusSteadyState, shard.Status)
+	assert.Equal(t, newLeader, *shard.Leader)
+
+	time.Sleep(500 * time.Millisecond)
+	close(stopWriting)
+	wg.Wait()
+	close(writeErrors)
+	for err := range writeErrors {
+		assert.NoError(t, err)
+	}
+
+	// Transferring to a server outside the ensemble fails
+	assert.Error(t, sc.TransferLeadership(model.Server{Public: "other:6648", Internal: "other:6649"}))
+
+	assert.Eventually(t, func() bool {
+		_, res, version2, err := client.Get(ctx, "my-key")
+		return err == nil && string(res) == "my-value" && version1.VersionId == version2.VersionId
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.NoError(t, client.Close())
+
+	assert.NoError(t, c.Close())
+	assert.NoError(t, clientPool.Close())
+
+	assert.NoError(t, s1.Close())
+	assert.NoError(t, s2.Close())
+	assert.NoError(t, s3.Close())
+}
+
+func TestCoordinator_LeaderBalancer(t *testing.T) {
+	s1, sa1 := newServer(t)
+	s2, sa2 := newServer(t)
+	s3, sa3 := newServer(t)
+
+	metadataProvider := NewMetadataProviderMemory()
+	clusterConfig := model.ClusterConfig{
+		Namespaces: []model.NamespaceConfig{{
+			Name:              common.DefaultNamespace,
+			ReplicationFactor: 3,
+			InitialShardCount: 3,
+		}},
+		Servers: []model.Server{sa1, sa2, sa3},
+		LeaderBalancer: &model.LeaderBalancerConfig{
+			Interval: 1 * time.Second,
+		},
+	}
+	clientPool := common.NewClientPool(nil, nil)
+
+	c, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool), nil)
+	assert.NoError(t, err)
+
+	allShardsSteady := func() bool {
+		for _, shard := range c.ClusterStatus().Namespaces[common.DefaultNamespace].Shards {
+			if shard.Status != model.ShardStatusSteadyState {
+				return false
+			}
+		}
+		return true
+	}
+	assert.Eventually(t, allShardsSteady, 10*time.Second, 10*time.Millisecond)
+
+	// Pile up all the leaderships on the first server
+	c.(*coordinator).Lock()
+	shardControllers := make([]ShardController, 0)
+	for _, sc := range c.(*coordinator).shardControllers {
+		shardControllers = append(shardControllers, sc)
+	}
+	c.(*coordinator).Unlock()
+	for _, sc := range shardControllers {
+		_ = sc.TransferLeadership(sa1)
+	}
+
+	// The leader balancer spreads them again
+	assert.Eventually(t, func() bool {
+		if !allShardsSteady() {
+			return false
+		}
+
+		leaders := map[string]int{}
+		for _, shard := range c.ClusterStatus().Namespaces[common.DefaultNamespace].Shards {
+			leaders[shard.Leader.GetIdentifier()]++
+		}
+		return leaders[sa1.GetIdentifier()] == 1 && leaders[sa2.GetIdentifier()] == 1 &&
+			leaders[sa3.GetIdentifier()] == 1
+	}, 30*time.Second, 100*time.Millisecond)
+
+	assert.NoError(t, c.Close())
+	assert.NoError(t, clientPool.Close())
+
+	assert.NoError(t, s1.Close())
+	assert.NoError(t, s2.Close())
+	assert.NoError(t, s3.Close())
+}
+
 func TestCoordinator_AddRemoveNodes(t *testing.T) {
 	s1, sa1 := newServer(t)
 	s2, sa2 := newServer(t)
@@ -631,7 +1038,7 @@ func TestCoordinator_AddRemoveNodes(t *testing.T) {
 	}
 
 	configChangesCh := make(chan any)
-	c, err := NewCoordinator(metadataProvider, configProvider, configChangesCh, NewRpcProvider(clientPool))
+	c, err := NewCoordinator(metadataProvider, configProvider, configChangesCh, NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 
 	assert.Equal(t, 3, len(c.(*coordinator).getNodeControllers()))
@@ -693,7 +1100,7 @@ func TestCoordinator_ShrinkCluster(t *testing.T) {
 	}
 
 	configChangesCh := make(chan any)
-	c, err := NewCoordinator(metadataProvider, configProvider, configChangesCh, NewRpcProvider(clientPool))
+	c, err := NewCoordinator(metadataProvider, configProvider, configChangesCh, NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 
 	// Wait for all shards to be ready
@@ -790,7 +1197,7 @@ func TestCoordinator_RefreshServerInfo(t *testing.T) {
 	c, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) {
 		return clusterConfig, nil
 	}, configChangesCh,
-		NewRpcProvider(common.NewClientPool(nil, nil)))
+		NewRpcProvider(common.NewClientPool(nil, nil)), nil)
 	assert.NoError(t, err)
 
 	// wait for all shards to be ready
@@ -847,3 +1254,324 @@ func TestCoordinator_RefreshServerInfo(t *testing.T) {
 	err = c.Close()
 	assert.NoError(t, err)
 }
+
+func TestCoordinator_BulkLoadFailover(t *testing.T) {
+	s1, sa1 := newServer(t)
+	s2, sa2 := newServer(t)
+	s3, sa3 := newServer(t)
+	servers := map[model.Server]*server.Server{
+		sa1: s1,
+		sa2: s2,
+		sa3: s3,
+	}
+
+	metadataProvider := NewMetadataProviderMemory()
+	clusterConfig := model.ClusterConfig{
+		Namespaces: []model.NamespaceConfig{{
+			Name:              common.DefaultNamespace,
+			ReplicationFactor: 3,
+			InitialShardCount: 1,
+		}},
+		Servers: []model.Server{sa1, sa2, sa3},
+	}
+	clientPool := common.NewClientPool(nil, nil)
+
+	coordinator, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool), nil)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		shard := coordinator.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+		return shard.Status == model.ShardStatusSteadyState
+	}, 10*time.Second, 10*time.Millisecond)
+
+	leader := *coordinator.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0].Leader
+	var follower model.Server
+	for serverObj := range servers {
+		if serverObj != leader {
+			follower = serverObj
+			break
+		}
+	}
+
+	var records []byte
+	for i := 0; i < 100; i++ {
+		record, err := (&proto.ExportedRecord{
+			Key:   fmt.Sprintf("key-%d", i),
+			Value: []byte(fmt.Sprint(i)),
+		}).MarshalVT()
+		assert.NoError(t, err)
+		records = binary.AppendUvarint(records, uint64(len(record)))
+		records = append(records, record...)
+	}
+
+	ctx := context.Background()
+	count, err := backup.BulkLoad(ctx, clientPool, backup.BulkLoadOptions{
+		ServiceAddress: follower.Public,
+		Namespace:      common.DefaultNamespace,
+		WorkDir:        filepath.Join(t.TempDir(), "work"),
+	}, bytes.NewReader(records))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 100, count)
+
+	// The followers must have ingested the records as well
+	assert.NoError(t, servers[leader].Close())
+	delete(servers, leader)
+
+	assert.Eventually(t, func() bool {
+		shard := coordinator.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+		return shard.Status == model.ShardStatusSteadyState
+	}, 10*time.Second, 10*time.Millisecond)
+
+	var client oxia.SyncClient
+	assert.Eventually(t, func() bool {
+		client, _ = oxia.NewSyncClient(follower.Public)
+		_, _, _, err := client.Get(ctx, "key-0")
+		return err == nil
+	}, 10*time.Second, 10*time.Millisecond)
+
+	for i := 0; i < 100; i++ {
+		_, value, _, err := client.Get(ctx, fmt.Sprintf("key-%d", i))
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprint(i), string(value))
+	}
+	assert.NoError(t, client.Close())
+
+	assert.NoError(t, coordinator.Close())
+	assert.NoError(t, clientPool.Close())
+
+	for _, serverObj := range servers {
+		assert.NoError(t, serverObj.Close())
+	}
+}
+
+func TestCoordinator_Observers(t *testing.T) {
+	s1, sa1 := newServer(t)
+	s2, sa2 := newServer(t)
+	s3, sa3 := newServer(t)
+	so, sao := newServer(t)
+	servers := map[model.Server]*server.Server{
+		sa1: s1,
+		sa2: s2,
+		sa3: s3,
+	}
+
+	metadataProvider := NewMetadataProviderMemory()
+	clusterConfig := model.ClusterConfig{
+		Namespaces: []model.NamespaceConfig{{
+			Name:              common.DefaultNamespace,
+			ReplicationFactor: 3,
+			InitialShardCount: 1,
+			Observers:         []model.Server{sao},
+		}},
+		Servers: []model.Server{sa1, sa2, sa3},
+	}
+	clientPool := common.NewClientPool(nil, nil)
+
+	configChangesCh := make(chan any)
+	coordinator, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil },
+		configChangesCh, NewRpcProvider(clientPool), nil)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		shard := coordinator.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+		return shard.Status == model.ShardStatusSteadyState
+	}, 10*time.Second, 10*time.Millisecond)
+
+	shard := coordinator.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+	assert.Equal(t, []model.Server{sao}, shard.Observers)
+	assert.NotContains(t, shard.Ensemble, sao)
+	leader := *shard.Leader
+
+	ctx := context.Background()
+	client, err := oxia.NewSyncClient(sa1.Public)
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, _, err := client.Put(ctx, fmt.Sprintf("key-%d", i), []byte(fmt.Sprint(i)))
+		assert.NoError(t, err)
+	}
+	// The commit offset is propagated along with the following entries
+	_, _, err = client.Put(ctx, "marker", []byte("0"))
+	assert.NoError(t, err)
+
+	observerClient, err := oxia.NewSyncClient(sa1.Public, oxia.WithObserverReads(sao.Public))
+	assert.NoError(t, err)
+
+	// The observer receives the log without being part of the quorum
+	assert.Eventually(t, func() bool {
+		keys, err := observerClient.List(ctx, "key-", "key-~")
+		return err == nil && len(keys) == 10
+	}, 10*time.Second, 10*time.Millisecond)
+
+	_, value, _, err := observerClient.Get(ctx, "key-5")
+	assert.NoError(t, err)
+	assert.Equal(t, "5", string(value))
+
+	// The observer is never elected, and it's attached again to the new leader
+	assert.NoError(t, servers[leader].Close())
+	delete(servers, leader)
+
+	assert.Eventually(t, func() bool {
+		shard := coordinator.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+		return shard.Status == model.ShardStatusSteadyState && shard.Leader != nil && *shard.Leader != leader
+	}, 10*time.Second, 10*time.Millisecond)
+
+	shard = coordinator.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+	assert.NotEqual(t, sao, *shard.Leader)
+	assert.NoError(t, client.Close())
+
+	var follower model.Server
+	for sa := range servers {
+		follower = sa
+		break
+	}
+
+	assert.Eventually(t, func() bool {
+		client, _ = oxia.NewSyncClient(follower.Public)
+		_, _, err := client.Put(ctx, "key-10", []byte("10"))
+		return err == nil
+	}, 10*time.Second, 10*time.Millisecond)
+	_, _, err = client.Put(ctx, "marker", []byte("1"))
+	assert.NoError(t, err)
+	assert.NoError(t, client.Close())
+
+	assert.Eventually(t, func() bool {
+		_ = observerClient.Close()
+		observerClient, _ = oxia.NewSyncClient(follower.Public, oxia.WithObserverReads(sao.Public))
+		_, value, _, err := observerClient.Get(ctx, "key-10")
+		return err == nil && string(value) == "10"
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.NoError(t, observerClient.Close())
+
+	// Removing the observer from the namespace
+	clusterConfig.Namespaces = []model.NamespaceConfig{{
+		Name:              common.DefaultNamespace,
+		ReplicationFactor: 3,
+		InitialShardCount: 1,
+	}}
+	configChangesCh <- nil
+
+	assert.Eventually(t, func() bool {
+		shard := coordinator.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+		return shard.Status == model.ShardStatusSteadyState && len(shard.Observers) == 0
+	}, 10*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, coordinator.Close())
+	assert.NoError(t, clientPool.Close())
+
+	for _, serverObj := range servers {
+		assert.NoError(t, serverObj.Close())
+	}
+	assert.NoError(t, so.Close())
+}
+
+func TestCoordinator_Witnesses(t *testing.T) {
+	configs := map[model.Server]server.Config{}
+	servers := map[model.Server]*server.Server{}
+	for i := 0; i < 3; i++ {
+		config := server.Config{
+			PublicServiceAddr:          "localhost:0",
+			InternalServiceAddr:        "localhost:0",
+			DataDir:                    t.TempDir(),
+			WalDir:                     t.TempDir(),
+			NotificationsRetentionTime: 1 * time.Minute,
+		}
+		s, err := server.New(config)
+		assert.NoError(t, err)
+
+		// Keep the same addresses and directories for when the server is restarted
+		sa := model.Server{
+			Public:   fmt.Sprintf("localhost:%d", s.PublicPort()),
+			Internal: fmt.Sprintf("localhost:%d", s.InternalPort()),
+		}
+		config.PublicServiceAddr = sa.Public
+		config.InternalServiceAddr = sa.Internal
+		servers[sa] = s
+		configs[sa] = config
+	}
+
+	serverList := make([]model.Server, 0, len(servers))
+	for sa := range servers {
+		serverList = append(serverList, sa)
+	}
+
+	metadataProvider := NewMetadataProviderMemory()
+	clusterConfig := model.ClusterConfig{
+		Namespaces: []model.NamespaceConfig{{
+			Name:              common.DefaultNamespace,
+			ReplicationFactor: 3,
+			InitialShardCount: 1,
+			Witnesses:         1,
+		}},
+		Servers: serverList,
+	}
+	clientPool := common.NewClientPool(nil, nil)
+
+	c, err := NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, NewRpcProvider(clientPool), nil)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		shard := c.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+		return shard.Status == model.ShardStatusSteadyState
+	}, 10*time.Second, 10*time.Millisecond)
+
+	shard := c.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+	assert.Len(t, shard.Witnesses, 1)
+	witness := shard.Witnesses[0]
+	leader := *shard.Leader
+	assert.NotEqual(t, witness, leader)
+
+	var follower model.Server
+	for sa := range servers {
+		if sa != leader && sa != witness {
+			follower = sa
+		}
+	}
+
+	// The witnesses can't take over the leadership
+	c.(*coordinator).Lock()
+	sc := c.(*coordinator).shardControllers[0]
+	c.(*coordinator).Unlock()
+	assert.Error(t, sc.TransferLeadership(witness))
+
+	// With the follower down, the entries are only committed on the
+	// leader and the witness
+	assert.NoError(t, servers[follower].Close())
+
+	ctx := context.Background()
+	client, err := oxia.NewSyncClient(leader.Public)
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, _, err := client.Put(ctx, fmt.Sprintf("key-%d", i), []byte(fmt.Sprint(i)))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, client.Close())
+
+	// The follower is elected once it's back, after recovering the entries
+	// from the witness
+	assert.NoError(t, servers[leader].Close())
+	delete(servers, leader)
+
+	servers[follower], err = server.New(configs[follower])
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		shard := c.ClusterStatus().Namespaces[common.DefaultNamespace].Shards[0]
+		return shard.Status == model.ShardStatusSteadyState && shard.Leader != nil && *shard.Leader == follower
+	}, 30*time.Second, 10*time.Millisecond)
+
+	client, err = oxia.NewSyncClient(follower.Public)
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, value, _, err := client.Get(ctx, fmt.Sprintf("key-%d", i))
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprint(i), string(value))
+	}
+	assert.NoError(t, client.Close())
+
+	assert.NoError(t, c.Close())
+	assert.NoError(t, clientPool.Close())
+
+	for _, serverObj := range servers {
+		assert.NoError(t, serverObj.Close())
+	}
+}
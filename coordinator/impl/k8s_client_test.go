@@ -15,6 +15,7 @@
 package impl
 
 import (
+	"encoding/json"
 	"errors"
 	"strconv"
 
@@ -47,6 +48,26 @@ func K8SResourceVersionSupport(tracker testing.ObjectTracker) testing.ReactionFu
 			}
 			incrementVersion(objMeta)
 			return false, action.GetObject(), nil
+		case testing.PatchActionImpl:
+			existing, err := tracker.Get(gvr, ns, action.GetName())
+			if err != nil {
+				//nolint:nilerr
+				return false, nil, nil
+			}
+			existingObjMeta := accessor(existing)
+			patched := existing.DeepCopyObject()
+			if err := json.Unmarshal(action.GetPatch(), patched); err != nil {
+				return true, nil, err
+			}
+			objMeta := accessor(patched)
+			if objMeta.GetResourceVersion() != existingObjMeta.GetResourceVersion() {
+				return true, nil, k8serrors.NewConflict(gvr.GroupResource(), action.GetName(), errors.New("conflict"))
+			}
+			incrementVersion(objMeta)
+			if err := tracker.Update(gvr, patched, ns); err != nil {
+				return true, nil, err
+			}
+			return true, patched, nil
 		}
 
 		return false, nil, nil
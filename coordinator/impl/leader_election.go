@@ -0,0 +1,439 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impl
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/coordinator/model"
+)
+
+const DefaultLeaderLeaseDuration = 5 * time.Second
+
+var ErrLeadershipLost = errors.New("coordinator is not the leader")
+
+// LeaderElection allows running multiple coordinators on the same metadata, with
+// only one of them being active at any given time.
+//
+// The active coordinator holds a lease that is stored together with the cluster
+// status and that gets renewed periodically. Every renewal bumps the metadata
+// version, so the standby coordinators consider the lease expired once the
+// version stays unchanged for the whole lease duration. Since all the updates
+// are conditional on the version, a coordinator that was deposed cannot update
+// the cluster status anymore.
+type LeaderElection interface {
+	// MetadataProvider gives the active coordinator access to the cluster status.
+	MetadataProvider
+
+	// WaitForLeadership blocks until this coordinator has acquired the lease.
+	WaitForLeadership(ctx context.Context) error
+
+	// LeadershipLost returns a channel that is closed when the leadership
+	// acquired by the last WaitForLeadership call is lost.
+	LeadershipLost() <-chan struct{}
+
+	// Resign releases the lease, if held, so that a standby coordinator can
+	// take over right away.
+	Resign() error
+}
+
+type leaderElection struct {
+	sync.Mutex
+
+	// Serializes the updates to the underlying provider
+	storeLock sync.Mutex
+
+	provider      MetadataProvider
+	id            string
+	leaseDuration time.Duration
+
+	leader bool
+	lostCh chan struct{}
+
+	// The cluster status as seen by the coordinator, without the lease. It's
+	// nil when the cluster was not initialized yet.
+	clusterStatus *model.ClusterStatus
+
+	// The version of the record in the provider, which changes at every lease
+	// renewal, and the version of the last update done by the coordinator.
+	version            Version
+	coordinatorVersion Version
+
+	leaseExpiry time.Time
+	expiryTimer *time.Timer
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	log    *slog.Logger
+}
+
+func NewLeaderElection(provider MetadataProvider, id string, leaseDuration time.Duration) LeaderElection {
+	if leaseDuration <= 0 {
+		leaseDuration = DefaultLeaderLeaseDuration
+	}
+
+	e := &leaderElection{
+		provider:      provider,
+		id:            id,
+		leaseDuration: leaseDuration,
+		lostCh:        make(chan struct{}),
+		log: slog.With(
+			slog.String("component", "coordinator-leader-election"),
+			slog.String("coordinator-id", id),
+		),
+	}
+
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+	return e
+}
+
+func (e *leaderElection) WaitForLeadership(ctx context.Context) error {
+	e.log.Info(
+		"Waiting to become the leader coordinator",
+		slog.Duration("lease-duration", e.leaseDuration),
+	)
+
+	ticker := time.NewTicker(e.leaseDuration / 3)
+	defer ticker.Stop()
+
+	observer := &leaseObserver{}
+	for {
+		if e.tryAcquire(observer) {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.ctx.Done():
+			return ErrLeadershipLost
+		}
+	}
+}
+
+func (e *leaderElection) tryAcquire(observer *leaseObserver) bool {
+	cs, version, err := e.provider.Get()
+	if err != nil {
+		e.log.Warn(
+			"Failed to read the coordinator lease",
+			slog.Any("error", err),
+		)
+		return false
+	}
+
+	if !observer.isAvailable(cs, version, e.id) {
+		return false
+	}
+
+	var current *model.ClusterStatus
+	if cs != nil && !isUninitialized(cs) {
+		current = cs.Clone()
+		current.CoordinatorLease = nil
+	}
+
+	e.storeLock.Lock()
+	defer e.storeLock.Unlock()
+
+	start := time.Now()
+	newVersion, err := e.provider.Store(e.withLease(current), version)
+	if err != nil {
+		if !errors.Is(err, ErrMetadataBadVersion) {
+			e.log.Warn(
+				"Failed to acquire the coordinator lease",
+				slog.Any("error", err),
+			)
+		}
+		return false
+	}
+
+	e.Lock()
+	defer e.Unlock()
+
+	e.leader = true
+	e.lostCh = make(chan struct{})
+	e.clusterStatus = current
+	e.version = newVersion
+	e.coordinatorVersion = newVersion
+	if current == nil {
+		e.coordinatorVersion = MetadataNotExists
+	}
+
+	lostCh := e.lostCh
+	e.leaseExpiry = start.Add(e.leaseDuration)
+	e.expiryTimer = time.AfterFunc(e.leaseDuration, func() { e.checkLeaseExpiry(lostCh) })
+
+	go common.DoWithLabels(
+		e.ctx,
+		map[string]string{
+			"oxia": "coordinator-lease-renewal",
+		},
+		func() { e.renewLoop(lostCh) },
+	)
+
+	var previousHolder string
+	if cs != nil && cs.CoordinatorLease != nil {
+		previousHolder = cs.CoordinatorLease.Holder
+	}
+	e.log.Info(
+		"Became the leader coordinator",
+		slog.String("previous-holder", previousHolder),
+		slog.Any("metadata-version", newVersion),
+	)
+	return true
+}
+
+func (e *leaderElection) LeadershipLost() <-chan struct{} {
+	e.Lock()
+	defer e.Unlock()
+	return e.lostCh
+}
+
+func (e *leaderElection) Get() (cs *model.ClusterStatus, version Version, err error) {
+	e.Lock()
+	defer e.Unlock()
+
+	if !e.leader {
+		return nil, "", ErrLeadershipLost
+	}
+
+	if e.clusterStatus == nil {
+		return nil, e.coordinatorVersion, nil
+	}
+	return e.clusterStatus.Clone(), e.coordinatorVersion, nil
+}
+
+func (e *leaderElection) Store(cs *model.ClusterStatus, expectedVersion Version) (newVersion Version, err error) {
+	e.storeLock.Lock()
+	defer e.storeLock.Unlock()
+
+	e.Lock()
+	if !e.leader {
+		e.Unlock()
+		return "", ErrLeadershipLost
+	}
+	if expectedVersion != e.coordinatorVersion {
+		e.Unlock()
+		return "", ErrMetadataBadVersion
+	}
+	version := e.version
+	lostCh := e.lostCh
+	e.Unlock()
+
+	start := time.Now()
+	newVersion, err = e.provider.Store(e.withLease(cs), version)
+
+	e.Lock()
+	defer e.Unlock()
+
+	if err != nil {
+		if errors.Is(err, ErrMetadataBadVersion) {
+			e.loseLeadership(lostCh, "the metadata was updated by another coordinator")
+		}
+		return "", err
+	}
+
+	if !e.isCurrentLeadership(lostCh) {
+		return "", ErrLeadershipLost
+	}
+
+	e.clusterStatus = cs.Clone()
+	e.version = newVersion
+	e.coordinatorVersion = newVersion
+	e.leaseExpiry = start.Add(e.leaseDuration)
+	return newVersion, nil
+}
+
+func (e *leaderElection) renewLoop(lostCh chan struct{}) {
+	ticker := time.NewTicker(e.leaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.renewLease(lostCh)
+		case <-lostCh:
+			return
+		case <-e.ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *leaderElection) renewLease(lostCh chan struct{}) {
+	e.storeLock.Lock()
+	defer e.storeLock.Unlock()
+
+	e.Lock()
+	if !e.isCurrentLeadership(lostCh) {
+		e.Unlock()
+		return
+	}
+	cs := e.clusterStatus
+	version := e.version
+	e.Unlock()
+
+	start := time.Now()
+	newVersion, err := e.provider.Store(e.withLease(cs), version)
+
+	e.Lock()
+	defer e.Unlock()
+
+	if err != nil {
+		if errors.Is(err, ErrMetadataBadVersion) {
+			e.loseLeadership(lostCh, "the lease was taken over by another coordinator")
+		} else {
+			e.log.Warn(
+				"Failed to renew the coordinator lease",
+				slog.Any("error", err),
+			)
+		}
+		return
+	}
+
+	if !e.isCurrentLeadership(lostCh) {
+		return
+	}
+
+	e.version = newVersion
+	e.leaseExpiry = start.Add(e.leaseDuration)
+	e.expiryTimer.Reset(time.Until(e.leaseExpiry))
+}
+
+func (e *leaderElection) checkLeaseExpiry(lostCh chan struct{}) {
+	e.Lock()
+	defer e.Unlock()
+
+	if !e.isCurrentLeadership(lostCh) {
+		return
+	}
+
+	if remaining := time.Until(e.leaseExpiry); remaining > 0 {
+		// The lease was renewed in the meantime
+		e.expiryTimer.Reset(remaining)
+		return
+	}
+
+	e.loseLeadership(lostCh, "the lease could not be renewed in time")
+}
+
+// This is called while already holding the lock on the leader election.
+func (e *leaderElection) isCurrentLeadership(lostCh chan struct{}) bool {
+	return e.leader && e.lostCh == lostCh
+}
+
+// This is called while already holding the lock on the leader election.
+func (e *leaderElection) loseLeadership(lostCh chan struct{}, reason string) {
+	if !e.isCurrentLeadership(lostCh) {
+		return
+	}
+
+	e.log.Warn(
+		"Lost the coordinator leadership",
+		slog.String("reason", reason),
+	)
+	e.leader = false
+	e.expiryTimer.Stop()
+	close(e.lostCh)
+}
+
+func (e *leaderElection) Resign() error {
+	e.storeLock.Lock()
+	defer e.storeLock.Unlock()
+
+	e.Lock()
+	if !e.leader {
+		e.Unlock()
+		return nil
+	}
+
+	cs := e.clusterStatus
+	version := e.version
+	e.leader = false
+	e.expiryTimer.Stop()
+	close(e.lostCh)
+	e.Unlock()
+
+	e.log.Info("Releasing the coordinator lease")
+
+	released := model.NewClusterStatus()
+	if cs != nil {
+		released = cs.Clone()
+	}
+	if _, err := e.provider.Store(released, version); err != nil {
+		return errors.Wrap(err, "failed to release the coordinator lease")
+	}
+	return nil
+}
+
+func (e *leaderElection) Close() error {
+	e.cancel()
+	return multierr.Combine(
+		e.Resign(),
+		e.provider.Close(),
+	)
+}
+
+func (e *leaderElection) withLease(cs *model.ClusterStatus) *model.ClusterStatus {
+	r := model.NewClusterStatus()
+	if cs != nil {
+		r = cs.Clone()
+	}
+
+	r.CoordinatorLease = &model.CoordinatorLease{
+		Holder:   e.id,
+		Duration: e.leaseDuration,
+	}
+	return r
+}
+
+// A record that only contains a lease is stored when a coordinator is elected
+// before the cluster gets initialized.
+func isUninitialized(cs *model.ClusterStatus) bool {
+	return len(cs.Namespaces) == 0 && cs.ShardIdGenerator == 0 && cs.ServerIdx == 0
+}
+
+// The leaseObserver tracks how long the lease of another coordinator has gone
+// without renewals. It relies on the local clock only, since the clocks of the
+// different coordinators are not synchronized.
+type leaseObserver struct {
+	version Version
+	since   time.Time
+}
+
+func (o *leaseObserver) isAvailable(cs *model.ClusterStatus, version Version, id string) bool {
+	if cs == nil || cs.CoordinatorLease == nil || cs.CoordinatorLease.Holder == "" ||
+		cs.CoordinatorLease.Holder == id {
+		// There is no active coordinator, or the lease is from a previous
+		// instance of this same coordinator
+		return true
+	}
+
+	now := time.Now()
+	if version != o.version {
+		o.version = version
+		o.since = now
+		return false
+	}
+
+	return now.Sub(o.since) >= cs.CoordinatorLease.Duration
+}
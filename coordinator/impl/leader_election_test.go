@@ -0,0 +1,203 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impl
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/streamnative/oxia/coordinator/model"
+)
+
+// Each factory returns new providers that share the same underlying storage,
+// as if they were running in different coordinator processes.
+var sharedMetadataProviders = map[string]func(t *testing.T) func() MetadataProvider{
+	"memory": func(t *testing.T) func() MetadataProvider {
+		t.Helper()
+
+		m := NewMetadataProviderMemory()
+		return func() MetadataProvider { return m }
+	},
+	"file": func(t *testing.T) func() MetadataProvider {
+		t.Helper()
+
+		path := filepath.Join(t.TempDir(), "metadata")
+		return func() MetadataProvider { return NewMetadataProviderFile(path) }
+	},
+	"configmap": func(t *testing.T) func() MetadataProvider {
+		t.Helper()
+
+		f := fake.NewSimpleClientset()
+		f.PrependReactor("*", "*", K8SResourceVersionSupport(f.Tracker()))
+		return func() MetadataProvider { return NewMetadataProviderConfigMap(f, "ns", "n") }
+	},
+	"oxia": func(t *testing.T) func() MetadataProvider {
+		t.Helper()
+
+		serviceAddress := newBootstrapOxia(t)
+		return func() MetadataProvider {
+			return NewMetadataProviderOxia(newBootstrapOxiaClient(t, serviceAddress), "")
+		}
+	},
+}
+
+func newTestClusterStatus() *model.ClusterStatus {
+	return &model.ClusterStatus{
+		Namespaces: map[string]model.NamespaceStatus{
+			"ns-1": {
+				ReplicationFactor: 1,
+				Shards: map[int64]model.ShardMetadata{
+					0: newSteadyStateShard(s1, s1),
+				},
+			},
+		},
+		ShardIdGenerator: 1,
+		ServerIdx:        1,
+	}
+}
+
+func TestLeaderElection(t *testing.T) {
+	for name, factory := range sharedMetadataProviders {
+		t.Run(name, func(t *testing.T) {
+			newProvider := factory(t)
+			e1 := NewLeaderElection(newProvider(), "c1", 1*time.Second)
+			e2 := NewLeaderElection(newProvider(), "c2", 1*time.Second)
+
+			assert.NoError(t, e1.WaitForLeadership(context.Background()))
+
+			// The cluster is not initialized yet
+			cs, version, err := e1.Get()
+			assert.NoError(t, err)
+			assert.Nil(t, cs)
+			assert.Equal(t, MetadataNotExists, version)
+
+			version, err = e1.Store(newTestClusterStatus(), MetadataNotExists)
+			assert.NoError(t, err)
+
+			provider := newProvider()
+			stored, _, err := provider.Get()
+			assert.NoError(t, err)
+			assert.Equal(t, &model.CoordinatorLease{Holder: "c1", Duration: 1 * time.Second}, stored.CoordinatorLease)
+			assert.NoError(t, provider.Close())
+
+			// The lease is held by c1
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			assert.ErrorIs(t, e2.WaitForLeadership(ctx), context.DeadlineExceeded)
+			cancel()
+
+			// The lease renewals are not visible to the coordinator
+			time.Sleep(500 * time.Millisecond)
+			_, err = e1.Store(newTestClusterStatus(), version)
+			assert.NoError(t, err)
+
+			// Closing the leader releases the lease immediately
+			assert.NoError(t, e1.Close())
+			assert.Eventually(t, isClosed(e1.LeadershipLost()), 1*time.Second, 10*time.Millisecond)
+
+			ctx, cancel = context.WithTimeout(context.Background(), 500*time.Millisecond)
+			assert.NoError(t, e2.WaitForLeadership(ctx))
+			cancel()
+
+			cs, _, err = e2.Get()
+			assert.NoError(t, err)
+			assert.Equal(t, newTestClusterStatus().Clone(), cs)
+
+			assert.NoError(t, e2.Close())
+		})
+	}
+}
+
+func TestLeaderElection_Takeover(t *testing.T) {
+	for name, factory := range sharedMetadataProviders {
+		t.Run(name, func(t *testing.T) {
+			newProvider := factory(t)
+			e1 := NewLeaderElection(newProvider(), "c1", 300*time.Millisecond)
+			e2 := NewLeaderElection(newProvider(), "c2", 300*time.Millisecond)
+
+			assert.NoError(t, e1.WaitForLeadership(context.Background()))
+			version, err := e1.Store(newTestClusterStatus(), MetadataNotExists)
+			assert.NoError(t, err)
+
+			// Simulate c1 crashing, by stopping the lease renewals
+			e1.(*leaderElection).cancel()
+
+			start := time.Now()
+			assert.NoError(t, e2.WaitForLeadership(context.Background()))
+			assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+
+			// The deposed coordinator is not able to update the cluster status
+			assert.Eventually(t, isClosed(e1.LeadershipLost()), 1*time.Second, 10*time.Millisecond)
+			_, err = e1.Store(newTestClusterStatus(), version)
+			assert.ErrorIs(t, err, ErrLeadershipLost)
+
+			cs, _, err := e2.Get()
+			assert.NoError(t, err)
+			assert.Equal(t, newTestClusterStatus().Clone(), cs)
+
+			assert.NoError(t, e2.Close())
+		})
+	}
+}
+
+func TestLeaderElection_Fencing(t *testing.T) {
+	for name, factory := range sharedMetadataProviders {
+		t.Run(name, func(t *testing.T) {
+			newProvider := factory(t)
+			e1 := NewLeaderElection(newProvider(), "c1", 10*time.Second)
+
+			assert.NoError(t, e1.WaitForLeadership(context.Background()))
+			version, err := e1.Store(newTestClusterStatus(), MetadataNotExists)
+			assert.NoError(t, err)
+
+			// Another coordinator takes over the lease
+			provider := newProvider()
+			cs, realVersion, err := provider.Get()
+			assert.NoError(t, err)
+			cs.CoordinatorLease = &model.CoordinatorLease{Holder: "c2", Duration: 10 * time.Second}
+			_, err = provider.Store(cs, realVersion)
+			assert.NoError(t, err)
+
+			_, err = e1.Store(newTestClusterStatus(), version)
+			assert.ErrorIs(t, err, ErrMetadataBadVersion)
+			assert.True(t, isClosed(e1.LeadershipLost())())
+
+			_, err = e1.Store(newTestClusterStatus(), version)
+			assert.ErrorIs(t, err, ErrLeadershipLost)
+
+			// The lease of c2 is not released when c1 closes
+			assert.NoError(t, e1.Close())
+			cs, _, err = provider.Get()
+			assert.NoError(t, err)
+			assert.Equal(t, "c2", cs.CoordinatorLease.Holder)
+			assert.NoError(t, provider.Close())
+		})
+	}
+}
+
+func isClosed(ch <-chan struct{}) func() bool {
+	return func() bool {
+		select {
+		case <-ch:
+			return true
+		default:
+			return false
+		}
+	}
+}
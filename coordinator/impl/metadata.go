@@ -26,7 +26,11 @@ type Version string
 
 var (
 	ErrMetadataNotInitialized = errors.New("metadata not initialized")
-	ErrMetadataBadVersion     = errors.New("metadata bad version")
+
+	// ErrMetadataBadVersion is returned by MetadataProvider.Store when the
+	// stored metadata doesn't match the expected version, because it was
+	// updated by someone else in the meantime.
+	ErrMetadataBadVersion = errors.New("metadata bad version")
 )
 
 const MetadataNotExists Version = "-1"
@@ -36,5 +40,9 @@ type MetadataProvider interface {
 
 	Get() (cs *model.ClusterStatus, version Version, err error)
 
+	// Store replaces the cluster status, only if the stored one is still at
+	// the expected version. Otherwise, it fails with ErrMetadataBadVersion
+	// and leaves the stored status untouched. The leader election relies on
+	// it to find out that another coordinator has taken over.
 	Store(cs *model.ClusterStatus, expectedVersion Version) (newVersion Version, err error)
 }
@@ -104,16 +104,26 @@ func (m *metadataProviderConfigMap) Store(status *model.ClusterStatus, expectedV
 	}
 
 	if version != expectedVersion {
-		slog.Error("Store metadata failed for version mismatch",
+		// Expected when another coordinator has taken over the lease
+		slog.Debug("Store metadata failed for version mismatch",
 			slog.Any("local-version", version),
 			slog.Any("expected-version", expectedVersion))
-		panic(ErrMetadataBadVersion)
+		return "", ErrMetadataBadVersion
 	}
 
 	data := configMap(m.name, status, expectedVersion)
 	cm, err := K8SConfigMaps(m.kubernetes).Upsert(m.namespace, m.name, data)
-	if k8serrors.IsConflict(err) {
-		panic(err)
+	if err != nil {
+		if k8serrors.IsConflict(err) {
+			// The config map was updated after we have read it
+			slog.Debug("Store metadata failed for conflicting update",
+				slog.Any("expected-version", expectedVersion))
+			return "", ErrMetadataBadVersion
+		}
+		slog.Error("Store metadata failed",
+			slog.Any("expected-version", expectedVersion),
+			slog.Any("error", err))
+		return "", err
 	}
 	version = Version(cm.ResourceVersion)
 	m.metadataSize.Store(int64(len(data.Data["status"])))
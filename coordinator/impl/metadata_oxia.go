@@ -0,0 +1,103 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/streamnative/oxia/coordinator/model"
+	"github.com/streamnative/oxia/oxia"
+)
+
+const DefaultOxiaMetadataKey = "/coordinator/cluster-status"
+
+// metadataProviderOxia keeps the cluster status in a record of a separate
+// bootstrap Oxia cluster, which gives bare-metal deployments a replicated
+// metadata store. The record version id is used for the conditional updates.
+//
+// The bootstrap cluster cannot be the one managed by this coordinator, since
+// its metadata is needed to start the shards in the first place.
+type metadataProviderOxia struct {
+	client oxia.SyncClient
+	key    string
+}
+
+func NewMetadataProviderOxia(client oxia.SyncClient, key string) MetadataProvider {
+	if key == "" {
+		key = DefaultOxiaMetadataKey
+	}
+
+	return &metadataProviderOxia{
+		client: client,
+		key:    key,
+	}
+}
+
+func (m *metadataProviderOxia) Get() (cs *model.ClusterStatus, version Version, err error) {
+	_, value, v, err := m.client.Get(context.Background(), m.key)
+	if err != nil {
+		if errors.Is(err, oxia.ErrKeyNotFound) {
+			return nil, MetadataNotExists, nil
+		}
+		return nil, "", err
+	}
+
+	cs = &model.ClusterStatus{}
+	if err = json.Unmarshal(value, cs); err != nil {
+		return nil, "", err
+	}
+
+	version = Version(strconv.FormatInt(v.VersionId, 10))
+	slog.Debug("Get metadata successful",
+		slog.Any("version", version))
+	return cs, version, nil
+}
+
+func (m *metadataProviderOxia) Store(cs *model.ClusterStatus, expectedVersion Version) (newVersion Version, err error) {
+	value, err := json.Marshal(cs)
+	if err != nil {
+		return "", err
+	}
+
+	var option oxia.PutOption
+	if expectedVersion == MetadataNotExists {
+		option = oxia.ExpectedRecordNotExists()
+	} else {
+		versionId, err := strconv.ParseInt(string(expectedVersion), 10, 64)
+		if err != nil {
+			return "", ErrMetadataBadVersion
+		}
+		option = oxia.ExpectedVersionId(versionId)
+	}
+
+	_, v, err := m.client.Put(context.Background(), m.key, value, option)
+	if err != nil {
+		if errors.Is(err, oxia.ErrUnexpectedVersionId) {
+			return "", ErrMetadataBadVersion
+		}
+		return "", err
+	}
+
+	return Version(strconv.FormatInt(v.VersionId, 10)), nil
+}
+
+func (m *metadataProviderOxia) Close() error {
+	return m.client.Close()
+}
@@ -15,6 +15,7 @@
 package impl
 
 import (
+	"fmt"
 	"path/filepath"
 	"testing"
 
@@ -22,6 +23,8 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/streamnative/oxia/coordinator/model"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/server"
 )
 
 var (
@@ -46,9 +49,35 @@ var (
 
 			return NewMetadataProviderConfigMap(_fake, "ns", "n")
 		},
+		"oxia": func(t *testing.T) MetadataProvider {
+			t.Helper()
+
+			return NewMetadataProviderOxia(newBootstrapOxiaClient(t, newBootstrapOxia(t)), "")
+		},
 	}
 )
 
+// Start a standalone Oxia to be used as metadata store.
+func newBootstrapOxia(t *testing.T) string {
+	t.Helper()
+
+	standalone, err := server.NewStandalone(server.NewTestConfig(t.TempDir()))
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, standalone.Close())
+	})
+
+	return fmt.Sprintf("localhost:%d", standalone.RpcPort())
+}
+
+func newBootstrapOxiaClient(t *testing.T, serviceAddress string) oxia.SyncClient {
+	t.Helper()
+
+	client, err := oxia.NewSyncClient(serviceAddress)
+	assert.NoError(t, err)
+	return client
+}
+
 func TestMetadataProvider(t *testing.T) {
 	for name, provider := range metadataProviders {
 		t.Run(name, func(t *testing.T) {
@@ -59,12 +88,10 @@ func TestMetadataProvider(t *testing.T) {
 			assert.Equal(t, MetadataNotExists, version)
 			assert.Nil(t, res)
 
-			assert.PanicsWithError(t, ErrMetadataBadVersion.Error(), func() {
-				_, err := m.Store(&model.ClusterStatus{
-					Namespaces: map[string]model.NamespaceStatus{},
-				}, "")
-				assert.NoError(t, err)
-			})
+			_, err = m.Store(&model.ClusterStatus{
+				Namespaces: map[string]model.NamespaceStatus{},
+			}, "")
+			assert.ErrorIs(t, err, ErrMetadataBadVersion)
 
 			newVersion, err := m.Store(&model.ClusterStatus{
 				Namespaces: map[string]model.NamespaceStatus{},
@@ -83,3 +110,39 @@ func TestMetadataProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestMetadataProvider_ConcurrentUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata")
+	sharedProviders := map[string]func() MetadataProvider{
+		"file": func() MetadataProvider {
+			return NewMetadataProviderFile(path)
+		},
+		"configmap": func() MetadataProvider {
+			return NewMetadataProviderConfigMap(_fake, "ns", "concurrent-update")
+		},
+	}
+
+	for name, provider := range sharedProviders {
+		t.Run(name, func(t *testing.T) {
+			m1 := provider()
+			m2 := provider()
+
+			version, err := m1.Store(&model.ClusterStatus{}, MetadataNotExists)
+			assert.NoError(t, err)
+
+			_, err = m1.Store(&model.ClusterStatus{ShardIdGenerator: 1}, version)
+			assert.NoError(t, err)
+
+			// The second provider is still expecting the older version
+			_, err = m2.Store(&model.ClusterStatus{ShardIdGenerator: 2}, version)
+			assert.ErrorIs(t, err, ErrMetadataBadVersion)
+
+			res, _, err := m2.Get()
+			assert.NoError(t, err)
+			assert.EqualValues(t, 1, res.ShardIdGenerator)
+
+			assert.NoError(t, m1.Close())
+			assert.NoError(t, m2.Close())
+		})
+	}
+}
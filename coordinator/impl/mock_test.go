@@ -46,6 +46,7 @@ type mockShardAssignmentsProvider struct {
 	sync.Mutex
 	cond    common.ConditionContext
 	current *proto.ShardAssignments
+	shards  []int64
 }
 
 func newMockShardAssignmentsProvider() *mockShardAssignmentsProvider {
@@ -78,6 +79,20 @@ func (sap *mockShardAssignmentsProvider) WaitForNextUpdate(ctx context.Context,
 	return sap.current, nil
 }
 
+func (sap *mockShardAssignmentsProvider) setShards(shards ...int64) {
+	sap.Lock()
+	defer sap.Unlock()
+
+	sap.shards = shards
+}
+
+func (sap *mockShardAssignmentsProvider) ShardsForServer(model.Server) []int64 {
+	sap.Lock()
+	defer sap.Unlock()
+
+	return sap.shards
+}
+
 type mockNodeAvailabilityListener struct {
 	events chan model.Server
 }
@@ -123,6 +138,12 @@ type mockPerNodeChannels struct {
 		error
 	}
 
+	prepareHandoverRequests  chan *proto.PrepareLeaderHandoverRequest
+	prepareHandoverResponses chan struct {
+		*proto.PrepareLeaderHandoverResponse
+		error
+	}
+
 	shardAssignmentsStream *mockShardAssignmentClient
 	healthClient           *mockHealthClient
 	err                    error
@@ -176,6 +197,20 @@ func (m *mockPerNodeChannels) BecomeLeaderResponse(err error) {
 	}{&proto.BecomeLeaderResponse{}, err}
 }
 
+func (m *mockPerNodeChannels) DeleteShardResponse(err error) {
+	m.deleteShardResponses <- struct {
+		*proto.DeleteShardResponse
+		error
+	}{&proto.DeleteShardResponse{}, err}
+}
+
+func (m *mockPerNodeChannels) PrepareHandoverResponse(headOffset int64, err error) {
+	m.prepareHandoverResponses <- struct {
+		*proto.PrepareLeaderHandoverResponse
+		error
+	}{&proto.PrepareLeaderHandoverResponse{HeadOffset: headOffset}, err}
+}
+
 func (m *mockPerNodeChannels) AddFollowerResponse(err error) {
 	m.addFollowerResponses <- struct {
 		*proto.AddFollowerResponse
@@ -200,11 +235,21 @@ func newMockPerNodeChannels() *mockPerNodeChannels {
 			*proto.GetStatusResponse
 			error
 		}, 100),
+		deleteShardRequests: make(chan *proto.DeleteShardRequest, 100),
+		deleteShardResponses: make(chan struct {
+			*proto.DeleteShardResponse
+			error
+		}, 100),
 		addFollowerRequests: make(chan *proto.AddFollowerRequest, 100),
 		addFollowerResponses: make(chan struct {
 			*proto.AddFollowerResponse
 			error
 		}, 100),
+		prepareHandoverRequests: make(chan *proto.PrepareLeaderHandoverRequest, 100),
+		prepareHandoverResponses: make(chan struct {
+			*proto.PrepareLeaderHandoverResponse
+			error
+		}, 100),
 		shardAssignmentsStream: newMockShardAssignmentClient(),
 		healthClient:           newMockHealthClient(),
 	}
@@ -361,6 +406,29 @@ func (r *mockRpcProvider) DeleteShard(ctx context.Context, node model.Server, re
 	}
 }
 
+func (r *mockRpcProvider) PrepareLeaderHandover(ctx context.Context, node model.Server, req *proto.PrepareLeaderHandoverRequest) (*proto.PrepareLeaderHandoverResponse, error) {
+	r.Lock()
+
+	s := r.getNode(node)
+	s.prepareHandoverRequests <- req
+
+	if s.err != nil {
+		r.Unlock()
+		return nil, s.err
+	}
+
+	r.Unlock()
+
+	select {
+	case response := <-s.prepareHandoverResponses:
+		return response.PrepareLeaderHandoverResponse, response.error
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(3 * time.Second):
+		return nil, errors.New("timeout")
+	}
+}
+
 func (r *mockRpcProvider) AddFollower(ctx context.Context, node model.Server, req *proto.AddFollowerRequest) (*proto.AddFollowerResponse, error) {
 	r.Lock()
 
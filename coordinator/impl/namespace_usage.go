@@ -0,0 +1,87 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package impl
+
+import (
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/common/metrics"
+)
+
+// The data stored in a namespace, as counted for its quota, from the usage
+// reported by the leaders of its shards.
+type namespaceUsageGauges struct {
+	keys     metrics.Gauge
+	dataSize metrics.Gauge
+}
+
+// Add the usage gauges of the new namespaces, and remove the ones of the
+// deleted namespaces. This is called while already holding the lock on the
+// coordinator.
+func (c *coordinator) updateNamespaceUsageGauges() {
+	for namespace := range c.clusterStatus.Namespaces {
+		if _, ok := c.namespaceUsage[namespace]; ok {
+			continue
+		}
+
+		labels := map[string]any{"namespace": namespace}
+		c.namespaceUsage[namespace] = &namespaceUsageGauges{
+			keys: metrics.NewGauge("oxia_coordinator_namespace_keys",
+				"The number of records stored in the namespace", "count", labels, func() int64 {
+					keys, _ := c.namespaceDataUsage(namespace)
+					return keys
+				}),
+			dataSize: metrics.NewGauge("oxia_coordinator_namespace_data_size",
+				"The size of the keys and values stored in the namespace", metrics.Bytes, labels, func() int64 {
+					_, dataSize := c.namespaceDataUsage(namespace)
+					return dataSize
+				}),
+		}
+	}
+
+	for namespace, gauges := range c.namespaceUsage {
+		if _, ok := c.clusterStatus.Namespaces[namespace]; !ok {
+			gauges.unregister()
+			delete(c.namespaceUsage, namespace)
+		}
+	}
+}
+
+func (c *coordinator) namespaceDataUsage(namespace string) (keys int64, dataSize int64) {
+	c.Lock()
+	shards := common.NewSet[int64]()
+	for shard := range c.clusterStatus.Namespaces[namespace].Shards {
+		shards.Add(shard)
+	}
+	nodeControllers := make([]NodeController, 0, len(c.nodeControllers))
+	for _, nc := range c.nodeControllers {
+		nodeControllers = append(nodeControllers, nc)
+	}
+	c.Unlock()
+
+	for _, nc := range nodeControllers {
+		for shard, sl := range nc.ShardsLoad() {
+			if sl.Leader && shards.Contains(shard) {
+				keys += sl.Keys
+				dataSize += sl.DataSize
+			}
+		}
+	}
+	return keys, dataSize
+}
+
+func (g *namespaceUsageGauges) unregister() {
+	g.keys.Unregister()
+	g.dataSize.Unregister()
+}
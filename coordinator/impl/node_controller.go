@@ -43,8 +43,24 @@ const (
 	healthCheckProbeInterval   = 2 * time.Second
 	healthCheckProbeTimeout    = 2 * time.Second
 	defaultInitialRetryBackoff = 10 * time.Second
+	shardsLoadCollectInterval  = 15 * time.Second
 )
 
+// ShardLoad is the load of a shard replica, as reported by the storage node.
+// The rates are in operations per second and they are only reported by the
+// shard leader, since it's the only replica serving the clients.
+type ShardLoad struct {
+	Leader    bool
+	WriteRate float64
+	ReadRate  float64
+	DbSize    int64
+	WalSize   int64
+
+	// Number and size of the records, as counted for the quota
+	Keys     int64
+	DataSize int64
+}
+
 // The NodeController takes care of checking the health-status of each node
 // and to push all the service discovery updates.
 type NodeController interface {
@@ -53,6 +69,10 @@ type NodeController interface {
 	Status() NodeStatus
 
 	SetStatus(status NodeStatus)
+
+	// ShardsLoad returns the load of the shards hosted by the node, from
+	// the most recent metrics sample
+	ShardsLoad() map[int64]ShardLoad
 }
 
 type nodeController struct {
@@ -75,6 +95,10 @@ type nodeController struct {
 
 	initialRetryBackoff time.Duration
 
+	shardsLoad           map[int64]ShardLoad
+	lastShardsStatus     map[int64]*proto.GetStatusResponse
+	lastShardsStatusTime time.Time
+
 	nodeIsRunningGauge metrics.Gauge
 	failedHealthChecks metrics.Counter
 }
@@ -103,6 +127,8 @@ func newNodeController(server model.Server,
 			slog.Any("server", server),
 		),
 		initialRetryBackoff: initialRetryBackoff,
+		shardsLoad:          map[int64]ShardLoad{},
+		lastShardsStatus:    map[int64]*proto.GetStatusResponse{},
 
 		failedHealthChecks: metrics.NewCounter("oxia_coordinator_node_health_checks_failed",
 			"The number of failed health checks to a node", "count", labels),
@@ -137,6 +163,15 @@ func newNodeController(server model.Server,
 		nc.sendAssignmentsUpdatesWithRetries,
 	)
 
+	go common.DoWithLabels(
+		nc.ctx,
+		map[string]string{
+			"oxia":   "node-controller-collect-shards-load",
+			"server": nc.server.GetIdentifier(),
+		},
+		nc.collectShardsLoadLoop,
+	)
+
 	nc.log.Info("Started node controller")
 	return nc
 }
@@ -154,6 +189,86 @@ func (n *nodeController) SetStatus(status NodeStatus) {
 	n.log.Info("Changed status", slog.Any("status", status))
 }
 
+func (n *nodeController) ShardsLoad() map[int64]ShardLoad {
+	n.Lock()
+	defer n.Unlock()
+	return n.shardsLoad
+}
+
+func (n *nodeController) collectShardsLoadLoop() {
+	ticker := time.NewTicker(shardsLoadCollectInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			n.collectShardsLoad(now)
+
+		case <-n.ctx.Done():
+			return
+		}
+	}
+}
+
+// Sample the status of all the shards hosted by the node. The rates are computed
+// from the difference in the operations counters between two samples.
+func (n *nodeController) collectShardsLoad(now time.Time) {
+	if n.Status() != Running {
+		return
+	}
+
+	shardsStatus := map[int64]*proto.GetStatusResponse{}
+	shardsLoad := map[int64]ShardLoad{}
+
+	for _, shard := range n.shardAssignmentsProvider.ShardsForServer(n.server) {
+		ctx, cancel := context.WithTimeout(n.ctx, healthCheckProbeTimeout)
+		status, err := n.rpc.GetStatus(ctx, n.server, &proto.GetStatusRequest{Shard: shard})
+		cancel()
+		if err != nil {
+			n.log.Debug(
+				"Failed to get shard status",
+				slog.Int64("shard", shard),
+				slog.Any("error", err),
+			)
+			continue
+		}
+
+		shardsStatus[shard] = status
+
+		// The counters are only comparable if the node had the same role
+		// in the same term, in both the samples
+		previous, ok := n.lastShardsStatus[shard]
+		if !ok || previous.Term != status.Term || previous.Status != status.Status {
+			continue
+		}
+
+		elapsed := now.Sub(n.lastShardsStatusTime).Seconds()
+		shardsLoad[shard] = ShardLoad{
+			Leader:    status.Status == proto.ServingStatus_LEADER,
+			WriteRate: opsRate(previous.WriteOps, status.WriteOps, elapsed),
+			ReadRate:  opsRate(previous.ReadOps, status.ReadOps, elapsed),
+			DbSize:    status.DbSize,
+			WalSize:   status.WalSize,
+			Keys:      status.Keys,
+			DataSize:  status.DataSize,
+		}
+	}
+
+	n.Lock()
+	n.lastShardsStatus = shardsStatus
+	n.lastShardsStatusTime = now
+	n.shardsLoad = shardsLoad
+	n.Unlock()
+}
+
+func opsRate(previous int64, current int64, elapsedSeconds float64) float64 {
+	if current < previous || elapsedSeconds <= 0 {
+		return 0
+	}
+
+	return float64(current-previous) / elapsedSeconds
+}
+
 func (n *nodeController) healthCheckWithRetries() {
 	backOff := common.NewBackOffWithInitialInterval(n.ctx, n.initialRetryBackoff)
 	_ = backoff.RetryNotify(func() error {
@@ -122,3 +122,48 @@ func TestNodeController_ShardsAssignments(t *testing.T) {
 
 	assert.NoError(t, nc.Close())
 }
+
+func TestNodeController_ShardsLoad(t *testing.T) {
+	addr := model.Server{
+		Public:   "my-server:9190",
+		Internal: "my-server:8190",
+	}
+
+	sap := newMockShardAssignmentsProvider()
+	sap.setShards(1, 2)
+	nal := newMockNodeAvailabilityListener()
+	rpc := newMockRpcProvider()
+	nc := newNodeController(addr, sap, nal, rpc, 1*time.Second)
+
+	node := rpc.GetNode(addr)
+
+	sendStatus := func(res *proto.GetStatusResponse) {
+		node.getStatusResponses <- struct {
+			*proto.GetStatusResponse
+			error
+		}{res, nil}
+	}
+
+	start := time.Now()
+	sendStatus(&proto.GetStatusResponse{Term: 1, Status: proto.ServingStatus_LEADER,
+		WriteOps: 100, ReadOps: 200, DbSize: 1000, WalSize: 500})
+	sendStatus(&proto.GetStatusResponse{Term: 1, Status: proto.ServingStatus_LEADER,
+		WriteOps: 100, ReadOps: 200, DbSize: 10, WalSize: 5})
+	nc.(*nodeController).collectShardsLoad(start)
+
+	// The rates cannot be computed from a single sample
+	assert.Empty(t, nc.ShardsLoad())
+
+	sendStatus(&proto.GetStatusResponse{Term: 1, Status: proto.ServingStatus_LEADER,
+		WriteOps: 300, ReadOps: 1200, DbSize: 1000, WalSize: 500})
+	// Shard 2 has moved to a new term in between
+	sendStatus(&proto.GetStatusResponse{Term: 2, Status: proto.ServingStatus_FOLLOWER,
+		DbSize: 10, WalSize: 5})
+	nc.(*nodeController).collectShardsLoad(start.Add(10 * time.Second))
+
+	assert.Equal(t, map[int64]ShardLoad{
+		1: {Leader: true, WriteRate: 20, ReadRate: 100, DbSize: 1000, WalSize: 500},
+	}, nc.ShardsLoad())
+
+	assert.NoError(t, nc.Close())
+}
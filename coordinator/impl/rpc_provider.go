@@ -35,6 +35,7 @@ type RpcProvider interface {
 	AddFollower(ctx context.Context, node model.Server, req *proto.AddFollowerRequest) (*proto.AddFollowerResponse, error)
 	GetStatus(ctx context.Context, node model.Server, req *proto.GetStatusRequest) (*proto.GetStatusResponse, error)
 	DeleteShard(ctx context.Context, node model.Server, req *proto.DeleteShardRequest) (*proto.DeleteShardResponse, error)
+	PrepareLeaderHandover(ctx context.Context, node model.Server, req *proto.PrepareLeaderHandoverRequest) (*proto.PrepareLeaderHandoverResponse, error)
 
 	GetHealthClient(node model.Server) (grpc_health_v1.HealthClient, io.Closer, error)
 
@@ -118,6 +119,18 @@ func (r *rpcProvider) DeleteShard(ctx context.Context, node model.Server, req *p
 	return rpc.DeleteShard(ctx, req)
 }
 
+func (r *rpcProvider) PrepareLeaderHandover(ctx context.Context, node model.Server, req *proto.PrepareLeaderHandoverRequest) (*proto.PrepareLeaderHandoverResponse, error) {
+	rpc, err := r.pool.GetCoordinationRpc(node.Internal)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+
+	return rpc.PrepareLeaderHandover(ctx, req)
+}
+
 func (r *rpcProvider) GetHealthClient(node model.Server) (grpc_health_v1.HealthClient, io.Closer, error) {
 	return r.pool.GetHealthRpc(node.Internal)
 }
@@ -52,6 +52,17 @@ type swapNodeRequest struct {
 	res  chan error
 }
 
+type transferLeadershipRequest struct {
+	to  model.Server
+	res chan error
+}
+
+type changeEnsembleRequest struct {
+	add    []model.Server
+	remove []model.Server
+	res    chan error
+}
+
 type newTermAndAddFollowerRequest struct {
 	ctx  context.Context
 	node model.Server
@@ -68,8 +79,17 @@ type ShardController interface {
 	SyncServerAddress()
 
 	SwapNode(from model.Server, to model.Server) error
+	ChangeEnsemble(add []model.Server, remove []model.Server) error
+	TransferLeadership(to model.Server) error
 	DeleteShard()
 
+	// SetObservers updates the observers of the shard, in background
+	SetObservers(observers []model.Server)
+
+	// SetQuota updates the quota of the namespace, in background. The nodes
+	// enforce it from the new term that it starts.
+	SetQuota(quota *model.QuotaConfig)
+
 	Term() int64
 	Leader() *model.Server
 	Status() model.ShardStatus
@@ -88,7 +108,11 @@ type shardController struct {
 	deleteOp                chan any
 	nodeFailureOp           chan model.Server
 	swapNodeOp              chan swapNodeRequest
+	changeEnsembleOp        chan changeEnsembleRequest
+	transferLeadershipOp    chan transferLeadershipRequest
 	newTermAndAddFollowerOp chan newTermAndAddFollowerRequest
+	observersOp             chan []model.Server
+	quotaOp                 chan *model.QuotaConfig
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -119,7 +143,11 @@ func NewShardController(namespace string, shard int64, namespaceConfig *model.Na
 		deleteOp:                make(chan any, chanBufferSize),
 		nodeFailureOp:           make(chan model.Server, chanBufferSize),
 		swapNodeOp:              make(chan swapNodeRequest, chanBufferSize),
+		changeEnsembleOp:        make(chan changeEnsembleRequest, chanBufferSize),
+		transferLeadershipOp:    make(chan transferLeadershipRequest, chanBufferSize),
 		newTermAndAddFollowerOp: make(chan newTermAndAddFollowerRequest, chanBufferSize),
+		observersOp:             make(chan []model.Server, chanBufferSize),
+		quotaOp:                 make(chan *model.QuotaConfig, chanBufferSize),
 		log: slog.With(
 			slog.String("component", "shard-controller"),
 			slog.String("namespace", namespace),
@@ -181,6 +209,8 @@ func (s *shardController) run() {
 		if !s.verifyCurrentEnsemble() {
 			s.electLeaderWithRetries()
 		} else {
+			s.currentElectionCtx, s.currentElectionCancel = context.WithCancel(s.ctx)
+			s.verifyObservers()
 			s.SyncServerAddress()
 		}
 	}
@@ -204,9 +234,21 @@ func (s *shardController) run() {
 		case sw := <-s.swapNodeOp:
 			s.swapNode(sw.from, sw.to, sw.res)
 
+		case ce := <-s.changeEnsembleOp:
+			s.changeEnsemble(ce.add, ce.remove, ce.res)
+
+		case tl := <-s.transferLeadershipOp:
+			s.transferLeadership(tl.to, tl.res)
+
 		case a := <-s.newTermAndAddFollowerOp:
 			s.internalNewTermAndAddFollower(a.ctx, a.node, a.res)
 
+		case observers := <-s.observersOp:
+			s.changeObservers(observers)
+
+		case quota := <-s.quotaOp:
+			s.changeQuota(quota)
+
 		case <-s.electionOp:
 			s.electLeaderWithRetries()
 		}
@@ -285,6 +327,25 @@ func (s *shardController) verifyCurrentEnsemble() bool {
 	return true
 }
 
+// The observers are not part of the ensemble, so the ones that are
+// not following the current leader are attached again, without
+// triggering a new election.
+func (s *shardController) verifyObservers() {
+	for _, node := range s.shardMetadata.Observers {
+		nodeStatus, err := s.rpc.GetStatus(s.ctx, node, &proto.GetStatusRequest{Shard: s.shard})
+		if err == nil && nodeStatus.Status == proto.ServingStatus_FOLLOWER && nodeStatus.Term == s.shardMetadata.Term {
+			continue
+		}
+
+		s.log.Warn(
+			"Observer is not following the leader, attaching it again",
+			slog.Any("observer", node),
+			slog.Any("error", err),
+		)
+		s.retryAddFollower(s.currentElectionCtx, node)
+	}
+}
+
 func (s *shardController) electLeaderWithRetries() {
 	_ = backoff.RetryNotify(s.electLeader, common.NewBackOff(s.ctx),
 		func(err error, duration time.Duration) {
@@ -298,6 +359,19 @@ func (s *shardController) electLeaderWithRetries() {
 }
 
 func (s *shardController) electLeader() error {
+	return s.electPreferredLeader(nil)
+}
+
+// Run a leader election, giving precedence to the preferred leader if it's
+// among the eligible candidates.
+func (s *shardController) electPreferredLeader(preferredLeader *model.Server) error {
+	return s.electLeaderWithMetadata(s.shardMetadata.Clone(), preferredLeader)
+}
+
+// Run a leader election with a new version of the shard metadata. The
+// metadata only replaces the current one once it's stored, so that a
+// failure leaves the shard controller in its previous state.
+func (s *shardController) electLeaderWithMetadata(metadata model.ShardMetadata, preferredLeader *model.Server) error {
 	timer := s.leaderElectionLatency.Timer()
 
 	if s.currentElectionCancel != nil {
@@ -307,30 +381,35 @@ func (s *shardController) electLeader() error {
 
 	s.currentElectionCtx, s.currentElectionCancel = context.WithCancel(s.ctx)
 
-	s.shardMetadataMutex.Lock()
-	s.shardMetadata.Status = model.ShardStatusElection
-	s.shardMetadata.Leader = nil
-	s.shardMetadata.Term++
+	metadata.Status = model.ShardStatusElection
+	metadata.Leader = nil
+	metadata.Term++
 	// it's a safe point to update the service info
-	s.shardMetadata.Ensemble = s.getRefreshedEnsemble()
-	s.shardMetadataMutex.Unlock()
+	metadata.Ensemble = s.getRefreshedEnsemble(metadata.Ensemble)
 
 	s.log.Info(
 		"Starting leader election",
-		slog.Int64("term", s.shardMetadata.Term),
+		slog.Int64("term", metadata.Term),
 	)
 
-	if err := s.coordinator.InitiateLeaderElection(s.namespace, s.shard, s.shardMetadata); err != nil {
+	if err := s.coordinator.InitiateLeaderElection(s.namespace, s.shard, metadata); err != nil {
 		return err
 	}
 
+	s.shardMetadataMutex.Lock()
+	s.shardMetadata = metadata
+	s.shardMetadataMutex.Unlock()
+
 	// Send NewTerm to all the ensemble members
 	fr, err := s.newTermQuorum()
 	if err != nil {
 		return err
 	}
 
-	newLeader, followers := selectNewLeader(fr)
+	newLeader, followers, err := selectNewLeader(fr, s.shardMetadata.Witnesses, preferredLeader)
+	if err != nil {
+		return err
+	}
 
 	if s.log.Enabled(context.Background(), slog.LevelInfo) {
 		f := make([]struct {
@@ -355,7 +434,7 @@ func (s *shardController) electLeader() error {
 		return err
 	}
 
-	metadata := s.shardMetadata.Clone()
+	metadata = s.shardMetadata.Clone()
 	metadata.Status = model.ShardStatusSteadyState
 	metadata.Leader = &newLeader
 
@@ -384,11 +463,11 @@ func (s *shardController) electLeader() error {
 	timer.Done()
 
 	s.keepFencingFailedFollowers(followers)
+	s.attachObservers(s.shardMetadata.Observers)
 	return nil
 }
 
-func (s *shardController) getRefreshedEnsemble() []model.Server {
-	currentEnsemble := s.shardMetadata.Ensemble
+func (s *shardController) getRefreshedEnsemble(currentEnsemble []model.Server) []model.Server {
 	refreshedEnsembleServiceAddress := make([]model.Server, len(currentEnsemble))
 	for idx, candidate := range currentEnsemble {
 		if refreshedAddress, exist := s.coordinator.FindServerByIdentifier(candidate.GetIdentifier()); exist {
@@ -448,12 +527,29 @@ func (s *shardController) keepFencingFailedFollowers(successfulFollowers map[mod
 	}
 }
 
+// The observers are brought into the new term only after the leader is
+// elected, since they are not part of the fencing quorum.
+func (s *shardController) attachObservers(observers []model.Server) {
+	for _, node := range observers {
+		s.log.Info(
+			"Attaching observer",
+			slog.Any("observer", node),
+		)
+
+		s.retryAddFollower(s.currentElectionCtx, node)
+	}
+}
+
 func (s *shardController) keepFencingFollower(ctx context.Context, node model.Server) {
 	s.log.Info(
 		"Node has failed in leader election, retrying",
 		slog.Any("follower", node),
 	)
 
+	s.retryAddFollower(ctx, node)
+}
+
+func (s *shardController) retryAddFollower(ctx context.Context, node model.Server) {
 	go common.DoWithLabels(
 		s.ctx,
 		map[string]string{
@@ -515,7 +611,7 @@ func (s *shardController) internalNewTermAndAddFollower(ctx context.Context, nod
 		return
 	}
 
-	if err = s.addFollower(*s.shardMetadata.Leader, node.Internal, &proto.EntryId{
+	if err = s.addFollower(*s.shardMetadata.Leader, node, &proto.EntryId{
 		Term:   fr.Term,
 		Offset: fr.Offset,
 	}); err != nil {
@@ -523,17 +619,26 @@ func (s *shardController) internalNewTermAndAddFollower(ctx context.Context, nod
 		return
 	}
 
-	s.log.Info(
-		"Successfully rejoined the quorum",
-		slog.Any("follower", node),
-		slog.Int64("term", fr.Term),
-	)
+	if listContains(s.shardMetadata.Observers, node) {
+		s.log.Info(
+			"Successfully attached observer",
+			slog.Any("observer", node),
+			slog.Int64("term", fr.Term),
+		)
+	} else {
+		s.log.Info(
+			"Successfully rejoined the quorum",
+			slog.Any("follower", node),
+			slog.Int64("term", fr.Term),
+		)
+	}
 
 	res <- nil
 }
 
 // Send NewTerm to all the ensemble members in parallel and wait for
-// a majority of them to reply successfully.
+// a majority of them to reply successfully. The observers are left out,
+// so they are never candidates in selectNewLeader.
 func (s *shardController) newTermQuorum() (map[model.Server]*proto.EntryId, error) { //nolint:revive
 	timer := s.newTermQuorumLatency.Timer()
 
@@ -541,6 +646,10 @@ func (s *shardController) newTermQuorum() (map[model.Server]*proto.EntryId, erro
 	fencingQuorumSize := len(fencingQuorum)
 	majority := fencingQuorumSize/2 + 1
 
+	// The removed nodes can't be elected, so a majority of the ensemble
+	// must be fenced as well
+	ensembleMajority := len(s.shardMetadata.Ensemble)/2 + 1
+
 	// Use a new context, so we can cancel the pending requests
 	ctx, cancel := context.WithCancel(s.ctx)
 	defer cancel()
@@ -593,7 +702,7 @@ func (s *shardController) newTermQuorum() (map[model.Server]*proto.EntryId, erro
 	var err error
 
 	// Wait for a majority to respond
-	for successResponses < majority && totalResponses < fencingQuorumSize {
+	for (successResponses < majority || len(res) < ensembleMajority) && totalResponses < fencingQuorumSize {
 		r := <-ch
 
 		totalResponses++
@@ -609,7 +718,7 @@ func (s *shardController) newTermQuorum() (map[model.Server]*proto.EntryId, erro
 		}
 	}
 
-	if successResponses < majority {
+	if successResponses < majority || len(res) < ensembleMajority {
 		return nil, errors.Wrap(err, "failed to newTerm shard")
 	}
 
@@ -620,7 +729,9 @@ func (s *shardController) newTermQuorum() (map[model.Server]*proto.EntryId, erro
 		case r := <-ch:
 			totalResponses++
 			if r.error == nil {
-				res[r.Server] = r.EntryId
+				if listContains(s.shardMetadata.Ensemble, r.Server) {
+					res[r.Server] = r.EntryId
+				}
 			} else {
 				err = multierr.Append(err, r.error)
 			}
@@ -642,6 +753,9 @@ func (s *shardController) newTerm(ctx context.Context, node model.Server) (*prot
 		Term:      s.shardMetadata.Term,
 		Options: &proto.NewTermOptions{
 			EnableNotifications: s.namespaceConfig.NotificationsEnabled.Get(),
+			Observer:            listContains(s.shardMetadata.Observers, node),
+			Witness:             listContains(s.shardMetadata.Witnesses, node),
+			Quota:               shardQuota(s.namespaceConfig),
 		},
 	})
 	if err != nil {
@@ -661,8 +775,8 @@ func (s *shardController) deleteShardRpc(ctx context.Context, node model.Server)
 	return err
 }
 
-func selectNewLeader(newTermResponses map[model.Server]*proto.EntryId) (
-	leader model.Server, followers map[model.Server]*proto.EntryId) {
+func selectNewLeader(newTermResponses map[model.Server]*proto.EntryId, witnesses []model.Server,
+	preferredLeader *model.Server) (leader model.Server, followers map[model.Server]*proto.EntryId, err error) {
 	// Select all the nodes that have the highest term first
 	var currentMaxTerm int64 = -1
 	// Select all the nodes that have the highest entry in the wal
@@ -670,6 +784,12 @@ func selectNewLeader(newTermResponses map[model.Server]*proto.EntryId) (
 	var candidates []model.Server
 
 	for addr, headEntryId := range newTermResponses {
+		// The witnesses don't have the data, so they can't be elected. The
+		// new leader recovers from them the entries that it's missing.
+		if listContains(witnesses, addr) {
+			continue
+		}
+
 		if headEntryId.Term > currentMaxTerm {
 			// the new max
 			currentMaxTerm = headEntryId.Term
@@ -686,23 +806,35 @@ func selectNewLeader(newTermResponses map[model.Server]*proto.EntryId) (
 		}
 	}
 
-	// Select a random leader among the nodes with the highest entry in the wal
+	if len(candidates) == 0 {
+		return leader, nil, errors.New("none of the servers that responded can be elected as leader")
+	}
+
+	// Select a random leader among the nodes with the highest entry in the wal,
+	// unless the preferred leader is one of them
 	leader = candidates[rand.Intn(len(candidates))] //nolint:gosec
+	if preferredLeader != nil && listContains(candidates, *preferredLeader) {
+		leader = *preferredLeader
+	}
 	followers = make(map[model.Server]*proto.EntryId)
 	for a, e := range newTermResponses {
 		if a != leader {
 			followers[a] = e
 		}
 	}
-	return leader, followers
+	return leader, followers, nil
 }
 
 func (s *shardController) becomeLeader(leader model.Server, followers map[model.Server]*proto.EntryId) error {
 	timer := s.leaderElectionLatency.Timer()
 
 	followersMap := make(map[string]*proto.EntryId)
+	var witnesses []string
 	for server, e := range followers {
 		followersMap[server.GetIdentifier()] = e
+		if listContains(s.shardMetadata.Witnesses, server) {
+			witnesses = append(witnesses, server.GetIdentifier())
+		}
 	}
 
 	if _, err := s.rpc.BecomeLeader(s.ctx, leader, &proto.BecomeLeaderRequest{
@@ -711,6 +843,7 @@ func (s *shardController) becomeLeader(leader model.Server, followers map[model.
 		Term:              s.shardMetadata.Term,
 		ReplicationFactor: uint32(len(s.shardMetadata.Ensemble)),
 		FollowerMaps:      followersMap,
+		Witnesses:         witnesses,
 	}); err != nil {
 		return err
 	}
@@ -719,13 +852,15 @@ func (s *shardController) becomeLeader(leader model.Server, followers map[model.
 	return nil
 }
 
-func (s *shardController) addFollower(leader model.Server, follower string, followerHeadEntryId *proto.EntryId) error {
+func (s *shardController) addFollower(leader model.Server, follower model.Server, followerHeadEntryId *proto.EntryId) error {
 	if _, err := s.rpc.AddFollower(s.ctx, leader, &proto.AddFollowerRequest{
 		Namespace:           s.namespace,
 		Shard:               s.shard,
 		Term:                s.shardMetadata.Term,
-		FollowerName:        follower,
+		FollowerName:        follower.Internal,
 		FollowerHeadEntryId: followerHeadEntryId,
+		Observer:            listContains(s.shardMetadata.Observers, follower),
+		Witness:             listContains(s.shardMetadata.Witnesses, follower),
 	}); err != nil {
 		return err
 	}
@@ -828,6 +963,10 @@ func (s *shardController) swapNode(from model.Server, to model.Server, res chan
 	s.shardMetadataMutex.Lock()
 	s.shardMetadata.RemovedNodes = append(s.shardMetadata.RemovedNodes, from)
 	s.shardMetadata.Ensemble = replaceInList(s.shardMetadata.Ensemble, from, to)
+	if listContains(s.shardMetadata.Witnesses, from) {
+		// The new node takes over the witness role
+		s.shardMetadata.Witnesses = replaceInList(s.shardMetadata.Witnesses, from, to)
+	}
 	s.shardMetadataMutex.Unlock()
 
 	s.log.Info(
@@ -866,6 +1005,233 @@ func (s *shardController) swapNode(from model.Server, to model.Server, res chan
 	res <- nil
 }
 
+func (s *shardController) ChangeEnsemble(add []model.Server, remove []model.Server) error {
+	res := make(chan error)
+	s.changeEnsembleOp <- changeEnsembleRequest{
+		add:    add,
+		remove: remove,
+		res:    res,
+	}
+
+	return <-res
+}
+
+func (s *shardController) changeEnsemble(add []model.Server, remove []model.Server, res chan error) {
+	s.log.Info(
+		"Changing ensemble",
+		slog.Any("current-ensemble", s.shardMetadata.Ensemble),
+		slog.Any("add", add),
+		slog.Any("remove", remove),
+	)
+
+	if s.shardMetadata.Leader == nil || s.shardMetadata.Status != model.ShardStatusSteadyState {
+		res <- errors.New("shard is not in steady state")
+		return
+	}
+
+	// Nodes are removed one at a time. The new term is fenced by a majority
+	// of the new ensemble, and shrinking it by a single node guarantees that
+	// this majority overlaps with the majority that has acknowledged the
+	// entries in the previous term, even when the removed node is the only
+	// other one holding them.
+	for _, node := range remove {
+		metadata := s.shardMetadata.Clone()
+		metadata.RemovedNodes = append(metadata.RemovedNodes, node)
+		metadata.Ensemble = removeFromList(metadata.Ensemble, []model.Server{node})
+		metadata.Witnesses = removeFromList(metadata.Witnesses, []model.Server{node})
+
+		if err := s.electLeaderWithMetadata(metadata, nil); err != nil {
+			res <- err
+			return
+		}
+	}
+
+	// Nodes are added one at a time. Growing the ensemble by a single
+	// node guarantees that the majority of the new ensemble, used to fence
+	// the new term, overlaps with the majority that has acknowledged the
+	// entries in the previous term.
+	for _, node := range add {
+		metadata := s.shardMetadata.Clone()
+		metadata.Ensemble = append(metadata.Ensemble, node)
+
+		if err := s.electLeaderWithMetadata(metadata, nil); err != nil {
+			res <- err
+			return
+		}
+
+		// The new follower will receive a snapshot from the leader. Wait for it
+		// to be caught up before adding the next one.
+		if err := s.waitForFollowersToCatchUp(s.currentElectionCtx, *s.shardMetadata.Leader,
+			s.shardMetadata.Ensemble); err != nil {
+			s.log.Error(
+				"Failed to wait for followers to catch up",
+				slog.Any("error", err),
+			)
+			res <- err
+			return
+		}
+	}
+
+	s.log.Info(
+		"Successfully changed ensemble",
+		slog.Any("ensemble", s.shardMetadata.Ensemble),
+	)
+	res <- nil
+}
+
+func (s *shardController) SetObservers(observers []model.Server) {
+	s.observersOp <- observers
+}
+
+func (s *shardController) changeObservers(observers []model.Server) {
+	if s.shardMetadata.Status == model.ShardStatusDeleting {
+		return
+	}
+
+	added := removeFromList(observers, s.shardMetadata.Observers)
+	removed := removeFromList(s.shardMetadata.Observers, observers)
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+
+	s.log.Info(
+		"Changing observers",
+		slog.Any("current-observers", s.shardMetadata.Observers),
+		slog.Any("add", added),
+		slog.Any("remove", removed),
+	)
+
+	s.shardMetadataMutex.Lock()
+	s.shardMetadata.Observers = observers
+	s.shardMetadataMutex.Unlock()
+
+	if len(removed) > 0 {
+		// The leader only detaches the removed observers when moving
+		// into a new term. The new term also attaches all the others.
+		s.electLeaderWithRetries()
+
+		for _, node := range removed {
+			if err := s.deleteShardRpc(s.ctx, node); err != nil {
+				s.log.Warn(
+					"Failed to delete shard from removed observer",
+					slog.Any("error", err),
+					slog.Any("observer", node),
+				)
+			}
+		}
+		return
+	}
+
+	if err := s.coordinator.ElectedLeader(s.namespace, s.shard, s.shardMetadata.Clone()); err != nil {
+		s.log.Warn(
+			"Failed to store the shard observers",
+			slog.Any("error", err),
+		)
+	}
+
+	s.attachObservers(added)
+}
+
+func (s *shardController) SetQuota(quota *model.QuotaConfig) {
+	s.quotaOp <- quota
+}
+
+func (s *shardController) changeQuota(quota *model.QuotaConfig) {
+	if s.shardMetadata.Status == model.ShardStatusDeleting || reflect.DeepEqual(quota, s.namespaceConfig.Quota) {
+		return
+	}
+
+	s.log.Info(
+		"Changing quota",
+		slog.Any("current-quota", s.namespaceConfig.Quota),
+		slog.Any("quota", quota),
+	)
+
+	// The namespace config is shared with the coordinator, so it's replaced
+	// instead of being modified
+	namespaceConfig := *s.namespaceConfig
+	namespaceConfig.Quota = quota
+	s.namespaceConfig = &namespaceConfig
+
+	// The quota is sent to the leader and the followers with the new term
+	s.electLeaderWithRetries()
+}
+
+func (s *shardController) TransferLeadership(to model.Server) error {
+	res := make(chan error)
+	s.transferLeadershipOp <- transferLeadershipRequest{
+		to:  to,
+		res: res,
+	}
+
+	return <-res
+}
+
+func (s *shardController) transferLeadership(to model.Server, res chan error) {
+	leader := s.shardMetadata.Leader
+	if leader == nil || s.shardMetadata.Status != model.ShardStatusSteadyState {
+		res <- errors.New("shard is not in steady state")
+		return
+	}
+
+	if leader.GetIdentifier() == to.GetIdentifier() {
+		res <- nil
+		return
+	}
+
+	if !listContains(s.shardMetadata.Ensemble, to) {
+		res <- errors.Errorf("server %s is not part of the shard ensemble", to.GetIdentifier())
+		return
+	}
+
+	if listContains(s.shardMetadata.Witnesses, to) {
+		res <- errors.Errorf("server %s is a witness of the shard", to.GetIdentifier())
+		return
+	}
+
+	s.log.Info(
+		"Transferring shard leadership",
+		slog.Any("from", leader),
+		slog.Any("to", to),
+	)
+
+	// The new leader needs to have all the entries, otherwise it
+	// won't be eligible in the new term
+	if err := s.waitForFollowersToCatchUp(s.currentElectionCtx, *leader, []model.Server{to}); err != nil {
+		res <- err
+		return
+	}
+
+	// Ask the current leader to stop accepting writes and to complete the
+	// pending ones, so that the clients can retry them on the new leader
+	// instead of getting failures when the shard is fenced
+	if _, err := s.rpc.PrepareLeaderHandover(s.currentElectionCtx, *leader, &proto.PrepareLeaderHandoverRequest{
+		Namespace: s.namespace,
+		Shard:     s.shard,
+		Term:      s.shardMetadata.Term,
+		NewLeader: to.Internal,
+	}); err != nil {
+		res <- errors.Wrap(err, "failed to prepare the leadership handover")
+		return
+	}
+
+	if err := s.electPreferredLeader(&to); err != nil {
+		res <- err
+		return
+	}
+
+	if s.shardMetadata.Leader.GetIdentifier() != to.GetIdentifier() {
+		res <- errors.Errorf("server %s was not eligible as leader in the new term", to.GetIdentifier())
+		return
+	}
+
+	s.log.Info(
+		"Successfully transferred shard leadership",
+		slog.Any("leader", to),
+	)
+	res <- nil
+}
+
 func (s *shardController) isFollowerCatchUp(ctx context.Context, server model.Server, leaderHeadOffset int64) error {
 	fs, err := s.rpc.GetStatus(ctx, server, &proto.GetStatusRequest{Shard: s.shard})
 	if err != nil {
@@ -951,6 +1317,24 @@ func listContains(list []model.Server, sa model.Server) bool {
 	return false
 }
 
+// Splits the namespace quota evenly among its shards. The max value size
+// applies to each record, so it's the same for all the shards.
+func shardQuota(nc *model.NamespaceConfig) *proto.ShardQuota {
+	if nc.Quota == nil {
+		return nil
+	}
+
+	shards := max(int64(nc.InitialShardCount), 1)
+	split := func(limit int64) int64 {
+		return (limit + shards - 1) / shards
+	}
+	return &proto.ShardQuota{
+		MaxBytes:     split(nc.Quota.MaxBytes),
+		MaxKeys:      split(nc.Quota.MaxKeys),
+		MaxValueSize: nc.Quota.MaxValueSize,
+	}
+}
+
 func mergeLists[T any](lists ...[]T) []T {
 	var res []T
 	for _, list := range lists {
@@ -970,3 +1354,14 @@ func replaceInList(list []model.Server, oldServer, newServer model.Server) []mod
 	res = append(res, newServer)
 	return res
 }
+
+func removeFromList(list []model.Server, toRemove []model.Server) []model.Server {
+	var res []model.Server
+	for _, item := range list {
+		if !listContains(toRemove, item) {
+			res = append(res, item)
+		}
+	}
+
+	return res
+}
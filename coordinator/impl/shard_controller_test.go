@@ -39,6 +39,8 @@ func TestLeaderElection_ShouldChooseHighestTerm(t *testing.T) {
 	tests := []struct {
 		name                   string
 		candidates             map[model.Server]*proto.EntryId
+		witnesses              []model.Server
+		preferredLeader        *model.Server
 		expectedLeader         model.Server
 		expectedFollowersCount int
 		expectedFollowers      map[model.Server]*proto.EntryId
@@ -94,11 +96,53 @@ func TestLeaderElection_ShouldChooseHighestTerm(t *testing.T) {
 			expectedFollowersCount: 0,
 			expectedFollowers:      map[model.Server]*proto.EntryId{},
 		},
+		{
+			name: "Preferred leader among the candidates",
+			candidates: map[model.Server]*proto.EntryId{
+				{Public: "1", Internal: "1"}: {Term: 200, Offset: 1500},
+				{Public: "2", Internal: "2"}: {Term: 200, Offset: 1500},
+				{Public: "3", Internal: "3"}: {Term: 200, Offset: 1500},
+			},
+			preferredLeader:        &model.Server{Public: "3", Internal: "3"},
+			expectedLeader:         model.Server{Public: "3", Internal: "3"},
+			expectedFollowersCount: 2,
+			expectedFollowers: map[model.Server]*proto.EntryId{
+				{Public: "1", Internal: "1"}: {Term: 200, Offset: 1500},
+				{Public: "2", Internal: "2"}: {Term: 200, Offset: 1500},
+			},
+		},
+		{
+			name: "Preferred leader not caught up",
+			candidates: map[model.Server]*proto.EntryId{
+				{Public: "1", Internal: "1"}: {Term: 200, Offset: 1500},
+				{Public: "2", Internal: "2"}: {Term: 200, Offset: 1400},
+			},
+			preferredLeader:        &model.Server{Public: "2", Internal: "2"},
+			expectedLeader:         model.Server{Public: "1", Internal: "1"},
+			expectedFollowersCount: 1,
+			expectedFollowers: map[model.Server]*proto.EntryId{
+				{Public: "2", Internal: "2"}: {Term: 200, Offset: 1400},
+			},
+		},
+		{
+			name: "Witness with the highest entry",
+			candidates: map[model.Server]*proto.EntryId{
+				{Public: "1", Internal: "1"}: {Term: 200, Offset: 1400},
+				{Public: "2", Internal: "2"}: {Term: 200, Offset: 1500},
+			},
+			witnesses:              []model.Server{{Public: "2", Internal: "2"}},
+			expectedLeader:         model.Server{Public: "1", Internal: "1"},
+			expectedFollowersCount: 1,
+			expectedFollowers: map[model.Server]*proto.EntryId{
+				{Public: "2", Internal: "2"}: {Term: 200, Offset: 1500},
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			leader, followers := selectNewLeader(tt.candidates)
+			leader, followers, err := selectNewLeader(tt.candidates, tt.witnesses, tt.preferredLeader)
+			assert.NoError(t, err)
 
 			// Check leader
 			assert.Equal(t, tt.expectedLeader, leader)
@@ -419,6 +463,194 @@ func TestShardController_NotificationsDisabled(t *testing.T) {
 	assert.NoError(t, sc.Close())
 }
 
+func TestShardController_ChangeEnsembleIgnoresLateRemovedNodes(t *testing.T) {
+	var shard int64 = 5
+	rpc := newMockRpcProvider()
+	coordinator := newMockCoordinator()
+
+	s1 := model.Server{Public: "s1:9091", Internal: "s1:8191"}
+	s2 := model.Server{Public: "s2:9091", Internal: "s2:8191"}
+	s3 := model.Server{Public: "s3:9091", Internal: "s3:8191"}
+
+	sc := NewShardController(common.DefaultNamespace, shard, namespaceConfig, model.ShardMetadata{
+		Status:   model.ShardStatusUnknown,
+		Term:     1,
+		Leader:   nil,
+		Ensemble: []model.Server{s1, s2, s3},
+	}, rpc, coordinator)
+
+	rpc.GetNode(s1).NewTermResponse(1, 0, nil)
+	rpc.GetNode(s2).NewTermResponse(1, -1, nil)
+	rpc.GetNode(s3).NewTermResponse(1, -1, nil)
+	rpc.GetNode(s1).BecomeLeaderResponse(nil)
+
+	rpc.GetNode(s1).expectNewTermRequest(t, shard, 2, true)
+	rpc.GetNode(s2).expectNewTermRequest(t, shard, 2, true)
+	rpc.GetNode(s3).expectNewTermRequest(t, shard, 2, true)
+	rpc.GetNode(s1).expectBecomeLeaderRequest(t, shard, 2, 3)
+
+	assert.Eventually(t, func() bool {
+		return sc.Status() == model.ShardStatusSteadyState
+	}, 10*time.Second, 100*time.Millisecond)
+
+	res := make(chan error)
+	go func() {
+		res <- sc.ChangeEnsemble(nil, []model.Server{s3})
+	}()
+
+	rpc.GetNode(s1).NewTermResponse(2, 1, nil)
+	rpc.GetNode(s2).NewTermResponse(2, 0, nil)
+	rpc.GetNode(s1).expectNewTermRequest(t, shard, 3, true)
+	rpc.GetNode(s2).expectNewTermRequest(t, shard, 3, true)
+	rpc.GetNode(s3).expectNewTermRequest(t, shard, 3, true)
+
+	// The removed node only responds within the fencing grace period, after
+	// the quorum was already reached. It must not be added as a follower.
+	time.Sleep(quorumFencingGracePeriod / 2)
+	rpc.GetNode(s3).NewTermResponse(2, 0, nil)
+
+	rpc.GetNode(s1).BecomeLeaderResponse(nil)
+	r := <-rpc.GetNode(s1).becomeLeaderRequests
+	assert.EqualValues(t, 3, r.Term)
+	assert.EqualValues(t, 2, r.ReplicationFactor)
+	assert.Contains(t, r.FollowerMaps, s2.GetIdentifier())
+	assert.NotContains(t, r.FollowerMaps, s3.GetIdentifier())
+
+	rpc.GetNode(s3).DeleteShardResponse(nil)
+	assert.NoError(t, <-res)
+
+	assert.Equal(t, model.ShardStatusSteadyState, sc.Status())
+	assert.Equal(t, s1, *sc.Leader())
+
+	assert.NoError(t, sc.Close())
+}
+
+func TestShardController_ChangeEnsembleMetadataStoreFailure(t *testing.T) {
+	var shard int64 = 5
+	rpc := newMockRpcProvider()
+	coordinator := newMockCoordinator()
+
+	s1 := model.Server{Public: "s1:9091", Internal: "s1:8191"}
+	s2 := model.Server{Public: "s2:9091", Internal: "s2:8191"}
+	s3 := model.Server{Public: "s3:9091", Internal: "s3:8191"}
+	s4 := model.Server{Public: "s4:9091", Internal: "s4:8191"}
+
+	sc := NewShardController(common.DefaultNamespace, shard, namespaceConfig, model.ShardMetadata{
+		Status:   model.ShardStatusUnknown,
+		Term:     1,
+		Leader:   nil,
+		Ensemble: []model.Server{s1, s2, s3},
+	}, rpc, coordinator)
+
+	rpc.GetNode(s1).NewTermResponse(1, 0, nil)
+	rpc.GetNode(s2).NewTermResponse(1, -1, nil)
+	rpc.GetNode(s3).NewTermResponse(1, -1, nil)
+	rpc.GetNode(s1).BecomeLeaderResponse(nil)
+
+	rpc.GetNode(s1).expectBecomeLeaderRequest(t, shard, 2, 3)
+	assert.Eventually(t, func() bool {
+		return sc.Status() == model.ShardStatusSteadyState
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// The new ensemble can't be stored
+	mc := coordinator.(*mockCoordinator)
+	mc.Lock()
+	mc.err = errors.New("failed to store the metadata")
+	mc.Unlock()
+
+	assert.Error(t, sc.ChangeEnsemble([]model.Server{s4}, []model.Server{s3}))
+
+	// The shard controller is left with the previous metadata
+	assert.Equal(t, model.ShardStatusSteadyState, sc.Status())
+	assert.EqualValues(t, 2, sc.Term())
+	assert.Equal(t, s1, *sc.Leader())
+
+	scImpl := sc.(*shardController)
+	scImpl.shardMetadataMutex.RLock()
+	assert.Equal(t, []model.Server{s1, s2, s3}, scImpl.shardMetadata.Ensemble)
+	assert.Empty(t, scImpl.shardMetadata.RemovedNodes)
+	scImpl.shardMetadataMutex.RUnlock()
+
+	assert.NoError(t, sc.Close())
+}
+
+func TestShardController_ChangeEnsembleRemovedNodesWithHighestEntry(t *testing.T) {
+	var shard int64 = 5
+	rpc := newMockRpcProvider()
+	coordinator := newMockCoordinator()
+
+	s1 := model.Server{Public: "s1:9091", Internal: "s1:8191"}
+	s2 := model.Server{Public: "s2:9091", Internal: "s2:8191"}
+	s3 := model.Server{Public: "s3:9091", Internal: "s3:8191"}
+	s4 := model.Server{Public: "s4:9091", Internal: "s4:8191"}
+	s5 := model.Server{Public: "s5:9091", Internal: "s5:8191"}
+
+	sc := NewShardController(common.DefaultNamespace, shard, namespaceConfig, model.ShardMetadata{
+		Status:   model.ShardStatusUnknown,
+		Term:     1,
+		Leader:   nil,
+		Ensemble: []model.Server{s1, s2, s3, s4, s5},
+	}, rpc, coordinator)
+
+	rpc.GetNode(s1).NewTermResponse(1, 0, nil)
+	rpc.GetNode(s2).NewTermResponse(1, -1, nil)
+	rpc.GetNode(s3).NewTermResponse(1, -1, nil)
+	rpc.GetNode(s4).NewTermResponse(1, -1, nil)
+	rpc.GetNode(s5).NewTermResponse(1, -1, nil)
+	rpc.GetNode(s1).BecomeLeaderResponse(nil)
+
+	rpc.GetNode(s1).expectBecomeLeaderRequest(t, shard, 2, 5)
+	assert.Eventually(t, func() bool {
+		return sc.Status() == model.ShardStatusSteadyState
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// The last committed entry was only acknowledged by the leader and by
+	// the nodes being removed, and the leader is not reachable anymore
+	rpc.FailNode(s1, errors.New("failed to connect"))
+
+	res := make(chan error)
+	go func() {
+		res <- sc.ChangeEnsemble(nil, []model.Server{s4, s5})
+	}()
+
+	// The first new term only removes s4, leaving s5 to be elected
+	rpc.GetNode(s2).NewTermResponse(2, 9, nil)
+	rpc.GetNode(s3).NewTermResponse(2, 9, nil)
+	rpc.GetNode(s4).NewTermResponse(2, 10, nil)
+	rpc.GetNode(s5).NewTermResponse(2, 10, nil)
+	rpc.GetNode(s5).BecomeLeaderResponse(nil)
+	rpc.GetNode(s4).DeleteShardResponse(nil)
+
+	r := <-rpc.GetNode(s5).becomeLeaderRequests
+	assert.EqualValues(t, 3, r.Term)
+	assert.EqualValues(t, 4, r.ReplicationFactor)
+	assert.Contains(t, r.FollowerMaps, s2.GetIdentifier())
+	assert.Contains(t, r.FollowerMaps, s3.GetIdentifier())
+	assert.NotContains(t, r.FollowerMaps, s4.GetIdentifier())
+
+	// The second new term removes s5, once the others have caught up with it
+	rpc.GetNode(s2).NewTermResponse(3, 10, nil)
+	rpc.GetNode(s3).NewTermResponse(3, 10, nil)
+	rpc.GetNode(s5).NewTermResponse(3, 10, nil)
+	rpc.GetNode(s2).BecomeLeaderResponse(nil)
+	rpc.GetNode(s3).BecomeLeaderResponse(nil)
+	rpc.GetNode(s5).DeleteShardResponse(nil)
+
+	assert.NoError(t, <-res)
+
+	assert.Equal(t, model.ShardStatusSteadyState, sc.Status())
+	assert.EqualValues(t, 4, sc.Term())
+	assert.Contains(t, []model.Server{s2, s3}, *sc.Leader())
+
+	scImpl := sc.(*shardController)
+	scImpl.shardMetadataMutex.RLock()
+	assert.Equal(t, []model.Server{s1, s2, s3}, scImpl.shardMetadata.Ensemble)
+	assert.Empty(t, scImpl.shardMetadata.RemovedNodes)
+	scImpl.shardMetadataMutex.RUnlock()
+
+	assert.NoError(t, sc.Close())
+}
+
 type sCoordinatorEvents struct {
 	shard    int64
 	metadata model.ShardMetadata
@@ -450,6 +682,10 @@ func (m *mockCoordinator) WaitForNextUpdate(ctx context.Context, currentValue *p
 	panic("not implemented")
 }
 
+func (m *mockCoordinator) ShardsForServer(model.Server) []int64 {
+	panic("not implemented")
+}
+
 func (m *mockCoordinator) FindServerByIdentifier(_ string) (*model.Server, bool) {
 	return nil, false
 }
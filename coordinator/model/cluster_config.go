@@ -14,11 +14,44 @@
 
 package model
 
-import "github.com/streamnative/oxia/common"
+import (
+	"time"
+
+	"github.com/streamnative/oxia/common"
+)
 
 type ClusterConfig struct {
-	Namespaces []NamespaceConfig `json:"namespaces" yaml:"namespaces"`
-	Servers    []Server          `json:"servers" yaml:"servers"`
+	Namespaces   []NamespaceConfig   `json:"namespaces" yaml:"namespaces"`
+	Servers      []Server            `json:"servers" yaml:"servers"`
+	LoadBalancer *LoadBalancerConfig `json:"loadBalancer,omitempty" yaml:"loadBalancer,omitempty"`
+
+	LeaderBalancer *LeaderBalancerConfig `json:"leaderBalancer,omitempty" yaml:"leaderBalancer,omitempty"`
+}
+
+// LoadBalancerConfig controls the rebalancing of shards based on the load
+// reported by the storage nodes, rather than on the shards count alone.
+type LoadBalancerConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Interval between two load balancing rounds
+	Interval time.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// Maximum number of leadership and replica moves in a single round
+	MaxMovesPerInterval int `json:"maxMovesPerInterval,omitempty" yaml:"maxMovesPerInterval,omitempty"`
+}
+
+// LeaderBalancerConfig controls the periodic transfer of shard leaderships, so that
+// each server leads about the same number of shards. It's enabled by default and it's
+// not used when the load balancer is enabled, since that already places the leaderships
+// based on the actual load.
+type LeaderBalancerConfig struct {
+	Enabled common.OptBooleanDefaultTrue `json:"enabled" yaml:"enabled"`
+
+	// Interval between two leader balancing rounds
+	Interval time.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// Maximum number of leadership transfers in a single round
+	MaxMovesPerInterval int `json:"maxMovesPerInterval,omitempty" yaml:"maxMovesPerInterval,omitempty"`
 }
 
 type NamespaceConfig struct {
@@ -26,4 +59,34 @@ type NamespaceConfig struct {
 	InitialShardCount    uint32                       `json:"initialShardCount" yaml:"initialShardCount"`
 	ReplicationFactor    uint32                       `json:"replicationFactor" yaml:"replicationFactor"`
 	NotificationsEnabled common.OptBooleanDefaultTrue `json:"notificationsEnabled" yaml:"notificationsEnabled"`
+
+	// Observers are servers that get a read-only copy of all the shards of
+	// the namespace, outside the quorum. They are not expected to be listed
+	// among the cluster servers.
+	Observers []Server `json:"observers,omitempty" yaml:"observers,omitempty"`
+
+	// Witnesses is the number of members of each shard ensemble that only
+	// keep the log, without the database. They count towards the quorum,
+	// though they are never elected as leaders. It's capped to half of the
+	// replication factor, to always have a full replica in the majority. It
+	// only applies to the namespaces when they're created.
+	Witnesses uint32 `json:"witnesses,omitempty" yaml:"witnesses,omitempty"`
+
+	// Quota limits the data stored in the namespace. The limits are split
+	// evenly among the initial shards, and they're enforced by each shard
+	// leader. A change of the quota moves the shards to a new term.
+	Quota *QuotaConfig `json:"quota,omitempty" yaml:"quota,omitempty"`
+}
+
+// QuotaConfig has the limits on the data stored in a namespace. The zero
+// values are unlimited.
+type QuotaConfig struct {
+	// Maximum total size of the keys and the values of the records
+	MaxBytes int64 `json:"maxBytes,omitempty" yaml:"maxBytes,omitempty"`
+
+	// Maximum number of records
+	MaxKeys int64 `json:"maxKeys,omitempty" yaml:"maxKeys,omitempty"`
+
+	// Maximum size of a single value
+	MaxValueSize int64 `json:"maxValueSize,omitempty" yaml:"maxValueSize,omitempty"`
 }
@@ -14,6 +14,8 @@
 
 package model
 
+import "time"
+
 type Int32HashRange struct {
 	// The minimum inclusive hash that the shard can contain
 	Min uint32 `json:"min"`
@@ -29,6 +31,15 @@ type ShardMetadata struct {
 	Ensemble       []Server       `json:"ensemble" yaml:"ensemble"`
 	RemovedNodes   []Server       `json:"removedNodes" yaml:"removedNodes"`
 	Int32HashRange Int32HashRange `json:"int32HashRange" yaml:"int32HashRange"`
+
+	// Observers receive the replicated log from the leader, though they are
+	// not part of the ensemble: they are never counted in the quorum, nor they
+	// can be elected as leaders
+	Observers []Server `json:"observers,omitempty" yaml:"observers,omitempty"`
+
+	// Witnesses are the members of the ensemble that only keep the log. They
+	// count towards the quorum, though they can't be elected as leaders
+	Witnesses []Server `json:"witnesses,omitempty" yaml:"witnesses,omitempty"`
 }
 
 type NamespaceStatus struct {
@@ -40,6 +51,18 @@ type ClusterStatus struct {
 	Namespaces       map[string]NamespaceStatus `json:"namespaces" yaml:"namespaces"`
 	ShardIdGenerator int64                      `json:"shardIdGenerator" yaml:"shardIdGenerator"`
 	ServerIdx        uint32                     `json:"serverIdx" yaml:"serverIdx"`
+
+	// The lease of the active coordinator, when running multiple coordinators
+	CoordinatorLease *CoordinatorLease `json:"coordinatorLease,omitempty" yaml:"coordinatorLease,omitempty"`
+}
+
+// CoordinatorLease identifies the coordinator that is currently allowed to
+// update the cluster status. It is stored together with the cluster status, so
+// that any change in leadership invalidates the version held by the previous
+// coordinator.
+type CoordinatorLease struct {
+	Holder   string        `json:"holder" yaml:"holder"`
+	Duration time.Duration `json:"duration" yaml:"duration"`
 }
 
 func NewClusterStatus() *ClusterStatus {
@@ -70,6 +93,16 @@ func (sm ShardMetadata) Clone() ShardMetadata {
 	copy(r.Ensemble, sm.Ensemble)
 	copy(r.RemovedNodes, sm.RemovedNodes)
 
+	if sm.Observers != nil {
+		r.Observers = make([]Server, len(sm.Observers))
+		copy(r.Observers, sm.Observers)
+	}
+
+	if sm.Witnesses != nil {
+		r.Witnesses = make([]Server, len(sm.Witnesses))
+		copy(r.Witnesses, sm.Witnesses)
+	}
+
 	return r
 }
 
@@ -93,6 +126,11 @@ func (c ClusterStatus) Clone() *ClusterStatus {
 		ServerIdx:        c.ServerIdx,
 	}
 
+	if c.CoordinatorLease != nil {
+		lease := *c.CoordinatorLease
+		r.CoordinatorLease = &lease
+	}
+
 	for name, n := range c.Namespaces {
 		r.Namespaces[name] = n.Clone()
 	}
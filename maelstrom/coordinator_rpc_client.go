@@ -102,6 +102,15 @@ func (m *maelstromCoordinatorRpcProvider) DeleteShard(ctx context.Context, node
 	return res.(*proto.DeleteShardResponse), nil
 }
 
+func (m *maelstromCoordinatorRpcProvider) PrepareLeaderHandover(ctx context.Context, node model.Server, req *proto.PrepareLeaderHandoverRequest) (*proto.PrepareLeaderHandoverResponse, error) {
+	res, err := m.dispatcher.RpcRequest(ctx, node.Internal, MsgTypePrepareHandoverRequest, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*proto.PrepareLeaderHandoverResponse), nil
+}
+
 func (m *maelstromCoordinatorRpcProvider) GetHealthClient(node model.Server) (grpc_health_v1.HealthClient, io.Closer, error) {
 	c := &maelstromHealthCheckClient{
 		provider: m,
@@ -102,6 +102,13 @@ func (m *maelstromGrpcProvider) HandleOxiaRequest(msgType MsgType, msg *Message[
 			m.sendResponse(msg, MsgTypeGetStatusResponse, gsr)
 		}
 
+	case MsgTypePrepareHandoverRequest:
+		if phr, err := m.getService(oxiaCoordination).(proto.OxiaCoordinationServer).PrepareLeaderHandover(context.Background(), message.(*proto.PrepareLeaderHandoverRequest)); err != nil {
+			sendError(msg.Body.MsgId, msg.Src, err)
+		} else {
+			m.sendResponse(msg, MsgTypePrepareHandoverResponse, phr)
+		}
+
 	case MsgTypeHealthCheck:
 		m.sendResponse(msg, MsgTypeHealthCheckOk, &proto.BecomeLeaderResponse{})
 	}
@@ -60,6 +60,9 @@ const (
 	MsgTypeHealthCheck          MsgType = "health"
 	MsgTypeHealthCheckOk        MsgType = "health-ok"
 
+	MsgTypePrepareHandoverRequest  MsgType = "handover-req"
+	MsgTypePrepareHandoverResponse MsgType = "handover-resp"
+
 	MsgTypeShardAssignmentsResponse MsgType = "shards"
 )
 
@@ -72,6 +75,8 @@ var (
 		MsgTypeHealthCheck:         true,
 		MsgTypeGetStatusRequest:    true,
 		MsgTypeDeleteShardRequest:  true,
+
+		MsgTypePrepareHandoverRequest: true,
 	}
 
 	oxiaResponses = map[MsgType]bool{
@@ -82,6 +87,8 @@ var (
 		MsgTypeHealthCheckOk:        true,
 		MsgTypeGetStatusResponse:    true,
 		MsgTypeDeleteShardResponse:  true,
+
+		MsgTypePrepareHandoverResponse: true,
 	}
 
 	oxiaStreamRequests = map[MsgType]bool{
@@ -233,6 +240,9 @@ var protoMsgMapping = map[MsgType]pb.Message{
 	MsgTypeGetStatusRequest:     &proto.GetStatusRequest{},
 	MsgTypeGetStatusResponse:    &proto.GetStatusResponse{},
 
+	MsgTypePrepareHandoverRequest:  &proto.PrepareLeaderHandoverRequest{},
+	MsgTypePrepareHandoverResponse: &proto.PrepareLeaderHandoverResponse{},
+
 	MsgTypeShardAssignmentsResponse: &proto.ShardAssignments{},
 }
 
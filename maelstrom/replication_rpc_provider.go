@@ -87,6 +87,14 @@ func (r *maelstromReplicationRpcProvider) SendSnapshot(ctx context.Context, foll
 	panic("not implemented")
 }
 
+func (r *maelstromReplicationRpcProvider) SendIngestFile(ctx context.Context, follower string, namespace string, shard int64, term int64) (proto.OxiaLogReplication_SendIngestFileClient, error) {
+	panic("not implemented")
+}
+
+func (r *maelstromReplicationRpcProvider) FetchLog(ctx context.Context, follower string, req *proto.FetchLogRequest) (proto.OxiaLogReplication_FetchLogClient, error) {
+	panic("not implemented")
+}
+
 // //////// ReplicateClient.
 type maelstromReplicateClient struct {
 	BaseStream
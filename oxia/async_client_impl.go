@@ -73,7 +73,8 @@ func NewAsyncClient(serviceAddress string, opts ...ClientOption) (AsyncClient, e
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	executor := internal.NewExecutor(ctx, options.namespace, clientPool, shardManager, options.serviceAddress)
+	executor := internal.NewExecutor(ctx, options.namespace, clientPool, shardManager, options.serviceAddress,
+		options.observerAddress)
 	batcherFactory := batch.NewBatcherFactory(
 		executor,
 		options.namespace,
@@ -138,8 +139,10 @@ func (c *clientImpl) Put(key string, value []byte, options ...PutOption) <-chan
 		Callback:           callback,
 		SecondaryIndexes:   toSecondaryIndexes(opts.secondaryIndexes),
 	}
-	if opts.ephemeral {
+	if opts.ephemeral || opts.recordIdentity {
 		putCall.ClientIdentity = &c.options.identity
+	}
+	if opts.ephemeral {
 		c.sessions.executeWithSessionId(shardId, func(sessionId int64, err error) {
 			if err != nil {
 				callback(nil, err)
@@ -332,6 +332,27 @@ func TestAsyncClientImpl_ClientIdentity(t *testing.T) {
 	assert.NoError(t, client2.Close())
 }
 
+func TestAsyncClientImpl_RecordIdentity(t *testing.T) {
+	client, err := NewSyncClient(serviceAddress, WithIdentity("client-1"))
+	assert.NoError(t, err)
+
+	k := newKey()
+	_, version, err := client.Put(context.Background(), k, []byte("v1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "", version.ClientIdentity)
+
+	_, version, err = client.Put(context.Background(), k, []byte("v2"), RecordIdentity())
+	assert.NoError(t, err)
+	assert.False(t, version.Ephemeral)
+	assert.Equal(t, "client-1", version.ClientIdentity)
+
+	_, _, version, err = client.Get(context.Background(), k)
+	assert.NoError(t, err)
+	assert.Equal(t, "client-1", version.ClientIdentity)
+
+	assert.NoError(t, client.Close())
+}
+
 func TestSyncClientImpl_SessionNotifications(t *testing.T) {
 	standaloneServer, err := server.NewStandalone(server.NewTestConfig(t.TempDir()))
 	assert.NoError(t, err)
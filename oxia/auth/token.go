@@ -16,6 +16,13 @@ package auth
 
 import (
 	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 type tokenAuthentication struct {
@@ -43,3 +50,59 @@ func NewTokenAuthenticationWithToken(token string, requireTransportSecurity bool
 		return token
 	}, requireTransportSecurity)
 }
+
+type fileTokenSource struct {
+	sync.Mutex
+	path            string
+	refreshInterval time.Duration
+	token           string
+	lastRead        time.Time
+}
+
+func (s *fileTokenSource) read() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return errors.Errorf("empty token in file %s", s.path)
+	}
+	s.token = token
+	s.lastRead = time.Now()
+	return nil
+}
+
+func (s *fileTokenSource) get() string {
+	s.Lock()
+	defer s.Unlock()
+
+	if time.Since(s.lastRead) >= s.refreshInterval {
+		if err := s.read(); err != nil {
+			// Keep using the last token, the file might be in the middle
+			// of being replaced
+			slog.Warn(
+				"Failed to refresh the authentication token",
+				slog.String("path", s.path),
+				slog.Any("error", err),
+			)
+			s.lastRead = time.Now()
+		}
+	}
+	return s.token
+}
+
+// NewTokenAuthenticationWithFile creates an authentication that reads the token
+// from a file, like the tokens that are mounted and rotated by Kubernetes. The
+// file is read again once the refresh interval has elapsed.
+func NewTokenAuthenticationWithFile(path string, refreshInterval time.Duration,
+	requireTransportSecurity bool) (Authentication, error) {
+	source := &fileTokenSource{
+		path:            path,
+		refreshInterval: refreshInterval,
+	}
+	if err := source.read(); err != nil {
+		return nil, err
+	}
+	return NewTokenAuthenticationWithFunc(source.get, requireTransportSecurity), nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenAuthenticationWithFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	_, err := NewTokenAuthenticationWithFile(path, time.Minute, false)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(path, []byte("token-1\n"), 0600))
+	authentication, err := NewTokenAuthenticationWithFile(path, 50*time.Millisecond, false)
+	require.NoError(t, err)
+	assert.False(t, authentication.RequireTransportSecurity())
+
+	md, err := authentication.GetRequestMetadata(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token-1", md["authorization"])
+
+	// The rotated token is picked up after the refresh interval
+	require.NoError(t, os.WriteFile(path, []byte("token-2"), 0600))
+	assert.Eventually(t, func() bool {
+		md, err := authentication.GetRequestMetadata(context.Background())
+		return err == nil && md["authorization"] == "Bearer token-2"
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// The last token is kept while the file can't be read
+	require.NoError(t, os.Remove(path))
+	time.Sleep(100 * time.Millisecond)
+	md, err = authentication.GetRequestMetadata(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token-2", md["authorization"])
+}
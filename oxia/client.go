@@ -40,6 +40,10 @@ var (
 	// ErrRequestTooLarge is returned when a request is larger than the maximum batch size.
 	ErrRequestTooLarge = batch.ErrRequestTooLarge
 
+	// ErrQuotaExceeded The put would make the namespace exceed its limits on the
+	// stored data, or the value is larger than the maximum value size.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+
 	// ErrUnknownStatus Unknown error.
 	ErrUnknownStatus = errors.New("unknown status")
 )
@@ -199,7 +203,7 @@ type Version struct {
 	SessionId int64
 
 	// For ephemeral records, the unique identity of the Oxia client that did last modify it.
-	// It will be empty for all non-ephemeral records.
+	// It will be empty for all non-ephemeral records, unless they were put with [RecordIdentity].
 	ClientIdentity string
 }
 
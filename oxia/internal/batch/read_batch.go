@@ -94,15 +94,19 @@ func (b *readBatch) doRequestWithRetries(request *proto.ReadRequest) (response *
 	ctx, cancel := context.WithTimeout(context.Background(), b.requestTimeout)
 	defer cancel()
 
-	backOff := common.NewBackOff(ctx)
+	backOff := newRetryBackOff(ctx)
 
 	err = backoff.RetryNotify(func() error {
 		response, err = b.doRequest(ctx, request)
 		if !isRetriable(err) {
 			return backoff.Permanent(err)
 		}
+		backOff.observe(err)
 		return err
 	}, backOff, func(err error, duration time.Duration) {
+		if _, throttled := common.RetryAfter(err); throttled {
+			b.metrics.RecordThrottled("read", duration)
+		}
 		slog.Debug(
 			"Failed to perform request, retrying later",
 			slog.Any("error", err),
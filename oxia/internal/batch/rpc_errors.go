@@ -15,6 +15,10 @@
 package batch
 
 import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -37,7 +41,39 @@ func isRetriable(err error) bool {
 		// We're making a request to a node that is not leader anymore.
 		// Retry to make the request to the new leader
 		return true
+	case codes.ResourceExhausted:
+		// The request was rate limited, and it can be retried after the
+		// suggested delay
+		_, ok := common.RetryAfter(err)
+		return ok
 	}
 
 	return false
 }
+
+// retryBackOff waits at least for the delay suggested by the server, when the
+// last request was rate limited.
+type retryBackOff struct {
+	backoff.BackOffContext
+	retryAfter time.Duration
+}
+
+func newRetryBackOff(ctx context.Context) *retryBackOff {
+	return &retryBackOff{
+		BackOffContext: backoff.WithContext(common.NewBackOff(ctx), ctx),
+	}
+}
+
+// observe records the retry delay suggested in the error of the last request.
+func (b *retryBackOff) observe(err error) {
+	b.retryAfter, _ = common.RetryAfter(err)
+}
+
+func (b *retryBackOff) NextBackOff() time.Duration {
+	next := b.BackOffContext.NextBackOff()
+	if next != backoff.Stop && b.retryAfter > next {
+		next = b.retryAfter
+	}
+	b.retryAfter = 0
+	return next
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/streamnative/oxia/common"
+)
+
+func TestIsRetriable(t *testing.T) {
+	assert.True(t, isRetriable(status.Error(codes.Unavailable, "unavailable")))
+	assert.True(t, isRetriable(common.NewRateLimitedError("write", time.Second)))
+	assert.False(t, isRetriable(status.Error(codes.ResourceExhausted, "message too large")))
+	assert.False(t, isRetriable(errors.New("error")))
+}
+
+func TestRetryBackOff(t *testing.T) {
+	backOff := newRetryBackOff(context.Background())
+
+	backOff.observe(common.NewRateLimitedError("write", 10*time.Second))
+	assert.Equal(t, 10*time.Second, backOff.NextBackOff())
+
+	// The hint is only used for the next retry
+	assert.Less(t, backOff.NextBackOff(), 10*time.Second)
+
+	backOff.observe(status.Error(codes.Unavailable, "unavailable"))
+	assert.Less(t, backOff.NextBackOff(), 10*time.Second)
+}
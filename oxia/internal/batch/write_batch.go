@@ -114,15 +114,19 @@ func (b *writeBatch) doRequestWithRetries(request *proto.WriteRequest) (response
 	ctx, cancel := context.WithTimeout(context.Background(), b.requestTimeout)
 	defer cancel()
 
-	backOff := common.NewBackOff(ctx)
+	backOff := newRetryBackOff(ctx)
 
 	err = backoff.RetryNotify(func() error {
 		response, err = b.execute(ctx, request)
 		if !isRetriable(err) {
 			return backoff.Permanent(err)
 		}
+		backOff.observe(err)
 		return err
 	}, backOff, func(err error, duration time.Duration) {
+		if _, throttled := common.RetryAfter(err); throttled {
+			b.metrics.RecordThrottled("write", duration)
+		}
 		slog.Debug(
 			"Failed to perform request, retrying later",
 			slog.Any("error", err),
@@ -23,6 +23,7 @@ func toShard(assignment *proto.ShardAssignment) Shard {
 		Id:        assignment.Shard,
 		Leader:    assignment.Leader,
 		HashRange: toHashRange(assignment),
+		Observers: assignment.Observers,
 	}
 }
 
@@ -17,6 +17,7 @@ package internal
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 
 	"google.golang.org/grpc/metadata"
@@ -39,20 +40,25 @@ type executorImpl struct {
 	ShardManager   ShardManager
 	ServiceAddress string
 
+	// When set, the reads for the shards replicated to this observer are sent to it
+	ObserverAddress string
+
 	writeStreams map[int64]*streamWrapper
 
 	ctx       context.Context
 	namespace string
 }
 
-func NewExecutor(ctx context.Context, namespace string, pool common.ClientPool, manager ShardManager, serviceAddress string) Executor {
+func NewExecutor(ctx context.Context, namespace string, pool common.ClientPool, manager ShardManager,
+	serviceAddress string, observerAddress string) Executor {
 	e := &executorImpl{
-		ctx:            ctx,
-		namespace:      namespace,
-		ClientPool:     pool,
-		ShardManager:   manager,
-		ServiceAddress: serviceAddress,
-		writeStreams:   make(map[int64]*streamWrapper),
+		ctx:             ctx,
+		namespace:       namespace,
+		ClientPool:      pool,
+		ShardManager:    manager,
+		ServiceAddress:  serviceAddress,
+		ObserverAddress: observerAddress,
+		writeStreams:    make(map[int64]*streamWrapper),
 	}
 
 	return e
@@ -68,7 +74,7 @@ func (e *executorImpl) ExecuteWrite(ctx context.Context, request *proto.WriteReq
 }
 
 func (e *executorImpl) ExecuteRead(ctx context.Context, request *proto.ReadRequest) (proto.OxiaClient_ReadClient, error) {
-	rpc, err := e.rpc(request.Shard)
+	rpc, err := e.readRpc(request.Shard)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +83,7 @@ func (e *executorImpl) ExecuteRead(ctx context.Context, request *proto.ReadReque
 }
 
 func (e *executorImpl) ExecuteList(ctx context.Context, request *proto.ListRequest) (proto.OxiaClient_ListClient, error) {
-	rpc, err := e.rpc(request.Shard)
+	rpc, err := e.readRpc(request.Shard)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +92,7 @@ func (e *executorImpl) ExecuteList(ctx context.Context, request *proto.ListReque
 }
 
 func (e *executorImpl) ExecuteRangeScan(ctx context.Context, request *proto.RangeScanRequest) (proto.OxiaClient_RangeScanClient, error) {
-	rpc, err := e.rpc(request.Shard)
+	rpc, err := e.readRpc(request.Shard)
 	if err != nil {
 		return nil, err
 	}
@@ -94,6 +100,15 @@ func (e *executorImpl) ExecuteRangeScan(ctx context.Context, request *proto.Rang
 	return rpc.RangeScan(ctx, request)
 }
 
+func (e *executorImpl) readRpc(shardId *int64) (proto.OxiaClientClient, error) {
+	if shardId != nil && e.ObserverAddress != "" &&
+		slices.Contains(e.ShardManager.Observers(*shardId), e.ObserverAddress) {
+		return e.ClientPool.GetClientRpc(e.ObserverAddress)
+	}
+
+	return e.rpc(shardId)
+}
+
 func (e *executorImpl) rpc(shardId *int64) (proto.OxiaClientClient, error) {
 	var target string
 	if shardId != nil {
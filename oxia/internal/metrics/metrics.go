@@ -18,6 +18,7 @@ import (
 	"context"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 
 	"github.com/streamnative/oxia/common/metrics"
@@ -36,6 +37,8 @@ type Metrics struct {
 	batchExecTime  Timer
 	batchValue     metric.Int64Histogram
 	batchRequests  metric.Int64Histogram
+
+	throttledTime Timer
 }
 
 func NewMetrics(provider metric.MeterProvider) *Metrics {
@@ -55,6 +58,8 @@ func newMetrics(provider metric.MeterProvider, timeFunc func() time.Time, sinceF
 		batchExecTime:  newTimer(meter, "oxia_client_batch_exec"),
 		batchValue:     newHistogram(meter, "oxia_client_batch_value", metrics.Bytes),
 		batchRequests:  newHistogram(meter, "oxia_client_batch_request", ""),
+
+		throttledTime: newTimer(meter, "oxia_client_batch_throttled"),
 	}
 }
 
@@ -132,6 +137,14 @@ func (m *Metrics) ReadCallback() func(time.Time, *proto.ReadRequest, *proto.Read
 	}
 }
 
+// RecordThrottled records a batch that was throttled by the server, either
+// rate limited or rejected by an overloaded leader, and the time it waits
+// before being retried.
+func (m *Metrics) RecordThrottled(requestType string, retryAfter time.Duration) {
+	m.throttledTime.Record(context.TODO(), retryAfter,
+		metric.WithAttributes(attribute.Key("type").String(requestType)))
+}
+
 func (m *Metrics) metricContextFunc(requestType string) func(error) (context.Context, time.Time, metric.MeasurementOption) {
 	start := m.timeFunc()
 	return func(err error) (context.Context, time.Time, metric.MeasurementOption) {
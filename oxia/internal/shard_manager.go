@@ -35,6 +35,7 @@ type ShardManager interface {
 	Get(key string) int64
 	GetAll() []int64
 	Leader(shardId int64) string
+	Observers(shardId int64) []string
 }
 
 type shardManagerImpl struct {
@@ -134,6 +135,16 @@ func (s *shardManagerImpl) Leader(shardId int64) string {
 	panic("shard not found")
 }
 
+func (s *shardManagerImpl) Observers(shardId int64) []string {
+	s.RLock()
+	defer s.RUnlock()
+
+	if shard, ok := s.shards[shardId]; ok {
+		return shard.Observers
+	}
+	panic("shard not found")
+}
+
 func (s *shardManagerImpl) isClosed() bool {
 	return s.ctx.Err() != nil
 }
@@ -22,6 +22,7 @@ type Shard struct {
 	Id        int64
 	Leader    string
 	HashRange HashRange
+	Observers []string
 }
 
 type HashRange struct {
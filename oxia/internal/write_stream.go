@@ -16,7 +16,6 @@ package internal
 
 import (
 	"context"
-	"io"
 	"log/slog"
 	"sync"
 	"sync/atomic"
@@ -40,7 +39,6 @@ func newStreamWrapper(stream proto.OxiaClient_WriteStreamClient) *streamWrapper
 	}
 
 	go sw.handleResponses()
-	go sw.handleStreamClosed()
 	return sw
 }
 
@@ -60,26 +58,20 @@ func (sw *streamWrapper) Send(ctx context.Context, req *proto.WriteRequest) (*pr
 	return f.Wait(ctx)
 }
 
-func (sw *streamWrapper) handleStreamClosed() {
-	<-sw.stream.Context().Done()
-
-	// Fail all pending requests
-	sw.Lock()
-	defer sw.Unlock()
-
-	for _, f := range sw.pendingRequests {
-		f.Fail(io.EOF)
-	}
-	sw.pendingRequests = nil
-	sw.failed.Store(true)
-}
-
 func (sw *streamWrapper) handleResponses() {
 	for {
 		response, err := sw.stream.Recv()
 		sw.Lock()
 
 		if err != nil {
+			// Fail all pending requests with the error returned by the server,
+			// so that the caller can decide whether they can be retried.
+			// The stream is also closed when its context is done, in which
+			// case we get the context error here.
+			for _, f := range sw.pendingRequests {
+				f.Fail(err)
+			}
+			sw.pendingRequests = nil
 			sw.failed.Store(true)
 			sw.Unlock()
 			return
@@ -94,6 +86,11 @@ func (sw *streamWrapper) handleResponses() {
 		f, sw.pendingRequests = sw.pendingRequests[0], sw.pendingRequests[1:]
 		sw.Unlock()
 
+		// A rejected request fails alone, while the stream stays usable
+		if response.Rejection != nil {
+			f.Fail(common.WriteRejectionError(response.Rejection))
+			continue
+		}
 		f.Complete(response)
 	}
 }
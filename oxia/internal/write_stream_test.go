@@ -0,0 +1,113 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/proto"
+)
+
+type mockWriteStreamClient struct {
+	grpc.ClientStream
+	ctx       context.Context
+	requests  chan *proto.WriteRequest
+	responses chan *proto.WriteResponse
+	errors    chan error
+}
+
+func newMockWriteStreamClient() *mockWriteStreamClient {
+	return &mockWriteStreamClient{
+		ctx:       context.Background(),
+		requests:  make(chan *proto.WriteRequest, 10),
+		responses: make(chan *proto.WriteResponse, 10),
+		errors:    make(chan error, 1),
+	}
+}
+
+func (m *mockWriteStreamClient) Context() context.Context {
+	return m.ctx
+}
+
+func (m *mockWriteStreamClient) Send(req *proto.WriteRequest) error {
+	m.requests <- req
+	return nil
+}
+
+func (m *mockWriteStreamClient) Recv() (*proto.WriteResponse, error) {
+	select {
+	case res := <-m.responses:
+		return res, nil
+	case err := <-m.errors:
+		return nil, err
+	}
+}
+
+func TestStreamWrapper_FailPendingRequestsWithServerError(t *testing.T) {
+	stream := newMockWriteStreamClient()
+	sw := newStreamWrapper(stream)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream.responses <- &proto.WriteResponse{}
+	res, err := sw.Send(ctx, &proto.WriteRequest{})
+	assert.NoError(t, err)
+	assert.NotNil(t, res)
+
+	// The server closes the stream while a request is pending. The request
+	// must fail with the server error, so that it can be retried.
+	errCh := make(chan error)
+	go func() {
+		_, err := sw.Send(ctx, &proto.WriteRequest{})
+		errCh <- err
+	}()
+
+	<-stream.requests
+	<-stream.requests
+	stream.errors <- common.ErrorNodeIsNotLeader
+
+	err = <-errCh
+	assert.Equal(t, common.CodeNodeIsNotLeader, status.Code(err))
+	assert.True(t, sw.failed.Load())
+}
+
+func TestStreamWrapper_RejectedRequest(t *testing.T) {
+	stream := newMockWriteStreamClient()
+	sw := newStreamWrapper(stream)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream.responses <- common.NewWriteRejection(common.NewRateLimitedError("write", time.Second))
+	_, err := sw.Send(ctx, &proto.WriteRequest{})
+	retryAfter, ok := common.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, retryAfter)
+
+	// The stream is still usable
+	assert.False(t, sw.failed.Load())
+	stream.responses <- &proto.WriteResponse{}
+	res, err := sw.Send(ctx, &proto.WriteRequest{})
+	assert.NoError(t, err)
+	assert.NotNil(t, res)
+}
@@ -48,6 +48,7 @@ var (
 	ErrInvalidOptionNamespace           = errors.New("Namespace cannot be empty")
 	ErrInvalidOptionTLS                 = errors.New("Tls cannot be empty")
 	ErrInvalidOptionAuthentication      = errors.New("Authentication cannot be empty")
+	ErrInvalidOptionObserverAddress     = errors.New("ObserverAddress cannot be empty")
 )
 
 // clientOptions contains options for the Oxia client.
@@ -63,6 +64,7 @@ type clientOptions struct {
 	identity            string
 	tls                 *tls.Config
 	authentication      auth.Authentication
+	observerAddress     string
 }
 
 func defaultIdentity() string {
@@ -183,6 +185,21 @@ func WithSessionTimeout(sessionTimeout time.Duration) ClientOption {
 	})
 }
 
+// WithObserverReads sends the reads (Get, List and RangeScan) for the shards that are
+// replicated to the observer with the given public address, e.g. `host:port`, to that observer.
+// The observers are outside the quorum and the reads served by them can return stale data,
+// that doesn't reflect the most recent writes, even the ones done by this same client.
+// The reads for any other shard are still sent to the shard leader.
+func WithObserverReads(observerAddress string) ClientOption {
+	return clientOptionFunc(func(options clientOptions) (clientOptions, error) {
+		if observerAddress == "" {
+			return options, ErrInvalidOptionObserverAddress
+		}
+		options.observerAddress = observerAddress
+		return options, nil
+	})
+}
+
 func WithIdentity(identity string) ClientOption {
 	return clientOptionFunc(func(options clientOptions) (clientOptions, error) {
 		if identity == "" {
@@ -20,6 +20,7 @@ type putOptions struct {
 	baseOptions
 	expectedVersion    *int64
 	ephemeral          bool
+	recordIdentity     bool
 	sequenceKeysDeltas []uint64
 	secondaryIndexes   []*secondaryIdxOption
 }
@@ -78,6 +79,20 @@ func Ephemeral() PutOption {
 	return ephemeralFlag
 }
 
+type recordIdentity struct{}
+
+var recordIdentityFlag = &recordIdentity{}
+
+func (*recordIdentity) applyPut(opts *putOptions) {
+	opts.recordIdentity = true
+}
+
+// RecordIdentity stores the identity of the client, set with [WithIdentity],
+// in the version of the record, as it's always done for the ephemeral records.
+func RecordIdentity() PutOption {
+	return recordIdentityFlag
+}
+
 type sequenceKeysDeltas struct {
 	sequenceKeysDeltas []uint64
 }
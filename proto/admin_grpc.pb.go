@@ -0,0 +1,416 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v5.27.3
+// source: admin.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// OxiaAdminClient is the client API for OxiaAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OxiaAdminClient interface {
+	// *
+	// Streams a consistent snapshot of the database of a shard. The snapshot
+	// contains the commit offset of the shard, from where the write-ahead-log
+	// can be replayed.
+	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (OxiaAdmin_BackupClient, error)
+	// *
+	// Streams all the records of a shard, from a consistent view of its
+	// database. The ephemeral records are not included.
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (OxiaAdmin_ExportClient, error)
+	// *
+	// Ingests an sstable in the database of a shard, without passing its records
+	// through the write-ahead-log. The sstable is built by the bulk-load tool,
+	// and the first chunk must have the namespace and the shard.
+	Ingest(ctx context.Context, opts ...grpc.CallOption) (OxiaAdmin_IngestClient, error)
+	// *
+	// Reads the records of a shard with all their stored attributes. The keys
+	// that don't exist or that belong to ephemeral records are omitted.
+	GetRecords(ctx context.Context, in *GetRecordsRequest, opts ...grpc.CallOption) (*GetRecordsResponse, error)
+	// *
+	// Returns the keys and the key prefixes of a shard that were accessed the
+	// most in the last minutes, estimated from a sample of the reads and the
+	// writes.
+	GetHotKeys(ctx context.Context, in *GetHotKeysRequest, opts ...grpc.CallOption) (*GetHotKeysResponse, error)
+	// *
+	// Returns the last operations of a shard that took longer than the slow
+	// operation threshold of its leader, the most recent first.
+	GetSlowOperations(ctx context.Context, in *GetSlowOperationsRequest, opts ...grpc.CallOption) (*GetSlowOperationsResponse, error)
+}
+
+type oxiaAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOxiaAdminClient(cc grpc.ClientConnInterface) OxiaAdminClient {
+	return &oxiaAdminClient{cc}
+}
+
+func (c *oxiaAdminClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (OxiaAdmin_BackupClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OxiaAdmin_ServiceDesc.Streams[0], "/admin.OxiaAdmin/Backup", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &oxiaAdminBackupClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type OxiaAdmin_BackupClient interface {
+	Recv() (*SnapshotChunk, error)
+	grpc.ClientStream
+}
+
+type oxiaAdminBackupClient struct {
+	grpc.ClientStream
+}
+
+func (x *oxiaAdminBackupClient) Recv() (*SnapshotChunk, error) {
+	m := new(SnapshotChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *oxiaAdminClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (OxiaAdmin_ExportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OxiaAdmin_ServiceDesc.Streams[1], "/admin.OxiaAdmin/Export", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &oxiaAdminExportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type OxiaAdmin_ExportClient interface {
+	Recv() (*ExportResponse, error)
+	grpc.ClientStream
+}
+
+type oxiaAdminExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *oxiaAdminExportClient) Recv() (*ExportResponse, error) {
+	m := new(ExportResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *oxiaAdminClient) Ingest(ctx context.Context, opts ...grpc.CallOption) (OxiaAdmin_IngestClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OxiaAdmin_ServiceDesc.Streams[2], "/admin.OxiaAdmin/Ingest", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &oxiaAdminIngestClient{stream}
+	return x, nil
+}
+
+type OxiaAdmin_IngestClient interface {
+	Send(*IngestChunk) error
+	CloseAndRecv() (*IngestResponse, error)
+	grpc.ClientStream
+}
+
+type oxiaAdminIngestClient struct {
+	grpc.ClientStream
+}
+
+func (x *oxiaAdminIngestClient) Send(m *IngestChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *oxiaAdminIngestClient) CloseAndRecv() (*IngestResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(IngestResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *oxiaAdminClient) GetRecords(ctx context.Context, in *GetRecordsRequest, opts ...grpc.CallOption) (*GetRecordsResponse, error) {
+	out := new(GetRecordsResponse)
+	err := c.cc.Invoke(ctx, "/admin.OxiaAdmin/GetRecords", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oxiaAdminClient) GetHotKeys(ctx context.Context, in *GetHotKeysRequest, opts ...grpc.CallOption) (*GetHotKeysResponse, error) {
+	out := new(GetHotKeysResponse)
+	err := c.cc.Invoke(ctx, "/admin.OxiaAdmin/GetHotKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oxiaAdminClient) GetSlowOperations(ctx context.Context, in *GetSlowOperationsRequest, opts ...grpc.CallOption) (*GetSlowOperationsResponse, error) {
+	out := new(GetSlowOperationsResponse)
+	err := c.cc.Invoke(ctx, "/admin.OxiaAdmin/GetSlowOperations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OxiaAdminServer is the server API for OxiaAdmin service.
+// All implementations must embed UnimplementedOxiaAdminServer
+// for forward compatibility
+type OxiaAdminServer interface {
+	// *
+	// Streams a consistent snapshot of the database of a shard. The snapshot
+	// contains the commit offset of the shard, from where the write-ahead-log
+	// can be replayed.
+	Backup(*BackupRequest, OxiaAdmin_BackupServer) error
+	// *
+	// Streams all the records of a shard, from a consistent view of its
+	// database. The ephemeral records are not included.
+	Export(*ExportRequest, OxiaAdmin_ExportServer) error
+	// *
+	// Ingests an sstable in the database of a shard, without passing its records
+	// through the write-ahead-log. The sstable is built by the bulk-load tool,
+	// and the first chunk must have the namespace and the shard.
+	Ingest(OxiaAdmin_IngestServer) error
+	// *
+	// Reads the records of a shard with all their stored attributes. The keys
+	// that don't exist or that belong to ephemeral records are omitted.
+	GetRecords(context.Context, *GetRecordsRequest) (*GetRecordsResponse, error)
+	// *
+	// Returns the keys and the key prefixes of a shard that were accessed the
+	// most in the last minutes, estimated from a sample of the reads and the
+	// writes.
+	GetHotKeys(context.Context, *GetHotKeysRequest) (*GetHotKeysResponse, error)
+	// *
+	// Returns the last operations of a shard that took longer than the slow
+	// operation threshold of its leader, the most recent first.
+	GetSlowOperations(context.Context, *GetSlowOperationsRequest) (*GetSlowOperationsResponse, error)
+	mustEmbedUnimplementedOxiaAdminServer()
+}
+
+// UnimplementedOxiaAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedOxiaAdminServer struct {
+}
+
+func (UnimplementedOxiaAdminServer) Backup(*BackupRequest, OxiaAdmin_BackupServer) error {
+	return status.Errorf(codes.Unimplemented, "method Backup not implemented")
+}
+func (UnimplementedOxiaAdminServer) Export(*ExportRequest, OxiaAdmin_ExportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (UnimplementedOxiaAdminServer) Ingest(OxiaAdmin_IngestServer) error {
+	return status.Errorf(codes.Unimplemented, "method Ingest not implemented")
+}
+func (UnimplementedOxiaAdminServer) GetRecords(context.Context, *GetRecordsRequest) (*GetRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRecords not implemented")
+}
+func (UnimplementedOxiaAdminServer) GetHotKeys(context.Context, *GetHotKeysRequest) (*GetHotKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHotKeys not implemented")
+}
+func (UnimplementedOxiaAdminServer) GetSlowOperations(context.Context, *GetSlowOperationsRequest) (*GetSlowOperationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSlowOperations not implemented")
+}
+func (UnimplementedOxiaAdminServer) mustEmbedUnimplementedOxiaAdminServer() {}
+
+// UnsafeOxiaAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OxiaAdminServer will
+// result in compilation errors.
+type UnsafeOxiaAdminServer interface {
+	mustEmbedUnimplementedOxiaAdminServer()
+}
+
+func RegisterOxiaAdminServer(s grpc.ServiceRegistrar, srv OxiaAdminServer) {
+	s.RegisterService(&OxiaAdmin_ServiceDesc, srv)
+}
+
+func _OxiaAdmin_Backup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BackupRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OxiaAdminServer).Backup(m, &oxiaAdminBackupServer{stream})
+}
+
+type OxiaAdmin_BackupServer interface {
+	Send(*SnapshotChunk) error
+	grpc.ServerStream
+}
+
+type oxiaAdminBackupServer struct {
+	grpc.ServerStream
+}
+
+func (x *oxiaAdminBackupServer) Send(m *SnapshotChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _OxiaAdmin_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OxiaAdminServer).Export(m, &oxiaAdminExportServer{stream})
+}
+
+type OxiaAdmin_ExportServer interface {
+	Send(*ExportResponse) error
+	grpc.ServerStream
+}
+
+type oxiaAdminExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *oxiaAdminExportServer) Send(m *ExportResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _OxiaAdmin_Ingest_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(OxiaAdminServer).Ingest(&oxiaAdminIngestServer{stream})
+}
+
+type OxiaAdmin_IngestServer interface {
+	SendAndClose(*IngestResponse) error
+	Recv() (*IngestChunk, error)
+	grpc.ServerStream
+}
+
+type oxiaAdminIngestServer struct {
+	grpc.ServerStream
+}
+
+func (x *oxiaAdminIngestServer) SendAndClose(m *IngestResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *oxiaAdminIngestServer) Recv() (*IngestChunk, error) {
+	m := new(IngestChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _OxiaAdmin_GetRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OxiaAdminServer).GetRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/admin.OxiaAdmin/GetRecords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OxiaAdminServer).GetRecords(ctx, req.(*GetRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OxiaAdmin_GetHotKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHotKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OxiaAdminServer).GetHotKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/admin.OxiaAdmin/GetHotKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OxiaAdminServer).GetHotKeys(ctx, req.(*GetHotKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OxiaAdmin_GetSlowOperations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSlowOperationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OxiaAdminServer).GetSlowOperations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/admin.OxiaAdmin/GetSlowOperations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OxiaAdminServer).GetSlowOperations(ctx, req.(*GetSlowOperationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// OxiaAdmin_ServiceDesc is the grpc.ServiceDesc for OxiaAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var OxiaAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "admin.OxiaAdmin",
+	HandlerType: (*OxiaAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetRecords",
+			Handler:    _OxiaAdmin_GetRecords_Handler,
+		},
+		{
+			MethodName: "GetHotKeys",
+			Handler:    _OxiaAdmin_GetHotKeys_Handler,
+		},
+		{
+			MethodName: "GetSlowOperations",
+			Handler:    _OxiaAdmin_GetSlowOperations_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Backup",
+			Handler:       _OxiaAdmin_Backup_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Export",
+			Handler:       _OxiaAdmin_Export_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Ingest",
+			Handler:       _OxiaAdmin_Ingest_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "admin.proto",
+}
@@ -28,6 +28,7 @@ type OxiaCoordinationClient interface {
 	AddFollower(ctx context.Context, in *AddFollowerRequest, opts ...grpc.CallOption) (*AddFollowerResponse, error)
 	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
 	DeleteShard(ctx context.Context, in *DeleteShardRequest, opts ...grpc.CallOption) (*DeleteShardResponse, error)
+	PrepareLeaderHandover(ctx context.Context, in *PrepareLeaderHandoverRequest, opts ...grpc.CallOption) (*PrepareLeaderHandoverResponse, error)
 }
 
 type oxiaCoordinationClient struct {
@@ -117,6 +118,15 @@ func (c *oxiaCoordinationClient) DeleteShard(ctx context.Context, in *DeleteShar
 	return out, nil
 }
 
+func (c *oxiaCoordinationClient) PrepareLeaderHandover(ctx context.Context, in *PrepareLeaderHandoverRequest, opts ...grpc.CallOption) (*PrepareLeaderHandoverResponse, error) {
+	out := new(PrepareLeaderHandoverResponse)
+	err := c.cc.Invoke(ctx, "/replication.OxiaCoordination/PrepareLeaderHandover", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OxiaCoordinationServer is the server API for OxiaCoordination service.
 // All implementations must embed UnimplementedOxiaCoordinationServer
 // for forward compatibility
@@ -127,6 +137,7 @@ type OxiaCoordinationServer interface {
 	AddFollower(context.Context, *AddFollowerRequest) (*AddFollowerResponse, error)
 	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
 	DeleteShard(context.Context, *DeleteShardRequest) (*DeleteShardResponse, error)
+	PrepareLeaderHandover(context.Context, *PrepareLeaderHandoverRequest) (*PrepareLeaderHandoverResponse, error)
 	mustEmbedUnimplementedOxiaCoordinationServer()
 }
 
@@ -152,6 +163,9 @@ func (UnimplementedOxiaCoordinationServer) GetStatus(context.Context, *GetStatus
 func (UnimplementedOxiaCoordinationServer) DeleteShard(context.Context, *DeleteShardRequest) (*DeleteShardResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteShard not implemented")
 }
+func (UnimplementedOxiaCoordinationServer) PrepareLeaderHandover(context.Context, *PrepareLeaderHandoverRequest) (*PrepareLeaderHandoverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PrepareLeaderHandover not implemented")
+}
 func (UnimplementedOxiaCoordinationServer) mustEmbedUnimplementedOxiaCoordinationServer() {}
 
 // UnsafeOxiaCoordinationServer may be embedded to opt out of forward compatibility for this service.
@@ -281,6 +295,24 @@ func _OxiaCoordination_DeleteShard_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OxiaCoordination_PrepareLeaderHandover_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrepareLeaderHandoverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OxiaCoordinationServer).PrepareLeaderHandover(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/replication.OxiaCoordination/PrepareLeaderHandover",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OxiaCoordinationServer).PrepareLeaderHandover(ctx, req.(*PrepareLeaderHandoverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // OxiaCoordination_ServiceDesc is the grpc.ServiceDesc for OxiaCoordination service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -308,6 +340,10 @@ var OxiaCoordination_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteShard",
 			Handler:    _OxiaCoordination_DeleteShard_Handler,
 		},
+		{
+			MethodName: "PrepareLeaderHandover",
+			Handler:    _OxiaCoordination_PrepareLeaderHandover_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -326,6 +362,10 @@ type OxiaLogReplicationClient interface {
 	Truncate(ctx context.Context, in *TruncateRequest, opts ...grpc.CallOption) (*TruncateResponse, error)
 	Replicate(ctx context.Context, opts ...grpc.CallOption) (OxiaLogReplication_ReplicateClient, error)
 	SendSnapshot(ctx context.Context, opts ...grpc.CallOption) (OxiaLogReplication_SendSnapshotClient, error)
+	SendIngestFile(ctx context.Context, opts ...grpc.CallOption) (OxiaLogReplication_SendIngestFileClient, error)
+	// Streams the entries in the wal of a fenced witness, to let the new
+	// leader recover the entries that only the witness has
+	FetchLog(ctx context.Context, in *FetchLogRequest, opts ...grpc.CallOption) (OxiaLogReplication_FetchLogClient, error)
 }
 
 type oxiaLogReplicationClient struct {
@@ -410,6 +450,72 @@ func (x *oxiaLogReplicationSendSnapshotClient) CloseAndRecv() (*SnapshotResponse
 	return m, nil
 }
 
+func (c *oxiaLogReplicationClient) SendIngestFile(ctx context.Context, opts ...grpc.CallOption) (OxiaLogReplication_SendIngestFileClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OxiaLogReplication_ServiceDesc.Streams[2], "/replication.OxiaLogReplication/SendIngestFile", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &oxiaLogReplicationSendIngestFileClient{stream}
+	return x, nil
+}
+
+type OxiaLogReplication_SendIngestFileClient interface {
+	Send(*SnapshotChunk) error
+	CloseAndRecv() (*IngestFileResponse, error)
+	grpc.ClientStream
+}
+
+type oxiaLogReplicationSendIngestFileClient struct {
+	grpc.ClientStream
+}
+
+func (x *oxiaLogReplicationSendIngestFileClient) Send(m *SnapshotChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *oxiaLogReplicationSendIngestFileClient) CloseAndRecv() (*IngestFileResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(IngestFileResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *oxiaLogReplicationClient) FetchLog(ctx context.Context, in *FetchLogRequest, opts ...grpc.CallOption) (OxiaLogReplication_FetchLogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OxiaLogReplication_ServiceDesc.Streams[3], "/replication.OxiaLogReplication/FetchLog", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &oxiaLogReplicationFetchLogClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type OxiaLogReplication_FetchLogClient interface {
+	Recv() (*LogEntry, error)
+	grpc.ClientStream
+}
+
+type oxiaLogReplicationFetchLogClient struct {
+	grpc.ClientStream
+}
+
+func (x *oxiaLogReplicationFetchLogClient) Recv() (*LogEntry, error) {
+	m := new(LogEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // OxiaLogReplicationServer is the server API for OxiaLogReplication service.
 // All implementations must embed UnimplementedOxiaLogReplicationServer
 // for forward compatibility
@@ -417,6 +523,10 @@ type OxiaLogReplicationServer interface {
 	Truncate(context.Context, *TruncateRequest) (*TruncateResponse, error)
 	Replicate(OxiaLogReplication_ReplicateServer) error
 	SendSnapshot(OxiaLogReplication_SendSnapshotServer) error
+	SendIngestFile(OxiaLogReplication_SendIngestFileServer) error
+	// Streams the entries in the wal of a fenced witness, to let the new
+	// leader recover the entries that only the witness has
+	FetchLog(*FetchLogRequest, OxiaLogReplication_FetchLogServer) error
 	mustEmbedUnimplementedOxiaLogReplicationServer()
 }
 
@@ -433,6 +543,12 @@ func (UnimplementedOxiaLogReplicationServer) Replicate(OxiaLogReplication_Replic
 func (UnimplementedOxiaLogReplicationServer) SendSnapshot(OxiaLogReplication_SendSnapshotServer) error {
 	return status.Errorf(codes.Unimplemented, "method SendSnapshot not implemented")
 }
+func (UnimplementedOxiaLogReplicationServer) SendIngestFile(OxiaLogReplication_SendIngestFileServer) error {
+	return status.Errorf(codes.Unimplemented, "method SendIngestFile not implemented")
+}
+func (UnimplementedOxiaLogReplicationServer) FetchLog(*FetchLogRequest, OxiaLogReplication_FetchLogServer) error {
+	return status.Errorf(codes.Unimplemented, "method FetchLog not implemented")
+}
 func (UnimplementedOxiaLogReplicationServer) mustEmbedUnimplementedOxiaLogReplicationServer() {}
 
 // UnsafeOxiaLogReplicationServer may be embedded to opt out of forward compatibility for this service.
@@ -516,6 +632,53 @@ func (x *oxiaLogReplicationSendSnapshotServer) Recv() (*SnapshotChunk, error) {
 	return m, nil
 }
 
+func _OxiaLogReplication_SendIngestFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(OxiaLogReplicationServer).SendIngestFile(&oxiaLogReplicationSendIngestFileServer{stream})
+}
+
+type OxiaLogReplication_SendIngestFileServer interface {
+	SendAndClose(*IngestFileResponse) error
+	Recv() (*SnapshotChunk, error)
+	grpc.ServerStream
+}
+
+type oxiaLogReplicationSendIngestFileServer struct {
+	grpc.ServerStream
+}
+
+func (x *oxiaLogReplicationSendIngestFileServer) SendAndClose(m *IngestFileResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *oxiaLogReplicationSendIngestFileServer) Recv() (*SnapshotChunk, error) {
+	m := new(SnapshotChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _OxiaLogReplication_FetchLog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FetchLogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OxiaLogReplicationServer).FetchLog(m, &oxiaLogReplicationFetchLogServer{stream})
+}
+
+type OxiaLogReplication_FetchLogServer interface {
+	Send(*LogEntry) error
+	grpc.ServerStream
+}
+
+type oxiaLogReplicationFetchLogServer struct {
+	grpc.ServerStream
+}
+
+func (x *oxiaLogReplicationFetchLogServer) Send(m *LogEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // OxiaLogReplication_ServiceDesc is the grpc.ServiceDesc for OxiaLogReplication service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -540,6 +703,16 @@ var OxiaLogReplication_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _OxiaLogReplication_SendSnapshot_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "SendIngestFile",
+			Handler:       _OxiaLogReplication_SendIngestFile_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "FetchLog",
+			Handler:       _OxiaLogReplication_FetchLog_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "replication.proto",
 }
@@ -0,0 +1,157 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"io"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+const (
+	AuditOperationPut               = "put"
+	AuditOperationDelete            = "delete"
+	AuditOperationDeleteRange       = "delete-range"
+	AuditOperationCreateSession     = "create-session"
+	AuditOperationCloseSession      = "close-session"
+	AuditOperationBackup            = "backup"
+	AuditOperationExport            = "export"
+	AuditOperationIngest            = "ingest"
+	AuditOperationGetRecords        = "get-records"
+	AuditOperationGetHotKeys        = "get-hot-keys"
+	AuditOperationGetSlowOperations = "get-slow-operations"
+
+	// The actions of the coordinator on the namespaces and the shards
+	AuditOperationCreateShard             = "create-shard"
+	AuditOperationDeleteShard             = "delete-shard"
+	AuditOperationChangeReplicationFactor = "change-replication-factor"
+	AuditOperationChangeObservers         = "change-observers"
+	AuditOperationChangeQuota             = "change-quota"
+	AuditOperationChangeEnsemble          = "change-ensemble"
+	AuditOperationSwapNode                = "swap-node"
+	AuditOperationTransferLeader          = "transfer-leader"
+
+	// AuditPrincipalCoordinator is the principal of the actions that the
+	// coordinator takes on its own, like the rebalancing of the shards
+	AuditPrincipalCoordinator = "coordinator"
+
+	defaultAuditMaxFileSize = 100 * 1024 * 1024
+	defaultAuditMaxFiles    = 10
+)
+
+// AuditEvent is a mutating or an admin operation, as recorded in the
+// audit log.
+type AuditEvent struct {
+	Time      time.Time `json:"time"`
+	Principal string    `json:"principal,omitempty"`
+	Peer      string    `json:"peer,omitempty"`
+	Operation string    `json:"operation"`
+	Namespace string    `json:"namespace,omitempty"`
+	Shard     *int64    `json:"shard,omitempty"`
+
+	// Keys are the keys of the operation. The delete-range operations have
+	// the start and the end of the range.
+	Keys       []string          `json:"keys,omitempty"`
+	Session    *int64            `json:"session,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AuditSink stores the events of the audit log.
+type AuditSink interface {
+	io.Closer
+
+	Write(event *AuditEvent) error
+}
+
+type AuditOptions struct {
+	// File is the audit log file. It's rotated when it grows larger than
+	// MaxFileSize, keeping at most MaxFiles rotated files.
+	File        string
+	MaxFileSize int64
+	MaxFiles    int
+
+	// Sink replaces the file with a custom destination of the events
+	Sink AuditSink `json:"-"`
+}
+
+func (op *AuditOptions) IsEnabled() bool {
+	return op != nil && (op.File != "" || op.Sink != nil)
+}
+
+// AuditLog records the events in the audit sink. A nil audit log discards
+// all the events.
+type AuditLog struct {
+	sink AuditSink
+	log  *slog.Logger
+}
+
+func NewAuditLog(options AuditOptions) (*AuditLog, error) {
+	if !options.IsEnabled() {
+		return nil, nil
+	}
+
+	sink := options.Sink
+	if sink == nil {
+		var err error
+		if sink, err = newAuditFileSink(options.File, options.MaxFileSize, options.MaxFiles); err != nil {
+			return nil, err
+		}
+	}
+
+	return &AuditLog{
+		sink: sink,
+		log: slog.With(
+			slog.String("component", "audit-log"),
+		),
+	}, nil
+}
+
+// Log records the event, with the status of the error. The failures of the
+// sink are logged and don't fail the operation.
+func (l *AuditLog) Log(event *AuditEvent, err error) {
+	if l == nil {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Status == "" || err != nil {
+		event.Status = status.Code(err).String()
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	if err := l.sink.Write(event); err != nil {
+		l.log.Warn(
+			"Failed to write the audit event",
+			slog.Any("event", event),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func (l *AuditLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.sink.Close()
+}
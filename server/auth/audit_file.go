@@ -0,0 +1,140 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// auditFileSink writes the events as JSON lines. When the file grows larger
+// than the max size, it's renamed with the ".1" suffix, after shifting the
+// suffix of the older files.
+type auditFileSink struct {
+	sync.Mutex
+
+	path        string
+	maxFileSize int64
+	maxFiles    int
+
+	file *os.File
+	size int64
+}
+
+func newAuditFileSink(path string, maxFileSize int64, maxFiles int) (*auditFileSink, error) {
+	if maxFileSize <= 0 {
+		maxFileSize = defaultAuditMaxFileSize
+	}
+	if maxFiles <= 0 {
+		maxFiles = defaultAuditMaxFiles
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create the audit log directory")
+	}
+
+	s := &auditFileSink{
+		path:        path,
+		maxFileSize: maxFileSize,
+		maxFiles:    maxFiles,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *auditFileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to open the audit log")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return errors.Wrap(err, "failed to open the audit log")
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+func (s *auditFileSink) Write(event *AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.file == nil {
+		return os.ErrClosed
+	}
+
+	if s.size > 0 && s.size+int64(len(line)) > s.maxFileSize {
+		if err = s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+func (s *auditFileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+
+	if err := os.Remove(s.rotatedPath(s.maxFiles)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := s.maxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(s.rotatedPath(i), s.rotatedPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(s.path, s.rotatedPath(1)); err != nil {
+		return err
+	}
+
+	return s.open()
+}
+
+func (s *auditFileSink) rotatedPath(index int) string {
+	return fmt.Sprintf("%s.%d", s.path, index)
+}
+
+func (s *auditFileSink) Close() error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
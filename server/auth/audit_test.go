@@ -0,0 +1,293 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/proto"
+)
+
+type memoryAuditSink struct {
+	sync.Mutex
+	events []*AuditEvent
+}
+
+func (s *memoryAuditSink) Write(event *AuditEvent) error {
+	s.Lock()
+	defer s.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (*memoryAuditSink) Close() error {
+	return nil
+}
+
+func readAuditFile(t *testing.T, path string) []*AuditEvent {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var events []*AuditEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		event := &AuditEvent{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), event))
+		events = append(events, event)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestAuditLog_Disabled(t *testing.T) {
+	auditLog, err := NewAuditLog(AuditOptions{})
+	assert.NoError(t, err)
+	assert.Nil(t, auditLog)
+
+	// A nil audit log discards the events
+	auditLog.Log(&AuditEvent{Operation: AuditOperationPut}, nil)
+	assert.NoError(t, auditLog.Close())
+}
+
+func TestAuditLog_FileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	auditLog, err := NewAuditLog(AuditOptions{File: path, MaxFileSize: 300, MaxFiles: 2})
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		auditLog.Log(&AuditEvent{
+			Principal: "alice",
+			Operation: AuditOperationDeleteRange,
+			Namespace: "default",
+			Keys:      []string{"/a", "/b"},
+		}, nil)
+	}
+	auditLog.Log(&AuditEvent{Operation: AuditOperationPut}, status.Error(codes.PermissionDenied, "denied"))
+	require.NoError(t, auditLog.Close())
+
+	assert.FileExists(t, path+".1")
+	assert.FileExists(t, path+".2")
+	assert.NoFileExists(t, path+".3")
+
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(p)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(300))
+	}
+
+	events := readAuditFile(t, path)
+	last := events[len(events)-1]
+	assert.Equal(t, AuditOperationPut, last.Operation)
+	assert.Equal(t, codes.PermissionDenied.String(), last.Status)
+	assert.Equal(t, "rpc error: code = PermissionDenied desc = denied", last.Error)
+
+	first := readAuditFile(t, path+".1")[0]
+	assert.Equal(t, "alice", first.Principal)
+	assert.Equal(t, AuditOperationDeleteRange, first.Operation)
+	assert.Equal(t, []string{"/a", "/b"}, first.Keys)
+	assert.Equal(t, codes.OK.String(), first.Status)
+	assert.False(t, first.Time.IsZero())
+}
+
+func TestAuditLog_UnaryWrite(t *testing.T) {
+	sink := &memoryAuditSink{}
+	auditLog, err := NewAuditLog(AuditOptions{Sink: sink})
+	require.NoError(t, err)
+
+	ctx := WithUserName(metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(common.MetadataNamespace, "ns-1")), "alice")
+	shard := int64(3)
+	write := &proto.WriteRequest{
+		Shard:        &shard,
+		Puts:         []*proto.PutRequest{{Key: "/a"}},
+		Deletes:      []*proto.DeleteRequest{{Key: "/b"}},
+		DeleteRanges: []*proto.DeleteRangeRequest{{StartInclusive: "/c", EndExclusive: "/d"}},
+	}
+
+	interceptor := auditLog.GetAuditUnaryInterceptor()
+	_, err = interceptor(ctx, write, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return &proto.WriteResponse{
+			Puts:         []*proto.PutResponse{{Status: proto.Status_UNEXPECTED_VERSION_ID}},
+			Deletes:      []*proto.DeleteResponse{{Status: proto.Status_OK}},
+			DeleteRanges: []*proto.DeleteRangeResponse{{Status: proto.Status_OK}},
+		}, nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sink.events, 3)
+	for _, event := range sink.events {
+		assert.Equal(t, "alice", event.Principal)
+		assert.Equal(t, "ns-1", event.Namespace)
+		assert.Equal(t, shard, *event.Shard)
+	}
+	assert.Equal(t, AuditOperationPut, sink.events[0].Operation)
+	assert.Equal(t, proto.Status_UNEXPECTED_VERSION_ID.String(), sink.events[0].Status)
+	assert.Equal(t, AuditOperationDelete, sink.events[1].Operation)
+	assert.Equal(t, []string{"/b"}, sink.events[1].Keys)
+	assert.Equal(t, AuditOperationDeleteRange, sink.events[2].Operation)
+	assert.Equal(t, []string{"/c", "/d"}, sink.events[2].Keys)
+	assert.Equal(t, proto.Status_OK.String(), sink.events[2].Status)
+
+	// The read operations are not recorded
+	_, err = interceptor(ctx, &proto.ReadRequest{}, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, sink.events, 3)
+}
+
+type mockServerStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	requests []any
+}
+
+func (s *mockServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *mockServerStream) RecvMsg(m any) error {
+	if len(s.requests) == 0 {
+		return status.Error(codes.Canceled, "closed")
+	}
+	switch r := m.(type) {
+	case *proto.WriteRequest:
+		r.Shard = s.requests[0].(*proto.WriteRequest).Shard
+		r.Puts = s.requests[0].(*proto.WriteRequest).Puts
+	case *proto.IngestChunk:
+		r.Namespace = s.requests[0].(*proto.IngestChunk).Namespace
+		r.Shard = s.requests[0].(*proto.IngestChunk).Shard
+	}
+	s.requests = s.requests[1:]
+	return nil
+}
+
+func (*mockServerStream) SendMsg(any) error {
+	return nil
+}
+
+func TestAuditLog_WriteStream(t *testing.T) {
+	sink := &memoryAuditSink{}
+	auditLog, err := NewAuditLog(AuditOptions{Sink: sink})
+	require.NoError(t, err)
+
+	shard := int64(1)
+	ss := &mockServerStream{
+		ctx: WithUserName(context.Background(), "bob"),
+		requests: []any{
+			&proto.WriteRequest{Shard: &shard, Puts: []*proto.PutRequest{{Key: "/a"}}},
+			&proto.WriteRequest{Shard: &shard, Puts: []*proto.PutRequest{{Key: "/b"}}},
+		},
+	}
+
+	interceptor := auditLog.GetAuditStreamInterceptor()
+	err = interceptor(nil, ss, &grpc.StreamServerInfo{}, func(_ any, stream grpc.ServerStream) error {
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, stream.RecvMsg(&proto.WriteRequest{}))
+		}
+		// Only the first write gets its response before the stream is closed
+		return stream.SendMsg(&proto.WriteResponse{Puts: []*proto.PutResponse{{Status: proto.Status_OK}}})
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sink.events, 2)
+	assert.Equal(t, []string{"/a"}, sink.events[0].Keys)
+	assert.Equal(t, "bob", sink.events[0].Principal)
+	assert.Equal(t, proto.Status_OK.String(), sink.events[0].Status)
+	assert.Equal(t, []string{"/b"}, sink.events[1].Keys)
+	assert.Equal(t, codes.Canceled.String(), sink.events[1].Status)
+}
+
+func TestAuditLog_RejectedStreamWrite(t *testing.T) {
+	sink := &memoryAuditSink{}
+	auditLog, err := NewAuditLog(AuditOptions{Sink: sink})
+	require.NoError(t, err)
+
+	shard := int64(1)
+	ss := &mockServerStream{
+		ctx: WithUserName(context.Background(), "bob"),
+		requests: []any{
+			&proto.WriteRequest{Shard: &shard, Puts: []*proto.PutRequest{{Key: "/a"}, {Key: "/b"}}},
+			&proto.WriteRequest{Shard: &shard, Puts: []*proto.PutRequest{{Key: "/c"}}},
+		},
+	}
+
+	interceptor := auditLog.GetAuditStreamInterceptor()
+	err = interceptor(nil, ss, &grpc.StreamServerInfo{}, func(_ any, stream grpc.ServerStream) error {
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, stream.RecvMsg(&proto.WriteRequest{}))
+		}
+		// The first write is rejected, without closing the stream
+		assert.NoError(t, stream.SendMsg(common.NewWriteRejection(common.NewRateLimitedError("write", time.Second))))
+		return stream.SendMsg(&proto.WriteResponse{Puts: []*proto.PutResponse{{Status: proto.Status_OK}}})
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sink.events, 3)
+	for _, event := range sink.events[:2] {
+		assert.Equal(t, codes.ResourceExhausted.String(), event.Status)
+		assert.Contains(t, event.Error, "write rate limit exceeded")
+	}
+	assert.Equal(t, []string{"/b"}, sink.events[1].Keys)
+	assert.Equal(t, []string{"/c"}, sink.events[2].Keys)
+	assert.Equal(t, proto.Status_OK.String(), sink.events[2].Status)
+	assert.Empty(t, sink.events[2].Error)
+}
+
+func TestAuditLog_IngestStream(t *testing.T) {
+	sink := &memoryAuditSink{}
+	auditLog, err := NewAuditLog(AuditOptions{Sink: sink})
+	require.NoError(t, err)
+
+	ss := &mockServerStream{
+		ctx: WithUserName(context.Background(), "admin"),
+		requests: []any{
+			&proto.IngestChunk{Namespace: "ns-1", Shard: 2},
+			&proto.IngestChunk{},
+		},
+	}
+
+	interceptor := auditLog.GetAuditStreamInterceptor()
+	err = interceptor(nil, ss, &grpc.StreamServerInfo{}, func(_ any, stream grpc.ServerStream) error {
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, stream.RecvMsg(&proto.IngestChunk{}))
+		}
+		return status.Error(codes.Internal, "failed")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, AuditOperationIngest, sink.events[0].Operation)
+	assert.Equal(t, "ns-1", sink.events[0].Namespace)
+	assert.Equal(t, int64(2), *sink.events[0].Shard)
+	assert.Equal(t, codes.Internal.String(), sink.events[0].Status)
+}
@@ -21,9 +21,13 @@ import (
 )
 
 const (
-	ProviderOIDC = "oidc"
+	ProviderOIDC   = "oidc"
+	ProviderMTLS   = "mtls"
+	ProviderJWT    = "jwt"
+	ProviderStatic = "static"
 
-	ProviderParamTypeToken = "token"
+	ProviderParamTypeToken       = "token"
+	ProviderParamTypeCertificate = "certificate"
 )
 
 var (
@@ -31,6 +35,7 @@ var (
 	ErrUnMatchedAuthenticationParamType = errors.New("unmatched authentication parameter type")
 	ErrEmptyToken                       = errors.New("empty token")
 	ErrMalformedToken                   = errors.New("malformed token")
+	ErrMissingClientCertificate         = errors.New("missing verified client certificate")
 )
 
 var Disabled = Options{}
@@ -38,6 +43,18 @@ var Disabled = Options{}
 type Options struct {
 	ProviderName   string
 	ProviderParams string
+
+	// PolicyFile is the authorization policy of the public services. All
+	// the authenticated users are allowed everything when it's not set.
+	PolicyFile string
+
+	// RejectIdentityMismatch fails the writes and the sessions whose client
+	// identity isn't the authenticated user, instead of replacing it.
+	RejectIdentityMismatch bool
+
+	// Audit records the mutating and the admin operations of the public
+	// services. It doesn't require the authentication to be enabled.
+	Audit AuditOptions
 }
 
 func (op *Options) IsEnabled() bool {
@@ -54,6 +71,12 @@ func NewAuthenticationProvider(ctx context.Context, options Options) (Authentica
 	switch options.ProviderName {
 	case ProviderOIDC:
 		return NewOIDCProvider(ctx, options.ProviderParams)
+	case ProviderMTLS:
+		return NewMTLSProvider(options.ProviderParams)
+	case ProviderJWT:
+		return NewJWTProvider(options.ProviderParams)
+	case ProviderStatic:
+		return NewStaticProvider(options.ProviderParams)
 	default:
 		return nil, ErrUnsupportedProvider
 	}
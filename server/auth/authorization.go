@@ -0,0 +1,281 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
+)
+
+type Permission string
+
+const (
+	PermissionRead  Permission = "read"
+	PermissionWrite Permission = "write"
+
+	// PermissionAdmin grants the admin operations, like backup and export,
+	// and implies the read and write permissions
+	PermissionAdmin Permission = "admin"
+
+	// Wildcard matches any namespace in the grants and any user in the bindings
+	Wildcard = "*"
+)
+
+var (
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrUnknownRole       = errors.New("unknown role")
+	ErrEmptyPolicy       = errors.New("empty authorization policy")
+)
+
+// Grant gives a set of permissions on the keys of a namespace that start with
+// the key prefix. An empty key prefix covers the whole namespace.
+//
+// The range operations, like list or delete-range, are only covered when the
+// key prefix is empty or ends with '/', since the hierarchical sorting of the
+// keys doesn't keep the other prefixes contiguous.
+type Grant struct {
+	Namespace   string       `yaml:"namespace"`
+	KeyPrefix   string       `yaml:"keyPrefix,omitempty"`
+	Permissions []Permission `yaml:"permissions"`
+}
+
+type Role struct {
+	Name   string  `yaml:"name"`
+	Grants []Grant `yaml:"grants"`
+}
+
+type Binding struct {
+	Role  string   `yaml:"role"`
+	Users []string `yaml:"users"`
+}
+
+// Policy assigns roles to the authenticated users. A user without any role
+// is denied all the operations.
+type Policy struct {
+	Roles    []Role    `yaml:"roles"`
+	Bindings []Binding `yaml:"bindings"`
+}
+
+func (p *Policy) Validate() error {
+	roles := map[string]bool{}
+	for _, role := range p.Roles {
+		for _, grant := range role.Grants {
+			for _, permission := range grant.Permissions {
+				switch permission {
+				case PermissionRead, PermissionWrite, PermissionAdmin:
+				default:
+					return errors.Wrapf(ErrUnknownPermission, "role %q: %q", role.Name, permission)
+				}
+			}
+		}
+		roles[role.Name] = true
+	}
+
+	for _, binding := range p.Bindings {
+		if !roles[binding.Role] {
+			return errors.Wrapf(ErrUnknownRole, "%q", binding.Role)
+		}
+	}
+	return nil
+}
+
+func (p *Policy) grantsOf(user string) []Grant {
+	var grants []Grant
+	for _, binding := range p.Bindings {
+		if !slices.Contains(binding.Users, user) && !slices.Contains(binding.Users, Wildcard) {
+			continue
+		}
+		for _, role := range p.Roles {
+			if role.Name == binding.Role {
+				grants = append(grants, role.Grants...)
+			}
+		}
+	}
+	return grants
+}
+
+func (g *Grant) allows(namespace string, permission Permission) bool {
+	if g.Namespace != namespace && g.Namespace != Wildcard {
+		return false
+	}
+	return slices.Contains(g.Permissions, permission) || slices.Contains(g.Permissions, PermissionAdmin)
+}
+
+func (g *Grant) coversRange(start, end string) bool {
+	if g.KeyPrefix == "" {
+		return true
+	}
+	return strings.HasSuffix(g.KeyPrefix, "/") &&
+		strings.HasPrefix(start, g.KeyPrefix) && strings.HasPrefix(end, g.KeyPrefix)
+}
+
+func ReadPolicy(r io.Reader) (*Policy, error) {
+	// An empty policy is rejected, since that's what the reload sees while
+	// the file is being rewritten
+	policy := &Policy{}
+	if err := yaml.NewDecoder(r).Decode(policy); errors.Is(err, io.EOF) {
+		return nil, ErrEmptyPolicy
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the authorization policy")
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid authorization policy")
+	}
+	return policy, nil
+}
+
+func readPolicyFile(path string) (*Policy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadPolicy(f)
+}
+
+// Authorizer checks the operations of the authenticated users against the
+// policy. When it's created from a file, the policy is reloaded every time
+// the file changes.
+type Authorizer struct {
+	policy  atomic.Pointer[Policy]
+	watcher *fsnotify.Watcher
+	log     *slog.Logger
+}
+
+func NewAuthorizer(policy *Policy) *Authorizer {
+	a := &Authorizer{
+		log: slog.With(slog.String("component", "authorizer")),
+	}
+	a.policy.Store(policy)
+	return a
+}
+
+func NewAuthorizerFromFile(path string) (*Authorizer, error) {
+	policy, err := readPolicyFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	a := NewAuthorizer(policy)
+	a.log = a.log.With(slog.String("policy-file", path))
+	if a.watcher, err = fsnotify.NewWatcher(); err != nil {
+		return nil, err
+	}
+
+	// Watch the directory, since the file can be replaced rather than
+	// written in place, as it happens with the mounted config maps
+	if err = a.watcher.Add(filepath.Dir(path)); err != nil {
+		_ = a.watcher.Close()
+		return nil, err
+	}
+	go a.watch(path)
+	return a, nil
+}
+
+func (a *Authorizer) watch(path string) {
+	for {
+		select {
+		case _, ok := <-a.watcher.Events:
+			if !ok {
+				return
+			}
+			policy, err := readPolicyFile(path)
+			if err != nil {
+				a.log.Warn(
+					"Failed to reload the authorization policy, keeping the previous one",
+					slog.Any("error", err),
+				)
+				continue
+			}
+			a.policy.Store(policy)
+			a.log.Info("Reloaded the authorization policy")
+
+		case err, ok := <-a.watcher.Errors:
+			if !ok {
+				return
+			}
+			a.log.Warn("Failed to watch the authorization policy", slog.Any("error", err))
+		}
+	}
+}
+
+// AuthorizeKeys checks that the user has the permission on all the keys.
+func (a *Authorizer) AuthorizeKeys(user, namespace string, permission Permission, keys ...string) error {
+	grants := a.policy.Load().grantsOf(user)
+	for _, key := range keys {
+		if !slices.ContainsFunc(grants, func(g Grant) bool {
+			return g.allows(namespace, permission) && strings.HasPrefix(key, g.KeyPrefix)
+		}) {
+			return status.Errorf(codes.PermissionDenied,
+				"user %q doesn't have the %s permission on key %q of namespace %q", user, permission, key, namespace)
+		}
+	}
+	return nil
+}
+
+// AuthorizeRange checks that the user has the permission on all the keys in
+// the range [start, end).
+func (a *Authorizer) AuthorizeRange(user, namespace string, permission Permission, start, end string) error {
+	if !slices.ContainsFunc(a.policy.Load().grantsOf(user), func(g Grant) bool {
+		return g.allows(namespace, permission) && g.coversRange(start, end)
+	}) {
+		return status.Errorf(codes.PermissionDenied,
+			"user %q doesn't have the %s permission on range [%q, %q) of namespace %q",
+			user, permission, start, end, namespace)
+	}
+	return nil
+}
+
+// AuthorizeNamespace checks that the user has the permission on the whole
+// namespace.
+func (a *Authorizer) AuthorizeNamespace(user, namespace string, permission Permission) error {
+	if !slices.ContainsFunc(a.policy.Load().grantsOf(user), func(g Grant) bool {
+		return g.allows(namespace, permission) && g.KeyPrefix == ""
+	}) {
+		return status.Errorf(codes.PermissionDenied,
+			"user %q doesn't have the %s permission on namespace %q", user, permission, namespace)
+	}
+	return nil
+}
+
+// AuthorizeAnyKey checks that the user has the permission on at least some
+// keys of the namespace.
+func (a *Authorizer) AuthorizeAnyKey(user, namespace string, permission Permission) error {
+	if !slices.ContainsFunc(a.policy.Load().grantsOf(user), func(g Grant) bool {
+		return g.allows(namespace, permission)
+	}) {
+		return status.Errorf(codes.PermissionDenied,
+			"user %q doesn't have the %s permission in namespace %q", user, permission, namespace)
+	}
+	return nil
+}
+
+func (a *Authorizer) Close() error {
+	if a.watcher == nil {
+		return nil
+	}
+	return a.watcher.Close()
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testPolicy = `
+roles:
+  - name: team-a
+    grants:
+      - namespace: ns-a
+        keyPrefix: /team-a/
+        permissions: [read, write]
+      - namespace: ns-a
+        keyPrefix: /shared
+        permissions: [read]
+  - name: ops
+    grants:
+      - namespace: "*"
+        permissions: [admin]
+bindings:
+  - role: team-a
+    users: [alice]
+  - role: ops
+    users: [carol]
+`
+
+func TestAuthorizer(t *testing.T) {
+	policy, err := ReadPolicy(strings.NewReader(testPolicy))
+	require.NoError(t, err)
+	a := NewAuthorizer(policy)
+
+	assert.NoError(t, a.AuthorizeKeys("alice", "ns-a", PermissionWrite, "/team-a/x", "/team-a/y/z"))
+	assert.NoError(t, a.AuthorizeKeys("alice", "ns-a", PermissionRead, "/shared-config"))
+	assertDenied(t, a.AuthorizeKeys("alice", "ns-a", PermissionWrite, "/team-a/x", "/shared-config"))
+	assertDenied(t, a.AuthorizeKeys("alice", "ns-b", PermissionRead, "/team-a/x"))
+	assertDenied(t, a.AuthorizeKeys("bob", "ns-a", PermissionRead, "/team-a/x"))
+
+	assert.NoError(t, a.AuthorizeRange("alice", "ns-a", PermissionRead, "/team-a/", "/team-a//"))
+	assertDenied(t, a.AuthorizeRange("alice", "ns-a", PermissionRead, "/team-a/", "/team-b/"))
+	// The prefixes without a trailing '/' only cover the single keys
+	assertDenied(t, a.AuthorizeRange("alice", "ns-a", PermissionRead, "/shared-a", "/shared-z"))
+
+	assertDenied(t, a.AuthorizeNamespace("alice", "ns-a", PermissionRead))
+	assert.NoError(t, a.AuthorizeAnyKey("alice", "ns-a", PermissionWrite))
+	assertDenied(t, a.AuthorizeAnyKey("alice", "ns-b", PermissionWrite))
+
+	// The admin permission implies the others, on all the namespaces
+	assert.NoError(t, a.AuthorizeNamespace("carol", "ns-b", PermissionAdmin))
+	assert.NoError(t, a.AuthorizeRange("carol", "ns-a", PermissionWrite, "", "~"))
+	assert.NoError(t, a.AuthorizeKeys("carol", "default", PermissionRead, "/team-a/x"))
+}
+
+func TestAuthorizer_InvalidPolicy(t *testing.T) {
+	_, err := ReadPolicy(strings.NewReader(`
+roles:
+  - name: r
+    grants:
+      - namespace: ns
+        permissions: [delete]
+`))
+	assert.ErrorIs(t, err, ErrUnknownPermission)
+
+	_, err = ReadPolicy(strings.NewReader(`
+bindings:
+  - role: missing
+    users: [alice]
+`))
+	assert.ErrorIs(t, err, ErrUnknownRole)
+
+	_, err = ReadPolicy(strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrEmptyPolicy)
+
+	// A policy without roles denies everything
+	policy, err := ReadPolicy(strings.NewReader("roles: []"))
+	assert.NoError(t, err)
+	assertDenied(t, NewAuthorizer(policy).AuthorizeAnyKey("alice", "ns", PermissionRead))
+}
+
+func TestAuthorizer_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0600))
+
+	a, err := NewAuthorizerFromFile(path)
+	require.NoError(t, err)
+	defer a.Close()
+
+	assertDenied(t, a.AuthorizeKeys("bob", "ns-a", PermissionRead, "/team-a/x"))
+
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy+`
+  - role: team-a
+    users: [bob]
+`), 0600))
+	assert.Eventually(t, func() bool {
+		return a.AuthorizeKeys("bob", "ns-a", PermissionRead, "/team-a/x") == nil
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// An invalid policy is ignored
+	require.NoError(t, os.WriteFile(path, []byte("roles: ["), 0600))
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, a.AuthorizeKeys("bob", "ns-a", PermissionRead, "/team-a/x"))
+}
+
+func assertDenied(t *testing.T, err error) {
+	t.Helper()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
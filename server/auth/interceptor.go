@@ -19,12 +19,18 @@ import (
 	"errors"
 	"log/slog"
 	"strings"
+	"sync"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/proto"
 )
 
 const (
@@ -44,26 +50,50 @@ type GrpcAuthenticationDelegator struct {
 
 func (delegator *GrpcAuthenticationDelegator) GetUnaryInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		_, err := delegator.validate(ctx, delegator.provider)
+		userName, err := delegator.validate(ctx, delegator.provider)
 		if err != nil {
 			return nil, status.Error(codes.Unauthenticated, err.Error())
 		}
-		// todo: set username to metadata to support authorization
-		return handler(ctx, req)
+		return handler(WithUserName(ctx, userName), req)
 	}
 }
 
 func (delegator *GrpcAuthenticationDelegator) GetStreamInterceptor() grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		_, err := delegator.validate(ss.Context(), delegator.provider)
+		userName, err := delegator.validate(ss.Context(), delegator.provider)
 		if err != nil {
 			return status.Error(codes.Unauthenticated, err.Error())
 		}
-		// todo: set username to metadata to support authorization
-		return handler(srv, ss)
+		return handler(srv, &authenticatedServerStream{
+			ServerStream: ss,
+			ctx:          WithUserName(ss.Context(), userName),
+		})
 	}
 }
 
+type userNameKey struct{}
+
+// WithUserName returns a context carrying the name of the authenticated user.
+func WithUserName(ctx context.Context, userName string) context.Context {
+	return context.WithValue(ctx, userNameKey{}, userName)
+}
+
+// UserNameFromContext returns the name of the user authenticated by the
+// interceptors, if the authentication is enabled.
+func UserNameFromContext(ctx context.Context) (string, bool) {
+	userName, ok := ctx.Value(userNameKey{}).(string)
+	return userName, ok
+}
+
+type authenticatedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedServerStream) Context() context.Context {
+	return s.ctx
+}
+
 func NewGrpcAuthenticationDelegator(provider AuthenticationProvider) (*GrpcAuthenticationDelegator, error) {
 	delegator := &GrpcAuthenticationDelegator{
 		provider: provider,
@@ -71,6 +101,8 @@ func NewGrpcAuthenticationDelegator(provider AuthenticationProvider) (*GrpcAuthe
 	switch provider.AcceptParamType() {
 	case ProviderParamTypeToken:
 		delegator.validate = validateTokenWithContext
+	case ProviderParamTypeCertificate:
+		delegator.validate = validateCertificateWithContext
 	default:
 		return nil, ErrUnMatchedAuthenticationParamType
 	}
@@ -103,3 +135,220 @@ func validateTokenWithContext(ctx context.Context, provider AuthenticationProvid
 	}
 	return userName, nil
 }
+
+func validateCertificateWithContext(ctx context.Context, provider AuthenticationProvider) (string, error) {
+	peerMeta, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", ErrMetadataFetchFailed
+	}
+	tlsInfo, ok := peerMeta.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		slog.Debug("Receive no verified certificate from the client",
+			slog.String("peer", peerMeta.Addr.String()))
+		return "", ErrMissingClientCertificate
+	}
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	userName, err := provider.Authenticate(ctx, cert)
+	if err != nil {
+		slog.Debug("Failed to authenticate certificate",
+			slog.String("peer", peerMeta.Addr.String()),
+			slog.String("subject", cert.Subject.String()))
+		return "", err
+	}
+	return userName, nil
+}
+
+// GetAuditUnaryInterceptor records the mutating and the admin operations in the
+// audit log. It's chained after the authentication, to know the principal.
+func (l *AuditLog) GetAuditUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		res, err := handler(ctx, req)
+
+		switch r := req.(type) {
+		case *proto.WriteRequest:
+			events := newWriteAuditEvents(ctx, r)
+			wr, _ := res.(*proto.WriteResponse)
+			l.logWrite(events, wr, err)
+		case *proto.CreateSessionRequest:
+			event := newAuditEvent(ctx, AuditOperationCreateSession, metadataNamespace(ctx), r.Shard)
+			if csr, ok := res.(*proto.CreateSessionResponse); ok && err == nil {
+				event.Session = &csr.SessionId
+			}
+			l.Log(event, err)
+		case *proto.CloseSessionRequest:
+			event := newAuditEvent(ctx, AuditOperationCloseSession, metadataNamespace(ctx), r.Shard)
+			event.Session = &r.SessionId
+			l.Log(event, err)
+		case *proto.GetRecordsRequest:
+			event := newAuditEvent(ctx, AuditOperationGetRecords, r.Namespace, r.Shard)
+			event.Keys = r.Keys
+			l.Log(event, err)
+		case *proto.GetHotKeysRequest:
+			l.Log(newAuditEvent(ctx, AuditOperationGetHotKeys, r.Namespace, r.Shard), err)
+		case *proto.GetSlowOperationsRequest:
+			l.Log(newAuditEvent(ctx, AuditOperationGetSlowOperations, r.Namespace, r.Shard), err)
+		}
+		return res, err
+	}
+}
+
+func (l *AuditLog) GetAuditStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		stream := &auditedServerStream{
+			ServerStream: ss,
+			audit:        l,
+		}
+		err := handler(srv, stream)
+		stream.close(err)
+		return err
+	}
+}
+
+// auditedServerStream records the writes received from a write stream when
+// their response is sent, in the same order, and the admin operations when the
+// stream is closed.
+type auditedServerStream struct {
+	grpc.ServerStream
+	audit *AuditLog
+
+	sync.Mutex
+	pendingWrites [][]*AuditEvent
+	event         *AuditEvent
+}
+
+func (s *auditedServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	switch r := m.(type) {
+	case *proto.WriteRequest:
+		s.pendingWrites = append(s.pendingWrites, newWriteAuditEvents(s.Context(), r))
+	case *proto.BackupRequest:
+		s.event = newAuditEvent(s.Context(), AuditOperationBackup, r.Namespace, r.Shard)
+	case *proto.ExportRequest:
+		s.event = newAuditEvent(s.Context(), AuditOperationExport, r.Namespace, r.Shard)
+	case *proto.IngestChunk:
+		// Only the first chunk has the namespace and the shard
+		if s.event == nil {
+			s.event = newAuditEvent(s.Context(), AuditOperationIngest, r.Namespace, r.Shard)
+		}
+	}
+	return nil
+}
+
+func (s *auditedServerStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+
+	if wr, ok := m.(*proto.WriteResponse); ok {
+		s.Lock()
+		if len(s.pendingWrites) > 0 {
+			events := s.pendingWrites[0]
+			s.pendingWrites = s.pendingWrites[1:]
+			s.audit.logWrite(events, wr, err)
+		}
+		s.Unlock()
+	}
+	return err
+}
+
+func (s *auditedServerStream) close(err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	pendingErr := err
+	if pendingErr == nil {
+		pendingErr = status.Error(codes.Canceled, "write stream closed before the response")
+	}
+	for _, events := range s.pendingWrites {
+		s.audit.logWrite(events, nil, pendingErr)
+	}
+	s.pendingWrites = nil
+
+	if s.event != nil {
+		s.audit.Log(s.event, err)
+	}
+}
+
+func newAuditEvent(ctx context.Context, operation string, namespace string, shard int64) *AuditEvent {
+	principal, _ := UserNameFromContext(ctx)
+	var peerAddress string
+	if p, ok := peer.FromContext(ctx); ok {
+		peerAddress = p.Addr.String()
+	}
+
+	return &AuditEvent{
+		Time:      time.Now(),
+		Principal: principal,
+		Peer:      peerAddress,
+		Operation: operation,
+		Namespace: namespace,
+		Shard:     &shard,
+	}
+}
+
+// newWriteAuditEvents returns an event for each of the operations of the
+// write request, in the order of the results of the write response.
+func newWriteAuditEvents(ctx context.Context, write *proto.WriteRequest) []*AuditEvent {
+	namespace := metadataNamespace(ctx)
+	events := make([]*AuditEvent, 0, len(write.Puts)+len(write.Deletes)+len(write.DeleteRanges))
+	for _, put := range write.Puts {
+		event := newAuditEvent(ctx, AuditOperationPut, namespace, write.GetShard())
+		event.Keys = []string{put.Key}
+		events = append(events, event)
+	}
+	for _, del := range write.Deletes {
+		event := newAuditEvent(ctx, AuditOperationDelete, namespace, write.GetShard())
+		event.Keys = []string{del.Key}
+		events = append(events, event)
+	}
+	for _, dr := range write.DeleteRanges {
+		event := newAuditEvent(ctx, AuditOperationDeleteRange, namespace, write.GetShard())
+		event.Keys = []string{dr.StartInclusive, dr.EndExclusive}
+		events = append(events, event)
+	}
+	return events
+}
+
+// logWrite records the events of a write with the status of each operation in
+// the response. The operations of a write rejected on its own, without closing
+// the stream, are recorded with the error of the rejection.
+func (l *AuditLog) logWrite(events []*AuditEvent, response *proto.WriteResponse, err error) {
+	if err == nil && response.GetRejection() != nil {
+		err = common.WriteRejectionError(response.Rejection)
+	}
+
+	var results []proto.Status
+	if response != nil {
+		for _, put := range response.Puts {
+			results = append(results, put.Status)
+		}
+		for _, del := range response.Deletes {
+			results = append(results, del.Status)
+		}
+		for _, dr := range response.DeleteRanges {
+			results = append(results, dr.Status)
+		}
+	}
+
+	for i, event := range events {
+		if err == nil && i < len(results) {
+			event.Status = results[i].String()
+		}
+		l.Log(event, err)
+	}
+}
+
+func metadataNamespace(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if val := md.Get(common.MetadataNamespace); len(val) > 0 {
+		return val[0]
+	}
+	return ""
+}
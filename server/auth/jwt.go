@@ -0,0 +1,221 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"strings"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrEmptyPublicKeys = errors.New("empty public keys and jwks files")
+	ErrUnknownKeyId    = errors.New("unknown key id")
+)
+
+var jwtValidMethods = []string{
+	"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA",
+}
+
+type JWTOptions struct {
+	// PublicKeyFiles are the PEM files of the public keys that can sign the
+	// tokens, separated by commas
+	PublicKeyFiles string `json:"publicKeyFiles,omitempty"`
+	// JWKSFiles are the files of the JSON web key sets that can sign the
+	// tokens, separated by commas
+	JWKSFiles        string `json:"jwksFiles,omitempty"`
+	AllowedIssuers   string `json:"allowedIssuers,omitempty"`
+	AllowedAudiences string `json:"allowedAudiences,omitempty"`
+	UserNameClaim    string `json:"userNameClaim,omitempty"`
+}
+
+func (op *JWTOptions) Validate() error {
+	if op.PublicKeyFiles == "" && op.JWKSFiles == "" {
+		return ErrEmptyPublicKeys
+	}
+	if op.AllowedAudiences == "" {
+		return ErrEmptyAllowedAudiences
+	}
+	return nil
+}
+
+func (op *JWTOptions) withDefault() {
+	if op.UserNameClaim == "" {
+		op.UserNameClaim = DefaultUserNameCalm
+	}
+}
+
+// JWTProvider validates the tokens against the keys that are configured
+// locally, so that the storage nodes don't need to reach an issuer.
+type JWTProvider struct {
+	userNameClaim    string
+	allowedIssuers   map[string]bool
+	allowedAudiences map[string]bool
+
+	// The keys without an id are tried in turn for the tokens without the
+	// "kid" header
+	keysById  map[string]crypto.PublicKey
+	keysNoIds []crypto.PublicKey
+}
+
+func (*JWTProvider) AcceptParamType() string {
+	return ProviderParamTypeToken
+}
+
+func (p *JWTProvider) Authenticate(_ context.Context, param any) (string, error) {
+	token, ok := param.(string)
+	if !ok {
+		return "", ErrUnMatchedAuthenticationParamType
+	}
+
+	parser := jwt.NewParser(jwt.WithValidMethods(jwtValidMethods), jwt.WithExpirationRequired())
+	claims := jwt.MapClaims{}
+	if _, err := parser.ParseWithClaims(token, claims, p.verificationKey); err != nil {
+		return "", err
+	}
+
+	if len(p.allowedIssuers) > 0 {
+		issuer, err := claims.GetIssuer()
+		if err != nil {
+			return "", err
+		}
+		if !p.allowedIssuers[issuer] {
+			return "", ErrUnknownIssuer
+		}
+	}
+
+	audiences, err := claims.GetAudience()
+	if err != nil {
+		return "", err
+	}
+	audienceAllowed := false
+	for _, audience := range audiences {
+		if p.allowedAudiences[audience] {
+			audienceAllowed = true
+		}
+	}
+	if !audienceAllowed {
+		return "", ErrForbiddenAudience
+	}
+
+	userName, ok := claims[p.userNameClaim].(string)
+	if !ok || userName == "" {
+		return "", ErrUserNameNotFound
+	}
+	return userName, nil
+}
+
+func (p *JWTProvider) verificationKey(token *jwt.Token) (any, error) {
+	if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+		key, found := p.keysById[kid]
+		if !found {
+			return nil, errors.Wrapf(ErrUnknownKeyId, "%q", kid)
+		}
+		return key, nil
+	}
+
+	keys := jwt.VerificationKeySet{}
+	for _, key := range p.keysNoIds {
+		keys.Keys = append(keys.Keys, key)
+	}
+	return keys, nil
+}
+
+func (p *JWTProvider) addPublicKeyFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse the public key in %s", path)
+		}
+		p.keysNoIds = append(p.keysNoIds, key)
+	}
+	return nil
+}
+
+func (p *JWTProvider) addJWKSFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	jwks := jose.JSONWebKeySet{}
+	if err = json.Unmarshal(data, &jwks); err != nil {
+		return errors.Wrapf(err, "failed to parse the jwks in %s", path)
+	}
+	for _, key := range jwks.Keys {
+		if !key.IsPublic() {
+			key = key.Public()
+		}
+		if key.KeyID == "" {
+			p.keysNoIds = append(p.keysNoIds, key.Key)
+		} else {
+			p.keysById[key.KeyID] = key.Key
+		}
+	}
+	return nil
+}
+
+func NewJWTProvider(jsonParam string) (AuthenticationProvider, error) {
+	jwtParams := &JWTOptions{}
+	if err := json.Unmarshal([]byte(jsonParam), jwtParams); err != nil {
+		return nil, err
+	}
+	jwtParams.withDefault()
+	if err := jwtParams.Validate(); err != nil {
+		return nil, err
+	}
+
+	provider := &JWTProvider{
+		userNameClaim:    jwtParams.UserNameClaim,
+		allowedIssuers:   splitSet(jwtParams.AllowedIssuers),
+		allowedAudiences: splitSet(jwtParams.AllowedAudiences),
+		keysById:         map[string]crypto.PublicKey{},
+	}
+	for path := range splitSet(jwtParams.PublicKeyFiles) {
+		if err := provider.addPublicKeyFile(path); err != nil {
+			return nil, err
+		}
+	}
+	for path := range splitSet(jwtParams.JWKSFiles) {
+		if err := provider.addJWKSFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if len(provider.keysById) == 0 && len(provider.keysNoIds) == 0 {
+		return nil, ErrEmptyPublicKeys
+	}
+	return provider, nil
+}
+
+func splitSet(list string) map[string]bool {
+	set := map[string]bool{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			set[item] = true
+		}
+	}
+	return set
+}
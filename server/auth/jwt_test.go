@@ -0,0 +1,151 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePublicKeyFile(t *testing.T, key any) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+	return path
+}
+
+func writeJWKSFile(t *testing.T, keys ...jose.JSONWebKey) string {
+	t.Helper()
+	data, err := json.Marshal(jose.JSONWebKeySet{Keys: keys})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestJWTProvider(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	params := fmt.Sprintf(`{"publicKeyFiles":"%s","jwksFiles":"%s","allowedIssuers":"issuer-1",`+
+		`"allowedAudiences":"oxia,other","userNameClaim":"email"}`,
+		writePublicKeyFile(t, &rsaKey.PublicKey),
+		writeJWKSFile(t, jose.JSONWebKey{Key: &ecKey.PublicKey, KeyID: "ec-1", Algorithm: "ES256", Use: "sig"}))
+	provider, err := NewJWTProvider(params)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderParamTypeToken, provider.AcceptParamType())
+
+	claims := func(modify func(c jwt.MapClaims)) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"iss":   "issuer-1",
+			"aud":   []string{"oxia"},
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"sub":   "subject",
+			"email": "alice@example.org",
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return c
+	}
+
+	for _, test := range []struct {
+		name     string
+		token    string
+		userName string
+	}{
+		{"rsa-pem", signToken(t, jwt.SigningMethodRS256, rsaKey, "", claims(nil)), "alice@example.org"},
+		{"ec-jwks", signToken(t, jwt.SigningMethodES256, ecKey, "ec-1", claims(nil)), "alice@example.org"},
+		{"unknown-key", signToken(t, jwt.SigningMethodRS256, otherKey, "", claims(nil)), ""},
+		{"unknown-key-id", signToken(t, jwt.SigningMethodES256, ecKey, "ec-2", claims(nil)), ""},
+		{"hmac", signToken(t, jwt.SigningMethodHS256, []byte("secret"), "", claims(nil)), ""},
+		{"expired", signToken(t, jwt.SigningMethodRS256, rsaKey, "", claims(func(c jwt.MapClaims) {
+			c["exp"] = time.Now().Add(-time.Minute).Unix()
+		})), ""},
+		{"no-expiration", signToken(t, jwt.SigningMethodRS256, rsaKey, "", claims(func(c jwt.MapClaims) {
+			delete(c, "exp")
+		})), ""},
+		{"unknown-issuer", signToken(t, jwt.SigningMethodRS256, rsaKey, "", claims(func(c jwt.MapClaims) {
+			c["iss"] = "issuer-2"
+		})), ""},
+		{"forbidden-audience", signToken(t, jwt.SigningMethodRS256, rsaKey, "", claims(func(c jwt.MapClaims) {
+			c["aud"] = []string{"another"}
+		})), ""},
+		{"no-user-name", signToken(t, jwt.SigningMethodRS256, rsaKey, "", claims(func(c jwt.MapClaims) {
+			delete(c, "email")
+		})), ""},
+		{"malformed", "not-a-token", ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			userName, err := provider.Authenticate(context.Background(), test.token)
+			if test.userName == "" {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.userName, userName)
+		})
+	}
+}
+
+func TestJWTProvider_InvalidOptions(t *testing.T) {
+	_, err := NewJWTProvider(`{"allowedAudiences":"oxia"}`)
+	assert.ErrorIs(t, err, ErrEmptyPublicKeys)
+
+	_, err = NewJWTProvider(`{"publicKeyFiles":"/missing.pem"}`)
+	assert.ErrorIs(t, err, ErrEmptyAllowedAudiences)
+
+	_, err = NewJWTProvider(`{"publicKeyFiles":"/missing.pem","allowedAudiences":"oxia"}`)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// A file without any key
+	path := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(path, []byte("\n"), 0600))
+	_, err = NewJWTProvider(fmt.Sprintf(`{"publicKeyFiles":"%s","allowedAudiences":"oxia"}`, path))
+	assert.ErrorIs(t, err, ErrEmptyPublicKeys)
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	UserNameFieldCN    = "cn"
+	UserNameFieldDNS   = "dns"
+	UserNameFieldURI   = "uri"
+	UserNameFieldEmail = "email"
+)
+
+var (
+	ErrUnknownUserNameField = errors.New("unknown user name field")
+)
+
+type MTLSOptions struct {
+	// UserNameField is the field of the client certificate that holds the
+	// user name: the subject common name ("cn", the default) or one of the
+	// "dns", "uri" and "email" subject alternative names.
+	UserNameField string `json:"userNameField,omitempty"`
+
+	// UserNamePattern is a regular expression that the whole field must match,
+	// as if it was enclosed in ^ and $. When it has a capturing group, the user
+	// name is the first group. With the alternative names, the first one that
+	// matches is used.
+	UserNamePattern string `json:"userNamePattern,omitempty"`
+}
+
+func (op *MTLSOptions) Validate() error {
+	switch op.UserNameField {
+	case UserNameFieldCN, UserNameFieldDNS, UserNameFieldURI, UserNameFieldEmail:
+		return nil
+	default:
+		return errors.Wrapf(ErrUnknownUserNameField, "%q", op.UserNameField)
+	}
+}
+
+func (op *MTLSOptions) withDefault() {
+	if op.UserNameField == "" {
+		op.UserNameField = UserNameFieldCN
+	}
+}
+
+// MTLSProvider authenticates the clients from the certificate that they
+// present in the TLS handshake, once it has been verified against the
+// trusted CA.
+type MTLSProvider struct {
+	userNameField   string
+	userNamePattern *regexp.Regexp
+}
+
+func (*MTLSProvider) AcceptParamType() string {
+	return ProviderParamTypeCertificate
+}
+
+func (p *MTLSProvider) Authenticate(_ context.Context, param any) (string, error) {
+	cert, ok := param.(*x509.Certificate)
+	if !ok {
+		return "", ErrUnMatchedAuthenticationParamType
+	}
+
+	var candidates []string
+	switch p.userNameField {
+	case UserNameFieldCN:
+		candidates = []string{cert.Subject.CommonName}
+	case UserNameFieldDNS:
+		candidates = cert.DNSNames
+	case UserNameFieldURI:
+		for _, uri := range cert.URIs {
+			candidates = append(candidates, uri.String())
+		}
+	case UserNameFieldEmail:
+		candidates = cert.EmailAddresses
+	}
+
+	for _, candidate := range candidates {
+		if userName := p.userNameOf(candidate); userName != "" {
+			return userName, nil
+		}
+	}
+	return "", ErrUserNameNotFound
+}
+
+func (p *MTLSProvider) userNameOf(value string) string {
+	if p.userNamePattern == nil {
+		return value
+	}
+
+	match := p.userNamePattern.FindStringSubmatch(value)
+	switch {
+	case match == nil:
+		return ""
+	case len(match) > 1:
+		return match[1]
+	default:
+		return match[0]
+	}
+}
+
+func NewMTLSProvider(jsonParam string) (AuthenticationProvider, error) {
+	mtlsParams := &MTLSOptions{}
+	if jsonParam != "" {
+		if err := json.Unmarshal([]byte(jsonParam), mtlsParams); err != nil {
+			return nil, err
+		}
+	}
+	mtlsParams.withDefault()
+	if err := mtlsParams.Validate(); err != nil {
+		return nil, err
+	}
+
+	provider := &MTLSProvider{
+		userNameField: mtlsParams.UserNameField,
+	}
+	if mtlsParams.UserNamePattern != "" {
+		var err error
+		// The pattern is anchored, so that a certificate can't be accepted
+		// because it merely contains the expected name
+		pattern := "^(?:" + mtlsParams.UserNamePattern + ")$"
+		if provider.userNamePattern, err = regexp.Compile(pattern); err != nil {
+			return nil, errors.Wrap(err, "invalid user name pattern")
+		}
+	}
+	return provider, nil
+}
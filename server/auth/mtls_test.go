@@ -0,0 +1,78 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMTLSProvider(t *testing.T) {
+	spiffeId, err := url.Parse("spiffe://example.org/ns/prod/sa/billing")
+	require.NoError(t, err)
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "billing-service"},
+		DNSNames:       []string{"localhost", "billing.prod.svc"},
+		URIs:           []*url.URL{spiffeId},
+		EmailAddresses: []string{"billing@example.org"},
+	}
+
+	for _, test := range []struct {
+		name     string
+		params   string
+		userName string
+		err      error
+	}{
+		{"default", "", "billing-service", nil},
+		{"cn", `{"userNameField":"cn"}`, "billing-service", nil},
+		{"dns", `{"userNameField":"dns"}`, "localhost", nil},
+		{"dns-pattern", `{"userNameField":"dns","userNamePattern":"^(.+)\\.prod\\.svc$"}`, "billing", nil},
+		{"uri-pattern", `{"userNameField":"uri","userNamePattern":"^spiffe://example.org/ns/[^/]+/sa/(.+)$"}`, "billing", nil},
+		{"email-no-group", `{"userNameField":"email","userNamePattern":".+@example\\.org"}`, "billing@example.org", nil},
+		{"no-match", `{"userNamePattern":"^admin$"}`, "", ErrUserNameNotFound},
+		{"cn-substring", `{"userNamePattern":"billing"}`, "", ErrUserNameNotFound},
+		{"dns-substring", `{"userNameField":"dns","userNamePattern":"(billing)\\.prod"}`, "", ErrUserNameNotFound},
+		{"alternation", `{"userNamePattern":"admin|billing"}`, "", ErrUserNameNotFound},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			provider, err := NewMTLSProvider(test.params)
+			require.NoError(t, err)
+			assert.Equal(t, ProviderParamTypeCertificate, provider.AcceptParamType())
+
+			userName, err := provider.Authenticate(context.Background(), cert)
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.userName, userName)
+		})
+	}
+
+	provider, err := NewMTLSProvider("")
+	require.NoError(t, err)
+	_, err = provider.Authenticate(context.Background(), "token")
+	assert.ErrorIs(t, err, ErrUnMatchedAuthenticationParamType)
+}
+
+func TestMTLSProvider_InvalidOptions(t *testing.T) {
+	_, err := NewMTLSProvider(`{"userNameField":"ou"}`)
+	assert.ErrorIs(t, err, ErrUnknownUserNameField)
+
+	_, err = NewMTLSProvider(`{"userNamePattern":"("}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrEmptyTokens  = errors.New("empty static tokens")
+	ErrUnknownToken = errors.New("unknown token")
+)
+
+type StaticOptions struct {
+	// Tokens maps each of the accepted tokens to its user name
+	Tokens map[string]string `json:"tokens,omitempty"`
+}
+
+func (op *StaticOptions) Validate() error {
+	if len(op.Tokens) == 0 {
+		return ErrEmptyTokens
+	}
+	return nil
+}
+
+// StaticProvider accepts a fixed list of tokens. It's meant for the
+// development and test clusters.
+type StaticProvider struct {
+	tokens map[string]string
+}
+
+func (*StaticProvider) AcceptParamType() string {
+	return ProviderParamTypeToken
+}
+
+func (p *StaticProvider) Authenticate(_ context.Context, param any) (string, error) {
+	token, ok := param.(string)
+	if !ok {
+		return "", ErrUnMatchedAuthenticationParamType
+	}
+
+	// Compare all the tokens in constant time, to not leak them through the
+	// response times
+	var userName string
+	for t, u := range p.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			userName = u
+		}
+	}
+	if userName == "" {
+		return "", ErrUnknownToken
+	}
+	return userName, nil
+}
+
+func NewStaticProvider(jsonParam string) (AuthenticationProvider, error) {
+	staticParams := &StaticOptions{}
+	if err := json.Unmarshal([]byte(jsonParam), staticParams); err != nil {
+		return nil, err
+	}
+	if err := staticParams.Validate(); err != nil {
+		return nil, err
+	}
+	return &StaticProvider{tokens: staticParams.Tokens}, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProvider(t *testing.T) {
+	provider, err := NewStaticProvider(`{"tokens":{"token-a":"alice","token-b":"bob"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderParamTypeToken, provider.AcceptParamType())
+
+	userName, err := provider.Authenticate(context.Background(), "token-a")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", userName)
+
+	userName, err = provider.Authenticate(context.Background(), "token-b")
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", userName)
+
+	_, err = provider.Authenticate(context.Background(), "token-c")
+	assert.ErrorIs(t, err, ErrUnknownToken)
+
+	_, err = provider.Authenticate(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrUnMatchedAuthenticationParamType)
+
+	_, err = NewStaticProvider(`{}`)
+	assert.ErrorIs(t, err, ErrEmptyTokens)
+}
@@ -0,0 +1,226 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backup takes consistent backups of the shards of a namespace, and
+// restores them up to a point in time by replaying the write-ahead-log.
+//
+// A backup directory has the same layout as the data directory of a storage
+// node, with an additional manifest for each namespace:
+//
+//	<backup-dir>/<namespace>/backup.json
+//	<backup-dir>/<namespace>/shard-<id>/...
+//
+// The records of a namespace can also be exported to a portable file, and
+// imported into a namespace of any cluster, either through the regular write
+// path or by bulk loading them as pre-built sstables.
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/kv"
+)
+
+const manifestFileName = "backup.json"
+
+// The notifications are never trimmed while the databases are opened by the
+// backup and restore tools.
+const notificationsRetentionTime = time.Duration(math.MaxInt64)
+
+// Manifest describes the backup of a namespace.
+type Manifest struct {
+	Namespace string          `json:"namespace"`
+	Time      time.Time       `json:"time"`
+	Shards    []ShardManifest `json:"shards"`
+}
+
+// ShardManifest describes the backup of a shard. The backup contains all the
+// entries up to the commit offset.
+type ShardManifest struct {
+	Shard        int64 `json:"shard"`
+	Term         int64 `json:"term"`
+	CommitOffset int64 `json:"commitOffset"`
+}
+
+type Options struct {
+	// The public address of any of the storage nodes
+	ServiceAddress string
+	Namespace      string
+	Dir            string
+}
+
+// Backup downloads a snapshot of the database of each shard of the namespace
+// from its leader.
+func Backup(ctx context.Context, clientPool common.ClientPool, options Options) (*Manifest, error) {
+	assignments, err := GetShardAssignments(ctx, clientPool, options.ServiceAddress, options.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaceDir := filepath.Join(options.Dir, options.Namespace)
+	if err := os.RemoveAll(namespaceDir); err != nil {
+		return nil, err
+	}
+
+	kvFactory, err := kv.NewPebbleKVFactory(&kv.FactoryOptions{
+		DataDir:     options.Dir,
+		CacheSizeMB: kv.DefaultFactoryOptions.CacheSizeMB,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer kvFactory.Close()
+
+	manifest := &Manifest{
+		Namespace: options.Namespace,
+		Time:      time.Now(),
+	}
+	for _, assignment := range assignments {
+		shardManifest, err := backupShard(ctx, clientPool, kvFactory, options.Namespace, assignment)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to backup shard %d", assignment.Shard)
+		}
+		manifest.Shards = append(manifest.Shards, *shardManifest)
+	}
+
+	if err := writeManifest(namespaceDir, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// GetShardAssignments returns the current shards of a namespace, with their
+// hash ranges and leaders.
+func GetShardAssignments(ctx context.Context, clientPool common.ClientPool, serviceAddress string,
+	namespace string) ([]*proto.ShardAssignment, error) {
+	rpc, err := clientPool.GetClientRpc(serviceAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := rpc.GetShardAssignments(ctx, &proto.ShardAssignmentsRequest{Namespace: namespace})
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := stream.Recv()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the shard assignments")
+	}
+
+	nsAssignments, ok := assignments.Namespaces[namespace]
+	if !ok {
+		return nil, common.ErrorNamespaceNotFound
+	}
+	return nsAssignments.Assignments, nil
+}
+
+func backupShard(ctx context.Context, clientPool common.ClientPool, kvFactory kv.Factory, namespace string,
+	assignment *proto.ShardAssignment) (*ShardManifest, error) {
+	rpc, err := clientPool.GetAdminRpc(assignment.Leader)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := rpc.Backup(ctx, &proto.BackupRequest{Namespace: namespace, Shard: assignment.Shard})
+	if err != nil {
+		return nil, err
+	}
+
+	loader, err := kvFactory.NewSnapshotLoader(namespace, assignment.Shard)
+	if err != nil {
+		return nil, err
+	}
+	defer loader.Close()
+
+	shardManifest := &ShardManifest{Shard: assignment.Shard}
+	var totalSize int64
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		shardManifest.Term = chunk.Term
+		if err := loader.AddChunk(chunk.Name, chunk.ChunkIndex, chunk.ChunkCount, chunk.Content); err != nil {
+			return nil, err
+		}
+		totalSize += int64(len(chunk.Content))
+	}
+	loader.Complete()
+
+	// Opening the database also verifies the backup
+	db, err := kv.NewDB(namespace, assignment.Shard, kvFactory, notificationsRetentionTime, common.SystemClock)
+	if err != nil {
+		return nil, err
+	}
+	if shardManifest.CommitOffset, err = db.ReadCommitOffset(); err != nil {
+		return nil, multierr.Append(err, db.Close())
+	}
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+
+	slog.Info(
+		"Backup of the shard completed",
+		slog.String("namespace", namespace),
+		slog.Int64("shard", assignment.Shard),
+		slog.String("leader", assignment.Leader),
+		slog.Int64("commit-offset", shardManifest.CommitOffset),
+		slog.Int64("size", totalSize),
+	)
+	return shardManifest, nil
+}
+
+func writeManifest(namespaceDir string, manifest *Manifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(namespaceDir, manifestFileName), content, 0600)
+}
+
+// ReadManifest reads the manifest of the backup of a namespace.
+func ReadManifest(dir string, namespace string) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(dir, namespace, manifestFileName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the backup manifest of namespace %s", namespace)
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, errors.Wrapf(err, "invalid backup manifest of namespace %s", namespace)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server"
+	"github.com/streamnative/oxia/server/kv"
+	"github.com/streamnative/oxia/server/wal"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	config := server.NewTestConfig(dir)
+	config.NumShards = 2
+	standalone, err := server.NewStandalone(config)
+	require.NoError(t, err)
+
+	serviceAddress := fmt.Sprintf("localhost:%d", standalone.RpcPort())
+	client, err := oxia.NewSyncClient(serviceAddress)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		_, _, err = client.Put(ctx, fmt.Sprintf("key-%d", i), []byte("v1"))
+		assert.NoError(t, err)
+	}
+
+	clientPool := common.NewClientPool(nil, nil)
+	backupDir := filepath.Join(dir, "backup")
+	manifest, err := Backup(ctx, clientPool, Options{
+		ServiceAddress: serviceAddress,
+		Namespace:      oxia.DefaultNamespace,
+		Dir:            backupDir,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, oxia.DefaultNamespace, manifest.Namespace)
+	assert.Len(t, manifest.Shards, 2)
+
+	readManifest, err := ReadManifest(backupDir, oxia.DefaultNamespace)
+	assert.NoError(t, err)
+	assert.Equal(t, manifest.Shards, readManifest.Shards)
+
+	// Changes after the backup
+	time.Sleep(10 * time.Millisecond)
+	afterBackup := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		_, _, err = client.Put(ctx, fmt.Sprintf("key-%d", i), []byte("v2"))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, client.DeleteRange(ctx, "key-5", "key-9"))
+
+	assert.NoError(t, client.Close())
+	assert.NoError(t, clientPool.Close())
+	assert.NoError(t, standalone.Close())
+
+	// Restore up to the latest entry
+	latestDir := filepath.Join(dir, "latest")
+	assert.NoError(t, Restore(RestoreOptions{
+		BackupDir: backupDir,
+		Namespace: oxia.DefaultNamespace,
+		DataDir:   latestDir,
+		WalDir:    config.WalDir,
+	}))
+
+	values := readValues(t, latestDir, manifest)
+	for i := 0; i < 10; i++ {
+		if i >= 5 && i < 9 {
+			assert.NotContains(t, values, fmt.Sprintf("key-%d", i))
+		} else {
+			assert.Equal(t, "v2", values[fmt.Sprintf("key-%d", i)])
+		}
+	}
+
+	// Restore up to the time of the backup
+	backupTimeDir := filepath.Join(dir, "backup-time")
+	assert.NoError(t, Restore(RestoreOptions{
+		BackupDir:   backupDir,
+		Namespace:   oxia.DefaultNamespace,
+		DataDir:     backupTimeDir,
+		WalDir:      config.WalDir,
+		ToTimestamp: afterBackup,
+	}))
+
+	values = readValues(t, backupTimeDir, manifest)
+	assert.Len(t, values, 10)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "v1", values[fmt.Sprintf("key-%d", i)])
+	}
+
+	// The restore point can't be before the backup
+	err = Restore(RestoreOptions{
+		BackupDir: backupDir,
+		Namespace: oxia.DefaultNamespace,
+		DataDir:   filepath.Join(dir, "before-backup"),
+		WalDir:    config.WalDir,
+		ToOffset:  -1,
+	})
+	assert.ErrorIs(t, err, ErrRestorePointBeforeBackup)
+}
+
+func readValues(t *testing.T, dataDir string, manifest *Manifest) map[string]string {
+	t.Helper()
+
+	factory, err := kv.NewPebbleKVFactory(&kv.FactoryOptions{DataDir: dataDir})
+	require.NoError(t, err)
+	defer factory.Close()
+
+	values := map[string]string{}
+	for _, shard := range manifest.Shards {
+		db, err := kv.NewDB(manifest.Namespace, shard.Shard, factory, notificationsRetentionTime, common.SystemClock)
+		require.NoError(t, err)
+
+		term, _, err := db.ReadTerm()
+		assert.NoError(t, err)
+		assert.Equal(t, wal.InvalidTerm, term)
+
+		for i := 0; i < 10; i++ {
+			key := fmt.Sprintf("key-%d", i)
+			res, err := db.Get(&proto.GetRequest{Key: key, IncludeValue: true})
+			assert.NoError(t, err)
+			if res.Status == proto.Status_OK {
+				values[key] = string(res.Value)
+			}
+		}
+		assert.NoError(t, db.Close())
+	}
+	return values
+}
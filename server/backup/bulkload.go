@@ -0,0 +1,234 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/kv"
+)
+
+var ErrBulkLoadUnsupportedRecord = errors.New("oxia: record not supported by the bulk load")
+
+type BulkLoadOptions struct {
+	// The public address of any of the storage nodes
+	ServiceAddress string
+	Namespace      string
+
+	// The directory where the sstables are built
+	WorkDir string
+}
+
+// BulkLoad writes the records read from r, in the format written by Export,
+// without passing them through the write-ahead-log. The records are sorted
+// in an sstable for each shard, which is then ingested by the replicas of
+// the shard. The records with secondary indexes are not supported.
+func BulkLoad(ctx context.Context, clientPool common.ClientPool, options BulkLoadOptions, r io.Reader) (int64, error) {
+	assignments, err := GetShardAssignments(ctx, clientPool, options.ServiceAddress, options.Namespace)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.RemoveAll(options.WorkDir); err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(options.WorkDir)
+
+	files, count, err := buildIngestFiles(r, assignments, options.WorkDir)
+	if err != nil {
+		return count, err
+	}
+
+	for _, assignment := range assignments {
+		file, ok := files[assignment.Shard]
+		if !ok {
+			continue
+		}
+		if err := ingestFile(ctx, clientPool, options.Namespace, assignment, file); err != nil {
+			return count, errors.Wrapf(err, "failed to ingest the records of shard %d", assignment.Shard)
+		}
+	}
+	return count, nil
+}
+
+func shardOf(assignments []*proto.ShardAssignment, record *proto.ExportedRecord) (int64, error) {
+	key := record.Key
+	if record.PartitionKey != nil {
+		key = *record.PartitionKey
+	}
+
+	hash := common.Xxh332(key)
+	for _, assignment := range assignments {
+		hashRange := assignment.GetInt32HashRange()
+		if hashRange.GetMinHashInclusive() <= hash && hash <= hashRange.GetMaxHashInclusive() {
+			return assignment.Shard, nil
+		}
+	}
+	return 0, errors.Errorf("no shard for key %q", record.Key)
+}
+
+// Builds the sstables, sorting the records of each shard in a temporary
+// database.
+func buildIngestFiles(r io.Reader, assignments []*proto.ShardAssignment,
+	workDir string) (files map[int64]string, count int64, err error) {
+	sorters := map[int64]*pebble.DB{}
+	defer func() {
+		for _, sorter := range sorters {
+			err = multierr.Append(err, sorter.Close())
+		}
+	}()
+
+	reader := bufio.NewReader(r)
+	for {
+		record, err := ReadExportedRecord(reader)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, count, err
+		}
+
+		if len(record.SecondaryIndexes) > 0 || strings.HasPrefix(record.Key, common.InternalKeyPrefix) {
+			return nil, count, errors.Wrapf(ErrBulkLoadUnsupportedRecord, "key %q", record.Key)
+		}
+
+		shard, err := shardOf(assignments, record)
+		if err != nil {
+			return nil, count, err
+		}
+
+		sorter, ok := sorters[shard]
+		if !ok {
+			if sorter, err = pebble.Open(filepath.Join(workDir, fmt.Sprint("sort-", shard)), &pebble.Options{
+				Comparer:   kv.OxiaSlashSpanComparer,
+				DisableWAL: true,
+			}); err != nil {
+				return nil, count, err
+			}
+			sorters[shard] = sorter
+		}
+
+		value, err := (&proto.StorageEntry{Value: record.Value, PartitionKey: record.PartitionKey}).MarshalVT()
+		if err != nil {
+			return nil, count, err
+		}
+		if err := sorter.Set([]byte(record.Key), value, pebble.NoSync); err != nil {
+			return nil, count, err
+		}
+		count++
+	}
+
+	files = map[int64]string{}
+	for shard, sorter := range sorters {
+		file := filepath.Join(workDir, fmt.Sprintf("shard-%d.sst", shard))
+		if err := writeIngestFile(sorter, file); err != nil {
+			return nil, count, err
+		}
+		files[shard] = file
+	}
+	return files, count, nil
+}
+
+func writeIngestFile(sorter *pebble.DB, file string) error {
+	f, err := vfs.Default.Create(file)
+	if err != nil {
+		return err
+	}
+
+	w := kv.NewIngestFileWriter(f)
+	it, err := sorter.NewIter(nil)
+	if err != nil {
+		return multierr.Append(err, w.Close())
+	}
+
+	for it.First(); it.Valid(); it.Next() {
+		if err := w.Set(it.Key(), it.Value()); err != nil {
+			return multierr.Combine(err, it.Close(), w.Close())
+		}
+	}
+	return multierr.Combine(it.Close(), w.Close())
+}
+
+func ingestFile(ctx context.Context, clientPool common.ClientPool, namespace string,
+	assignment *proto.ShardAssignment, file string) error {
+	rpc, err := clientPool.GetAdminRpc(assignment.Leader)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := rpc.Ingest(ctx)
+	if err != nil {
+		return err
+	}
+
+	var totalSize int64
+	for {
+		content := make([]byte, kv.MaxSnapshotChunkSize)
+		n, err := io.ReadFull(f, content)
+		if n > 0 || totalSize == 0 {
+			if err := stream.Send(&proto.IngestChunk{
+				Namespace: namespace,
+				Shard:     assignment.Shard,
+				Content:   content[:n],
+			}); err != nil {
+				return err
+			}
+			totalSize += int64(n)
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	response, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+
+	slog.Info(
+		"Ingested the records of the shard",
+		slog.String("namespace", namespace),
+		slog.Int64("shard", assignment.Shard),
+		slog.String("leader", assignment.Leader),
+		slog.Int64("offset", response.Offset),
+		slog.Int64("size", totalSize),
+	)
+	return nil
+}
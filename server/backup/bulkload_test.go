@@ -0,0 +1,122 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "google.golang.org/protobuf/proto"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server"
+)
+
+func TestBulkLoad(t *testing.T) {
+	config := server.NewTestConfig(t.TempDir())
+	config.NumShards = 3
+	standalone, err := server.NewStandalone(config)
+	require.NoError(t, err)
+	defer standalone.Close()
+
+	serviceAddress := fmt.Sprintf("localhost:%d", standalone.RpcPort())
+	client, err := oxia.NewSyncClient(serviceAddress)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	_, _, err = client.Put(ctx, "key-0", []byte("old"))
+	assert.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := bufio.NewWriter(buf)
+	for i := 99; i >= 0; i-- {
+		assert.NoError(t, writeExportedRecord(w, &proto.ExportedRecord{
+			Key:   fmt.Sprintf("key-%d", i),
+			Value: []byte(fmt.Sprint(i)),
+		}))
+	}
+	assert.NoError(t, writeExportedRecord(w, &proto.ExportedRecord{
+		Key:          "partitioned",
+		Value:        []byte("p"),
+		PartitionKey: pb.String("pk"),
+	}))
+	assert.NoError(t, w.Flush())
+
+	clientPool := common.NewClientPool(nil, nil)
+	defer clientPool.Close()
+
+	count, err := BulkLoad(ctx, clientPool, BulkLoadOptions{
+		ServiceAddress: serviceAddress,
+		Namespace:      oxia.DefaultNamespace,
+		WorkDir:        filepath.Join(t.TempDir(), "work"),
+	}, bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 101, count)
+
+	for i := 0; i < 100; i++ {
+		_, value, version, err := client.Get(ctx, fmt.Sprintf("key-%d", i))
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprint(i), string(value))
+		assert.EqualValues(t, 0, version.ModificationsCount)
+	}
+
+	_, value, _, err := client.Get(ctx, "partitioned", oxia.PartitionKey("pk"))
+	assert.NoError(t, err)
+	assert.Equal(t, "p", string(value))
+
+	keys, err := client.List(ctx, "key-10", "key-12")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"key-10", "key-11"}, keys)
+
+	// The loaded records can be updated as any other record
+	_, version, err := client.Put(ctx, "key-1", []byte("new"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, version.ModificationsCount)
+}
+
+func TestBulkLoad_SecondaryIndexes(t *testing.T) {
+	config := server.NewTestConfig(t.TempDir())
+	standalone, err := server.NewStandalone(config)
+	require.NoError(t, err)
+	defer standalone.Close()
+
+	buf := &bytes.Buffer{}
+	w := bufio.NewWriter(buf)
+	assert.NoError(t, writeExportedRecord(w, &proto.ExportedRecord{
+		Key:              "key",
+		Value:            []byte("value"),
+		SecondaryIndexes: []*proto.SecondaryIndex{{IndexName: "idx", SecondaryKey: "a"}},
+	}))
+	assert.NoError(t, w.Flush())
+
+	clientPool := common.NewClientPool(nil, nil)
+	defer clientPool.Close()
+
+	_, err = BulkLoad(context.Background(), clientPool, BulkLoadOptions{
+		ServiceAddress: fmt.Sprintf("localhost:%d", standalone.RpcPort()),
+		Namespace:      oxia.DefaultNamespace,
+		WorkDir:        filepath.Join(t.TempDir(), "work"),
+	}, bytes.NewReader(buf.Bytes()))
+	assert.ErrorIs(t, err, ErrBulkLoadUnsupportedRecord)
+}
@@ -0,0 +1,186 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/proto"
+)
+
+// The maximum size of an exported record, to detect a corrupted file.
+const maxExportedRecordSize = 64 * 1024 * 1024
+
+// The maximum number of records imported at the same time.
+const maxPendingImports = 1000
+
+var ErrInvalidExportedRecord = errors.New("oxia: invalid exported record")
+
+// Export writes all the records of the namespace, except for the ephemeral
+// ones. Each record is a proto.ExportedRecord prefixed by its size as a
+// varint. The records of each shard are consistent, though the shards are
+// exported one at a time.
+func Export(ctx context.Context, clientPool common.ClientPool, serviceAddress string, namespace string,
+	w io.Writer) (count int64, err error) {
+	assignments, err := GetShardAssignments(ctx, clientPool, serviceAddress, namespace)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, assignment := range assignments {
+		shardCount, err := exportShard(ctx, clientPool, namespace, assignment, w)
+		if err != nil {
+			return count, errors.Wrapf(err, "failed to export shard %d", assignment.Shard)
+		}
+		count += shardCount
+	}
+	return count, nil
+}
+
+func exportShard(ctx context.Context, clientPool common.ClientPool, namespace string,
+	assignment *proto.ShardAssignment, w io.Writer) (count int64, err error) {
+	rpc, err := clientPool.GetAdminRpc(assignment.Leader)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := rpc.Export(ctx, &proto.ExportRequest{Namespace: namespace, Shard: assignment.Shard})
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return count, err
+		}
+
+		for _, record := range response.Records {
+			if err := writeExportedRecord(w, record); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+
+	slog.Info(
+		"Exported the shard",
+		slog.String("namespace", namespace),
+		slog.Int64("shard", assignment.Shard),
+		slog.Int64("records", count),
+	)
+	return count, nil
+}
+
+func writeExportedRecord(w io.Writer, record *proto.ExportedRecord) error {
+	data, err := record.MarshalVT()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(binary.AppendUvarint(nil, uint64(len(data)))); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// ReadExportedRecord reads the next record written by Export. It returns
+// io.EOF after the last record.
+func ReadExportedRecord(r *bufio.Reader) (*proto.ExportedRecord, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, errors.Wrap(ErrInvalidExportedRecord, err.Error())
+	}
+	if size > maxExportedRecordSize {
+		return nil, errors.Wrapf(ErrInvalidExportedRecord, "record size %d is too large", size)
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, errors.Wrap(ErrInvalidExportedRecord, err.Error())
+	}
+
+	record := &proto.ExportedRecord{}
+	if err := record.UnmarshalVT(buf); err != nil {
+		return nil, errors.Wrap(ErrInvalidExportedRecord, err.Error())
+	}
+	return record, nil
+}
+
+// Import writes the records read from r, as exported by Export. The records
+// are assigned to the shards by the client, so the namespace can have a
+// different number of shards than the exported one. The records get new
+// versions, while their partition keys and secondary indexes are kept.
+func Import(client oxia.AsyncClient, r io.Reader) (count int64, err error) {
+	reader := bufio.NewReader(r)
+	pending := make([]<-chan oxia.PutResult, 0, maxPendingImports)
+
+	waitPending := func() error {
+		for _, ch := range pending {
+			if res := <-ch; res.Err != nil {
+				return res.Err
+			}
+			count++
+		}
+		pending = pending[:0]
+		return nil
+	}
+
+	for {
+		record, err := ReadExportedRecord(reader)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return count, multierr.Append(err, waitPending())
+		}
+
+		pending = append(pending, client.Put(record.Key, record.Value, putOptions(record)...))
+		if len(pending) == maxPendingImports {
+			if err := waitPending(); err != nil {
+				return count, err
+			}
+		}
+	}
+
+	return count, waitPending()
+}
+
+func putOptions(record *proto.ExportedRecord) []oxia.PutOption {
+	var options []oxia.PutOption
+	if record.PartitionKey != nil {
+		options = append(options, oxia.PartitionKey(*record.PartitionKey))
+	}
+	for _, si := range record.SecondaryIndexes {
+		options = append(options, oxia.SecondaryIndex(si.IndexName, si.SecondaryKey))
+	}
+	return options
+}
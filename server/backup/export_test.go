@@ -0,0 +1,108 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/server"
+)
+
+func TestExportAndImport(t *testing.T) {
+	config := server.NewTestConfig(t.TempDir())
+	config.NumShards = 2
+	source, err := server.NewStandalone(config)
+	require.NoError(t, err)
+	defer source.Close()
+
+	sourceAddress := fmt.Sprintf("localhost:%d", source.RpcPort())
+	sourceClient, err := oxia.NewSyncClient(sourceAddress)
+	require.NoError(t, err)
+	defer sourceClient.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		_, _, err = sourceClient.Put(ctx, fmt.Sprintf("key-%d", i), []byte(fmt.Sprint(i)),
+			oxia.SecondaryIndex("idx", fmt.Sprint(9-i)))
+		assert.NoError(t, err)
+	}
+	_, _, err = sourceClient.Put(ctx, "partitioned", []byte("p"), oxia.PartitionKey("pk"))
+	assert.NoError(t, err)
+	_, _, err = sourceClient.Put(ctx, "ephemeral", []byte("e"), oxia.Ephemeral())
+	assert.NoError(t, err)
+
+	clientPool := common.NewClientPool(nil, nil)
+	defer clientPool.Close()
+
+	buf := &bytes.Buffer{}
+	count, err := Export(ctx, clientPool, sourceAddress, oxia.DefaultNamespace, buf)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 11, count)
+
+	// Import in a namespace with a different number of shards
+	config = server.NewTestConfig(t.TempDir())
+	config.NumShards = 3
+	target, err := server.NewStandalone(config)
+	require.NoError(t, err)
+	defer target.Close()
+
+	targetAddress := fmt.Sprintf("localhost:%d", target.RpcPort())
+	asyncClient, err := oxia.NewAsyncClient(targetAddress)
+	require.NoError(t, err)
+
+	count, err = Import(asyncClient, bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 11, count)
+	assert.NoError(t, asyncClient.Close())
+
+	targetClient, err := oxia.NewSyncClient(targetAddress)
+	require.NoError(t, err)
+	defer targetClient.Close()
+
+	for i := 0; i < 10; i++ {
+		_, value, _, err := targetClient.Get(ctx, fmt.Sprintf("key-%d", i))
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprint(i), string(value))
+	}
+
+	keys, err := targetClient.List(ctx, "0", "3", oxia.UseIndex("idx"))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"key-9", "key-8", "key-7"}, keys)
+
+	_, value, _, err := targetClient.Get(ctx, "partitioned", oxia.PartitionKey("pk"))
+	assert.NoError(t, err)
+	assert.Equal(t, "p", string(value))
+
+	_, _, _, err = targetClient.Get(ctx, "ephemeral")
+	assert.ErrorIs(t, err, oxia.ErrKeyNotFound)
+}
+
+func TestReadExportedRecord_Invalid(t *testing.T) {
+	_, err := ReadExportedRecord(bufio.NewReader(bytes.NewReader(nil)))
+	assert.ErrorIs(t, err, io.EOF)
+
+	_, err = ReadExportedRecord(bufio.NewReader(bytes.NewReader([]byte{10, 1, 2})))
+	assert.ErrorIs(t, err, ErrInvalidExportedRecord)
+}
@@ -0,0 +1,248 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server"
+	"github.com/streamnative/oxia/server/encryption"
+	"github.com/streamnative/oxia/server/kv"
+	"github.com/streamnative/oxia/server/wal"
+)
+
+const restoreChunkSize = 1024 * 1024
+
+var (
+	ErrRestorePointBeforeBackup = errors.New("oxia: the restore point is before the backup")
+
+	// The sstables of the bulk loads are not kept after they're ingested, so a
+	// bulk load can only be restored from a backup taken after it.
+	ErrBulkLoadNotReplayable = errors.New("oxia: a bulk load can't be replayed")
+)
+
+type RestoreOptions struct {
+	BackupDir string
+	Namespace string
+
+	// The data directory where the databases of the shards are restored
+	DataDir string
+
+	// The write-ahead-log with the entries written after the backup. The
+	// offloaded segments are read from WalObjectStore, when set.
+	WalDir         string
+	WalObjectStore wal.ObjectStore
+
+	// The entries are replayed up to the first entry after ToOffset or
+	// ToTimestamp. A zero ToOffset replays all the entries.
+	ToOffset    int64
+	ToTimestamp time.Time
+
+	// The keys to read an encrypted write-ahead-log and to encrypt the
+	// restored databases
+	KeyProvider encryption.KeyProvider
+}
+
+// Restore rebuilds the databases of the shards of a namespace, by loading
+// their backup and replaying the write-ahead-log up to the restore point.
+// The storage node owning DataDir must be stopped.
+func Restore(options RestoreOptions) error {
+	if options.ToOffset == 0 {
+		options.ToOffset = math.MaxInt64
+	}
+
+	manifest, err := ReadManifest(options.BackupDir, options.Namespace)
+	if err != nil {
+		return err
+	}
+
+	kvFactory, err := kv.NewPebbleKVFactory(&kv.FactoryOptions{
+		DataDir:     options.DataDir,
+		CacheSizeMB: kv.DefaultFactoryOptions.CacheSizeMB,
+		KeyProvider: options.KeyProvider,
+	})
+	if err != nil {
+		return err
+	}
+
+	walFactory := wal.NewWalFactory(&wal.FactoryOptions{
+		BaseWalDir:  options.WalDir,
+		Retention:   math.MaxInt64,
+		SegmentSize: wal.DefaultFactoryOptions.SegmentSize,
+		KeyProvider: options.KeyProvider,
+		ObjectStore: options.WalObjectStore,
+	})
+
+	for _, shard := range manifest.Shards {
+		if err = restoreShard(options, kvFactory, walFactory, shard); err != nil {
+			err = errors.Wrapf(err, "failed to restore shard %d", shard.Shard)
+			break
+		}
+	}
+
+	return multierr.Combine(err, walFactory.Close(), kvFactory.Close())
+}
+
+func restoreShard(options RestoreOptions, kvFactory kv.Factory, walFactory wal.Factory, shard ShardManifest) error {
+	if shard.CommitOffset > options.ToOffset {
+		return errors.Wrapf(ErrRestorePointBeforeBackup, "the backup commit offset is %d", shard.CommitOffset)
+	}
+
+	if err := loadBackup(options, kvFactory, shard.Shard); err != nil {
+		return err
+	}
+
+	db, err := kv.NewDB(options.Namespace, shard.Shard, kvFactory, notificationsRetentionTime, common.SystemClock)
+	if err != nil {
+		return err
+	}
+
+	lastOffset, err := replay(options, walFactory, db, shard)
+	if err != nil {
+		return multierr.Append(err, db.Close())
+	}
+
+	// The term is reset, so that the shard can be fenced by any coordinator
+	_, termOptions, err := db.ReadTerm()
+	if err != nil {
+		return multierr.Append(err, db.Close())
+	}
+	if err = db.UpdateTerm(wal.InvalidTerm, termOptions); err != nil {
+		return multierr.Append(err, db.Close())
+	}
+
+	slog.Info(
+		"Restored the shard",
+		slog.String("namespace", options.Namespace),
+		slog.Int64("shard", shard.Shard),
+		slog.Int64("backup-commit-offset", shard.CommitOffset),
+		slog.Int64("restored-offset", lastOffset),
+	)
+	return db.Close()
+}
+
+// Copies the backup of the shard in the data directory.
+func loadBackup(options RestoreOptions, kvFactory kv.Factory, shard int64) error {
+	backupPath := filepath.Join(options.BackupDir, options.Namespace, fmt.Sprint("shard-", shard))
+	dirEntries, err := os.ReadDir(backupPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the backup")
+	}
+
+	loader, err := kvFactory.NewSnapshotLoader(options.Namespace, shard)
+	if err != nil {
+		return err
+	}
+	defer loader.Close()
+
+	for _, de := range dirEntries {
+		if de.IsDir() {
+			continue
+		}
+		if err := loadFile(loader, filepath.Join(backupPath, de.Name())); err != nil {
+			return err
+		}
+	}
+
+	loader.Complete()
+	return nil
+}
+
+func loadFile(loader kv.SnapshotLoader, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	chunkCount := int32(max((stat.Size()+restoreChunkSize-1)/restoreChunkSize, 1))
+	buf := make([]byte, restoreChunkSize)
+	for chunkIndex := int32(0); chunkIndex < chunkCount; chunkIndex++ {
+		n, err := io.ReadFull(f, buf)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if err := loader.AddChunk(filepath.Base(path), chunkIndex, chunkCount, buf[:n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Applies the entries after the backup, up to the restore point, and returns
+// the offset of the last applied entry.
+func replay(options RestoreOptions, walFactory wal.Factory, db kv.DB, shard ShardManifest) (int64, error) {
+	w, err := walFactory.NewWal(options.Namespace, shard.Shard, nil)
+	if err != nil {
+		return wal.InvalidOffset, err
+	}
+	defer w.Close()
+
+	reader, err := w.NewReader(shard.CommitOffset)
+	if err != nil {
+		return wal.InvalidOffset, errors.Wrapf(err, "the write-ahead-log doesn't have the entries after offset %d",
+			shard.CommitOffset)
+	}
+	defer reader.Close()
+
+	logEntryValue := proto.LogEntryValueFromVTPool()
+	defer logEntryValue.ReturnToVTPool()
+
+	lastOffset := shard.CommitOffset
+	for reader.HasNext() {
+		entry, err := reader.ReadNext()
+		if err != nil {
+			return wal.InvalidOffset, err
+		}
+
+		if entry.Offset > options.ToOffset ||
+			(!options.ToTimestamp.IsZero() && entry.Timestamp > uint64(options.ToTimestamp.UnixMilli())) {
+			break
+		}
+
+		logEntryValue.ResetVT()
+		if err := logEntryValue.UnmarshalVT(entry.Value); err != nil {
+			return wal.InvalidOffset, err
+		}
+		if logEntryValue.GetIngest() != nil {
+			return wal.InvalidOffset, errors.Wrapf(ErrBulkLoadNotReplayable, "offset %d", entry.Offset)
+		}
+		requests := logEntryValue.GetRequests()
+		for i, writeRequest := range requests.GetWrites() {
+			if _, err := db.ProcessWriteWithUsage(writeRequest, server.WriteUsageAt(requests, i), entry.Offset,
+				entry.Timestamp, server.WrapperUpdateOperationCallback); err != nil {
+				return wal.InvalidOffset, err
+			}
+		}
+		lastOffset = entry.Offset
+	}
+	return lastOffset, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Cipher creates the AES ciphers for the keys of a KeyProvider, and caches
+// them, since they are needed for every record and file.
+type Cipher struct {
+	provider KeyProvider
+	ciphers  sync.Map // KeyId -> *keyCipher
+}
+
+type keyCipher struct {
+	block cipher.Block
+	aead  cipher.AEAD
+}
+
+func NewCipher(provider KeyProvider) *Cipher {
+	return &Cipher{provider: provider}
+}
+
+// ActiveBlock returns the block cipher for the active key.
+func (c *Cipher) ActiveBlock() (KeyId, cipher.Block, error) {
+	kc, id, err := c.active()
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, kc.block, nil
+}
+
+// ActiveAEAD returns the AES-GCM cipher for the active key.
+func (c *Cipher) ActiveAEAD() (KeyId, cipher.AEAD, error) {
+	kc, id, err := c.active()
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, kc.aead, nil
+}
+
+// Block returns the block cipher for the given key.
+func (c *Cipher) Block(id KeyId) (cipher.Block, error) {
+	kc, err := c.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return kc.block, nil
+}
+
+// AEAD returns the AES-GCM cipher for the given key.
+func (c *Cipher) AEAD(id KeyId) (cipher.AEAD, error) {
+	kc, err := c.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return kc.aead, nil
+}
+
+func (c *Cipher) active() (*keyCipher, KeyId, error) {
+	key, err := c.provider.ActiveKey()
+	if err != nil {
+		return nil, 0, err
+	}
+	kc, err := c.getOrCreate(key)
+	return kc, key.Id, err
+}
+
+func (c *Cipher) get(id KeyId) (*keyCipher, error) {
+	if kc, ok := c.ciphers.Load(id); ok {
+		return kc.(*keyCipher), nil
+	}
+
+	key, err := c.provider.Key(id)
+	if err != nil {
+		return nil, err
+	}
+	return c.getOrCreate(key)
+}
+
+func (c *Cipher) getOrCreate(key *Key) (*keyCipher, error) {
+	if kc, ok := c.ciphers.Load(key.Id); ok {
+		return kc.(*keyCipher), nil
+	}
+
+	block, err := aes.NewCipher(key.Material)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidKey, "key id %s: %v", key.Id, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidKey, "key id %s: %v", key.Id, err)
+	}
+
+	kc, _ := c.ciphers.LoadOrStore(key.Id, &keyCipher{block: block, aead: aead})
+	return kc.(*keyCipher), nil
+}
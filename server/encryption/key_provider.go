@@ -0,0 +1,142 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// KeySize is the size of the AES-256 keys.
+const KeySize = 32
+
+var (
+	ErrKeyNotFound = errors.New("oxia: encryption key not found")
+	ErrInvalidKey  = errors.New("oxia: invalid encryption key")
+)
+
+// KeyId identifies a key in the data encrypted with it, so that the data can
+// still be decrypted after the active key is rotated.
+type KeyId uint32
+
+func (id KeyId) String() string {
+	return fmt.Sprintf("%08x", uint32(id))
+}
+
+type Key struct {
+	Id       KeyId
+	Material []byte
+}
+
+// KeyProvider gives access to the keys used to encrypt the data at rest.
+type KeyProvider interface {
+	// ActiveKey returns the key used to encrypt new data.
+	ActiveKey() (*Key, error)
+
+	// Key returns the key with the given id, to decrypt existing data.
+	Key(id KeyId) (*Key, error)
+}
+
+type staticKeyProvider struct {
+	active *Key
+	keys   map[KeyId]*Key
+}
+
+// NewStaticKeyProvider creates a provider for a fixed set of 256 bits keys. The
+// first key is used to encrypt the new data, while all of them can be used to
+// decrypt the existing data.
+func NewStaticKeyProvider(keys ...[]byte) (KeyProvider, error) {
+	p := &staticKeyProvider{
+		keys: map[KeyId]*Key{},
+	}
+
+	for i, material := range keys {
+		if len(material) != KeySize {
+			return nil, errors.Wrapf(ErrInvalidKey, "key #%d: expected %d bytes, got %d", i, KeySize, len(material))
+		}
+
+		key := &Key{Id: keyIdOf(material), Material: material}
+		if p.active == nil {
+			p.active = key
+		}
+		p.keys[key.Id] = key
+	}
+
+	if p.active == nil {
+		return nil, errors.Wrap(ErrInvalidKey, "no keys provided")
+	}
+	return p, nil
+}
+
+// NewFileKeyProvider loads the keys from a local file, with one base64 encoded
+// 256 bits key per line. The first key is used to encrypt the new data, while the
+// others are only used to decrypt the existing data. Keys can be rotated by adding
+// a new key at the top of the file.
+//
+// A key can be generated with `openssl rand -base64 32`.
+func NewFileKeyProvider(path string) (KeyProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the encryption key file")
+	}
+
+	var keys [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		material, err := base64.StdEncoding.DecodeString(line)
+		if err != nil || len(material) != KeySize {
+			return nil, errors.Wrapf(ErrInvalidKey, "%s:%d: expected a base64 encoded %d bytes key",
+				path, lineNumber, KeySize)
+		}
+		keys = append(keys, material)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.Wrapf(ErrInvalidKey, "no keys found in %s", path)
+	}
+	return NewStaticKeyProvider(keys...)
+}
+
+func (p *staticKeyProvider) ActiveKey() (*Key, error) {
+	return p.active, nil
+}
+
+func (p *staticKeyProvider) Key(id KeyId) (*Key, error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, errors.Wrapf(ErrKeyNotFound, "key id: %s", id)
+	}
+	return key, nil
+}
+
+// The id is derived from the key fingerprint, so that it doesn't depend on
+// the position of the key in the file.
+func keyIdOf(material []byte) KeyId {
+	sum := sha256.Sum256(material)
+	return KeyId(binary.BigEndian.Uint32(sum[:4]))
+}
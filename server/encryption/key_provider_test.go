@@ -0,0 +1,99 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileKeyProvider(t *testing.T) {
+	k1 := bytes.Repeat([]byte{1}, KeySize)
+	k2 := bytes.Repeat([]byte{2}, KeySize)
+
+	path := filepath.Join(t.TempDir(), "keys")
+	content := "# The active key is the first one\n" +
+		base64.StdEncoding.EncodeToString(k2) + "\n\n" +
+		base64.StdEncoding.EncodeToString(k1) + "\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	p, err := NewFileKeyProvider(path)
+	assert.NoError(t, err)
+
+	active, err := p.ActiveKey()
+	assert.NoError(t, err)
+	assert.Equal(t, k2, active.Material)
+	assert.Equal(t, keyIdOf(k2), active.Id)
+
+	key, err := p.Key(keyIdOf(k1))
+	assert.NoError(t, err)
+	assert.Equal(t, k1, key.Material)
+
+	_, err = p.Key(keyIdOf(bytes.Repeat([]byte{3}, KeySize)))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestFileKeyProvider_Invalid(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewFileKeyProvider(filepath.Join(dir, "non-existing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	for name, content := range map[string]string{
+		"empty":     "# no keys\n",
+		"not-b64":   "not a key\n",
+		"too-short": base64.StdEncoding.EncodeToString([]byte("short")),
+	} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		_, err = NewFileKeyProvider(path)
+		assert.ErrorIs(t, err, ErrInvalidKey, name)
+	}
+}
+
+func TestCipher(t *testing.T) {
+	k1 := bytes.Repeat([]byte{1}, KeySize)
+	k2 := bytes.Repeat([]byte{2}, KeySize)
+	p, err := NewStaticKeyProvider(k1, k2)
+	assert.NoError(t, err)
+	c := NewCipher(p)
+
+	id, aead, err := c.ActiveAEAD()
+	assert.NoError(t, err)
+	assert.Equal(t, keyIdOf(k1), id)
+
+	nonce := make([]byte, aead.NonceSize())
+	sealed := aead.Seal(nil, nonce, []byte("value"), nil)
+
+	other, err := c.AEAD(keyIdOf(k2))
+	assert.NoError(t, err)
+	_, err = other.Open(nil, nonce, sealed, nil)
+	assert.Error(t, err)
+
+	same, err := c.AEAD(id)
+	assert.NoError(t, err)
+	assert.Same(t, aead, same)
+	plaintext, err := same.Open(nil, nonce, sealed, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), plaintext)
+
+	_, err = c.Block(keyIdOf(bytes.Repeat([]byte{3}, KeySize)))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
@@ -21,6 +21,7 @@ import (
 	"log/slog"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
@@ -66,9 +67,25 @@ type FollowerController interface {
 
 	SendSnapshot(stream proto.OxiaLogReplication_SendSnapshotServer) error
 
+	// SendIngestFile stages an sstable, before the leader replicates the entry
+	// that ingests it
+	SendIngestFile(stream proto.OxiaLogReplication_SendIngestFileServer) error
+
+	// FetchLog streams the wal entries of a fenced follower, from the given
+	// offset. The new leader uses it to recover the entries that only a
+	// witness has.
+	FetchLog(request *proto.FetchLogRequest, stream proto.OxiaLogReplication_FetchLogServer) error
+
 	GetStatus(request *proto.GetStatusRequest) (*proto.GetStatusResponse, error)
 	DeleteShard(request *proto.DeleteShardRequest) (*proto.DeleteShardResponse, error)
 
+	// Read, List and RangeScan serve stale reads from the local database,
+	// when the node is an observer of the shard
+	Read(ctx context.Context, request *proto.ReadRequest) <-chan GetResult
+	List(ctx context.Context, request *proto.ListRequest) (<-chan string, error)
+	RangeScan(ctx context.Context, request *proto.RangeScanRequest) (<-chan *proto.GetResponse, <-chan error, error)
+
+	Namespace() string
 	Term() int64
 	CommitOffset() int64
 	Status() proto.ServingStatus
@@ -96,6 +113,14 @@ type followerController struct {
 	db          kv.DB
 	termOptions kv.TermOptions
 
+	// Whether the node is a witness, which doesn't apply the entries to
+	// the database. It's read by the goroutine applying the entries.
+	witness atomic.Bool
+
+	// Held by the observer reads, to prevent the database from
+	// being closed while they're iterating over it
+	dbReaders sync.RWMutex
+
 	ctx              context.Context
 	cancel           context.CancelFunc
 	syncCond         common.ConditionContext
@@ -143,7 +168,8 @@ func NewFollowerController(config Config, namespace string, shardId int64, wf wa
 		fc.status = proto.ServingStatus_FENCED
 	}
 
-	fc.db.EnableNotifications(fc.termOptions.NotificationsEnabled)
+	fc.witness.Store(fc.termOptions.Witness)
+	fc.db.EnableNotifications(fc.termOptions.NotificationsEnabled && !fc.termOptions.Witness)
 
 	commitOffset, err := fc.db.ReadCommitOffset()
 	if err != nil {
@@ -209,8 +235,10 @@ func (fc *followerController) close() error {
 	}
 
 	if fc.db != nil {
+		fc.dbReaders.Lock()
 		err = multierr.Append(err, fc.db.Close())
 		fc.db = nil
+		fc.dbReaders.Unlock()
 	}
 	return err
 }
@@ -242,6 +270,10 @@ func (fc *followerController) Status() proto.ServingStatus {
 	return fc.status
 }
 
+func (fc *followerController) Namespace() string {
+	return fc.namespace
+}
+
 func (fc *followerController) Term() int64 {
 	fc.Lock()
 	defer fc.Unlock()
@@ -281,7 +313,8 @@ func (fc *followerController) NewTerm(req *proto.NewTermRequest) (*proto.NewTerm
 		return nil, err
 	}
 
-	fc.db.EnableNotifications(fc.termOptions.NotificationsEnabled)
+	fc.witness.Store(fc.termOptions.Witness)
+	fc.db.EnableNotifications(fc.termOptions.NotificationsEnabled && !fc.termOptions.Witness)
 
 	fc.term = req.Term
 	fc.setLogger()
@@ -491,15 +524,20 @@ func (fc *followerController) applyAllCommittedEntries() {
 }
 
 func (fc *followerController) processCommitRequest(entry *proto.LogEntry, logEntryValue *proto.LogEntryValue) error {
-	for _, br := range logEntryValue.GetRequests().Writes {
-		_, err := fc.db.ProcessWrite(br, entry.Offset, entry.Timestamp, WrapperUpdateOperationCallback)
-		if err != nil {
-			fc.log.Error(
-				"Error applying committed entry",
-				slog.Any("error", err),
-			)
-			return err
-		}
+	var err error
+	if fc.witness.Load() {
+		// The witnesses don't keep the data, though the commit offset is
+		// still tracked, since the wal is trimmed up to it
+		_, err = fc.db.ProcessWrite(&proto.WriteRequest{}, entry.Offset, entry.Timestamp, kv.NoOpCallback)
+	} else {
+		err = applyLogEntryValue(fc.db, entry, logEntryValue)
+	}
+	if err != nil {
+		fc.log.Error(
+			"Error applying committed entry",
+			slog.Any("error", err),
+		)
+		return err
 	}
 
 	return nil
@@ -660,13 +698,16 @@ func (fc *followerController) handleSnapshot(stream proto.OxiaLogReplication_Sen
 	}
 
 	if fc.db != nil {
+		fc.dbReaders.Lock()
 		err = fc.db.Close()
 		if err != nil {
+			fc.dbReaders.Unlock()
 			fc.closeStreamNoMutex(err)
 			return
 		}
 
 		fc.db = nil
+		fc.dbReaders.Unlock()
 	}
 
 	loader, err := fc.kvFactory.NewSnapshotLoader(fc.namespace, fc.shardId)
@@ -693,15 +734,31 @@ func (fc *followerController) handleSnapshot(stream proto.OxiaLogReplication_Sen
 
 	// The new term must be persisted, to avoid rolling it back
 	if err = newDb.UpdateTerm(fc.term, fc.termOptions); err != nil {
+		_ = newDb.Close()
 		fc.closeStreamNoMutex(errors.Wrap(err, "Failed to update term in db"))
+		return
 	}
 
 	commitOffset, err := newDb.ReadCommitOffset()
 	if err != nil {
+		_ = newDb.Close()
 		fc.closeStreamNoMutex(errors.Wrap(err, "Failed to read committed offset in the new snapshot"))
 		return
 	}
 
+	if fc.termOptions.Witness {
+		if newDb, err = fc.newWitnessDB(newDb, commitOffset); err != nil {
+			fc.closeStreamNoMutex(errors.Wrap(err, "Failed to discard the snapshot data in the witness"))
+			return
+		}
+	}
+
+	// The database is installed before responding, so that it gets
+	// closed with the follower even if the leader is gone
+	fc.db = newDb
+	fc.commitOffset.Store(commitOffset)
+	fc.lastAppendedOffset = commitOffset
+
 	if err = stream.SendAndClose(&proto.SnapshotResponse{
 		AckOffset: commitOffset,
 	}); err != nil {
@@ -709,9 +766,6 @@ func (fc *followerController) handleSnapshot(stream proto.OxiaLogReplication_Sen
 		return
 	}
 
-	fc.db = newDb
-	fc.commitOffset.Store(commitOffset)
-	fc.lastAppendedOffset = commitOffset
 	fc.closeStreamNoMutex(nil)
 
 	fc.log.Info(
@@ -722,18 +776,182 @@ func (fc *followerController) handleSnapshot(stream proto.OxiaLogReplication_Sen
 	)
 }
 
+// Replaces the database loaded from a snapshot with an empty one, since the
+// witnesses only need the commit offset, to resume the wal after it.
+func (fc *followerController) newWitnessDB(snapshotDb kv.DB, commitOffset int64) (kv.DB, error) {
+	if err := snapshotDb.Delete(); err != nil {
+		return nil, err
+	}
+
+	db, err := kv.NewDB(fc.namespace, fc.shardId, fc.kvFactory, fc.config.NotificationsRetentionTime, common.SystemClock)
+	if err != nil {
+		return nil, err
+	}
+
+	db.EnableNotifications(false)
+	if _, err = db.ProcessWrite(&proto.WriteRequest{}, commitOffset, uint64(time.Now().UnixMilli()), kv.NoOpCallback); err != nil {
+		return nil, multierr.Append(err, db.Close())
+	}
+
+	if err = db.UpdateTerm(fc.term, fc.termOptions); err != nil {
+		return nil, multierr.Append(err, db.Close())
+	}
+	return db, nil
+}
+
+func (fc *followerController) SendIngestFile(stream proto.OxiaLogReplication_SendIngestFileServer) error {
+	chunk, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	fc.Lock()
+	if fc.status != proto.ServingStatus_FENCED && fc.status != proto.ServingStatus_FOLLOWER {
+		fc.Unlock()
+		return common.ErrorInvalidStatus
+	}
+	if chunk.Term != fc.term {
+		fc.Unlock()
+		return common.ErrorInvalidTerm
+	}
+	w, err := fc.db.CreateIngestFile(chunk.Name)
+	fc.Unlock()
+	if err != nil {
+		return err
+	}
+
+	totalSize, err := receiveIngestFile(w, chunk.Content, func() ([]byte, error) {
+		chunk, err := stream.Recv()
+		return chunk.GetContent(), err
+	})
+	if err != nil {
+		return err
+	}
+
+	fc.log.Info(
+		"Staged the ingest file",
+		slog.String("file", chunk.Name),
+		slog.Int64("size", totalSize),
+	)
+	return stream.SendAndClose(&proto.IngestFileResponse{})
+}
+
+func (fc *followerController) FetchLog(request *proto.FetchLogRequest, stream proto.OxiaLogReplication_FetchLogServer) error {
+	fc.Lock()
+	if fc.status != proto.ServingStatus_FENCED {
+		fc.Unlock()
+		return common.ErrorInvalidStatus
+	}
+	if request.Term != fc.term {
+		fc.Unlock()
+		return common.ErrorInvalidTerm
+	}
+	fc.Unlock()
+
+	// The wal doesn't change while the follower is fenced, until the new
+	// leader starts replicating to it
+	reader, err := fc.wal.NewReader(request.StartOffset - 1)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for reader.HasNext() {
+		entry, err := reader.ReadNext()
+		if err != nil {
+			return err
+		}
+		if err = stream.Send(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (fc *followerController) GetStatus(_ *proto.GetStatusRequest) (*proto.GetStatusResponse, error) {
 	fc.Lock()
 	defer fc.Unlock()
 
+	var dbSize, walSize int64
+	if fc.db != nil {
+		dbSize = fc.db.DiskUsage()
+	}
+	if fc.wal != nil {
+		walSize = fc.wal.DiskUsage()
+	}
+
 	return &proto.GetStatusResponse{
 		Term:         fc.term,
 		Status:       fc.status,
 		HeadOffset:   fc.lastAppendedOffset,
 		CommitOffset: fc.CommitOffset(),
+		DbSize:       dbSize,
+		WalSize:      walSize,
 	}, nil
 }
 
+func (fc *followerController) Read(ctx context.Context, request *proto.ReadRequest) <-chan GetResult {
+	ch := make(chan GetResult)
+
+	db, err := fc.acquireObserverDB()
+	if err != nil {
+		go func() {
+			ch <- GetResult{Err: err}
+		}()
+		return ch
+	}
+
+	go func() {
+		defer fc.dbReaders.RUnlock()
+		read(ctx, fc.shardId, db, fc.log, request, ch, nil)
+	}()
+	return ch
+}
+
+func (fc *followerController) List(ctx context.Context, request *proto.ListRequest) (<-chan string, error) {
+	db, err := fc.acquireObserverDB()
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan string)
+	go func() {
+		defer fc.dbReaders.RUnlock()
+		list(ctx, fc.shardId, db, fc.log, request, ch, nil)
+	}()
+	return ch, nil
+}
+
+func (fc *followerController) RangeScan(ctx context.Context, request *proto.RangeScanRequest) (<-chan *proto.GetResponse, <-chan error, error) {
+	db, err := fc.acquireObserverDB()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan *proto.GetResponse)
+	errCh := make(chan error)
+	go func() {
+		defer fc.dbReaders.RUnlock()
+		rangeScan(ctx, fc.shardId, db, fc.log, request, ch, errCh, nil)
+	}()
+	return ch, errCh, nil
+}
+
+// Returns the database, holding the read lock on it, when the node is an
+// observer of the shard. Clients only send reads to observers when they
+// have opted in for stale reads.
+func (fc *followerController) acquireObserverDB() (kv.DB, error) {
+	fc.Lock()
+	defer fc.Unlock()
+
+	if !fc.termOptions.Observer || fc.db == nil {
+		return nil, common.ErrorNodeIsNotLeader
+	}
+
+	fc.dbReaders.RLock()
+	return fc.db, nil
+}
+
 func (fc *followerController) DeleteShard(request *proto.DeleteShardRequest) (*proto.DeleteShardResponse, error) {
 	fc.cancel()
 	<-fc.applyEntriesDone
@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/status"
 	pb "google.golang.org/protobuf/proto"
@@ -1005,12 +1006,213 @@ func TestFollower_GetStatus(t *testing.T) {
 
 	res, err := fc.GetStatus(&proto.GetStatusRequest{Shard: shardId})
 	assert.NoError(t, err)
-	assert.Equal(t, &proto.GetStatusResponse{
-		Term:         2,
-		Status:       proto.ServingStatus_FOLLOWER,
-		HeadOffset:   2,
-		CommitOffset: 1,
-	}, res)
+	assert.EqualValues(t, 2, res.Term)
+	assert.Equal(t, proto.ServingStatus_FOLLOWER, res.Status)
+	assert.EqualValues(t, 2, res.HeadOffset)
+	assert.EqualValues(t, 1, res.CommitOffset)
+	assert.Zero(t, res.WriteOps)
+	assert.Zero(t, res.ReadOps)
+	assert.Positive(t, res.WalSize)
+
+	assert.NoError(t, fc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
+
+func TestFollower_ObserverReads(t *testing.T) {
+	var shardId int64
+	kvFactory, err := kv.NewPebbleKVFactory(&kv.FactoryOptions{
+		DataDir:     t.TempDir(),
+		CacheSizeMB: 1,
+	})
+	assert.NoError(t, err)
+	walFactory := wal.NewWalFactory(&wal.FactoryOptions{
+		BaseWalDir: t.TempDir(),
+	})
+
+	fc, _ := NewFollowerController(Config{}, common.DefaultNamespace, shardId, walFactory, kvFactory)
+	_, _ = fc.NewTerm(&proto.NewTermRequest{Term: 1})
+
+	// Regular followers are not serving reads
+	r := <-fc.Read(context.Background(), &proto.ReadRequest{
+		Shard: &shardId,
+		Gets:  []*proto.GetRequest{{Key: "a", IncludeValue: true}},
+	})
+	assert.ErrorIs(t, r.Err, common.ErrorNodeIsNotLeader)
+
+	_, err = fc.NewTerm(&proto.NewTermRequest{Term: 2, Options: &proto.NewTermOptions{
+		EnableNotifications: true,
+		Observer:            true,
+	}})
+	assert.NoError(t, err)
+
+	stream := newMockServerReplicateStream()
+	go func() {
+		// cancelled due to fc.Close() below
+		assert.ErrorIs(t, fc.Replicate(stream), context.Canceled)
+	}()
+
+	stream.AddRequest(createAddRequest(t, 2, 0, map[string]string{"a": "0", "b": "1"}, wal.InvalidOffset))
+	stream.AddRequest(createAddRequest(t, 2, 1, map[string]string{"c": "2"}, 0))
+	stream.GetResponse()
+	stream.GetResponse()
+
+	assert.Eventually(t, func() bool {
+		return fc.CommitOffset() == 0
+	}, 10*time.Second, 100*time.Millisecond)
+
+	r = <-fc.Read(context.Background(), &proto.ReadRequest{
+		Shard: &shardId,
+		Gets:  []*proto.GetRequest{{Key: "a", IncludeValue: true}},
+	})
+	assert.NoError(t, r.Err)
+	assert.Equal(t, []byte("0"), r.Response.Value)
+
+	// The entries that are not yet committed are not visible
+	ch, err := fc.List(context.Background(), &proto.ListRequest{
+		Shard:          &shardId,
+		StartInclusive: "a",
+		EndExclusive:   "z",
+	})
+	assert.NoError(t, err)
+	var keys []string
+	for k := range ch {
+		keys = append(keys, k)
+	}
+	assert.Equal(t, []string{"a", "b"}, keys)
+	assert.NoError(t, fc.Close())
+
+	// The observer role is retained across restarts
+	fc, _ = NewFollowerController(Config{}, common.DefaultNamespace, shardId, walFactory, kvFactory)
+	r = <-fc.Read(context.Background(), &proto.ReadRequest{
+		Shard: &shardId,
+		Gets:  []*proto.GetRequest{{Key: "b", IncludeValue: true}},
+	})
+	assert.NoError(t, r.Err)
+	assert.Equal(t, []byte("1"), r.Response.Value)
+
+	assert.NoError(t, fc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
+
+func TestFollower_Witness(t *testing.T) {
+	var shardId int64
+	kvFactory, err := kv.NewPebbleKVFactory(&kv.FactoryOptions{
+		DataDir:     t.TempDir(),
+		CacheSizeMB: 1,
+	})
+	assert.NoError(t, err)
+	walFactory := wal.NewWalFactory(&wal.FactoryOptions{
+		BaseWalDir: t.TempDir(),
+	})
+
+	fc, _ := NewFollowerController(Config{}, common.DefaultNamespace, shardId, walFactory, kvFactory)
+	_, err = fc.NewTerm(&proto.NewTermRequest{Term: 1, Options: &proto.NewTermOptions{
+		EnableNotifications: true,
+		Witness:             true,
+	}})
+	assert.NoError(t, err)
+
+	stream := newMockServerReplicateStream()
+	go func() {
+		// cancelled due to fc.NewTerm() below
+		_ = fc.Replicate(stream)
+	}()
+
+	stream.AddRequest(createAddRequest(t, 1, 0, map[string]string{"a": "0"}, wal.InvalidOffset))
+	stream.AddRequest(createAddRequest(t, 1, 1, map[string]string{"b": "1"}, 0))
+	stream.AddRequest(createAddRequest(t, 1, 2, map[string]string{"c": "2"}, 1))
+	for i := int64(0); i < 3; i++ {
+		assert.Equal(t, i, stream.GetResponse().Offset)
+	}
+
+	assert.Eventually(t, func() bool {
+		return fc.CommitOffset() == 1
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// The committed entries are not applied to the database
+	db := fc.(*followerController).db
+	commitOffset, err := db.ReadCommitOffset()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, commitOffset)
+	dbRes, err := db.Get(&proto.GetRequest{Key: "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, proto.Status_KEY_NOT_FOUND, dbRes.Status)
+
+	// The wal can only be fetched once the witness is fenced
+	fetchStream := &mockServerFetchLogStream{}
+	err = fc.FetchLog(&proto.FetchLogRequest{Term: 1, StartOffset: 1}, fetchStream)
+	assert.ErrorIs(t, err, common.ErrorInvalidStatus)
+
+	_, err = fc.NewTerm(&proto.NewTermRequest{Term: 2, Options: &proto.NewTermOptions{Witness: true}})
+	assert.NoError(t, err)
+
+	err = fc.FetchLog(&proto.FetchLogRequest{Term: 1, StartOffset: 1}, fetchStream)
+	assert.ErrorIs(t, err, common.ErrorInvalidTerm)
+
+	assert.NoError(t, fc.FetchLog(&proto.FetchLogRequest{Term: 2, StartOffset: 1}, fetchStream))
+	assert.Len(t, fetchStream.entries, 2)
+	assert.EqualValues(t, 1, fetchStream.entries[0].Offset)
+	assert.EqualValues(t, 2, fetchStream.entries[1].Offset)
+
+	assert.NoError(t, fc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
+
+func TestFollower_WitnessSnapshot(t *testing.T) {
+	var shardId int64
+	kvFactory, err := kv.NewPebbleKVFactory(&kv.FactoryOptions{
+		DataDir: t.TempDir(),
+	})
+	assert.NoError(t, err)
+	walFactory := wal.NewWalFactory(&wal.FactoryOptions{BaseWalDir: t.TempDir()})
+
+	fc, err := NewFollowerController(Config{}, common.DefaultNamespace, shardId, walFactory, kvFactory)
+	assert.NoError(t, err)
+
+	_, err = fc.NewTerm(&proto.NewTermRequest{Term: 1, Options: &proto.NewTermOptions{Witness: true}})
+	assert.NoError(t, err)
+
+	snapshot := prepareTestDb(t)
+	snapshotStream := newMockServerSendSnapshotStream()
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		assert.NoError(t, fc.SendSnapshot(snapshotStream))
+		wg.Done()
+	}()
+
+	for ; snapshot.Valid(); snapshot.Next() {
+		chunk, err := snapshot.Chunk()
+		assert.NoError(t, err)
+		snapshotStream.AddChunk(&proto.SnapshotChunk{
+			Term:       1,
+			Name:       chunk.Name(),
+			Content:    chunk.Content(),
+			ChunkIndex: chunk.Index(),
+			ChunkCount: chunk.TotalCount(),
+		})
+	}
+
+	close(snapshotStream.chunks)
+	wg.Wait()
+
+	assert.EqualValues(t, 99, snapshotStream.GetResponse().AckOffset)
+	assert.EqualValues(t, 99, fc.CommitOffset())
+
+	// Only the commit offset of the snapshot is retained
+	dbRes, err := fc.(*followerController).db.Get(&proto.GetRequest{Key: "key-0"})
+	assert.NoError(t, err)
+	assert.Equal(t, proto.Status_KEY_NOT_FOUND, dbRes.Status)
+	assert.NoError(t, fc.Close())
+
+	fc, err = NewFollowerController(Config{}, common.DefaultNamespace, shardId, walFactory, kvFactory)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, fc.Term())
+	assert.EqualValues(t, 99, fc.CommitOffset())
 
 	assert.NoError(t, fc.Close())
 	assert.NoError(t, kvFactory.Close())
@@ -1088,6 +1290,68 @@ func TestFollower_HandleSnapshotWithWrongTerm(t *testing.T) {
 	assert.NoError(t, walFactory.Close())
 }
 
+func TestFollower_HandleSnapshotResponseFailure(t *testing.T) {
+	var shardId int64
+	kvFactory, err := kv.NewPebbleKVFactory(&kv.FactoryOptions{
+		DataDir: t.TempDir(),
+	})
+	assert.NoError(t, err)
+	walFactory := newTestWalFactory(t)
+
+	fc, err := NewFollowerController(Config{}, common.DefaultNamespace, shardId, walFactory, kvFactory)
+	assert.NoError(t, err)
+
+	_, err = fc.NewTerm(&proto.NewTermRequest{Term: 1})
+	assert.NoError(t, err)
+
+	snapshot := prepareTestDb(t)
+
+	// The leader is gone by the time the snapshot is loaded
+	snapshotStream := newMockServerSendSnapshotStream()
+	snapshotStream.sendErr = errors.New("stream closed")
+
+	wg := common.NewWaitGroup(1)
+
+	go func() {
+		err := fc.SendSnapshot(snapshotStream)
+		if err != nil {
+			wg.Fail(err)
+		} else {
+			wg.Done()
+		}
+	}()
+
+	for ; snapshot.Valid(); snapshot.Next() {
+		chunk, err := snapshot.Chunk()
+		assert.NoError(t, err)
+		snapshotStream.AddChunk(&proto.SnapshotChunk{
+			Term:       1,
+			Name:       chunk.Name(),
+			Content:    chunk.Content(),
+			ChunkIndex: chunk.Index(),
+			ChunkCount: chunk.TotalCount(),
+		})
+	}
+
+	close(snapshotStream.chunks)
+
+	assert.Error(t, wg.Wait(context.Background()))
+
+	// The database is still installed in the follower, so that it gets
+	// closed together with it, instead of being leaked
+	dbRes, err := fc.(*followerController).db.Get(&proto.GetRequest{
+		Key:          "key-0",
+		IncludeValue: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, proto.Status_OK, dbRes.Status)
+	assert.EqualValues(t, 99, fc.CommitOffset())
+
+	assert.NoError(t, fc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
+
 func closeChanIsNotNil(fc FollowerController) func() bool {
 	return func() bool {
 		_fc := fc.(*followerController)
@@ -1126,3 +1390,39 @@ func createAddRequest(t *testing.T, term int64, offset int64,
 		CommitOffset: commitOffset,
 	}
 }
+
+func TestApplyLogEntryValue_WriteUsage(t *testing.T) {
+	kvFactory, err := kv.NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	db, err := kv.NewDB(common.DefaultNamespace, 0, kvFactory, 1*time.Hour, common.SystemClock)
+	assert.NoError(t, err)
+	assert.NoError(t, db.UpdateTerm(1, kv.TermOptions{Quota: &proto.ShardQuota{MaxKeys: 100}}))
+
+	assert.NoError(t, applyLogEntryValue(db, &proto.LogEntry{Offset: 0}, &proto.LogEntryValue{
+		Value: &proto.LogEntryValue_Requests{Requests: &proto.WriteRequests{
+			Writes: []*proto.WriteRequest{{
+				Puts: []*proto.PutRequest{{Key: "a", Value: []byte("0")}, {Key: "b", Value: []byte("0")}},
+			}},
+		}},
+	}))
+	assert.Equal(t, kv.Usage{Keys: 2, Bytes: 4}, db.Usage())
+
+	// The follower applies the usage of the range replicated by the leader,
+	// without scanning the range
+	assert.NoError(t, applyLogEntryValue(db, &proto.LogEntry{Offset: 1}, &proto.LogEntryValue{
+		Value: &proto.LogEntryValue_Requests{Requests: &proto.WriteRequests{
+			Writes: []*proto.WriteRequest{
+				{Puts: []*proto.PutRequest{{Key: "c", Value: []byte("0")}}},
+				{DeleteRanges: []*proto.DeleteRangeRequest{{StartInclusive: "a", EndExclusive: "b"}}},
+			},
+			Usages: []*proto.WriteUsage{
+				{},
+				{DeleteRanges: []*proto.RecordsUsage{{Keys: 1, Bytes: 2}}},
+			},
+		}},
+	}))
+	assert.Equal(t, kv.Usage{Keys: 2, Bytes: 4}, db.Usage())
+
+	assert.NoError(t, db.Close())
+	assert.NoError(t, kvFactory.Close())
+}
@@ -42,6 +42,41 @@ import (
 type ReplicateStreamProvider interface {
 	GetReplicateStream(ctx context.Context, follower string, namespace string, shard int64, term int64) (proto.OxiaLogReplication_ReplicateClient, error)
 	SendSnapshot(ctx context.Context, follower string, namespace string, shard int64, term int64) (proto.OxiaLogReplication_SendSnapshotClient, error)
+	SendIngestFile(ctx context.Context, follower string, namespace string, shard int64, term int64) (proto.OxiaLogReplication_SendIngestFileClient, error)
+}
+
+// FollowerRole is the part that a follower has in the replication of the shard.
+type FollowerRole int
+
+const (
+	// FollowerRoleFull keeps a full copy of the shard and counts towards the quorum.
+	FollowerRoleFull FollowerRole = iota
+	// FollowerRoleObserver keeps a full copy of the shard, outside the quorum.
+	FollowerRoleObserver
+	// FollowerRoleWitness only keeps the wal, though it counts towards the quorum.
+	FollowerRoleWitness
+)
+
+func (r FollowerRole) String() string {
+	switch r {
+	case FollowerRoleObserver:
+		return "observer"
+	case FollowerRoleWitness:
+		return "witness"
+	default:
+		return "full"
+	}
+}
+
+func followerRoleOf(req *proto.AddFollowerRequest) FollowerRole {
+	switch {
+	case req.Observer:
+		return FollowerRoleObserver
+	case req.Witness:
+		return FollowerRoleWitness
+	default:
+		return FollowerRoleFull
+	}
 }
 
 // FollowerCursor
@@ -65,6 +100,7 @@ type followerCursor struct {
 
 	term                    int64
 	follower                string
+	role                    FollowerRole
 	replicateStreamProvider ReplicateStreamProvider
 	stream                  proto.OxiaLogReplication_ReplicateClient
 
@@ -74,8 +110,11 @@ type followerCursor struct {
 	db          kv.DB
 	lastPushed  atomic.Int64
 	ackOffset   atomic.Int64
-	namespace   string
-	shardId     int64
+
+	// Set when the follower can only catch up with a snapshot
+	snapshotRequired atomic.Bool
+	namespace        string
+	shardId          int64
 
 	backoff backoff.BackOff
 	closed  atomic.Bool
@@ -99,7 +138,8 @@ func NewFollowerCursor( //nolint:revive
 	ackTracker QuorumAckTracker,
 	walObject wal.Wal,
 	db kv.DB,
-	ackOffset int64) (FollowerCursor, error) {
+	ackOffset int64,
+	role FollowerRole) (FollowerCursor, error) {
 	labels := map[string]any{
 		"namespace": namespace,
 		"shard":     shardId,
@@ -109,6 +149,7 @@ func NewFollowerCursor( //nolint:revive
 	fc := &followerCursor{
 		term:                    term,
 		follower:                follower,
+		role:                    role,
 		ackTracker:              ackTracker,
 		replicateStreamProvider: replicateStreamProvider,
 		wal:                     walObject,
@@ -122,6 +163,7 @@ func NewFollowerCursor( //nolint:revive
 			slog.Int64("shard", shardId),
 			slog.Int64("term", term),
 			slog.String("follower", follower),
+			slog.Any("role", role),
 		),
 
 		snapshotsTransferTime: metrics.NewLatencyHistogram("oxia_server_snapshots_transfer_time",
@@ -143,7 +185,12 @@ func NewFollowerCursor( //nolint:revive
 	fc.ackOffset.Store(ackOffset)
 
 	var err error
-	if fc.cursorAcker, err = ackTracker.NewCursorAcker(ackOffset); err != nil {
+	if role == FollowerRoleObserver {
+		fc.cursorAcker, err = ackTracker.NewObserverAcker(ackOffset)
+	} else {
+		fc.cursorAcker, err = ackTracker.NewCursorAcker(ackOffset)
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -169,7 +216,13 @@ func (fc *followerCursor) shouldSendSnapshot() bool {
 	ackOffset := fc.ackOffset.Load()
 	walFirstOffset := fc.wal.FirstOffset()
 
-	if ackOffset == wal.InvalidOffset && fc.ackTracker.CommitOffset() >= 0 {
+	if fc.snapshotRequired.Load() {
+		fc.log.Info(
+			"The follower is behind an ingested sstable that is no longer staged",
+			slog.Int64("follower-ack-offset", ackOffset),
+		)
+		return true
+	} else if ackOffset == wal.InvalidOffset && fc.ackTracker.CommitOffset() >= 0 {
 		fc.log.Info(
 			"Sending snapshot to empty follower",
 			slog.Int64("follower-ack-offset", ackOffset),
@@ -315,6 +368,7 @@ func (fc *followerCursor) sendSnapshot() error {
 		slog.Int64("follower-ack-offset", response.AckOffset),
 	)
 	fc.ackOffset.Store(response.AckOffset)
+	fc.snapshotRequired.Store(false)
 	fc.snapshotsCompletedCounter.Inc()
 	return nil
 }
@@ -345,6 +399,10 @@ func (fc *followerCursor) streamEntriesLoop(ctx context.Context, reader wal.Read
 			slog.Int64("offset", le.Offset),
 		)
 
+		if err = fc.sendIngestFileOf(ctx, le); err != nil {
+			return err
+		}
+
 		if err = fc.stream.Send(&proto.Append{
 			Term:         fc.term,
 			Entry:        le,
@@ -358,6 +416,71 @@ func (fc *followerCursor) streamEntriesLoop(ctx context.Context, reader wal.Read
 	}
 }
 
+// Sends the sstable of an ingest entry, which must be staged on the follower
+// before the entry is appended. The witnesses don't apply the entries, so
+// they don't need it.
+func (fc *followerCursor) sendIngestFileOf(ctx context.Context, le *proto.LogEntry) error {
+	if fc.role == FollowerRoleWitness {
+		return nil
+	}
+
+	ingest, err := ingestRequestOf(le.Value)
+	if err != nil || ingest == nil {
+		return err
+	}
+
+	f, err := fc.db.OpenIngestFile(ingest.FileName)
+	if errors.Is(err, kv.ErrIngestFileNotFound) {
+		// The leader has already ingested the sstable and removed it
+		fc.snapshotRequired.Store(true)
+		return err
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stream, err := fc.replicateStreamProvider.SendIngestFile(ctx, fc.follower, fc.namespace, fc.shardId, fc.term)
+	if err != nil {
+		return err
+	}
+
+	var totalSize int64
+	for chunkIndex := int32(0); ; chunkIndex++ {
+		// The messages can't be modified after they're sent
+		content := make([]byte, kv.MaxSnapshotChunkSize)
+		n, err := io.ReadFull(f, content)
+		if n > 0 {
+			if err := stream.Send(&proto.SnapshotChunk{
+				Term:       fc.term,
+				Name:       ingest.FileName,
+				ChunkIndex: chunkIndex,
+				Content:    content[:n],
+			}); err != nil {
+				return err
+			}
+			totalSize += int64(n)
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if _, err = stream.CloseAndRecv(); err != nil {
+		return err
+	}
+
+	fc.log.Info(
+		"Sent the ingest file to follower",
+		slog.Int64("offset", le.Offset),
+		slog.String("file", ingest.FileName),
+		slog.String("size", humanize.IBytes(uint64(totalSize))),
+	)
+	return nil
+}
+
 func (fc *followerCursor) streamEntries() error {
 	ctx, cancel := context.WithCancel(fc.ctx)
 	defer cancel()
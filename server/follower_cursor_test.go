@@ -51,7 +51,7 @@ func TestFollowerCursor(t *testing.T) {
 	assert.NoError(t, err)
 	slog.Info("Appended entry 0 to the log")
 
-	fc, err := NewFollowerCursor("f1", term, common.DefaultNamespace, shard, stream, ackTracker, w, db, wal.InvalidOffset)
+	fc, err := NewFollowerCursor("f1", term, common.DefaultNamespace, shard, stream, ackTracker, w, db, wal.InvalidOffset, FollowerRoleFull)
 	assert.NoError(t, err)
 
 	time.Sleep(10 * time.Millisecond)
@@ -155,7 +155,7 @@ func TestFollowerCursor_SendSnapshot(t *testing.T) {
 
 	ackTracker := NewQuorumAckTracker(3, n-1, n-1)
 
-	fc, err := NewFollowerCursor("f1", term, common.DefaultNamespace, shard, stream, ackTracker, w, db, wal.InvalidOffset)
+	fc, err := NewFollowerCursor("f1", term, common.DefaultNamespace, shard, stream, ackTracker, w, db, wal.InvalidOffset, FollowerRoleFull)
 	assert.NoError(t, err)
 
 	s := stream.sendSnapshotStream
@@ -0,0 +1,336 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"cmp"
+	"container/heap"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/proto"
+)
+
+const (
+	// The sampled operations are counted in windows of this duration. The hot
+	// keys are the ones of the current and of the previous window
+	hotKeysWindow = time.Minute
+
+	// The max number of keys, and of prefixes, counted in a window
+	hotKeysMaxTracked = 10_000
+
+	// The number of hot keys returned when the request has no limit
+	defaultHotKeysLimit = 10
+)
+
+type hotKeyCounts struct {
+	reads  int64
+	writes int64
+}
+
+func (c *hotKeyCounts) add(write bool) {
+	if write {
+		c.writes++
+	} else {
+		c.reads++
+	}
+}
+
+func (c *hotKeyCounts) total() int64 {
+	return c.reads + c.writes
+}
+
+// hotKeyCounter is a tracked key, with the position in the heap of the keys.
+type hotKeyCounter struct {
+	hotKeyCounts
+
+	key string
+
+	// The count of the evicted key that this one replaced, which is the most
+	// operations that the key could have had before being tracked
+	overestimate int64
+	index        int
+}
+
+func (c *hotKeyCounter) count() int64 {
+	return c.total() + c.overestimate
+}
+
+// hotKeysHeap is a min-heap of the tracked keys by their count.
+type hotKeysHeap []*hotKeyCounter
+
+func (h hotKeysHeap) Len() int {
+	return len(h)
+}
+
+func (h hotKeysHeap) Less(i, j int) bool {
+	return h[i].count() < h[j].count()
+}
+
+func (h hotKeysHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *hotKeysHeap) Push(x any) {
+	c := x.(*hotKeyCounter)
+	c.index = len(*h)
+	*h = append(*h, c)
+}
+
+func (h *hotKeysHeap) Pop() any {
+	old := *h
+	n := len(old)
+	c := old[n-1]
+	*h = old[0 : n-1]
+	return c
+}
+
+// hotKeyCounters counts the operations of at most hotKeysMaxTracked keys with
+// the space-saving algorithm: when a new key doesn't fit, it replaces the key
+// with the fewest operations and inherits its count. The keys with more than
+// 1/hotKeysMaxTracked of the operations are never evicted.
+type hotKeyCounters struct {
+	counters map[string]*hotKeyCounter
+	heap     hotKeysHeap
+}
+
+func newHotKeyCounters() *hotKeyCounters {
+	return &hotKeyCounters{
+		counters: map[string]*hotKeyCounter{},
+	}
+}
+
+// Counts an operation on the key, in O(log n) of the tracked keys.
+func (h *hotKeyCounters) add(key string, write bool) {
+	c, ok := h.counters[key]
+	switch {
+	case ok:
+	case len(h.heap) < hotKeysMaxTracked:
+		c = &hotKeyCounter{key: key}
+		h.counters[key] = c
+		heap.Push(&h.heap, c)
+	default:
+		c = h.heap[0]
+		delete(h.counters, c.key)
+		*c = hotKeyCounter{key: key, overestimate: c.count(), index: c.index}
+		h.counters[key] = c
+	}
+
+	c.add(write)
+	heap.Fix(&h.heap, c.index)
+}
+
+// hotKeysSample are the operations sampled in a window.
+type hotKeysSample struct {
+	keys     *hotKeyCounters
+	prefixes *hotKeyCounters
+	total    hotKeyCounts
+}
+
+func newHotKeysSample() *hotKeysSample {
+	return &hotKeysSample{
+		keys:     newHotKeyCounters(),
+		prefixes: newHotKeyCounters(),
+	}
+}
+
+func (s *hotKeysSample) add(key string, write bool) {
+	s.total.add(write)
+	s.keys.add(key, write)
+	if prefix, ok := keyPrefix(key); ok {
+		s.prefixes.add(prefix, write)
+	}
+}
+
+// The prefix of a key is the part up to its last '/', included. The keys
+// with no '/' after the first character have no prefix.
+func keyPrefix(key string) (string, bool) {
+	if i := strings.LastIndexByte(key, '/'); i > 0 {
+		return key[:i+1], true
+	}
+	return "", false
+}
+
+// hotKeyTracker samples the keys of the reads and of the writes of a shard,
+// to find whether the traffic goes mostly to a few keys or prefixes. A nil
+// tracker doesn't sample anything.
+type hotKeyTracker struct {
+	sync.Mutex
+
+	sampleRate  float64
+	current     *hotKeysSample
+	previous    *hotKeysSample
+	windowStart time.Time
+	now         func() time.Time
+	random      func() float64
+}
+
+func newHotKeyTracker(sampleRate float64) *hotKeyTracker {
+	if sampleRate <= 0 {
+		return nil
+	}
+
+	return &hotKeyTracker{
+		sampleRate:  min(sampleRate, 1),
+		current:     newHotKeysSample(),
+		previous:    newHotKeysSample(),
+		windowStart: time.Now(),
+		now:         time.Now,
+		random:      rand.Float64,
+	}
+}
+
+func (t *hotKeyTracker) recordRead(request *proto.ReadRequest) {
+	if t == nil {
+		return
+	}
+
+	for _, get := range request.Gets {
+		t.record(get.Key, false)
+	}
+}
+
+// recordWrite samples the puts and the deletes of the request. The delete
+// ranges are not counted, as they don't have a single key.
+func (t *hotKeyTracker) recordWrite(request *proto.WriteRequest) {
+	if t == nil {
+		return
+	}
+
+	for _, put := range request.Puts {
+		t.record(put.Key, true)
+	}
+	for _, del := range request.Deletes {
+		t.record(del.Key, true)
+	}
+}
+
+func (t *hotKeyTracker) record(key string, write bool) {
+	if t.random() >= t.sampleRate || strings.HasPrefix(key, common.InternalKeyPrefix) {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	t.rotate()
+	t.current.add(key, write)
+}
+
+// Starts a new window when the current one is over. It must be called with
+// the lock.
+func (t *hotKeyTracker) rotate() {
+	now := t.now()
+	elapsed := now.Sub(t.windowStart)
+	if elapsed < hotKeysWindow {
+		return
+	}
+
+	if elapsed < 2*hotKeysWindow {
+		t.previous = t.current
+	} else {
+		t.previous = newHotKeysSample()
+	}
+	t.current = newHotKeysSample()
+	t.windowStart = now
+}
+
+// hotKeys returns the keys and the prefixes with the most operations in the
+// current and in the previous window, with the estimated number of operations.
+func (t *hotKeyTracker) hotKeys(limit int) *proto.GetHotKeysResponse {
+	if limit <= 0 {
+		limit = defaultHotKeysLimit
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	t.rotate()
+
+	total := t.estimate(hotKeyCounts{
+		reads:  t.previous.total.reads + t.current.total.reads,
+		writes: t.previous.total.writes + t.current.total.writes,
+	})
+	return &proto.GetHotKeysResponse{
+		Keys:     t.top(t.previous.keys, t.current.keys, limit),
+		Prefixes: t.top(t.previous.prefixes, t.current.prefixes, limit),
+		Reads:    total.reads,
+		Writes:   total.writes,
+	}
+}
+
+// hottestShares returns the percentage of the sampled operations that went to
+// the hottest key and to the hottest prefix.
+func (t *hotKeyTracker) hottestShares() (key int64, prefix int64) {
+	if t == nil {
+		return 0, 0
+	}
+
+	response := t.hotKeys(1)
+	total := response.Reads + response.Writes
+	if total == 0 {
+		return 0, 0
+	}
+	if len(response.Keys) > 0 {
+		key = 100 * (response.Keys[0].Reads + response.Keys[0].Writes) / total
+	}
+	if len(response.Prefixes) > 0 {
+		prefix = 100 * (response.Prefixes[0].Reads + response.Prefixes[0].Writes) / total
+	}
+	return key, prefix
+}
+
+// The operations are the ones counted since the keys were tracked, without
+// the inherited counts of the evicted keys, so they are never overestimated.
+func (t *hotKeyTracker) top(previous *hotKeyCounters, current *hotKeyCounters, limit int) []*proto.HotKey {
+	merged := make(map[string]hotKeyCounts, len(previous.counters)+len(current.counters))
+	for _, counters := range []*hotKeyCounters{previous, current} {
+		for key, c := range counters.counters {
+			m := merged[key]
+			m.reads += c.reads
+			m.writes += c.writes
+			merged[key] = m
+		}
+	}
+
+	hotKeys := make([]*proto.HotKey, 0, len(merged))
+	for key, c := range merged {
+		estimated := t.estimate(c)
+		hotKeys = append(hotKeys, &proto.HotKey{
+			Key:    key,
+			Reads:  estimated.reads,
+			Writes: estimated.writes,
+		})
+	}
+	slices.SortFunc(hotKeys, func(a, b *proto.HotKey) int {
+		if c := cmp.Compare(b.Reads+b.Writes, a.Reads+a.Writes); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return hotKeys[:min(limit, len(hotKeys))]
+}
+
+// The estimated number of operations, from the sampled ones.
+func (t *hotKeyTracker) estimate(sampled hotKeyCounts) hotKeyCounts {
+	return hotKeyCounts{
+		reads:  int64(float64(sampled.reads) / t.sampleRate),
+		writes: int64(float64(sampled.writes) / t.sampleRate),
+	}
+}
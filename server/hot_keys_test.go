@@ -0,0 +1,144 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/streamnative/oxia/proto"
+)
+
+func newTestHotKeyTracker(sampleRate float64) (*hotKeyTracker, *time.Time) {
+	now := time.Unix(1000, 0)
+	t := newHotKeyTracker(sampleRate)
+	t.now = func() time.Time { return now }
+	t.windowStart = now
+	t.random = func() float64 { return 0 }
+	return t, &now
+}
+
+func TestHotKeyTracker_Disabled(t *testing.T) {
+	tracker := newHotKeyTracker(0)
+	assert.Nil(t, tracker)
+
+	tracker.recordRead(&proto.ReadRequest{Gets: []*proto.GetRequest{{Key: "/a"}}})
+	tracker.recordWrite(&proto.WriteRequest{Puts: []*proto.PutRequest{{Key: "/a"}}})
+	key, prefix := tracker.hottestShares()
+	assert.EqualValues(t, 0, key)
+	assert.EqualValues(t, 0, prefix)
+}
+
+func TestHotKeyTracker_HotKeys(t *testing.T) {
+	tracker, _ := newTestHotKeyTracker(0.5)
+
+	for i := 0; i < 6; i++ {
+		tracker.recordRead(&proto.ReadRequest{Gets: []*proto.GetRequest{{Key: "/users/hot"}}})
+	}
+	tracker.recordWrite(&proto.WriteRequest{
+		Puts:    []*proto.PutRequest{{Key: "/users/hot"}, {Key: "/users/a"}, {Key: "/orders/1"}},
+		Deletes: []*proto.DeleteRequest{{Key: "/orders/2"}, {Key: "no-prefix"}},
+		// The internal keys and the ranges are not counted
+		DeleteRanges: []*proto.DeleteRangeRequest{{StartInclusive: "/a", EndExclusive: "/b"}},
+	})
+	tracker.recordWrite(&proto.WriteRequest{Puts: []*proto.PutRequest{{Key: "__oxia/session/1"}}})
+
+	res := tracker.hotKeys(2)
+	assert.EqualValues(t, 12, res.Reads)
+	assert.EqualValues(t, 10, res.Writes)
+	assert.Equal(t, []*proto.HotKey{
+		{Key: "/users/hot", Reads: 12, Writes: 2},
+		{Key: "/orders/1", Writes: 2},
+	}, res.Keys)
+	assert.Equal(t, []*proto.HotKey{
+		{Key: "/users/", Reads: 12, Writes: 4},
+		{Key: "/orders/", Writes: 4},
+	}, res.Prefixes)
+
+	key, prefix := tracker.hottestShares()
+	assert.EqualValues(t, 63, key)
+	assert.EqualValues(t, 72, prefix)
+}
+
+func TestHotKeyTracker_Sampling(t *testing.T) {
+	tracker, _ := newTestHotKeyTracker(0.1)
+	tracker.random = func() float64 { return 0.1 }
+
+	tracker.recordRead(&proto.ReadRequest{Gets: []*proto.GetRequest{{Key: "/a"}}})
+	res := tracker.hotKeys(0)
+	assert.EqualValues(t, 0, res.Reads)
+	assert.Empty(t, res.Keys)
+}
+
+func TestHotKeyTracker_Windows(t *testing.T) {
+	tracker, now := newTestHotKeyTracker(1)
+	read := func(key string) {
+		tracker.recordRead(&proto.ReadRequest{Gets: []*proto.GetRequest{{Key: key}}})
+	}
+
+	read("/a")
+	*now = now.Add(hotKeysWindow)
+	read("/b")
+	read("/b")
+
+	// The previous window is still counted
+	res := tracker.hotKeys(10)
+	assert.Equal(t, []*proto.HotKey{{Key: "/b", Reads: 2}, {Key: "/a", Reads: 1}}, res.Keys)
+
+	*now = now.Add(hotKeysWindow)
+	res = tracker.hotKeys(10)
+	assert.Equal(t, []*proto.HotKey{{Key: "/b", Reads: 2}}, res.Keys)
+
+	// After an idle period, all the counts are discarded
+	*now = now.Add(2 * hotKeysWindow)
+	res = tracker.hotKeys(10)
+	assert.Empty(t, res.Keys)
+	assert.EqualValues(t, 0, res.Reads)
+}
+
+func TestHotKeyTracker_MaxTracked(t *testing.T) {
+	tracker, _ := newTestHotKeyTracker(1)
+	read := func(key string) {
+		tracker.recordRead(&proto.ReadRequest{Gets: []*proto.GetRequest{{Key: key}}})
+	}
+
+	for i := 0; i < 3; i++ {
+		read("/hot")
+	}
+	for i := 0; i < hotKeysMaxTracked-1; i++ {
+		read(fmt.Sprintf("/key-%d", i))
+		read(fmt.Sprintf("/key-%d", i))
+	}
+	assert.Len(t, tracker.current.keys.counters, hotKeysMaxTracked)
+
+	// A new key replaces one of the keys with the fewest operations
+	read("/new")
+	assert.Len(t, tracker.current.keys.counters, hotKeysMaxTracked)
+	assert.Contains(t, tracker.current.keys.counters, "/new")
+
+	// A new key that gets hot is tracked, even though all the other keys
+	// were sampled more than once
+	for i := 0; i < 10; i++ {
+		read("/hotter")
+	}
+	assert.Len(t, tracker.current.keys.counters, hotKeysMaxTracked)
+
+	res := tracker.hotKeys(2)
+	assert.Equal(t, []*proto.HotKey{{Key: "/hotter", Reads: 10}, {Key: "/hot", Reads: 3}}, res.Keys)
+	assert.EqualValues(t, 2*hotKeysMaxTracked+12, res.Reads)
+}
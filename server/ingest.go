@@ -0,0 +1,87 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/kv"
+)
+
+// The field number of the ingest request in proto.LogEntryValue.
+const logEntryValueIngestField = 2
+
+// Returns the ingest request of a log entry value, or nil if it has write
+// requests. Only the value of the ingest entries is decoded.
+func ingestRequestOf(value []byte) (*proto.IngestRequest, error) {
+	if num, _, n := protowire.ConsumeTag(value); n < 0 || num != logEntryValueIngestField {
+		return nil, nil //nolint:nilnil
+	}
+
+	logEntryValue := &proto.LogEntryValue{}
+	if err := logEntryValue.UnmarshalVT(value); err != nil {
+		return nil, err
+	}
+	return logEntryValue.GetIngest(), nil
+}
+
+// Applies the write requests or the ingest request of a log entry.
+func applyLogEntryValue(db kv.DB, entry *proto.LogEntry, logEntryValue *proto.LogEntryValue) error {
+	if ingest := logEntryValue.GetIngest(); ingest != nil {
+		return db.ProcessIngest(ingest, entry.Offset, entry.Timestamp)
+	}
+
+	requests := logEntryValue.GetRequests()
+	for i, writeRequest := range requests.GetWrites() {
+		if _, err := db.ProcessWriteWithUsage(writeRequest, WriteUsageAt(requests, i), entry.Offset, entry.Timestamp,
+			WrapperUpdateOperationCallback); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteUsageAt returns the usage replicated with the write at the index of the
+// log entry, if any.
+func WriteUsageAt(requests *proto.WriteRequests, index int) *proto.WriteUsage {
+	if usages := requests.GetUsages(); index < len(usages) {
+		return usages[index]
+	}
+	return nil
+}
+
+// Writes the chunks received from a stream in a staged ingest file, starting
+// with the content of the first chunk.
+func receiveIngestFile(w io.WriteCloser, content []byte, recv func() ([]byte, error)) (totalSize int64, err error) {
+	for {
+		if _, err := w.Write(content); err != nil {
+			_ = w.Close()
+			return totalSize, err
+		}
+		totalSize += int64(len(content))
+
+		content, err = recv()
+		if errors.Is(err, io.EOF) {
+			return totalSize, w.Close()
+		} else if err != nil {
+			_ = w.Close()
+			return totalSize, err
+		}
+	}
+}
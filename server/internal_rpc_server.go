@@ -342,6 +342,61 @@ func (s *internalRpcServer) SendSnapshot(srv proto.OxiaLogReplication_SendSnapsh
 	return err
 }
 
+func (s *internalRpcServer) SendIngestFile(srv proto.OxiaLogReplication_SendIngestFileServer) error {
+	md, ok := metadata.FromIncomingContext(srv.Context())
+	if !ok {
+		return errors.New("shard id is not set in the request metadata")
+	}
+
+	shardId, err := ReadHeaderInt64(md, common.MetadataShardId)
+	if err != nil {
+		return err
+	}
+
+	follower, err := s.shardsDirector.GetFollower(shardId)
+	if err != nil {
+		return err
+	}
+
+	err = follower.SendIngestFile(srv)
+	if err != nil {
+		s.log.Warn(
+			"SendIngestFile failed",
+			slog.Any("error", err),
+			slog.Int64("shard", shardId),
+			slog.String("peer", common.GetPeer(srv.Context())),
+		)
+	}
+	return err
+}
+
+func (s *internalRpcServer) FetchLog(req *proto.FetchLogRequest, srv proto.OxiaLogReplication_FetchLogServer) error {
+	log := s.log.With(
+		slog.Any("request", req),
+		slog.String("peer", common.GetPeer(srv.Context())),
+	)
+
+	log.Info("Received FetchLog request")
+
+	follower, err := s.shardsDirector.GetFollower(req.Shard)
+	if err != nil {
+		log.Warn(
+			"FetchLog failed: could not get follower controller",
+			slog.Any("error", err),
+		)
+		return err
+	}
+
+	err = follower.FetchLog(req, srv)
+	if err != nil {
+		log.Warn(
+			"FetchLog failed",
+			slog.Any("error", err),
+		)
+	}
+	return err
+}
+
 func (s *internalRpcServer) GetStatus(_ context.Context, req *proto.GetStatusRequest) (*proto.GetStatusResponse, error) {
 	follower, err := s.shardsDirector.GetFollower(req.Shard)
 	if err == nil {
@@ -365,6 +420,33 @@ func (s *internalRpcServer) DeleteShard(_ context.Context, req *proto.DeleteShar
 	return s.shardsDirector.DeleteShard(req)
 }
 
+func (s *internalRpcServer) PrepareLeaderHandover(c context.Context, req *proto.PrepareLeaderHandoverRequest) (*proto.PrepareLeaderHandoverResponse, error) {
+	log := s.log.With(
+		slog.Any("request", req),
+		slog.String("peer", common.GetPeer(c)),
+	)
+
+	log.Info("Received PrepareLeaderHandover request")
+
+	leader, err := s.shardsDirector.GetLeader(req.Shard)
+	if err != nil {
+		log.Warn(
+			"PrepareLeaderHandover failed: could not get leader controller",
+			slog.Any("error", err),
+		)
+		return nil, err
+	}
+
+	res, err := leader.PrepareHandover(c, req)
+	if err != nil {
+		log.Warn(
+			"PrepareLeaderHandover failed",
+			slog.Any("error", err),
+		)
+	}
+	return res, err
+}
+
 func readHeader(md metadata.MD, key string) (value string, err error) {
 	arr := md.Get(key)
 	if len(arr) == 0 {
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -62,8 +64,22 @@ type RangeScanIterator interface {
 	Next() bool
 }
 
+// ExportIterator iterates over the records of the database, with all their
+// stored attributes.
+type ExportIterator interface {
+	io.Closer
+
+	Valid() bool
+	Key() string
+	Value() (*proto.StorageEntry, error)
+	Next() bool
+}
+
 type TermOptions struct {
 	NotificationsEnabled bool
+	Observer             bool              `json:",omitempty"`
+	Witness              bool              `json:",omitempty"`
+	Quota                *proto.ShardQuota `json:",omitempty"`
 }
 
 type DB interface {
@@ -72,9 +88,27 @@ type DB interface {
 	EnableNotifications(enable bool)
 
 	ProcessWrite(b *proto.WriteRequest, commitOffset int64, timestamp uint64, updateOperationCallback UpdateOperationCallback) (*proto.WriteResponse, error)
+
+	// ProcessWriteWithUsage applies a write with the usage of its delete
+	// ranges computed by the leader, if any
+	ProcessWriteWithUsage(b *proto.WriteRequest, writeUsage *proto.WriteUsage, commitOffset int64, timestamp uint64, updateOperationCallback UpdateOperationCallback) (*proto.WriteResponse, error)
+
+	// ProcessIngest adds the records of a staged sstable as new versions, and
+	// removes the sstable once they're persisted
+	ProcessIngest(req *proto.IngestRequest, commitOffset int64, timestamp uint64) error
+	CreateIngestFile(name string) (io.WriteCloser, error)
+	OpenIngestFile(name string) (io.ReadCloser, error)
+	RemoveIngestFile(name string) error
+	// ValidateIngestFile checks the records of a staged sstable, and returns their count
+	ValidateIngestFile(name string) (int64, error)
+
 	Get(request *proto.GetRequest) (*proto.GetResponse, error)
 	List(request *proto.ListRequest) (KeyIterator, error)
 	RangeScan(request *proto.RangeScanRequest) (RangeScanIterator, error)
+	// Export iterates over all the records, skipping the internal keys
+	Export() (ExportIterator, error)
+	// ReadRecord returns the stored attributes of a record, or ErrKeyNotFound
+	ReadRecord(key string) (*proto.StorageEntry, error)
 	ReadCommitOffset() (int64, error)
 
 	ReadNextNotifications(ctx context.Context, startOffset int64) ([]*proto.NotificationBatch, error)
@@ -84,6 +118,16 @@ type DB interface {
 
 	Snapshot() (Snapshot, error)
 
+	// DiskUsage returns the estimated disk space used by the database, in bytes
+	DiskUsage() int64
+
+	// Usage returns the number and the size of the records stored by the
+	// clients, when the shard has a quota that requires tracking them
+	Usage() Usage
+
+	// RangeUsage returns the number and the size of the records in the range
+	RangeUsage(keyStartInclusive string, keyEndExclusive string) (Usage, error)
+
 	// Delete and close the database and all its files
 	Delete() error
 }
@@ -136,6 +180,17 @@ func NewDB(namespace string, shardId int64, factory Factory, notificationRetenti
 	}
 	db.versionIdTracker.Store(lastVersionId)
 
+	_, termOptions, err := db.ReadTerm()
+	if err != nil {
+		return nil, err
+	}
+	if UsageTracked(termOptions.Quota) {
+		db.trackUsage = true
+		if db.usage, err = db.readUsage(); err != nil {
+			return nil, err
+		}
+	}
+
 	db.notificationsTracker = newNotificationsTracker(namespace, shardId, commitOffset, kv, notificationRetentionTime, clock)
 	return db, nil
 }
@@ -148,6 +203,10 @@ type db struct {
 	log                  *slog.Logger
 	notificationsEnabled bool
 
+	usageLock  sync.Mutex
+	trackUsage bool
+	usage      Usage
+
 	putCounter          metrics.Counter
 	deleteCounter       metrics.Counter
 	deleteRangesCounter metrics.Counter
@@ -164,6 +223,10 @@ func (d *db) Snapshot() (Snapshot, error) {
 	return d.kv.Snapshot()
 }
 
+func (d *db) DiskUsage() int64 {
+	return d.kv.DiskUsage()
+}
+
 func (d *db) EnableNotifications(enabled bool) {
 	d.notificationsEnabled = enabled
 }
@@ -186,7 +249,7 @@ func now() uint64 {
 	return uint64(time.Now().UnixMilli())
 }
 
-func (d *db) applyWriteRequest(b *proto.WriteRequest, batch WriteBatch, commitOffset int64, timestamp uint64, updateOperationCallback UpdateOperationCallback) (*notifications, *proto.WriteResponse, error) {
+func (d *db) applyWriteRequest(b *proto.WriteRequest, writeUsage *proto.WriteUsage, batch WriteBatch, usage *Usage, commitOffset int64, timestamp uint64, updateOperationCallback UpdateOperationCallback) (*notifications, *proto.WriteResponse, error) {
 	res := &proto.WriteResponse{}
 	var notifications *notifications
 	if d.notificationsEnabled {
@@ -195,7 +258,7 @@ func (d *db) applyWriteRequest(b *proto.WriteRequest, batch WriteBatch, commitOf
 
 	d.putCounter.Add(len(b.Puts))
 	for _, putReq := range b.Puts {
-		pr, err := d.applyPut(batch, notifications, putReq, timestamp, updateOperationCallback, false)
+		pr, err := d.applyPut(batch, notifications, usage, putReq, timestamp, updateOperationCallback, false)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -204,7 +267,7 @@ func (d *db) applyWriteRequest(b *proto.WriteRequest, batch WriteBatch, commitOf
 
 	d.deleteCounter.Add(len(b.Deletes))
 	for _, delReq := range b.Deletes {
-		dr, err := d.applyDelete(batch, notifications, delReq, updateOperationCallback)
+		dr, err := d.applyDelete(batch, notifications, usage, delReq, updateOperationCallback)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -213,7 +276,13 @@ func (d *db) applyWriteRequest(b *proto.WriteRequest, batch WriteBatch, commitOf
 	}
 
 	d.deleteRangesCounter.Add(len(b.DeleteRanges))
-	for _, delRangeReq := range b.DeleteRanges {
+	for i, delRangeReq := range b.DeleteRanges {
+		if usage != nil {
+			if err := addDeleteRangeUsage(batch, usage, b, writeUsage, res, i); err != nil {
+				return nil, nil, errors.Wrap(err, "oxia db: failed to delete range")
+			}
+		}
+
 		dr, err := d.applyDeleteRange(batch, notifications, delRangeReq, updateOperationCallback)
 		if err != nil {
 			return nil, nil, err
@@ -226,11 +295,19 @@ func (d *db) applyWriteRequest(b *proto.WriteRequest, batch WriteBatch, commitOf
 }
 
 func (d *db) ProcessWrite(b *proto.WriteRequest, commitOffset int64, timestamp uint64, updateOperationCallback UpdateOperationCallback) (*proto.WriteResponse, error) {
+	return d.ProcessWriteWithUsage(b, nil, commitOffset, timestamp, updateOperationCallback)
+}
+
+func (d *db) ProcessWriteWithUsage(b *proto.WriteRequest, writeUsage *proto.WriteUsage, commitOffset int64, timestamp uint64, updateOperationCallback UpdateOperationCallback) (*proto.WriteResponse, error) {
 	timer := d.batchWriteLatencyHisto.Timer()
 	defer timer.Done()
 
 	batch := d.kv.NewWriteBatch()
-	notifications, res, err := d.applyWriteRequest(b, batch, commitOffset, timestamp, updateOperationCallback)
+	var usage *Usage
+	if d.tracksUsage() {
+		usage = &Usage{}
+	}
+	notifications, res, err := d.applyWriteRequest(b, writeUsage, batch, usage, commitOffset, timestamp, updateOperationCallback)
 	if err != nil {
 		return nil, err
 	}
@@ -250,7 +327,7 @@ func (d *db) ProcessWrite(b *proto.WriteRequest, commitOffset int64, timestamp u
 		}
 	}
 
-	if err := batch.Commit(); err != nil {
+	if err := d.commitWithUsage(batch, usage); err != nil {
 		return nil, err
 	}
 
@@ -276,7 +353,7 @@ func (*db) addNotifications(batch WriteBatch, notifications *notifications) erro
 
 func (d *db) addASCIILong(key string, value int64, batch WriteBatch, timestamp uint64) error {
 	asciiValue := []byte(fmt.Sprintf("%d", value))
-	_, err := d.applyPut(batch, nil, &proto.PutRequest{
+	_, err := d.applyPut(batch, nil, nil, &proto.PutRequest{
 		Key:               key,
 		Value:             asciiValue,
 		ExpectedVersionId: nil,
@@ -368,6 +445,66 @@ func (d *db) RangeScan(request *proto.RangeScanRequest) (RangeScanIterator, erro
 	}, nil
 }
 
+type exportIterator struct {
+	KeyValueIterator
+}
+
+func (it *exportIterator) skipInternalKeys() {
+	for it.KeyValueIterator.Valid() && strings.HasPrefix(it.Key(), common.InternalKeyPrefix) {
+		it.KeyValueIterator.Next()
+	}
+}
+
+func (it *exportIterator) Next() bool {
+	it.KeyValueIterator.Next()
+	it.skipInternalKeys()
+	return it.Valid()
+}
+
+func (it *exportIterator) Value() (*proto.StorageEntry, error) {
+	value, err := it.KeyValueIterator.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	se := &proto.StorageEntry{}
+	if err = Deserialize(value, se); err != nil {
+		return nil, err
+	}
+	return se, nil
+}
+
+func (d *db) Export() (ExportIterator, error) {
+	it, err := d.kv.RangeScan("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	res := &exportIterator{KeyValueIterator: it}
+	res.skipInternalKeys()
+	return res, nil
+}
+
+func (d *db) ReadRecord(key string) (*proto.StorageEntry, error) {
+	if strings.HasPrefix(key, common.InternalKeyPrefix) {
+		return nil, ErrKeyNotFound
+	}
+
+	_, value, closer, err := d.kv.Get(key, ComparisonEqual)
+	if err != nil {
+		return nil, err
+	}
+
+	se := &proto.StorageEntry{}
+	if err = multierr.Append(
+		Deserialize(value, se),
+		closer.Close(),
+	); err != nil {
+		return nil, err
+	}
+	return se, nil
+}
+
 func (d *db) ReadCommitOffset() (int64, error) {
 	return d.readASCIILong(commitOffsetKey)
 }
@@ -401,7 +538,7 @@ func (d *db) readASCIILong(key string) (int64, error) {
 func (d *db) UpdateTerm(newTerm int64, options TermOptions) error {
 	batch := d.kv.NewWriteBatch()
 
-	if _, err := d.applyPut(batch, nil, &proto.PutRequest{
+	if _, err := d.applyPut(batch, nil, nil, &proto.PutRequest{
 		Key:   termKey,
 		Value: []byte(fmt.Sprintf("%d", newTerm)),
 	}, now(), NoOpCallback, true); err != nil {
@@ -412,16 +549,27 @@ func (d *db) UpdateTerm(newTerm int64, options TermOptions) error {
 	if err != nil {
 		return err
 	}
-	if _, err := d.applyPut(batch, nil, &proto.PutRequest{
+	if _, err := d.applyPut(batch, nil, nil, &proto.PutRequest{
 		Key:   termOptionsKey,
 		Value: serOptions,
 	}, now(), NoOpCallback, true); err != nil {
 		return err
 	}
 
+	d.usageLock.Lock()
+	defer d.usageLock.Unlock()
+
+	trackUsage := UsageTracked(options.Quota)
+	usage, err := d.updateUsageTracking(batch, trackUsage)
+	if err != nil {
+		return err
+	}
+
 	if err := batch.Commit(); err != nil {
 		return err
 	}
+	d.trackUsage = trackUsage
+	d.usage = usage
 
 	if err := batch.Close(); err != nil {
 		return err
@@ -464,7 +612,7 @@ func (d *db) ReadTerm() (term int64, options TermOptions, err error) {
 	return term, options, nil
 }
 
-func (d *db) applyPut(batch WriteBatch, notifications *notifications, putReq *proto.PutRequest, timestamp uint64, updateOperationCallback UpdateOperationCallback, internal bool) (*proto.PutResponse, error) { //nolint:revive
+func (d *db) applyPut(batch WriteBatch, notifications *notifications, usage *Usage, putReq *proto.PutRequest, timestamp uint64, updateOperationCallback UpdateOperationCallback, internal bool) (*proto.PutResponse, error) { //nolint:revive
 	var se *proto.StorageEntry
 	var err error
 	var newKey string
@@ -495,6 +643,10 @@ func (d *db) applyPut(batch WriteBatch, notifications *notifications, putReq *pr
 		}, nil
 	}
 
+	if usage != nil {
+		usage.addPut(putReq.Key, putReq.Value, se)
+	}
+
 	var versionId int64
 	if internal {
 		versionId = wal.InvalidOffset
@@ -561,7 +713,7 @@ func (d *db) applyPut(batch WriteBatch, notifications *notifications, putReq *pr
 	return pr, nil
 }
 
-func (d *db) applyDelete(batch WriteBatch, notifications *notifications, delReq *proto.DeleteRequest, updateOperationCallback UpdateOperationCallback) (*proto.DeleteResponse, error) {
+func (d *db) applyDelete(batch WriteBatch, notifications *notifications, usage *Usage, delReq *proto.DeleteRequest, updateOperationCallback UpdateOperationCallback) (*proto.DeleteResponse, error) {
 	se, err := checkExpectedVersionId(batch, delReq.Key, delReq.ExpectedVersionId)
 	if se != nil {
 		defer se.ReturnToVTPool()
@@ -583,6 +735,7 @@ func (d *db) applyDelete(batch WriteBatch, notifications *notifications, delReq
 		if err = batch.Delete(delReq.Key); err != nil {
 			return &proto.DeleteResponse{}, err
 		}
+		usage.addDelete(delReq.Key, se)
 
 		if notifications != nil {
 			notifications.Deleted(delReq.Key)
@@ -741,6 +894,9 @@ func ToDbOption(opt *proto.NewTermOptions) TermOptions {
 	to := TermOptions{NotificationsEnabled: true}
 	if opt != nil {
 		to.NotificationsEnabled = opt.EnableNotifications
+		to.Observer = opt.Observer
+		to.Witness = opt.Witness
+		to.Quota = opt.Quota
 	}
 
 	return to
@@ -0,0 +1,110 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/proto"
+)
+
+func (d *db) CreateIngestFile(name string) (io.WriteCloser, error) {
+	return d.kv.CreateIngestFile(name)
+}
+
+func (d *db) OpenIngestFile(name string) (io.ReadCloser, error) {
+	return d.kv.OpenIngestFile(name)
+}
+
+func (d *db) RemoveIngestFile(name string) error {
+	return d.kv.RemoveIngestFile(name)
+}
+
+// Checks that a record of an sstable to ingest is not an internal key and
+// that its value is a StorageEntry.
+func readIngestRecord(key string, value []byte) (*proto.StorageEntry, error) {
+	if strings.HasPrefix(key, common.InternalKeyPrefix) {
+		return nil, errors.Wrapf(ErrInvalidIngestFile, "internal key %q", key)
+	}
+
+	se := &proto.StorageEntry{}
+	if err := Deserialize(value, se); err != nil {
+		return nil, errors.Wrap(ErrInvalidIngestFile, err.Error())
+	}
+	return se, nil
+}
+
+func (d *db) ValidateIngestFile(name string) (int64, error) {
+	var count int64
+	err := d.kv.ReadIngestFile(name, func(key string, value []byte) error {
+		_, err := readIngestRecord(key, value)
+		count++
+		return err
+	})
+	return count, err
+}
+
+func (d *db) ProcessIngest(req *proto.IngestRequest, commitOffset int64, timestamp uint64) error {
+	timer := d.batchWriteLatencyHisto.Timer()
+	defer timer.Done()
+
+	// The records are ingested as new records, replacing the existing ones.
+	// Their value and partition key are the only attributes preserved.
+	lastVersionId := d.versionIdTracker.Load()
+	if err := d.kv.Ingest(req.FileName, func(key string, value []byte) ([]byte, error) {
+		se, err := readIngestRecord(key, value)
+		if err != nil {
+			return nil, err
+		}
+
+		ingested := &proto.StorageEntry{
+			Value:                 se.Value,
+			VersionId:             d.versionIdTracker.Add(1),
+			CreationTimestamp:     timestamp,
+			ModificationTimestamp: timestamp,
+			PartitionKey:          se.PartitionKey,
+		}
+		return ingested.MarshalVT()
+	}); err != nil {
+		// The same versions are assigned when the entry is applied again
+		d.versionIdTracker.Store(lastVersionId)
+		return err
+	}
+
+	batch := d.kv.NewWriteBatch()
+	if err := d.addASCIILong(commitOffsetKey, commitOffset, batch, timestamp); err != nil {
+		return err
+	}
+	if err := d.addASCIILong(commitLastVersionIdKey, d.versionIdTracker.Load(), batch, timestamp); err != nil {
+		return err
+	}
+	if err := d.commitRecomputedUsage(batch); err != nil {
+		return err
+	}
+	if err := batch.Close(); err != nil {
+		return err
+	}
+
+	// The staged sstable is needed to apply the entry again, until the new
+	// commit offset is persisted
+	if err := d.kv.Flush(); err != nil {
+		return err
+	}
+	return d.kv.RemoveIngestFile(req.FileName)
+}
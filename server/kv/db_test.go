@@ -20,6 +20,7 @@ import (
 	"path"
 	"testing"
 
+	"github.com/cockroachdb/pebble/vfs"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	pb "google.golang.org/protobuf/proto"
@@ -924,3 +925,286 @@ func TestDb_versionId(t *testing.T) {
 	assert.NoError(t, db.Close())
 	assert.NoError(t, factory.Close())
 }
+
+func TestDB_Export(t *testing.T) {
+	factory, err := NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	db, err := NewDB(common.DefaultNamespace, 1, factory, 0, common.SystemClock)
+	assert.NoError(t, err)
+
+	assert.NoError(t, db.UpdateTerm(1, TermOptions{}))
+
+	_, err = db.ProcessWrite(&proto.WriteRequest{
+		Puts: []*proto.PutRequest{{
+			Key:   "a",
+			Value: []byte("0"),
+		}, {
+			Key:          "/b/c",
+			Value:        []byte("1"),
+			PartitionKey: pb.String("x"),
+		}, {
+			Key:   "z",
+			Value: []byte("2"),
+		}},
+	}, 0, now(), NoOpCallback)
+	assert.NoError(t, err)
+
+	it, err := db.Export()
+	assert.NoError(t, err)
+
+	var keys []string
+	for ; it.Valid(); it.Next() {
+		se, err := it.Value()
+		assert.NoError(t, err)
+		if it.Key() == "/b/c" {
+			assert.Equal(t, "x", *se.PartitionKey)
+		}
+		keys = append(keys, it.Key())
+	}
+	assert.NoError(t, it.Close())
+	assert.ElementsMatch(t, []string{"a", "/b/c", "z"}, keys)
+
+	assert.NoError(t, db.Close())
+	assert.NoError(t, factory.Close())
+}
+
+func TestDB_Ingest(t *testing.T) {
+	factory, err := NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	db, err := NewDB(common.DefaultNamespace, 1, factory, 0, common.SystemClock)
+	assert.NoError(t, err)
+
+	assert.NoError(t, db.UpdateTerm(1, TermOptions{Quota: &proto.ShardQuota{MaxKeys: 100}}))
+
+	_, err = db.ProcessWrite(&proto.WriteRequest{
+		Puts: []*proto.PutRequest{{
+			Key:   "a",
+			Value: []byte("0"),
+		}},
+	}, 0, now(), NoOpCallback)
+	assert.NoError(t, err)
+
+	// Build the sstable and stage it
+	sstPath := path.Join(t.TempDir(), "test.sst")
+	f, err := vfs.Default.Create(sstPath)
+	assert.NoError(t, err)
+	w := NewIngestFileWriter(f)
+	for _, r := range []struct{ key, value, partitionKey string }{
+		{"a", "3", ""},
+		{"/b/c", "1", "x"},
+		{"/b/d", "2", ""},
+	} {
+		se := &proto.StorageEntry{Value: []byte(r.value)}
+		if r.partitionKey != "" {
+			se.PartitionKey = pb.String(r.partitionKey)
+		}
+		value, err := se.MarshalVT()
+		assert.NoError(t, err)
+		assert.NoError(t, w.Set([]byte(r.key), value))
+	}
+	assert.NoError(t, w.Close())
+
+	staged, err := db.CreateIngestFile("test.sst")
+	assert.NoError(t, err)
+	content, err := os.ReadFile(sstPath)
+	assert.NoError(t, err)
+	_, err = staged.Write(content)
+	assert.NoError(t, err)
+	assert.NoError(t, staged.Close())
+
+	count, err := db.ValidateIngestFile("test.sst")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+
+	assert.NoError(t, db.ProcessIngest(&proto.IngestRequest{FileName: "test.sst"}, 1, now()))
+
+	_, err = db.OpenIngestFile("test.sst")
+	assert.ErrorIs(t, err, ErrIngestFileNotFound)
+
+	commitOffset, err := db.ReadCommitOffset()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, commitOffset)
+	assert.Equal(t, Usage{Keys: 3, Bytes: 12}, db.Usage())
+
+	res, err := db.Get(&proto.GetRequest{Key: "a", IncludeValue: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "3", string(res.Value))
+	assert.EqualValues(t, 0, res.Version.ModificationsCount)
+
+	res, err = db.Get(&proto.GetRequest{Key: "/b/c", IncludeValue: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", string(res.Value))
+
+	// The versions keep increasing after the ingested records
+	wr, err := db.ProcessWrite(&proto.WriteRequest{
+		Puts: []*proto.PutRequest{{
+			Key:   "z",
+			Value: []byte("4"),
+		}},
+	}, 2, now(), NoOpCallback)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, wr.Puts[0].Version.VersionId)
+
+	assert.NoError(t, db.Close())
+	assert.NoError(t, factory.Close())
+}
+
+func TestDB_IngestInvalidFile(t *testing.T) {
+	factory, err := NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	db, err := NewDB(common.DefaultNamespace, 1, factory, 0, common.SystemClock)
+	assert.NoError(t, err)
+
+	staged, err := db.CreateIngestFile("invalid.sst")
+	assert.NoError(t, err)
+	_, err = staged.Write([]byte("not an sstable"))
+	assert.NoError(t, err)
+	assert.NoError(t, staged.Close())
+
+	_, err = db.ValidateIngestFile("invalid.sst")
+	assert.ErrorIs(t, err, ErrInvalidIngestFile)
+
+	_, err = db.CreateIngestFile("../escape.sst")
+	assert.ErrorIs(t, err, ErrInvalidIngestFile)
+
+	assert.NoError(t, db.Close())
+	assert.NoError(t, factory.Close())
+}
+
+func TestDB_ReadRecord(t *testing.T) {
+	factory, err := NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	db, err := NewDB(common.DefaultNamespace, 1, factory, 0, common.SystemClock)
+	assert.NoError(t, err)
+
+	_, err = db.ProcessWrite(&proto.WriteRequest{
+		Puts: []*proto.PutRequest{{
+			Key:              "a",
+			Value:            []byte("0"),
+			PartitionKey:     pb.String("x"),
+			SecondaryIndexes: []*proto.SecondaryIndex{{IndexName: "idx", SecondaryKey: "b"}},
+		}},
+	}, 0, now(), NoOpCallback)
+	assert.NoError(t, err)
+
+	se, err := db.ReadRecord("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "0", string(se.Value))
+	assert.Equal(t, "x", se.GetPartitionKey())
+	assert.Len(t, se.SecondaryIndexes, 1)
+
+	_, err = db.ReadRecord("b")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	_, err = db.ReadRecord(commitOffsetKey)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	assert.NoError(t, db.Close())
+	assert.NoError(t, factory.Close())
+}
+
+func TestDB_Usage(t *testing.T) {
+	factory, err := NewPebbleKVFactory(&FactoryOptions{
+		InMemory:    false,
+		CacheSizeMB: 1,
+		DataDir:     path.Join(os.TempDir(), uuid.New().String()),
+	})
+	assert.NoError(t, err)
+	db, err := NewDB(common.DefaultNamespace, 1, factory, 0, common.SystemClock)
+	assert.NoError(t, err)
+	assert.NoError(t, db.UpdateTerm(1, TermOptions{}))
+
+	// Without a quota, the usage is not tracked
+	_, err = db.ProcessWrite(&proto.WriteRequest{
+		Puts: []*proto.PutRequest{
+			{Key: "a", Value: []byte("0123")},
+			{Key: "b", Value: []byte("01")},
+			{Key: "c", Value: []byte("0")},
+			{Key: "d", Value: []byte("0"), ExpectedVersionId: pb.Int64(5)},
+		},
+	}, 0, now(), NoOpCallback)
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{}, db.Usage())
+	res, err := db.Get(&proto.GetRequest{Key: usageKey})
+	assert.NoError(t, err)
+	assert.Equal(t, proto.Status_KEY_NOT_FOUND, res.Status)
+
+	// The usage is computed from the records when the quota is set
+	quota := &proto.ShardQuota{MaxKeys: 100}
+	assert.NoError(t, db.UpdateTerm(2, TermOptions{Quota: quota}))
+	assert.Equal(t, Usage{Keys: 3, Bytes: 10}, db.Usage())
+
+	// Overwriting a record only changes the size
+	_, err = db.ProcessWrite(&proto.WriteRequest{
+		Puts: []*proto.PutRequest{{Key: "a", Value: []byte("0")}},
+	}, 1, now(), NoOpCallback)
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Keys: 3, Bytes: 7}, db.Usage())
+
+	rangeUsage, err := db.RangeUsage("c", "z")
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Keys: 1, Bytes: 2}, rangeUsage)
+
+	_, err = db.ProcessWrite(&proto.WriteRequest{
+		Deletes:      []*proto.DeleteRequest{{Key: "a"}, {Key: "non-existing"}},
+		DeleteRanges: []*proto.DeleteRangeRequest{{StartInclusive: "c", EndExclusive: "z"}},
+	}, 2, now(), NoOpCallback)
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Keys: 1, Bytes: 3}, db.Usage())
+
+	// The usage of the range replicated by the leader is used, unless the
+	// same request writes in the range
+	_, err = db.ProcessWrite(&proto.WriteRequest{
+		Puts: []*proto.PutRequest{{Key: "e", Value: []byte("0")}, {Key: "f", Value: []byte("0")}},
+	}, 3, now(), NoOpCallback)
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Keys: 3, Bytes: 7}, db.Usage())
+
+	_, err = db.ProcessWriteWithUsage(&proto.WriteRequest{
+		Puts: []*proto.PutRequest{{Key: "g", Value: []byte("0")}},
+		DeleteRanges: []*proto.DeleteRangeRequest{
+			{StartInclusive: "e", EndExclusive: "f"},
+			{StartInclusive: "f", EndExclusive: "z"},
+		},
+	}, &proto.WriteUsage{DeleteRanges: []*proto.RecordsUsage{{Keys: 1, Bytes: 2}, {Keys: 1, Bytes: 2}}}, 4, now(), NoOpCallback)
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Keys: 1, Bytes: 3}, db.Usage())
+
+	assert.NoError(t, db.Close())
+
+	// Reopen and verify the usage is maintained
+	db, err = NewDB(common.DefaultNamespace, 1, factory, 0, common.SystemClock)
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Keys: 1, Bytes: 3}, db.Usage())
+
+	// Without the persisted usage, it's computed from the records
+	_, err = db.ProcessWrite(&proto.WriteRequest{
+		Deletes: []*proto.DeleteRequest{{Key: usageKey}},
+	}, 5, now(), NoOpCallback)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+
+	db, err = NewDB(common.DefaultNamespace, 1, factory, 0, common.SystemClock)
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Keys: 1, Bytes: 3}, db.Usage())
+
+	// Removing the quota drops the persisted usage, so that it's not stale
+	// if the quota is set again
+	assert.NoError(t, db.UpdateTerm(3, TermOptions{}))
+	assert.Equal(t, Usage{}, db.Usage())
+	res, err = db.Get(&proto.GetRequest{Key: usageKey})
+	assert.NoError(t, err)
+	assert.Equal(t, proto.Status_KEY_NOT_FOUND, res.Status)
+
+	_, err = db.ProcessWrite(&proto.WriteRequest{
+		Puts: []*proto.PutRequest{{Key: "h", Value: []byte("0")}},
+	}, 6, now(), NoOpCallback)
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{}, db.Usage())
+
+	assert.NoError(t, db.UpdateTerm(4, TermOptions{Quota: quota}))
+	assert.Equal(t, Usage{Keys: 2, Bytes: 5}, db.Usage())
+
+	assert.NoError(t, db.Close())
+	assert.NoError(t, factory.Close())
+}
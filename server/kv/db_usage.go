@@ -0,0 +1,305 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+
+	"go.uber.org/multierr"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/proto"
+)
+
+const usageKey = common.InternalKeyPrefix + "usage"
+
+// Usage is the amount of data stored by the clients in a shard. The internal
+// records, like the sessions and the secondary indexes, are not counted.
+//
+// The usage is only tracked by the shards that have a quota on the number or
+// the size of the records, since it adds a write to each batch.
+type Usage struct {
+	Keys int64 `json:"keys"`
+
+	// Bytes is the total size of the keys and the values of the records
+	Bytes int64 `json:"bytes"`
+}
+
+// UsageTracked returns whether the shards with the quota track their usage.
+func UsageTracked(quota *proto.ShardQuota) bool {
+	return quota != nil && (quota.MaxBytes > 0 || quota.MaxKeys > 0)
+}
+
+func RecordSize(key string, value []byte) int64 {
+	return int64(len(key) + len(value))
+}
+
+func (u *Usage) addPut(key string, value []byte, existing *proto.StorageEntry) {
+	if strings.HasPrefix(key, common.InternalKeyPrefix) {
+		return
+	}
+
+	if existing == nil {
+		u.Keys++
+	} else {
+		u.Bytes -= RecordSize(key, existing.Value)
+	}
+	u.Bytes += RecordSize(key, value)
+}
+
+func (u *Usage) addDelete(key string, existing *proto.StorageEntry) {
+	if u == nil || strings.HasPrefix(key, common.InternalKeyPrefix) {
+		return
+	}
+
+	u.Keys--
+	u.Bytes -= RecordSize(key, existing.Value)
+}
+
+func (u *Usage) add(delta Usage) {
+	u.Keys += delta.Keys
+	u.Bytes += delta.Bytes
+}
+
+func (u *Usage) sub(delta Usage) {
+	u.Keys -= delta.Keys
+	u.Bytes -= delta.Bytes
+}
+
+type rangeScanner interface {
+	RangeScan(lowerBound, upperBound string) (KeyValueIterator, error)
+}
+
+// Computes the usage of the records in the range.
+func rangeUsage(r rangeScanner, keyStartInclusive string, keyEndExclusive string) (Usage, error) {
+	it, err := r.RangeScan(keyStartInclusive, keyEndExclusive)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	var usage Usage
+	for ; it.Valid(); it.Next() {
+		if strings.HasPrefix(it.Key(), common.InternalKeyPrefix) {
+			continue
+		}
+
+		value, err := it.Value()
+		if err != nil {
+			return Usage{}, multierr.Combine(err, it.Close())
+		}
+
+		se := proto.StorageEntryFromVTPool()
+		if err = Deserialize(value, se); err != nil {
+			se.ReturnToVTPool()
+			return Usage{}, multierr.Combine(err, it.Close())
+		}
+		usage.addPut(it.Key(), se.Value, nil)
+		se.ReturnToVTPool()
+	}
+	return usage, it.Close()
+}
+
+func (d *db) RangeUsage(keyStartInclusive string, keyEndExclusive string) (Usage, error) {
+	return rangeUsage(d.kv, keyStartInclusive, keyEndExclusive)
+}
+
+// Subtracts the records of a delete range from the usage, before they're
+// deleted. The leader replicates the usage of the range in the log entry, as
+// it was before the write request, so that it doesn't need to be scanned on
+// each replica. The range is only scanned when the request already changed
+// it, or when the usage is missing because the leader wasn't tracking it.
+func addDeleteRangeUsage(batch WriteBatch, usage *Usage, b *proto.WriteRequest, writeUsage *proto.WriteUsage,
+	res *proto.WriteResponse, index int) error {
+	delReq := b.DeleteRanges[index]
+	replicated := writeUsage.GetDeleteRanges()
+	if len(replicated) == len(b.DeleteRanges) && !rangeChangedByRequest(b, res, index) {
+		usage.sub(Usage{Keys: replicated[index].Keys, Bytes: replicated[index].Bytes})
+		return nil
+	}
+
+	deleted, err := rangeUsage(batch, delReq.StartInclusive, delReq.EndExclusive)
+	if err != nil {
+		return err
+	}
+	usage.sub(deleted)
+	return nil
+}
+
+// Whether the operations of the request that come before a delete range
+// write in its range.
+func rangeChangedByRequest(b *proto.WriteRequest, res *proto.WriteResponse, index int) bool {
+	delReq := b.DeleteRanges[index]
+	inRange := func(key string) bool {
+		return key >= delReq.StartInclusive && key < delReq.EndExclusive
+	}
+
+	for i, put := range b.Puts {
+		// The puts with sequence keys write on the generated key
+		if inRange(put.Key) || (i < len(res.Puts) && inRange(res.Puts[i].GetKey())) {
+			return true
+		}
+	}
+	for _, del := range b.Deletes {
+		if inRange(del.Key) {
+			return true
+		}
+	}
+	for _, other := range b.DeleteRanges[:index] {
+		if other.StartInclusive < delReq.EndExclusive && delReq.StartInclusive < other.EndExclusive {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *db) Usage() Usage {
+	d.usageLock.Lock()
+	defer d.usageLock.Unlock()
+	return d.usage
+}
+
+func (d *db) tracksUsage() bool {
+	d.usageLock.Lock()
+	defer d.usageLock.Unlock()
+	return d.trackUsage
+}
+
+// Adds the usage changes of a write to the batch, and commits it. The
+// batches with usage changes are committed one at a time, to always persist
+// the latest usage.
+func (d *db) commitWithUsage(batch WriteBatch, delta *Usage) error {
+	if delta == nil || *delta == (Usage{}) {
+		return batch.Commit()
+	}
+
+	d.usageLock.Lock()
+	defer d.usageLock.Unlock()
+
+	if !d.trackUsage {
+		return batch.Commit()
+	}
+
+	usage := d.usage
+	usage.add(*delta)
+	if err := d.putUsage(batch, usage); err != nil {
+		return err
+	}
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+	d.usage = usage
+	return nil
+}
+
+func (d *db) putUsage(batch WriteBatch, usage Usage) error {
+	value, err := json.Marshal(usage)
+	if err != nil {
+		return err
+	}
+	_, err = d.applyPut(batch, nil, nil, &proto.PutRequest{
+		Key:   usageKey,
+		Value: value,
+	}, now(), NoOpCallback, true)
+	return err
+}
+
+// Recomputes the usage from all the records, after they were changed
+// without going through the write batches, and commits it with the batch.
+func (d *db) commitRecomputedUsage(batch WriteBatch) error {
+	d.usageLock.Lock()
+	defer d.usageLock.Unlock()
+
+	if !d.trackUsage {
+		return batch.Commit()
+	}
+
+	usage, err := d.computeUsage()
+	if err != nil {
+		return err
+	}
+	if err = d.putUsage(batch, usage); err != nil {
+		return err
+	}
+	if err = batch.Commit(); err != nil {
+		return err
+	}
+	d.usage = usage
+	return nil
+}
+
+// Starts or stops tracking the usage when the quota of the shard changes. It
+// must be called with the usage lock, and the batch must be committed before
+// setting the returned usage. The persisted usage is removed when it's not
+// tracked anymore, so that it's never left stale.
+func (d *db) updateUsageTracking(batch WriteBatch, track bool) (Usage, error) {
+	switch {
+	case track && d.trackUsage:
+		return d.usage, nil
+	case track:
+		usage, err := d.readUsage()
+		if err != nil {
+			return Usage{}, err
+		}
+		return usage, d.putUsage(batch, usage)
+	default:
+		return Usage{}, batch.Delete(usageKey)
+	}
+}
+
+// Reads the usage persisted in the database. When the shard wasn't tracking
+// it yet, it's computed from all the records.
+func (d *db) readUsage() (Usage, error) {
+	gr, err := applyGet(d.kv, &proto.GetRequest{Key: usageKey, IncludeValue: true})
+	if err != nil {
+		return Usage{}, err
+	}
+
+	var usage Usage
+	if gr.Status == proto.Status_OK {
+		err = json.Unmarshal(gr.Value, &usage)
+		return usage, err
+	}
+
+	if usage, err = d.computeUsage(); err != nil {
+		return Usage{}, err
+	}
+	if usage != (Usage{}) {
+		d.log.Info(
+			"Computed the usage of the existing records",
+			slog.Int64("keys", usage.Keys),
+			slog.Int64("bytes", usage.Bytes),
+		)
+	}
+	return usage, nil
+}
+
+func (d *db) computeUsage() (Usage, error) {
+	it, err := d.Export()
+	if err != nil {
+		return Usage{}, err
+	}
+
+	var usage Usage
+	for ; it.Valid(); it.Next() {
+		se, err := it.Value()
+		if err != nil {
+			return Usage{}, multierr.Combine(err, it.Close())
+		}
+		usage.addPut(it.Key(), se.Value, nil)
+	}
+	return usage, it.Close()
+}
@@ -0,0 +1,312 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"os"
+
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/streamnative/oxia/server/encryption"
+)
+
+// Encrypted files:
+// +---------------+---------------+-------------+--------------+
+// | Magic(8Bytes) | KeyId(4Bytes) | IV(16Bytes) | Content(...) |
+// +---------------+---------------+-------------+--------------+
+// Magic:	Identifies the encrypted files, so that the files written before
+//			enabling the encryption can still be read.
+// KeyId:	The id of the key used to encrypt the file.
+// IV:		The random initialization vector of the file.
+// Content:	The file content, encrypted with AES-CTR. The counter is derived
+//			from the position in the file, to support random access.
+//
+// Since the IV is fixed for the whole file, each position of the content must
+// be written only once: rewriting it would reuse the same key stream and leak
+// the XOR of the two plaintexts. The writes below the already written content
+// are therefore rejected.
+
+var (
+	encryptedFileMagic = []byte("OXIAENC1")
+
+	errEncryptedFileRewrite = errors.New("encrypted files can't be rewritten in place")
+)
+
+const (
+	encryptedFileKeyIdLen  = 4
+	encryptedFileHeaderLen = 8 + encryptedFileKeyIdLen + aes.BlockSize
+)
+
+// encryptedFS encrypts the content of all the files created by Pebble. It
+// only protects the confidentiality of the data: there is no MAC, so the
+// Pebble checksums detect the accidental corruptions but not the tampering
+// of the files by someone with write access to the disk.
+type encryptedFS struct {
+	vfs.FS
+	cipher *encryption.Cipher
+}
+
+func newEncryptedFS(fs vfs.FS, c *encryption.Cipher) vfs.FS {
+	return &encryptedFS{FS: fs, cipher: c}
+}
+
+func (fs *encryptedFS) Create(name string) (vfs.File, error) {
+	f, err := fs.FS.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ef, err := fs.initialize(f)
+	if err != nil {
+		return nil, errors.Wrapf(multierr.Combine(err, f.Close()), "failed to create encrypted file %s", name)
+	}
+	return ef, nil
+}
+
+func (fs *encryptedFS) Open(name string, opts ...vfs.OpenOption) (vfs.File, error) {
+	f, err := fs.FS.Open(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return fs.wrap(name, f)
+}
+
+func (fs *encryptedFS) OpenReadWrite(name string, opts ...vfs.OpenOption) (vfs.File, error) {
+	f, err := fs.FS.OpenReadWrite(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, multierr.Combine(err, f.Close())
+	}
+	if stat.Size() > 0 {
+		return fs.wrap(name, f)
+	}
+
+	ef, err := fs.initialize(f)
+	if err != nil {
+		return nil, errors.Wrapf(multierr.Combine(err, f.Close()), "failed to create encrypted file %s", name)
+	}
+	ef.readWrite = true
+	return ef, nil
+}
+
+func (fs *encryptedFS) ReuseForWrite(oldname, newname string) (vfs.File, error) {
+	// The recycled files would keep the IV of the old file, so they are
+	// replaced instead
+	if err := fs.FS.Remove(oldname); err != nil {
+		return nil, err
+	}
+	return fs.Create(newname)
+}
+
+func (fs *encryptedFS) Stat(name string) (os.FileInfo, error) {
+	stat, err := fs.FS.Stat(name)
+	if err != nil || stat.IsDir() || stat.Size() < encryptedFileHeaderLen {
+		return stat, err
+	}
+
+	// Check the header to know whether the file is encrypted
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Stat()
+}
+
+// Writes the header of a new file.
+func (fs *encryptedFS) initialize(f vfs.File) (*encryptedFile, error) {
+	keyId, block, err := fs.cipher.ActiveBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, encryptedFileHeaderLen)
+	copy(header, encryptedFileMagic)
+	binary.BigEndian.PutUint32(header[len(encryptedFileMagic):], uint32(keyId))
+	iv := header[len(encryptedFileMagic)+encryptedFileKeyIdLen:]
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &encryptedFile{File: f, block: block, iv: iv}, nil
+}
+
+// Wraps an existing file, which is left as it is if it was written before
+// enabling the encryption.
+func (fs *encryptedFS) wrap(name string, f vfs.File) (vfs.File, error) {
+	header := make([]byte, encryptedFileHeaderLen)
+	if n, err := f.ReadAt(header, 0); n < len(header) {
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, multierr.Combine(err, f.Close())
+		}
+		return f, nil
+	}
+	if !bytes.Equal(header[:len(encryptedFileMagic)], encryptedFileMagic) {
+		return f, nil
+	}
+
+	keyId := encryption.KeyId(binary.BigEndian.Uint32(header[len(encryptedFileMagic):]))
+	block, err := fs.cipher.Block(keyId)
+	if err != nil {
+		return nil, errors.Wrapf(multierr.Combine(err, f.Close()), "failed to open encrypted file %s", name)
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, multierr.Combine(err, f.Close())
+	}
+
+	return &encryptedFile{
+		File:      f,
+		block:     block,
+		iv:        header[len(encryptedFileMagic)+encryptedFileKeyIdLen:],
+		readWrite: true,
+		written:   stat.Size() - encryptedFileHeaderLen,
+	}, nil
+}
+
+type encryptedFile struct {
+	vfs.File
+	block cipher.Block
+	iv    []byte
+
+	// The position for the sequential reads and writes, excluding the header
+	offset int64
+
+	// The files that were not just created are written with WriteAt, since
+	// their position is not after the header
+	readWrite bool
+
+	// The end of the written content, below which the file can't be written
+	// again without reusing the key stream
+	written int64
+}
+
+func (f *encryptedFile) Read(p []byte) (n int, err error) {
+	n, err = f.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *encryptedFile) ReadAt(p []byte, off int64) (n int, err error) {
+	n, err = f.File.ReadAt(p, off+encryptedFileHeaderLen)
+	f.xorKeyStream(p[:n], p[:n], off)
+	return n, err
+}
+
+func (f *encryptedFile) Write(p []byte) (n int, err error) {
+	if f.readWrite {
+		n, err = f.WriteAt(p, f.offset)
+		f.offset += int64(n)
+		return n, err
+	}
+
+	// The vfs.File contract allows modifying the buffer
+	f.xorKeyStream(p, p, f.offset)
+	n, err = f.File.Write(p)
+	f.offset += int64(n)
+	f.written = max(f.written, f.offset)
+	return n, err
+}
+
+func (f *encryptedFile) WriteAt(p []byte, off int64) (n int, err error) {
+	if off < f.written {
+		return 0, errEncryptedFileRewrite
+	}
+
+	buf := make([]byte, len(p))
+	f.xorKeyStream(buf, p, off)
+	n, err = f.File.WriteAt(buf, off+encryptedFileHeaderLen)
+	f.written = max(f.written, off+int64(n))
+	return n, err
+}
+
+func (f *encryptedFile) Preallocate(offset, length int64) error {
+	return f.File.Preallocate(offset+encryptedFileHeaderLen, length)
+}
+
+func (f *encryptedFile) Stat() (os.FileInfo, error) {
+	stat, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedFileInfo{FileInfo: stat}, nil
+}
+
+func (f *encryptedFile) SyncTo(length int64) (fullSync bool, err error) {
+	return f.File.SyncTo(length + encryptedFileHeaderLen)
+}
+
+func (f *encryptedFile) Prefetch(offset int64, length int64) error {
+	return f.File.Prefetch(offset+encryptedFileHeaderLen, length)
+}
+
+// Fd is not exposed, since the file descriptor would give access to the
+// encrypted content.
+func (*encryptedFile) Fd() uintptr {
+	return vfs.InvalidFd
+}
+
+// Applies the AES-CTR key stream starting at the given position of the content.
+func (f *encryptedFile) xorKeyStream(dst, src []byte, off int64) {
+	if len(src) == 0 {
+		return
+	}
+
+	counter := make([]byte, aes.BlockSize)
+	copy(counter, f.iv)
+	addCounter(counter, uint64(off/aes.BlockSize))
+
+	stream := cipher.NewCTR(f.block, counter)
+	if skip := off % aes.BlockSize; skip > 0 {
+		discard := make([]byte, skip)
+		stream.XORKeyStream(discard, discard)
+	}
+	stream.XORKeyStream(dst, src)
+}
+
+// Adds n to the 128 bits big endian counter.
+func addCounter(counter []byte, n uint64) {
+	low := binary.BigEndian.Uint64(counter[8:])
+	sum := low + n
+	binary.BigEndian.PutUint64(counter[8:], sum)
+	if sum < low {
+		high := binary.BigEndian.Uint64(counter[:8])
+		binary.BigEndian.PutUint64(counter[:8], high+1)
+	}
+}
+
+type encryptedFileInfo struct {
+	os.FileInfo
+}
+
+func (fi *encryptedFileInfo) Size() int64 {
+	return max(fi.FileInfo.Size()-encryptedFileHeaderLen, 0)
+}
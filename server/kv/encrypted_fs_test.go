@@ -0,0 +1,221 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/server/encryption"
+)
+
+func newTestKeyProvider(t *testing.T, key byte) encryption.KeyProvider {
+	t.Helper()
+
+	p, err := encryption.NewStaticKeyProvider(bytes.Repeat([]byte{key}, encryption.KeySize))
+	assert.NoError(t, err)
+	return p
+}
+
+func TestEncryptedFS(t *testing.T) {
+	fs := newEncryptedFS(vfs.Default, encryption.NewCipher(newTestKeyProvider(t, 1)))
+	path := filepath.Join(t.TempDir(), "file")
+
+	content := bytes.Repeat([]byte("0123456789-secret-"), 1000)
+
+	f, err := fs.Create(path)
+	assert.NoError(t, err)
+	// Write in chunks that are not aligned to the AES blocks
+	for remaining := content; len(remaining) > 0; {
+		n := min(len(remaining), 37)
+		chunk := make([]byte, n)
+		copy(chunk, remaining[:n])
+		_, err = f.Write(chunk)
+		assert.NoError(t, err)
+		remaining = remaining[n:]
+	}
+	assert.NoError(t, f.Sync())
+	assert.NoError(t, f.Close())
+
+	raw, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, raw, len(content)+encryptedFileHeaderLen)
+	assert.False(t, bytes.Contains(raw, []byte("secret")))
+
+	stat, err := fs.Stat(path)
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(content), stat.Size())
+
+	f, err = fs.Open(path)
+	assert.NoError(t, err)
+	read, err := io.ReadAll(f)
+	assert.NoError(t, err)
+	assert.Equal(t, content, read)
+
+	for _, off := range []int64{0, 5, 16, 33, 1000, int64(len(content)) - 3} {
+		buf := make([]byte, 20)
+		n, err := f.ReadAt(buf, off)
+		if off+20 > int64(len(content)) {
+			assert.ErrorIs(t, err, io.EOF)
+		} else {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, content[off:off+int64(n)], buf[:n], "offset %d", off)
+	}
+	assert.NoError(t, f.Close())
+
+	// The files written before enabling the encryption are read as they are
+	plainPath := filepath.Join(t.TempDir(), "plain")
+	assert.NoError(t, os.WriteFile(plainPath, content, 0644))
+	f, err = fs.Open(plainPath)
+	assert.NoError(t, err)
+	read, err = io.ReadAll(f)
+	assert.NoError(t, err)
+	assert.Equal(t, content, read)
+	assert.NoError(t, f.Close())
+
+	// The file cannot be read without its key
+	otherFS := newEncryptedFS(vfs.Default, encryption.NewCipher(newTestKeyProvider(t, 2)))
+	_, err = otherFS.Open(path)
+	assert.ErrorIs(t, err, encryption.ErrKeyNotFound)
+}
+
+func TestEncryptedFS_RewriteInPlace(t *testing.T) {
+	fs := newEncryptedFS(vfs.Default, encryption.NewCipher(newTestKeyProvider(t, 1)))
+	path := filepath.Join(t.TempDir(), "file")
+
+	f, err := fs.OpenReadWrite(path)
+	assert.NoError(t, err)
+	_, err = f.WriteAt([]byte("first-secret"), 0)
+	assert.NoError(t, err)
+	_, err = f.WriteAt([]byte("second-secret"), 12)
+	assert.NoError(t, err)
+
+	// Rewriting the content would reuse the same key stream
+	_, err = f.WriteAt([]byte("other"), 5)
+	assert.ErrorIs(t, err, errEncryptedFileRewrite)
+	assert.NoError(t, f.Close())
+
+	// The limit is kept when the file is opened again
+	f, err = fs.OpenReadWrite(path)
+	assert.NoError(t, err)
+	_, err = f.WriteAt([]byte("other"), 0)
+	assert.ErrorIs(t, err, errEncryptedFileRewrite)
+	_, err = f.Write([]byte("other"))
+	assert.ErrorIs(t, err, errEncryptedFileRewrite)
+	_, err = f.WriteAt([]byte("-appended"), 25)
+	assert.NoError(t, err)
+
+	read := make([]byte, 34)
+	_, err = f.ReadAt(read, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "first-secretsecond-secret-appended", string(read))
+	assert.NoError(t, f.Close())
+}
+
+func TestPebbleEncryption_Snapshot(t *testing.T) {
+	originalLocation := t.TempDir()
+	newLocation := t.TempDir()
+
+	factory, err := NewPebbleKVFactory(&FactoryOptions{
+		DataDir:     originalLocation,
+		CacheSizeMB: 1,
+		KeyProvider: newTestKeyProvider(t, 1),
+	})
+	assert.NoError(t, err)
+	kv, err := factory.NewKV(common.DefaultNamespace, 1)
+	assert.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		wb := kv.NewWriteBatch()
+		for j := 0; j < 100; j++ {
+			assert.NoError(t, wb.Put(fmt.Sprintf("key-%d-%d", i, j),
+				[]byte(fmt.Sprintf("secret-value-%d-%d", i, j))))
+		}
+		assert.NoError(t, wb.Commit())
+		assert.NoError(t, wb.Close())
+	}
+
+	snapshot, err := kv.Snapshot()
+	assert.NoError(t, err)
+
+	// The receiving node uses a different key
+	factory2, err := NewPebbleKVFactory(&FactoryOptions{
+		DataDir:     newLocation,
+		CacheSizeMB: 1,
+		KeyProvider: newTestKeyProvider(t, 2),
+	})
+	assert.NoError(t, err)
+
+	loader, err := factory2.NewSnapshotLoader(common.DefaultNamespace, 1)
+	assert.NoError(t, err)
+	for ; snapshot.Valid(); snapshot.Next() {
+		f, err := snapshot.Chunk()
+		assert.NoError(t, err)
+		assert.NoError(t, loader.AddChunk(f.Name(), f.Index(), f.TotalCount(), f.Content()))
+	}
+	loader.Complete()
+	assert.NoError(t, loader.Close())
+	assert.NoError(t, snapshot.Close())
+	assert.NoError(t, kv.Close())
+	assert.NoError(t, factory.Close())
+
+	kv2, err := factory2.NewKV(common.DefaultNamespace, 1)
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 100; j++ {
+			k := fmt.Sprintf("key-%d-%d", i, j)
+			key, r, closer, err := kv2.Get(k, ComparisonEqual)
+			assert.NoError(t, err)
+			assert.Equal(t, k, key)
+			assert.Equal(t, fmt.Sprintf("secret-value-%d-%d", i, j), string(r))
+			assert.NoError(t, closer.Close())
+		}
+	}
+	assert.NoError(t, kv2.Close())
+	assert.NoError(t, factory2.Close())
+
+	// None of the database files contain the values in clear
+	for _, location := range []string{originalLocation, newLocation} {
+		assert.NoError(t, filepath.WalkDir(location, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			content, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.False(t, bytes.Contains(content, []byte("secret-value")), path)
+			return nil
+		}))
+	}
+
+	// The database cannot be opened without the key
+	factory3, err := NewPebbleKVFactory(&FactoryOptions{
+		DataDir:     newLocation,
+		CacheSizeMB: 1,
+		KeyProvider: newTestKeyProvider(t, 1),
+	})
+	assert.NoError(t, err)
+	_, err = factory3.NewKV(common.DefaultNamespace, 1)
+	assert.ErrorIs(t, err, encryption.ErrKeyNotFound)
+	assert.NoError(t, factory3.Close())
+}
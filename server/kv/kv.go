@@ -20,6 +20,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/encryption"
 )
 
 var (
@@ -121,8 +122,23 @@ type KV interface {
 
 	Snapshot() (Snapshot, error)
 
+	// CreateIngestFile creates a file in the staging area of the sstables to
+	// ingest. The file is synced when it's closed.
+	CreateIngestFile(name string) (io.WriteCloser, error)
+	OpenIngestFile(name string) (io.ReadCloser, error)
+	RemoveIngestFile(name string) error
+
+	// ReadIngestFile calls fn for each record of a staged sstable
+	ReadIngestFile(name string, fn func(key string, value []byte) error) error
+	// Ingest adds the records of a staged sstable to the database, with their
+	// values converted by transform. The staged file is not removed.
+	Ingest(name string, transform func(key string, value []byte) ([]byte, error)) error
+
 	Flush() error
 
+	// DiskUsage returns the estimated disk space used by the KV, in bytes
+	DiskUsage() int64
+
 	Delete() error
 }
 type FactoryOptions struct {
@@ -131,6 +147,9 @@ type FactoryOptions struct {
 
 	// Create a pure in-memory database. Used for unit-tests
 	InMemory bool
+
+	// When set, the database files are encrypted with the keys from the provider
+	KeyProvider encryption.KeyProvider
 }
 
 var DefaultFactoryOptions = &FactoryOptions{
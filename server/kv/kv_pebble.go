@@ -33,6 +33,7 @@ import (
 	"github.com/streamnative/oxia/common"
 	"github.com/streamnative/oxia/common/compare"
 	"github.com/streamnative/oxia/common/metrics"
+	"github.com/streamnative/oxia/server/encryption"
 )
 
 var (
@@ -55,6 +56,9 @@ type PebbleFactory struct {
 	cache   *pebble.Cache
 	options *FactoryOptions
 
+	// The file system for the databases and their snapshots
+	fs vfs.FS
+
 	gaugeCacheSize metrics.Gauge
 }
 
@@ -77,6 +81,7 @@ func NewPebbleKVFactory(options *FactoryOptions) (Factory, error) {
 	pf := &PebbleFactory{
 		dataDir: dataDir,
 		options: options,
+		fs:      vfs.Default,
 
 		// Share a single cache instance across the databases for all the shards
 		cache: cache,
@@ -88,6 +93,10 @@ func NewPebbleKVFactory(options *FactoryOptions) (Factory, error) {
 			}),
 	}
 
+	if options.KeyProvider != nil {
+		pf.fs = newEncryptedFS(vfs.Default, encryption.NewCipher(options.KeyProvider))
+	}
+
 	// Cleanup leftover snapshots from previous runs
 	if err := pf.cleanupSnapshots(); err != nil {
 		return nil, errors.Wrap(err, "failed to delete database snapshots")
@@ -144,6 +153,7 @@ type Pebble struct {
 	shardId         int64
 	dataDir         string
 	db              *pebble.DB
+	fs              vfs.FS
 	snapshotCounter atomic.Int64
 
 	dbMetrics          func() *pebble.Metrics
@@ -215,7 +225,7 @@ func newKVPebble(factory *PebbleFactory, namespace string, shardId int64) (KV, e
 				FilterType:     pebble.TableFilter,
 			},
 		},
-		FS:         vfs.Default,
+		FS:         factory.fs,
 		DisableWAL: true,
 		Logger: &pebbleLogger{
 			slog.With(
@@ -238,6 +248,7 @@ func newKVPebble(factory *PebbleFactory, namespace string, shardId int64) (KV, e
 	}
 
 	pb.db = db
+	pb.fs = pbOptions.FS
 
 	// Cache the calls to db.Metrics() which are common to all the gauges
 	pb.dbMetrics = common.Memoize(func() *pebble.Metrics {
@@ -373,6 +384,10 @@ func (p *Pebble) Flush() error {
 	return p.db.Flush()
 }
 
+func (p *Pebble) DiskUsage() int64 {
+	return int64(p.dbMetrics().DiskSpaceUsage())
+}
+
 func (p *Pebble) NewWriteBatch() WriteBatch {
 	return &PebbleBatch{p: p, b: p.db.NewIndexedBatch()}
 }
@@ -687,7 +702,7 @@ type pebbleSnapshotLoader struct {
 	shard     int64
 	dbPath    string
 	complete  bool
-	file      *os.File
+	file      vfs.File
 }
 
 func newPebbleSnapshotLoader(pf *PebbleFactory, namespace string, shard int64) (SnapshotLoader, error) {
@@ -724,7 +739,9 @@ func (sl *pebbleSnapshotLoader) AddChunk(fileName string, chunkIndex int32, chun
 		if sl.file != nil {
 			return errors.Errorf("Inconsistent snapshot: previous file not finished")
 		}
-		sl.file, err = os.OpenFile(filepath.Join(sl.dbPath, fileName), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		// The files are written through the database file system, so that they
+		// get encrypted with the keys of this node
+		sl.file, err = sl.pf.fs.Create(filepath.Join(sl.dbPath, fileName))
 		if err != nil {
 			return err
 		}
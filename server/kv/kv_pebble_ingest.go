@@ -0,0 +1,200 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/objstorage/objstorageprovider"
+	"github.com/cockroachdb/pebble/sstable"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// The directory, inside the database directory, where the sstables are
+// staged until they're ingested.
+const ingestDir = "ingest"
+
+// The suffix of the converted sstables, which are moved into the database.
+const ingestConvertedSuffix = ".converted"
+
+var (
+	ErrIngestFileNotFound = errors.New("oxia: ingest file not found")
+	ErrInvalidIngestFile  = errors.New("oxia: invalid ingest file")
+)
+
+// NewIngestFileWriter returns a writer for an sstable that can be ingested in
+// the database of a shard. The keys must be added in the order defined by
+// OxiaSlashSpanComparer, with proto.StorageEntry values.
+func NewIngestFileWriter(f vfs.File) *sstable.Writer {
+	return sstable.NewWriter(objstorageprovider.NewFileWritable(f), sstable.WriterOptions{
+		Comparer:    OxiaSlashSpanComparer,
+		Compression: sstable.ZstdCompression,
+		TableFormat: pebble.FormatNewest.MaxTableFormat(),
+	})
+}
+
+func (p *Pebble) ingestPath(name string) (string, error) {
+	if !filepath.IsLocal(name) || filepath.Base(name) != name || strings.HasSuffix(name, ingestConvertedSuffix) {
+		return "", errors.Wrapf(ErrInvalidIngestFile, "invalid file name %q", name)
+	}
+	return filepath.Join(p.factory.getKVPath(p.namespace, p.shardId), ingestDir, name), nil
+}
+
+type syncedFile struct {
+	vfs.File
+}
+
+func (f *syncedFile) Close() error {
+	return multierr.Combine(f.File.Sync(), f.File.Close())
+}
+
+func (p *Pebble) CreateIngestFile(name string) (io.WriteCloser, error) {
+	path, err := p.ingestPath(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	f, err := p.fs.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &syncedFile{f}, nil
+}
+
+func (p *Pebble) OpenIngestFile(name string) (io.ReadCloser, error) {
+	path, err := p.ingestPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return p.openIngestFile(path)
+}
+
+func (p *Pebble) openIngestFile(path string) (vfs.File, error) {
+	f, err := p.fs.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.Wrap(ErrIngestFileNotFound, filepath.Base(path))
+	}
+	return f, err
+}
+
+func (p *Pebble) RemoveIngestFile(name string) error {
+	path, err := p.ingestPath(name)
+	if err != nil {
+		return err
+	}
+
+	if err := p.fs.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (p *Pebble) openIngestReader(name string) (*sstable.Reader, string, error) {
+	path, err := p.ingestPath(name)
+	if err != nil {
+		return nil, "", err
+	}
+	f, err := p.openIngestFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	readable, err := sstable.NewSimpleReadable(f)
+	if err != nil {
+		return nil, "", multierr.Append(err, f.Close())
+	}
+	reader, err := sstable.NewReader(readable, sstable.ReaderOptions{Comparer: OxiaSlashSpanComparer})
+	if err != nil {
+		return nil, "", errors.Wrap(ErrInvalidIngestFile, err.Error())
+	}
+	return reader, path, nil
+}
+
+func (p *Pebble) ReadIngestFile(name string, fn func(key string, value []byte) error) error {
+	reader, _, err := p.openIngestReader(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = readIngestRecords(reader, fn)
+	return multierr.Append(err, reader.Close())
+}
+
+func (p *Pebble) Ingest(name string, transform func(key string, value []byte) ([]byte, error)) error {
+	reader, path, err := p.openIngestReader(name)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// The records are written in a new sstable, since their values are
+	// converted and the sstable is moved into the database
+	convertedPath := path + ingestConvertedSuffix
+	out, err := p.fs.Create(convertedPath)
+	if err != nil {
+		return err
+	}
+
+	w := sstable.NewWriter(objstorageprovider.NewFileWritable(out), sstable.WriterOptions{
+		Comparer:    OxiaSlashSpanComparer,
+		Compression: sstable.ZstdCompression,
+		TableFormat: p.db.FormatMajorVersion().MaxTableFormat(),
+	})
+	count, err := readIngestRecords(reader, func(key string, value []byte) error {
+		converted, err := transform(key, value)
+		if err != nil {
+			return err
+		}
+		return w.Set([]byte(key), converted)
+	})
+	if err = multierr.Append(err, w.Close()); err != nil || count == 0 {
+		return multierr.Append(err, p.fs.Remove(convertedPath))
+	}
+
+	return p.db.Ingest([]string{convertedPath})
+}
+
+func readIngestRecords(reader *sstable.Reader, fn func(key string, value []byte) error) (count int64, err error) {
+	it, err := reader.NewIter(nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+
+	for k, lv := it.First(); k != nil; k, lv = it.Next() {
+		if k.Kind() != pebble.InternalKeyKindSet {
+			return count, errors.Wrapf(ErrInvalidIngestFile, "unexpected record kind %s", k.Kind())
+		}
+
+		value, _, err := lv.Value(nil)
+		if err != nil {
+			return count, err
+		}
+		if err = fn(string(k.UserKey), value); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, it.Error()
+}
@@ -20,16 +20,18 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/cockroachdb/pebble/vfs"
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
 )
 
 type pebbleSnapshot struct {
+	fs         vfs.FS
 	path       string
 	files      []string
 	chunkCount int32
 	chunkIndex int32
-	file       *os.File
+	file       vfs.File
 }
 
 type pebbleSnapshotChunk struct {
@@ -41,6 +43,9 @@ type pebbleSnapshotChunk struct {
 
 func newPebbleSnapshot(p *Pebble) (Snapshot, error) {
 	ps := &pebbleSnapshot{
+		// The files are read through the database file system, so that the
+		// follower receives them decrypted
+		fs: p.factory.fs,
 		path: filepath.Join(p.dataDir, "snapshots",
 			fmt.Sprintf("shard-%d", p.shardId),
 			fmt.Sprintf("snapshot-%d", p.snapshotCounter.Add(1))),
@@ -126,7 +131,7 @@ func (psf *pebbleSnapshotChunk) TotalCount() int32 {
 func (ps *pebbleSnapshot) initalizeChunkContent() error {
 	var err error
 	filePath := filepath.Join(ps.path, ps.files[0])
-	stat, err := os.Stat(filePath)
+	stat, err := ps.fs.Stat(filePath)
 	if err != nil {
 		return err
 	}
@@ -140,7 +145,7 @@ func (ps *pebbleSnapshot) initalizeChunkContent() error {
 		ps.chunkCount = 1
 	}
 
-	ps.file, err = os.Open(filePath)
+	ps.file, err = ps.fs.Open(filePath)
 	if err != nil {
 		return err
 	}
@@ -156,13 +161,9 @@ func (ps *pebbleSnapshot) NextChunkContent() ([]byte, error) {
 		}
 	}
 
-	_, err := ps.file.Seek(int64(ps.chunkIndex)*MaxSnapshotChunkSize, io.SeekStart)
-	if err != nil {
-		return nil, err
-	}
 	content := make([]byte, MaxSnapshotChunkSize)
-	byteCount, err := io.ReadFull(ps.file, content)
-	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+	byteCount, err := ps.file.ReadAt(content, int64(ps.chunkIndex)*MaxSnapshotChunkSize)
+	if err != nil && !errors.Is(err, io.EOF) {
 		return nil, err
 	}
 	if int64(byteCount) < MaxSnapshotChunkSize {
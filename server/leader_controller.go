@@ -19,9 +19,13 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/dustin/go-humanize"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
 	"google.golang.org/grpc/status"
@@ -37,6 +41,15 @@ import (
 
 var ErrLeaderClosed = errors.New("the leader has been closed")
 
+const (
+	// Maximum time for which the writes are blocked during a leadership handover
+	leaderHandoverTimeout       = 10 * time.Second
+	leaderHandoverCheckInterval = 10 * time.Millisecond
+
+	// Maximum size of the records sent in each export response
+	exportMaxResponseSize = 1024 * 1024
+)
+
 type GetResult struct {
 	Response *proto.GetResponse
 	Err      error
@@ -62,18 +75,46 @@ type LeaderController interface {
 
 	GetNotifications(req *proto.NotificationsRequest, stream proto.OxiaClient_GetNotificationsServer) error
 
+	// Backup streams a snapshot of the database of the shard
+	Backup(req *proto.BackupRequest, stream proto.OxiaAdmin_BackupServer) error
+
+	// Export streams all the records of the shard
+	Export(req *proto.ExportRequest, stream proto.OxiaAdmin_ExportServer) error
+
+	GetRecords(req *proto.GetRecordsRequest) (*proto.GetRecordsResponse, error)
+
+	// GetHotKeys returns the most accessed keys and prefixes of the shard
+	GetHotKeys(req *proto.GetHotKeysRequest) (*proto.GetHotKeysResponse, error)
+
+	// GetSlowOperations returns the last slow operations of the shard
+	GetSlowOperations(req *proto.GetSlowOperationsRequest) (*proto.GetSlowOperationsResponse, error)
+
+	// Ingest stages the sstable received from the stream, starting with the
+	// given chunk, and replicates the entry that ingests it
+	Ingest(chunk *proto.IngestChunk, stream proto.OxiaAdmin_IngestServer) error
+
 	GetStatus(request *proto.GetStatusRequest) (*proto.GetStatusResponse, error)
 	DeleteShard(request *proto.DeleteShardRequest) (*proto.DeleteShardResponse, error)
 
+	// PrepareHandover Stops accepting writes and waits for the new leader to have all the entries
+	PrepareHandover(ctx context.Context, req *proto.PrepareLeaderHandoverRequest) (*proto.PrepareLeaderHandoverResponse, error)
+
 	// Term The current term of the leader
 	Term() int64
 
+	// Namespace The namespace of the shard
+	Namespace() string
+
 	// Status The Status of the leader
 	Status() proto.ServingStatus
 
 	CreateSession(*proto.CreateSessionRequest) (*proto.CreateSessionResponse, error)
 	KeepAlive(sessionId int64) error
 	CloseSession(*proto.CloseSessionRequest) (*proto.CloseSessionResponse, error)
+
+	// SessionIdentity The client identity of the session, which is the user
+	// that created it when the clients are authenticated
+	SessionIdentity(sessionId int64) (string, error)
 }
 
 type leaderController struct {
@@ -87,6 +128,12 @@ type leaderController struct {
 	quorumAckTracker  QuorumAckTracker
 	followers         map[string]FollowerCursor
 
+	// The subset of the followers that are observers, outside the quorum
+	observers map[string]bool
+
+	// The subset of the followers that are witnesses, which only keep the wal
+	witnesses map[string]bool
+
 	// This represents the last entry in the WAL at the time this node
 	// became leader. It's used in the logic for deciding where to
 	// truncate the followers.
@@ -102,11 +149,38 @@ type leaderController struct {
 	log            *slog.Logger
 
 	writeLatencyHisto       metrics.LatencyHistogram
+	pendingWritesGauge      metrics.Gauge
+	hottestKeyGauge         metrics.Gauge
+	hottestPrefixGauge      metrics.Gauge
 	headOffsetGauge         metrics.Gauge
 	commitOffsetGauge       metrics.Gauge
 	followerAckOffsetGauges map[string]metrics.Gauge
 
 	notificationDispatchers map[int64]*notificationDispatcher
+
+	// Number of operations served while being leader, reported
+	// to the coordinator to compute the load of the shard
+	writeOps atomic.Int64
+	readOps  atomic.Int64
+
+	// Writes appended to the WAL whose outcome was not yet returned to the client
+	pendingWrites atomic.Int64
+
+	// Usage of the writes appended to the WAL, checked against the quota
+	quota quotaTracker
+
+	// Slows down and rejects the client writes when the leader is overloaded
+	backpressure *writeBackpressure
+
+	// Samples the keys of the client operations
+	hotKeys *hotKeyTracker
+
+	// The last operations that took longer than the threshold
+	slowOps *slowOperationLog
+
+	// While the leadership is being handed over, new writes are rejected
+	// until this deadline
+	handoverDeadline time.Time
 }
 
 func NewLeaderController(config Config, namespace string, shardId int64, rpcClient ReplicationRpcProvider, walFactory wal.Factory, kvFactory kv.Factory) (LeaderController, error) {
@@ -123,8 +197,25 @@ func NewLeaderController(config Config, namespace string, shardId int64, rpcClie
 		writeLatencyHisto: metrics.NewLatencyHistogram("oxia_server_leader_write_latency",
 			"Latency for write operations in the leader", labels),
 		followerAckOffsetGauges: map[string]metrics.Gauge{},
+		backpressure:            newWriteBackpressure(config.WriteBackpressure, namespace, shardId),
+		hotKeys:                 newHotKeyTracker(config.HotKeysSampleRate),
+		slowOps:                 newSlowOperationLog(config.SlowOperationThreshold, namespace, shardId),
 	}
 
+	lc.pendingWritesGauge = metrics.NewGauge("oxia_server_leader_pending_writes",
+		"The writes appended to the WAL whose outcome was not returned yet", "count", labels, func() int64 {
+			return lc.pendingWrites.Load()
+		})
+	lc.hottestKeyGauge = metrics.NewGauge("oxia_server_leader_hottest_key_share",
+		"The percentage of the sampled operations that went to the hottest key", "%", labels, func() int64 {
+			key, _ := lc.hotKeys.hottestShares()
+			return key
+		})
+	lc.hottestPrefixGauge = metrics.NewGauge("oxia_server_leader_hottest_prefix_share",
+		"The percentage of the sampled operations that went to the hottest key prefix", "%", labels, func() int64 {
+			_, prefix := lc.hotKeys.hottestShares()
+			return prefix
+		})
 	lc.headOffsetGauge = metrics.NewGauge("oxia_server_leader_head_offset",
 		"The current head offset", "offset", labels, func() int64 {
 			qat := lc.quorumAckTracker
@@ -186,6 +277,10 @@ func (lc *leaderController) Status() proto.ServingStatus {
 	return lc.status
 }
 
+func (lc *leaderController) Namespace() string {
+	return lc.namespace
+}
+
 func (lc *leaderController) Term() int64 {
 	lc.RLock()
 	defer lc.RUnlock()
@@ -238,6 +333,8 @@ func (lc *leaderController) NewTerm(req *proto.NewTermRequest) (*proto.NewTermRe
 	lc.setLogger()
 	lc.status = proto.ServingStatus_FENCED
 	lc.replicationFactor = 0
+	lc.handoverDeadline = time.Time{}
+	lc.backpressure.reset()
 
 	lc.headOffsetGauge.Unregister()
 	lc.commitOffsetGauge.Unregister()
@@ -260,6 +357,8 @@ func (lc *leaderController) NewTerm(req *proto.NewTermRequest) (*proto.NewTermRe
 	}
 
 	lc.followers = nil
+	lc.observers = nil
+	lc.witnesses = nil
 	headEntryId, err := getLastEntryIdInWal(lc.wal)
 	if err != nil {
 		return nil, err
@@ -323,6 +422,15 @@ func (lc *leaderController) BecomeLeader(ctx context.Context, req *proto.BecomeL
 
 	lc.replicationFactor = req.GetReplicationFactor()
 	lc.followers = make(map[string]FollowerCursor)
+	lc.observers = make(map[string]bool)
+	lc.witnesses = make(map[string]bool)
+	for _, witness := range req.Witnesses {
+		lc.witnesses[witness] = true
+	}
+
+	if err := lc.recoverEntriesFromWitness(ctx, req); err != nil {
+		return nil, err
+	}
 
 	var err error
 	lc.leaderElectionHeadEntryId, err = getLastEntryIdInWal(lc.wal)
@@ -339,7 +447,11 @@ func (lc *leaderController) BecomeLeader(ctx context.Context, req *proto.BecomeL
 	lc.sessionManager = NewSessionManager(lc.ctx, lc.namespace, lc.shardId, lc)
 
 	for follower, followerHeadEntryId := range req.FollowerMaps {
-		if err := lc.addFollower(follower, followerHeadEntryId); err != nil { //nolint:contextcheck
+		role := FollowerRoleFull
+		if lc.witnesses[follower] {
+			role = FollowerRoleWitness
+		}
+		if err := lc.addFollower(follower, followerHeadEntryId, role); err != nil { //nolint:contextcheck
 			return nil, err
 		}
 	}
@@ -366,6 +478,77 @@ func (lc *leaderController) BecomeLeader(ctx context.Context, req *proto.BecomeL
 	return &proto.BecomeLeaderResponse{}, nil
 }
 
+// The witnesses are never elected, though they count towards the quorum, so
+// they can have entries that are missing in the new leader. The entries after
+// the leader commit offset are replaced with the ones of the witness with the
+// highest head entry, before the leader starts replicating.
+func (lc *leaderController) recoverEntriesFromWitness(ctx context.Context, req *proto.BecomeLeaderRequest) error {
+	headEntryId, err := getLastEntryIdInWal(lc.wal)
+	if err != nil {
+		return err
+	}
+
+	var witness string
+	witnessHeadEntryId := headEntryId
+	for _, w := range req.Witnesses {
+		if e, ok := req.FollowerMaps[w]; ok && isEntryIdAfter(e, witnessHeadEntryId) {
+			witness, witnessHeadEntryId = w, e
+		}
+	}
+	if witness == "" {
+		return nil
+	}
+
+	commitOffset, err := lc.db.ReadCommitOffset()
+	if err != nil {
+		return err
+	}
+
+	stream, err := lc.rpcClient.FetchLog(ctx, witness, &proto.FetchLogRequest{
+		Namespace:   lc.namespace,
+		Shard:       lc.shardId,
+		Term:        lc.term,
+		StartOffset: commitOffset + 1,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch the log from witness %s", witness)
+	}
+
+	if _, err = lc.wal.TruncateLog(commitOffset); err != nil {
+		return err
+	}
+
+	for {
+		entry, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to fetch the log from witness %s", witness)
+		}
+
+		if err = lc.wal.AppendAsync(entry); err != nil {
+			return err
+		}
+	}
+
+	if err = lc.wal.Sync(ctx); err != nil {
+		return err
+	}
+
+	lc.log.Info(
+		"Recovered the entries from witness",
+		slog.String("witness", witness),
+		slog.Int64("commit-offset", commitOffset),
+		slog.Any("leader-head-entry", headEntryId),
+		slog.Any("witness-head-entry", witnessHeadEntryId),
+	)
+	return nil
+}
+
+func isEntryIdAfter(a *proto.EntryId, b *proto.EntryId) bool {
+	return a.Term > b.Term || (a.Term == b.Term && a.Offset > b.Offset)
+}
+
 func (lc *leaderController) AddFollower(req *proto.AddFollowerRequest) (*proto.AddFollowerResponse, error) {
 	lc.Lock()
 	defer lc.Unlock()
@@ -382,18 +565,27 @@ func (lc *leaderController) AddFollower(req *proto.AddFollowerRequest) (*proto.A
 		return &proto.AddFollowerResponse{}, nil
 	}
 
-	if len(lc.followers) == int(lc.replicationFactor)-1 {
+	if !req.Observer && len(lc.followers)-len(lc.observers) == int(lc.replicationFactor)-1 {
 		return nil, errors.New("all followers are already attached")
 	}
 
-	if err := lc.addFollower(req.FollowerName, req.FollowerHeadEntryId); err != nil {
+	role := followerRoleOf(req)
+	if err := lc.addFollower(req.FollowerName, req.FollowerHeadEntryId, role); err != nil {
 		return nil, err
 	}
 
+	switch role {
+	case FollowerRoleObserver:
+		lc.observers[req.FollowerName] = true
+	case FollowerRoleWitness:
+		lc.witnesses[req.FollowerName] = true
+	default:
+	}
+
 	return &proto.AddFollowerResponse{}, nil
 }
 
-func (lc *leaderController) addFollower(follower string, followerHeadEntryId *proto.EntryId) error {
+func (lc *leaderController) addFollower(follower string, followerHeadEntryId *proto.EntryId, role FollowerRole) error {
 	followerHeadEntryId, err := lc.truncateFollowerIfNeeded(follower, followerHeadEntryId)
 	if err != nil {
 		lc.log.Error(
@@ -407,7 +599,7 @@ func (lc *leaderController) addFollower(follower string, followerHeadEntryId *pr
 	}
 
 	cursor, err := NewFollowerCursor(follower, lc.term, lc.namespace, lc.shardId, lc.rpcClient, lc.quorumAckTracker, lc.wal, lc.db,
-		followerHeadEntryId.Offset)
+		followerHeadEntryId.Offset, role)
 	if err != nil {
 		lc.log.Error(
 			"Failed to create follower cursor",
@@ -423,6 +615,7 @@ func (lc *leaderController) addFollower(follower string, followerHeadEntryId *pr
 		slog.Int64("term", lc.term),
 		slog.Any("leader-election-head-entry", lc.leaderElectionHeadEntryId),
 		slog.String("follower", follower),
+		slog.Any("role", role),
 		slog.Any("follower-head-entry", followerHeadEntryId),
 		slog.Int64("head-offset", lc.wal.LastOffset()),
 	)
@@ -448,10 +641,8 @@ func (lc *leaderController) applyAllEntriesIntoDBLoop(r wal.Reader) error {
 		if err = pb.Unmarshal(entry.Value, logEntryValue); err != nil {
 			return err
 		}
-		for _, writeRequest := range logEntryValue.GetRequests().Writes {
-			if _, err = lc.db.ProcessWrite(writeRequest, entry.Offset, entry.Timestamp, WrapperUpdateOperationCallback); err != nil {
-				return err
-			}
+		if err = applyLogEntryValue(lc.db, entry, logEntryValue); err != nil {
+			return err
 		}
 	}
 
@@ -591,33 +782,43 @@ func (lc *leaderController) Read(ctx context.Context, request *proto.ReadRequest
 		return ch
 	}
 
-	go lc.read(ctx, request, ch)
+	lc.readOps.Add(int64(len(request.Gets)))
+	lc.hotKeys.recordRead(request)
+	trace := lc.slowOps.start(slowOperationRead)
+	trace.readKeys(request)
+	go read(ctx, lc.shardId, lc.db, lc.log, request, ch, trace)
 
 	return ch
 }
 
-func (lc *leaderController) read(ctx context.Context, request *proto.ReadRequest, ch chan<- GetResult) {
+func read(ctx context.Context, shardId int64, db kv.DB, log *slog.Logger, request *proto.ReadRequest, ch chan<- GetResult,
+	trace *operationTrace) {
 	common.DoWithLabels(
 		ctx,
 		map[string]string{
 			"oxia":  "read",
-			"shard": fmt.Sprintf("%d", lc.shardId),
+			"shard": fmt.Sprintf("%d", shardId),
 			"peer":  common.GetPeer(ctx),
 		},
 		func() {
-			lc.log.Debug("Received read request")
+			log.Debug("Received read request")
 
 			for _, get := range request.Gets {
-				response, err := lc.db.Get(get)
+				response, err := db.Get(get)
 				if err != nil {
+					trace.finish(err)
 					return
 				}
+				if response.Status == proto.Status_OK {
+					trace.addResults(1, len(response.Value))
+				}
 				ch <- GetResult{Response: response}
 				if ctx.Err() != nil {
 					ch <- GetResult{Err: ctx.Err()}
 					break
 				}
 			}
+			trace.finish(ctx.Err())
 			close(ch)
 		},
 	)
@@ -633,34 +834,39 @@ func (lc *leaderController) List(ctx context.Context, request *proto.ListRequest
 		return nil, err
 	}
 
-	go lc.list(ctx, request, ch)
+	lc.readOps.Add(1)
+	trace := lc.slowOps.start(slowOperationList)
+	trace.keyRange(request.StartInclusive, request.EndExclusive)
+	go list(ctx, lc.shardId, lc.db, lc.log, request, ch, trace)
 
 	return ch, nil
 }
 
-func (lc *leaderController) list(ctx context.Context, request *proto.ListRequest, ch chan<- string) {
+func list(ctx context.Context, shardId int64, db kv.DB, log *slog.Logger, request *proto.ListRequest, ch chan<- string,
+	trace *operationTrace) {
 	common.DoWithLabels(
 		ctx,
 		map[string]string{
 			"oxia":  "list",
-			"shard": fmt.Sprintf("%d", lc.shardId),
+			"shard": fmt.Sprintf("%d", shardId),
 			"peer":  common.GetPeer(ctx),
 		},
 		func() {
-			lc.log.Debug("Received list request", slog.Any("request", request))
+			log.Debug("Received list request", slog.Any("request", request))
 
 			var it kv.KeyIterator
 			var err error
 			if request.SecondaryIndexName != nil {
-				it, err = newSecondaryIndexListIterator(request, lc.db)
+				it, err = newSecondaryIndexListIterator(request, db)
 			} else {
-				it, err = lc.db.List(request)
+				it, err = db.List(request)
 			}
 			if err != nil {
-				lc.log.Warn(
+				log.Warn(
 					"Failed to process list request",
 					slog.Any("error", err),
 				)
+				trace.finish(err)
 				close(ch)
 				return
 			}
@@ -675,18 +881,21 @@ func (lc *leaderController) list(ctx context.Context, request *proto.ListRequest
 			}()
 
 			for ; it.Valid(); it.Next() {
-				ch <- it.Key()
+				key := it.Key()
+				trace.addResults(1, len(key))
+				ch <- key
 				if ctx.Err() != nil {
 					break
 				}
 			}
+			trace.finish(ctx.Err())
 		},
 	)
 }
 
 func (lc *leaderController) ListSliceNoMutex(ctx context.Context, request *proto.ListRequest) ([]string, error) {
 	ch := make(chan string)
-	go lc.list(ctx, request, ch)
+	go list(ctx, lc.shardId, lc.db, lc.log, request, ch, nil)
 	keys := make([]string, 0)
 	for {
 		select {
@@ -712,35 +921,40 @@ func (lc *leaderController) RangeScan(ctx context.Context, request *proto.RangeS
 		return nil, nil, err
 	}
 
-	go lc.rangeScan(ctx, request, ch, errCh)
+	lc.readOps.Add(1)
+	trace := lc.slowOps.start(slowOperationRangeScan)
+	trace.keyRange(request.StartInclusive, request.EndExclusive)
+	go rangeScan(ctx, lc.shardId, lc.db, lc.log, request, ch, errCh, trace)
 
 	return ch, errCh, nil
 }
 
-func (lc *leaderController) rangeScan(ctx context.Context, request *proto.RangeScanRequest, ch chan<- *proto.GetResponse, errCh chan<- error) {
+func rangeScan(ctx context.Context, shardId int64, db kv.DB, log *slog.Logger, //nolint:revive
+	request *proto.RangeScanRequest, ch chan<- *proto.GetResponse, errCh chan<- error, trace *operationTrace) {
 	common.DoWithLabels(
 		ctx,
 		map[string]string{
 			"oxia":  "range-scan",
-			"shard": fmt.Sprintf("%d", lc.shardId),
+			"shard": fmt.Sprintf("%d", shardId),
 			"peer":  common.GetPeer(ctx),
 		},
 		func() {
-			lc.log.Debug("Received list request", slog.Any("request", request))
+			log.Debug("Received list request", slog.Any("request", request))
 
 			var it kv.RangeScanIterator
 			var err error
 			if request.SecondaryIndexName != nil {
-				it, err = newSecondaryIndexRangeScanIterator(request, lc.db)
+				it, err = newSecondaryIndexRangeScanIterator(request, db)
 			} else {
-				it, err = lc.db.RangeScan(request)
+				it, err = db.RangeScan(request)
 			}
 
 			if err != nil {
-				lc.log.Warn(
+				log.Warn(
 					"Failed to process range-scan request",
 					slog.Any("error", err),
 				)
+				trace.finish(err)
 				errCh <- err
 				close(ch)
 				close(errCh)
@@ -760,15 +974,18 @@ func (lc *leaderController) rangeScan(ctx context.Context, request *proto.RangeS
 			for ; it.Valid(); it.Next() {
 				gr, err := it.Value()
 				if err != nil {
+					trace.finish(err)
 					errCh <- err
 					return
 				}
 
+				trace.addResults(1, len(gr.GetKey())+len(gr.Value))
 				ch <- gr
 				if ctx.Err() != nil {
 					break
 				}
 			}
+			trace.finish(ctx.Err())
 		},
 	)
 }
@@ -780,41 +997,72 @@ func (lc *leaderController) rangeScan(ctx context.Context, request *proto.RangeS
 // if that value has not previously been written. The leader adds
 // the entry to its log, updates its head offset.
 func (lc *leaderController) Write(ctx context.Context, request *proto.WriteRequest) (*proto.WriteResponse, error) {
+	if err := lc.applyBackpressure(ctx); err != nil {
+		return nil, err
+	}
+
 	_, resp, err := lc.write(ctx, func(_ int64) *proto.WriteRequest {
 		return request
 	})
 	return resp, err
 }
 
-func (lc *leaderController) write(ctx context.Context, request func(int64) *proto.WriteRequest) (int64, *proto.WriteResponse, error) {
+func (lc *leaderController) write(ctx context.Context, request func(int64) *proto.WriteRequest) (offset int64, response *proto.WriteResponse, err error) {
 	timer := lc.writeLatencyHisto.Timer()
 	defer timer.Done() //nolint:contextcheck
 
 	lc.log.Debug("Write operation")
 
-	actualRequest, newOffset, timestamp, err := lc.appendToWal(ctx, request)
+	trace := lc.slowOps.start(slowOperationWrite)
+	defer func() {
+		trace.finish(err)
+	}()
+
+	actualRequest, reservation, newOffset, timestamp, err := lc.appendToWal(ctx, request)
 	if err != nil {
 		return wal.InvalidOffset, nil, err
 	}
+	defer lc.pendingWrites.Add(-1)
+	defer reservation.release()
+	trace.walAppended()
+	trace.writeKeys(actualRequest)
 
+	ackStart := time.Now()
 	if err := lc.quorumAckTracker.WaitForCommitOffset(ctx, newOffset); err != nil {
 		return wal.InvalidOffset, nil, err
 	}
-	writeResponse, err := lc.db.ProcessWrite(actualRequest, newOffset, timestamp, WrapperUpdateOperationCallback)
-	return newOffset, writeResponse, err
+	lc.backpressure.observeAckLatency(time.Since(ackStart))
+	trace.quorumAcked()
+
+	writeResponse, err := lc.db.ProcessWriteWithUsage(actualRequest, reservation.writeUsage(), newOffset, timestamp,
+		WrapperUpdateOperationCallback)
+	if err == nil {
+		trace.dbApplied()
+	}
+	return newOffset, reservation.merge(writeResponse), err
 }
 
-func (lc *leaderController) appendToWal(ctx context.Context, request func(int64) *proto.WriteRequest) (actualRequest *proto.WriteRequest, offset int64, timestamp uint64, err error) {
+func (lc *leaderController) appendToWal(ctx context.Context, request func(int64) *proto.WriteRequest) (actualRequest *proto.WriteRequest, reservation *quotaReservation, offset int64, timestamp uint64, err error) {
 	lc.Lock()
 
-	if err := checkStatusIsLeader(lc.status); err != nil {
+	if err := lc.checkAcceptingWrites(); err != nil {
 		lc.Unlock()
-		return nil, wal.InvalidOffset, 0, err
+		return nil, nil, wal.InvalidOffset, 0, err
 	}
 
+	lc.pendingWrites.Add(1)
+	defer func() {
+		if err != nil {
+			lc.pendingWrites.Add(-1)
+			reservation.release()
+		}
+	}()
+
 	newOffset := lc.quorumAckTracker.NextOffset()
 	timestamp = uint64(time.Now().UnixMilli())
-	actualRequest = request(newOffset)
+	actualRequest, reservation = lc.quota.check(lc.db, lc.termOptions.Quota, request(newOffset))
+	lc.writeOps.Add(writeOpsCount(actualRequest))
+	lc.hotKeys.recordWrite(actualRequest)
 
 	lc.log.Debug(
 		"Append operation",
@@ -827,12 +1075,13 @@ func (lc *leaderController) appendToWal(ctx context.Context, request func(int64)
 	logEntryValue.Value = &proto.LogEntryValue_Requests{
 		Requests: &proto.WriteRequests{
 			Writes: []*proto.WriteRequest{actualRequest},
+			Usages: reservation.logEntryUsages(),
 		},
 	}
 	value, err := logEntryValue.MarshalVT()
 	if err != nil {
 		lc.Unlock()
-		return actualRequest, wal.InvalidOffset, timestamp, err
+		return actualRequest, reservation, wal.InvalidOffset, timestamp, err
 	}
 	logEntry := &proto.LogEntry{
 		Term:      lc.term,
@@ -843,7 +1092,7 @@ func (lc *leaderController) appendToWal(ctx context.Context, request func(int64)
 
 	if err = lc.wal.AppendAsync(logEntry); err != nil {
 		lc.Unlock()
-		return actualRequest, wal.InvalidOffset, timestamp, errors.Wrap(err, "oxia: failed to append to wal")
+		return actualRequest, reservation, wal.InvalidOffset, timestamp, errors.Wrap(err, "oxia: failed to append to wal")
 	}
 
 	lc.Unlock()
@@ -851,10 +1100,10 @@ func (lc *leaderController) appendToWal(ctx context.Context, request func(int64)
 	// Sync the WAL outside the mutex, so that we can have multiple waiting
 	// sync requests
 	if err = lc.wal.Sync(ctx); err != nil {
-		return actualRequest, wal.InvalidOffset, timestamp, errors.Wrap(err, "oxia: failed to sync the wal")
+		return actualRequest, reservation, wal.InvalidOffset, timestamp, errors.Wrap(err, "oxia: failed to sync the wal")
 	}
 	lc.quorumAckTracker.AdvanceHeadOffset(newOffset)
-	return actualRequest, newOffset, timestamp, nil
+	return actualRequest, reservation, newOffset, timestamp, nil
 }
 
 func (lc *leaderController) WriteStream(stream proto.OxiaClient_WriteStreamServer) error {
@@ -886,10 +1135,15 @@ func (lc *leaderController) WriteStream(stream proto.OxiaClient_WriteStreamServe
 
 func (lc *leaderController) handleWriteStream(stream proto.OxiaClient_WriteStreamServer,
 	closeCh chan error) {
+	inFlight := newWriteStreamInFlight(stream, closeCh)
 	for {
 		req, err := stream.Recv()
 
-		if err != nil {
+		var rejected *writeRejectedError
+		if errors.As(err, &rejected) {
+			inFlight.reject(inFlight.add(nil), rejected.err)
+			continue
+		} else if err != nil {
 			sendNonBlocking(closeCh, err)
 			return
 		} else if req == nil {
@@ -897,57 +1151,86 @@ func (lc *leaderController) handleWriteStream(stream proto.OxiaClient_WriteStrea
 			return
 		}
 
+		if inFlight.failed() {
+			// The stream is going to be closed, the client will get the
+			// error for this request as well
+			continue
+		}
+
+		// Delaying the request also delays the next ones in the stream. A
+		// rejected request fails on its own, without the other in-flight
+		// writes of the stream
+		if err = lc.applyBackpressure(stream.Context()); err != nil {
+			inFlight.reject(inFlight.add(nil), err)
+			continue
+		}
+
 		timer := lc.writeLatencyHisto.Timer()
+		trace := lc.slowOps.start(slowOperationWrite)
 		slog.Debug("Got request in stream",
 			slog.Any("req", req))
 
-		lc.appendToWalStreamRequest(req, func(offset int64, timestamp uint64, err error) {
-			lc.handleWalSynced(stream, req, closeCh, offset, timestamp, err, timer)
+		r := inFlight.add(trace)
+		lc.appendToWalStreamRequest(req, func(actualRequest *proto.WriteRequest, reservation *quotaReservation,
+			offset int64, timestamp uint64, err error) {
+			lc.handleWalSynced(actualRequest, reservation, inFlight, r, offset, timestamp, err, timer, trace)
 		})
 	}
 }
 
-func (lc *leaderController) handleWalSynced(stream proto.OxiaClient_WriteStreamServer,
-	req *proto.WriteRequest, closeCh chan error,
-	offset int64, timestamp uint64, err error, timer metrics.Timer) {
+func (lc *leaderController) handleWalSynced(req *proto.WriteRequest, reservation *quotaReservation,
+	inFlight *writeStreamInFlight, r *writeStreamRequest,
+	offset int64, timestamp uint64, err error, timer metrics.Timer, trace *operationTrace) {
+	trace.writeKeys(req)
 	if err != nil {
 		timer.Done()
-		sendNonBlocking(closeCh, err)
+		inFlight.complete(r, nil, err)
 		return
 	}
 
+	trace.walAppended()
+	ackStart := time.Now()
 	lc.quorumAckTracker.WaitForCommitOffsetAsync(context.Background(), offset, callback.NewOnce(
 		func(_ any) {
 			defer timer.Done()
-			localResponse, err := lc.db.ProcessWrite(req, offset, timestamp, WrapperUpdateOperationCallback)
+			defer lc.pendingWrites.Add(-1)
+			lc.backpressure.observeAckLatency(time.Since(ackStart))
+			trace.quorumAcked()
+			localResponse, err := lc.db.ProcessWriteWithUsage(req, reservation.writeUsage(), offset, timestamp,
+				WrapperUpdateOperationCallback)
+			reservation.release()
 			if err != nil {
-				sendNonBlocking(closeCh, err)
-				return
-			}
-			if err = stream.Send(localResponse); err != nil {
-				sendNonBlocking(closeCh, err)
+				inFlight.complete(r, nil, err)
 				return
 			}
+			trace.dbApplied()
+			inFlight.complete(r, reservation.merge(localResponse), nil)
 		},
 		func(err error) {
 			defer timer.Done()
-			sendNonBlocking(closeCh, err)
+			defer lc.pendingWrites.Add(-1)
+			reservation.release()
+			inFlight.complete(r, nil, err)
 		},
 	))
 }
 
 func (lc *leaderController) appendToWalStreamRequest(request *proto.WriteRequest,
-	cb func(offset int64, timestamp uint64, err error)) {
+	cb func(actualRequest *proto.WriteRequest, reservation *quotaReservation, offset int64, timestamp uint64, err error)) {
 	lc.Lock()
 
-	if err := checkStatusIsLeader(lc.status); err != nil {
+	if err := lc.checkAcceptingWrites(); err != nil {
 		lc.Unlock()
-		cb(wal.InvalidOffset, 0, err)
+		cb(request, nil, wal.InvalidOffset, 0, err)
 		return
 	}
 
+	lc.pendingWrites.Add(1)
 	newOffset := lc.quorumAckTracker.NextOffset()
 	timestamp := uint64(time.Now().UnixMilli())
+	request, reservation := lc.quota.check(lc.db, lc.termOptions.Quota, request)
+	lc.writeOps.Add(writeOpsCount(request))
+	lc.hotKeys.recordWrite(request)
 
 	lc.log.Debug(
 		"Append operation",
@@ -960,12 +1243,15 @@ func (lc *leaderController) appendToWalStreamRequest(request *proto.WriteRequest
 	logEntryValue.Value = &proto.LogEntryValue_Requests{
 		Requests: &proto.WriteRequests{
 			Writes: []*proto.WriteRequest{request},
+			Usages: reservation.logEntryUsages(),
 		},
 	}
 	value, err := logEntryValue.MarshalVT()
 	if err != nil {
+		lc.pendingWrites.Add(-1)
+		reservation.release()
 		lc.Unlock()
-		cb(wal.InvalidOffset, timestamp, err)
+		cb(request, nil, wal.InvalidOffset, timestamp, err)
 		return
 	}
 	logEntry := &proto.LogEntry{
@@ -977,15 +1263,51 @@ func (lc *leaderController) appendToWalStreamRequest(request *proto.WriteRequest
 
 	lc.wal.AppendAndSync(logEntry, func(err error) {
 		if err != nil {
-			cb(wal.InvalidOffset, timestamp, errors.Wrap(err, "oxia: failed to append to wal"))
+			lc.pendingWrites.Add(-1)
+			reservation.release()
+			cb(request, nil, wal.InvalidOffset, timestamp, errors.Wrap(err, "oxia: failed to append to wal"))
 		} else {
 			lc.quorumAckTracker.AdvanceHeadOffset(newOffset)
-			cb(newOffset, timestamp, nil)
+			cb(request, reservation, newOffset, timestamp, nil)
 		}
 	})
 	lc.Unlock()
 }
 
+// Delays or rejects a new client write, depending on the load of the leader.
+func (lc *leaderController) applyBackpressure(ctx context.Context) error {
+	return lc.backpressure.throttle(ctx, lc.pendingWrites.Load(), lc.quorumFollowerLag)
+}
+
+// The number of entries that the followers needed for the quorum are behind
+// the head offset. The observers and the slower followers are not counted.
+func (lc *leaderController) quorumFollowerLag() int64 {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	requiredAcks := int(lc.replicationFactor / 2)
+	if lc.quorumAckTracker == nil || requiredAcks == 0 {
+		return 0
+	}
+
+	headOffset := lc.quorumAckTracker.HeadOffset()
+	lags := make([]int64, 0, len(lc.followers))
+	for name, follower := range lc.followers {
+		if lc.observers[name] {
+			continue
+		}
+		lags = append(lags, headOffset-follower.AckOffset())
+	}
+	if len(lags) < requiredAcks {
+		// The writes can't be committed anyway, and they are limited by the
+		// number of pending ones
+		return 0
+	}
+
+	slices.Sort(lags)
+	return lags[requiredAcks-1]
+}
+
 // ////
 
 func (lc *leaderController) GetNotifications(req *proto.NotificationsRequest, stream proto.OxiaClient_GetNotificationsServer) error {
@@ -995,6 +1317,298 @@ func (lc *leaderController) GetNotifications(req *proto.NotificationsRequest, st
 	return startNotificationDispatcher(lc, req, stream)
 }
 
+func (lc *leaderController) Backup(req *proto.BackupRequest, stream proto.OxiaAdmin_BackupServer) error {
+	lc.RLock()
+	if err := checkStatusIsLeader(lc.status); err != nil {
+		lc.RUnlock()
+		return err
+	}
+	if req.Namespace != lc.namespace {
+		lc.RUnlock()
+		return common.ErrorNamespaceNotFound
+	}
+	term := lc.term
+	snapshot, err := lc.db.Snapshot()
+	lc.RUnlock()
+	if err != nil {
+		return errors.Wrap(err, "failed to take the snapshot")
+	}
+
+	defer snapshot.Close()
+
+	var totalSize int64
+	startTime := time.Now()
+	for ; snapshot.Valid(); snapshot.Next() {
+		chunk, err := snapshot.Chunk()
+		if err != nil {
+			return err
+		}
+
+		content := chunk.Content()
+		if err := stream.Send(&proto.SnapshotChunk{
+			Term:       term,
+			Name:       chunk.Name(),
+			ChunkIndex: chunk.Index(),
+			ChunkCount: chunk.TotalCount(),
+			Content:    content,
+		}); err != nil {
+			return err
+		}
+		totalSize += int64(len(content))
+	}
+
+	lc.log.Info(
+		"Sent the backup of the shard",
+		slog.String("peer", common.GetPeer(stream.Context())),
+		slog.String("total-size", humanize.IBytes(uint64(totalSize))),
+		slog.Any("elapsed-time", time.Since(startTime)),
+	)
+	return nil
+}
+
+func (lc *leaderController) Export(req *proto.ExportRequest, stream proto.OxiaAdmin_ExportServer) error {
+	lc.RLock()
+	if err := checkStatusIsLeader(lc.status); err != nil {
+		lc.RUnlock()
+		return err
+	}
+	if req.Namespace != lc.namespace {
+		lc.RUnlock()
+		return common.ErrorNamespaceNotFound
+	}
+	it, err := lc.db.Export()
+	lc.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	defer it.Close()
+
+	var count int64
+	startTime := time.Now()
+	response := &proto.ExportResponse{}
+	responseSize := 0
+	for ; it.Valid(); it.Next() {
+		se, err := it.Value()
+		if err != nil {
+			return err
+		}
+
+		// The ephemeral records belong to the sessions of this cluster
+		if se.SessionId != nil {
+			continue
+		}
+
+		record := exportedRecordOf(it.Key(), se)
+		response.Records = append(response.Records, record)
+		responseSize += record.SizeVT()
+		count++
+
+		if responseSize >= exportMaxResponseSize {
+			if err := stream.Send(response); err != nil {
+				return err
+			}
+			response = &proto.ExportResponse{}
+			responseSize = 0
+		}
+	}
+
+	if len(response.Records) > 0 {
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+	}
+
+	lc.log.Info(
+		"Exported the records of the shard",
+		slog.String("peer", common.GetPeer(stream.Context())),
+		slog.Int64("records", count),
+		slog.Any("elapsed-time", time.Since(startTime)),
+	)
+	return nil
+}
+
+func exportedRecordOf(key string, se *proto.StorageEntry) *proto.ExportedRecord {
+	return &proto.ExportedRecord{
+		Key:                   key,
+		Value:                 se.Value,
+		VersionId:             se.VersionId,
+		ModificationsCount:    se.ModificationsCount,
+		CreationTimestamp:     se.CreationTimestamp,
+		ModificationTimestamp: se.ModificationTimestamp,
+		PartitionKey:          se.PartitionKey,
+		SecondaryIndexes:      se.SecondaryIndexes,
+	}
+}
+
+func (lc *leaderController) GetRecords(req *proto.GetRecordsRequest) (*proto.GetRecordsResponse, error) {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	if err := checkStatusIsLeader(lc.status); err != nil {
+		return nil, err
+	}
+	if req.Namespace != lc.namespace {
+		return nil, common.ErrorNamespaceNotFound
+	}
+
+	response := &proto.GetRecordsResponse{}
+	for _, key := range req.Keys {
+		se, err := lc.db.ReadRecord(key)
+		if errors.Is(err, kv.ErrKeyNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		// The ephemeral records belong to the sessions of this cluster
+		if se.SessionId != nil {
+			continue
+		}
+		response.Records = append(response.Records, exportedRecordOf(key, se))
+	}
+	return response, nil
+}
+
+func (lc *leaderController) GetHotKeys(req *proto.GetHotKeysRequest) (*proto.GetHotKeysResponse, error) {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	if err := checkStatusIsLeader(lc.status); err != nil {
+		return nil, err
+	}
+	if req.Namespace != lc.namespace {
+		return nil, common.ErrorNamespaceNotFound
+	}
+	if lc.hotKeys == nil {
+		return nil, common.ErrorHotKeysNotEnabled
+	}
+	return lc.hotKeys.hotKeys(int(req.Limit)), nil
+}
+
+func (lc *leaderController) GetSlowOperations(req *proto.GetSlowOperationsRequest) (*proto.GetSlowOperationsResponse, error) {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	if err := checkStatusIsLeader(lc.status); err != nil {
+		return nil, err
+	}
+	if req.Namespace != lc.namespace {
+		return nil, common.ErrorNamespaceNotFound
+	}
+	if lc.slowOps == nil {
+		return nil, common.ErrorSlowOperationsNotEnabled
+	}
+	return &proto.GetSlowOperationsResponse{Operations: lc.slowOps.last(int(req.Limit))}, nil
+}
+
+func (lc *leaderController) Ingest(chunk *proto.IngestChunk, stream proto.OxiaAdmin_IngestServer) error {
+	lc.RLock()
+	if err := checkStatusIsLeader(lc.status); err != nil {
+		lc.RUnlock()
+		return err
+	}
+	if chunk.Namespace != lc.namespace {
+		lc.RUnlock()
+		return common.ErrorNamespaceNotFound
+	}
+	db := lc.db
+	lc.RUnlock()
+
+	startTime := time.Now()
+	req := &proto.IngestRequest{FileName: uuid.NewString() + ".sst"}
+	w, err := db.CreateIngestFile(req.FileName)
+	if err != nil {
+		return err
+	}
+
+	totalSize, err := receiveIngestFile(w, chunk.Content, func() ([]byte, error) {
+		chunk, err := stream.Recv()
+		return chunk.GetContent(), err
+	})
+	if err != nil {
+		return multierr.Append(err, db.RemoveIngestFile(req.FileName))
+	}
+
+	// An invalid sstable must be rejected before it's replicated, since the
+	// entry could never be applied
+	count, err := db.ValidateIngestFile(req.FileName)
+	if err != nil {
+		return multierr.Append(err, db.RemoveIngestFile(req.FileName))
+	}
+
+	// Once appended, the entry must be applied even if the client is gone
+	offset, err := lc.ingest(lc.ctx, req) //nolint:contextcheck
+	if err != nil {
+		return err
+	}
+
+	lc.log.Info(
+		"Ingested sstable",
+		slog.String("peer", common.GetPeer(stream.Context())),
+		slog.Int64("offset", offset),
+		slog.Int64("records", count),
+		slog.String("size", humanize.IBytes(uint64(totalSize))),
+		slog.Any("elapsed-time", time.Since(startTime)),
+	)
+	return stream.SendAndClose(&proto.IngestResponse{Offset: offset})
+}
+
+func (lc *leaderController) ingest(ctx context.Context, req *proto.IngestRequest) (int64, error) {
+	offset, timestamp, err := lc.appendIngestToWal(ctx, req)
+	if err != nil {
+		return wal.InvalidOffset, err
+	}
+	defer lc.pendingWrites.Add(-1)
+
+	if err := lc.quorumAckTracker.WaitForCommitOffset(ctx, offset); err != nil {
+		return wal.InvalidOffset, err
+	}
+	return offset, lc.db.ProcessIngest(req, offset, timestamp)
+}
+
+func (lc *leaderController) appendIngestToWal(ctx context.Context, req *proto.IngestRequest) (offset int64, timestamp uint64, err error) {
+	lc.Lock()
+
+	if err := lc.checkAcceptingWrites(); err != nil {
+		lc.Unlock()
+		return wal.InvalidOffset, 0, err
+	}
+
+	lc.pendingWrites.Add(1)
+	defer func() {
+		if err != nil {
+			lc.pendingWrites.Add(-1)
+		}
+	}()
+
+	offset = lc.quorumAckTracker.NextOffset()
+	timestamp = uint64(time.Now().UnixMilli())
+	value, err := (&proto.LogEntryValue{Value: &proto.LogEntryValue_Ingest{Ingest: req}}).MarshalVT()
+	if err != nil {
+		lc.Unlock()
+		return wal.InvalidOffset, timestamp, err
+	}
+
+	if err = lc.wal.AppendAsync(&proto.LogEntry{
+		Term:      lc.term,
+		Offset:    offset,
+		Value:     value,
+		Timestamp: timestamp,
+	}); err != nil {
+		lc.Unlock()
+		return wal.InvalidOffset, timestamp, errors.Wrap(err, "oxia: failed to append to wal")
+	}
+
+	lc.Unlock()
+
+	if err = lc.wal.Sync(ctx); err != nil {
+		return wal.InvalidOffset, timestamp, errors.Wrap(err, "oxia: failed to sync the wal")
+	}
+	lc.quorumAckTracker.AdvanceHeadOffset(offset)
+	return offset, timestamp, nil
+}
+
 func (lc *leaderController) isClosed() bool {
 	return lc.ctx.Err() != nil
 }
@@ -1016,11 +1630,16 @@ func (lc *leaderController) close() error {
 		err = multierr.Append(err, follower.Close())
 	}
 	lc.followers = nil
+	lc.observers = nil
+	lc.witnesses = nil
 
 	for _, g := range lc.followerAckOffsetGauges {
 		g.Unregister()
 	}
 	lc.followerAckOffsetGauges = map[string]metrics.Gauge{}
+	lc.pendingWritesGauge.Unregister()
+	lc.hottestKeyGauge.Unregister()
+	lc.hottestPrefixGauge.Unregister()
 
 	err = lc.sessionManager.Close()
 
@@ -1078,20 +1697,40 @@ func (lc *leaderController) GetStatus(_ *proto.GetStatusRequest) (*proto.GetStat
 	var (
 		headOffset   = wal.InvalidOffset
 		commitOffset = wal.InvalidOffset
+		dbSize       int64
+		walSize      int64
+		usage        kv.Usage
 	)
 	if lc.quorumAckTracker != nil {
 		headOffset = lc.quorumAckTracker.HeadOffset()
 		commitOffset = lc.quorumAckTracker.CommitOffset()
 	}
+	if lc.db != nil {
+		dbSize = lc.db.DiskUsage()
+		usage = lc.db.Usage()
+	}
+	if lc.wal != nil {
+		walSize = lc.wal.DiskUsage()
+	}
 
 	return &proto.GetStatusResponse{
 		Term:         lc.term,
 		Status:       lc.status,
 		HeadOffset:   headOffset,
 		CommitOffset: commitOffset,
+		WriteOps:     lc.writeOps.Load(),
+		ReadOps:      lc.readOps.Load(),
+		DbSize:       dbSize,
+		WalSize:      walSize,
+		Keys:         usage.Keys,
+		DataSize:     usage.Bytes,
 	}, nil
 }
 
+func writeOpsCount(request *proto.WriteRequest) int64 {
+	return int64(len(request.Puts) + len(request.Deletes) + len(request.DeleteRanges))
+}
+
 func (lc *leaderController) DeleteShard(request *proto.DeleteShardRequest) (*proto.DeleteShardResponse, error) {
 	lc.Lock()
 	defer lc.Unlock()
@@ -1124,6 +1763,97 @@ func (lc *leaderController) DeleteShard(request *proto.DeleteShardRequest) (*pro
 	return &proto.DeleteShardResponse{}, nil
 }
 
+// PrepareHandover
+// The coordinator is transferring the leadership of the shard to one of the followers.
+//
+// To avoid failing any client request, the leader stops accepting new writes, which
+// are rejected with a retriable error, and waits for all the pending writes to be
+// completed and for the new leader to have acknowledged all the entries. After that,
+// the coordinator can fence the shard and elect the new leader, knowing it is the
+// most up-to-date node.
+//
+// Writes are only blocked for a limited time, if the new term doesn't arrive before
+// the handover deadline, the leader goes back to accepting them.
+func (lc *leaderController) PrepareHandover(ctx context.Context, req *proto.PrepareLeaderHandoverRequest) (*proto.PrepareLeaderHandoverResponse, error) {
+	lc.Lock()
+
+	if err := checkStatusIsLeader(lc.status); err != nil {
+		lc.Unlock()
+		return nil, err
+	}
+
+	if req.Term != lc.term {
+		lc.Unlock()
+		return nil, common.ErrorInvalidTerm
+	}
+
+	newLeader, ok := lc.followers[req.NewLeader]
+	if !ok || lc.observers[req.NewLeader] || lc.witnesses[req.NewLeader] {
+		lc.Unlock()
+		return nil, status.Errorf(common.CodeNodeIsNotFollower, "node %s is not a follower for shard %d",
+			req.NewLeader, lc.shardId)
+	}
+
+	deadline := time.Now().Add(leaderHandoverTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	lc.handoverDeadline = deadline
+	quorumAckTracker := lc.quorumAckTracker
+	lc.Unlock()
+
+	lc.log.Info(
+		"Preparing leadership handover",
+		slog.String("new-leader", req.NewLeader),
+	)
+
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	ticker := time.NewTicker(leaderHandoverCheckInterval)
+	defer ticker.Stop()
+
+	for lc.pendingWrites.Load() > 0 || newLeader.AckOffset() < quorumAckTracker.HeadOffset() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			lc.cancelHandover(req.Term)
+			return nil, errors.Wrap(ctx.Err(), "oxia: timed out waiting for the new leader to catch up")
+		}
+	}
+
+	headOffset := quorumAckTracker.HeadOffset()
+	lc.log.Info(
+		"Ready for leadership handover",
+		slog.String("new-leader", req.NewLeader),
+		slog.Int64("head-offset", headOffset),
+	)
+	return &proto.PrepareLeaderHandoverResponse{
+		HeadOffset: headOffset,
+	}, nil
+}
+
+func (lc *leaderController) cancelHandover(term int64) {
+	lc.Lock()
+	defer lc.Unlock()
+
+	if lc.term == term {
+		lc.handoverDeadline = time.Time{}
+	}
+}
+
+func (lc *leaderController) checkAcceptingWrites() error {
+	if err := checkStatusIsLeader(lc.status); err != nil {
+		return err
+	}
+
+	if time.Now().Before(lc.handoverDeadline) {
+		// The client will retry the write on the new leader
+		return status.Errorf(common.CodeNodeIsNotLeader, "leadership of shard %d is being transferred", lc.shardId)
+	}
+	return nil
+}
+
 func (lc *leaderController) CreateSession(request *proto.CreateSessionRequest) (*proto.CreateSessionResponse, error) {
 	return lc.sessionManager.CreateSession(request)
 }
@@ -1136,6 +1866,10 @@ func (lc *leaderController) CloseSession(request *proto.CloseSessionRequest) (*p
 	return lc.sessionManager.CloseSession(request)
 }
 
+func (lc *leaderController) SessionIdentity(sessionId int64) (string, error) {
+	return lc.sessionManager.SessionIdentity(sessionId)
+}
+
 func checkStatusIsLeader(actual proto.ServingStatus) error {
 	if actual != proto.ServingStatus_LEADER {
 		return status.Errorf(common.CodeInvalidStatus, "Received message in the wrong state. In %+v, should be %+v.", actual, proto.ServingStatus_LEADER)
@@ -1143,6 +1877,102 @@ func checkStatusIsLeader(actual proto.ServingStatus) error {
 	return nil
 }
 
+// writeStreamInFlight keeps track of the requests of a write stream that were not
+// completed yet. The clients match the responses with the requests by their
+// order, so a response is only sent after the ones of all the preceding
+// requests. When a request fails, the stream is closed once the responses for
+// all the preceding requests were sent, so that clients don't lose the outcome
+// of writes that were already accepted.
+type writeStreamInFlight struct {
+	sync.Mutex
+
+	stream   proto.OxiaClient_WriteStreamServer
+	requests []*writeStreamRequest
+	failing  bool
+	closeErr error
+	closeCh  chan error
+}
+
+// writeStreamRequest is the outcome of a request of a write stream, which is
+// either its response or the error that closes the stream. The trace of the
+// request is finished once the outcome is sent.
+type writeStreamRequest struct {
+	completed bool
+	response  *proto.WriteResponse
+	err       error
+	trace     *operationTrace
+}
+
+// writeRejectedError is returned by the wrappers of the write stream for a
+// request that is rejected without closing the stream.
+type writeRejectedError struct {
+	err error
+}
+
+func (e *writeRejectedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *writeRejectedError) Unwrap() error {
+	return e.err
+}
+
+func newWriteStreamInFlight(stream proto.OxiaClient_WriteStreamServer, closeCh chan error) *writeStreamInFlight {
+	return &writeStreamInFlight{stream: stream, closeCh: closeCh}
+}
+
+func (w *writeStreamInFlight) add(trace *operationTrace) *writeStreamRequest {
+	w.Lock()
+	defer w.Unlock()
+	r := &writeStreamRequest{trace: trace}
+	w.requests = append(w.requests, r)
+	return r
+}
+
+// complete sets the outcome of the request, and sends the responses that are
+// not waiting for the preceding requests anymore.
+func (w *writeStreamInFlight) complete(r *writeStreamRequest, response *proto.WriteResponse, err error) {
+	w.Lock()
+	defer w.Unlock()
+	r.completed = true
+	r.response = response
+	r.err = err
+	if err != nil {
+		w.failing = true
+	}
+
+	for len(w.requests) > 0 && w.requests[0].completed {
+		next := w.requests[0]
+		w.requests = w.requests[1:]
+		switch {
+		case w.closeErr != nil:
+			// The stream is closed, the response is not going to be sent
+			if next.err == nil {
+				next.err = w.closeErr
+			}
+		case next.err == nil:
+			next.err = w.stream.Send(next.response)
+		}
+		if next.err != nil && w.closeErr == nil {
+			w.closeErr = next.err
+			sendNonBlocking(w.closeCh, next.err)
+		}
+		next.trace.finish(next.err)
+	}
+}
+
+// reject completes the request with the response of a rejection, while the
+// stream is kept open.
+func (w *writeStreamInFlight) reject(r *writeStreamRequest, err error) {
+	w.complete(r, common.NewWriteRejection(err), nil)
+}
+
+func (w *writeStreamInFlight) failed() bool {
+	w.Lock()
+	defer w.Unlock()
+	return w.failing
+}
+
 func sendNonBlocking(ch chan error, err error) {
 	select {
 	case ch <- err:
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 	pb "google.golang.org/protobuf/proto"
@@ -553,6 +554,149 @@ func TestLeaderController_AddFollower(t *testing.T) {
 	assert.NoError(t, walFactory.Close())
 }
 
+func TestLeaderController_AddObserver(t *testing.T) {
+	var shard int64 = 1
+
+	kvFactory, err := kv.NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	walFactory := newTestWalFactory(t)
+
+	lc, err := NewLeaderController(Config{}, common.DefaultNamespace, shard, newMockRpcClient(), walFactory, kvFactory)
+	assert.NoError(t, err)
+
+	_, err = lc.NewTerm(&proto.NewTermRequest{
+		Term:  5,
+		Shard: shard,
+	})
+	assert.NoError(t, err)
+
+	_, err = lc.BecomeLeader(context.Background(), &proto.BecomeLeaderRequest{
+		Shard:             shard,
+		Term:              5,
+		ReplicationFactor: 1,
+		FollowerMaps:      nil,
+	})
+	assert.NoError(t, err)
+
+	// Observers are attached on top of the replication factor
+	afRes, err := lc.AddFollower(&proto.AddFollowerRequest{
+		Shard:               shard,
+		Term:                5,
+		FollowerName:        "o1",
+		FollowerHeadEntryId: InvalidEntryId,
+		Observer:            true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &proto.AddFollowerResponse{}, afRes)
+
+	afRes, err = lc.AddFollower(&proto.AddFollowerRequest{
+		Shard:               shard,
+		Term:                5,
+		FollowerName:        "f1",
+		FollowerHeadEntryId: InvalidEntryId,
+	})
+	assert.Nil(t, afRes)
+	assert.Error(t, err)
+
+	// The writes are committed without waiting for the observer
+	res, err := lc.Write(context.Background(), &proto.WriteRequest{
+		Shard: &shard,
+		Puts: []*proto.PutRequest{{
+			Key:   "a",
+			Value: []byte("value-a")}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, proto.Status_OK, res.Puts[0].Status)
+
+	// Observers can't take over the leadership
+	_, err = lc.PrepareHandover(context.Background(), &proto.PrepareLeaderHandoverRequest{
+		Shard:     shard,
+		Term:      5,
+		NewLeader: "o1",
+	})
+	assert.Equal(t, common.CodeNodeIsNotFollower, status.Code(err))
+
+	assert.NoError(t, lc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
+
+func TestLeaderController_RecoverEntriesFromWitness(t *testing.T) {
+	var shard int64 = 1
+
+	kvFactory, err := kv.NewPebbleKVFactory(&kv.FactoryOptions{DataDir: t.TempDir()})
+	assert.NoError(t, err)
+	walFactory := wal.NewWalFactory(&wal.FactoryOptions{BaseWalDir: t.TempDir()})
+
+	// The leader has applied the first entry, while the second one was
+	// replaced in the witness by a later term
+	walObject, err := walFactory.NewWal(common.DefaultNamespace, shard, nil)
+	assert.NoError(t, err)
+	db, err := kv.NewDB(common.DefaultNamespace, shard, kvFactory, 1*time.Hour, common.SystemClock)
+	assert.NoError(t, err)
+
+	assert.NoError(t, walObject.Append(createAddRequest(t, 1, 0, map[string]string{"a": "0"}, wal.InvalidOffset).Entry))
+	assert.NoError(t, walObject.Append(createAddRequest(t, 1, 1, map[string]string{"b": "stale"}, wal.InvalidOffset).Entry))
+	_, err = db.ProcessWrite(&proto.WriteRequest{Puts: []*proto.PutRequest{{Key: "a", Value: []byte("0")}}}, 0, 0, kv.NoOpCallback)
+	assert.NoError(t, err)
+
+	assert.NoError(t, db.UpdateTerm(2, kv.TermOptions{}))
+	assert.NoError(t, db.Close())
+	assert.NoError(t, walObject.Close())
+
+	rpc := newMockRpcClient()
+	rpc.fetchLogEntries = []*proto.LogEntry{
+		createAddRequest(t, 2, 1, map[string]string{"b": "1"}, wal.InvalidOffset).Entry,
+		createAddRequest(t, 2, 2, map[string]string{"c": "2"}, wal.InvalidOffset).Entry,
+	}
+
+	lc, err := NewLeaderController(Config{}, common.DefaultNamespace, shard, rpc, walFactory, kvFactory)
+	assert.NoError(t, err)
+
+	_, err = lc.NewTerm(&proto.NewTermRequest{
+		Term:  3,
+		Shard: shard,
+	})
+	assert.NoError(t, err)
+
+	_, err = lc.BecomeLeader(context.Background(), &proto.BecomeLeaderRequest{
+		Shard:             shard,
+		Term:              3,
+		ReplicationFactor: 2,
+		FollowerMaps: map[string]*proto.EntryId{
+			"w1": {Term: 2, Offset: 2},
+		},
+		Witnesses: []string{"w1"},
+	})
+	assert.NoError(t, err)
+
+	req := <-rpc.fetchLogReqs
+	assert.EqualValues(t, 3, req.Term)
+	assert.EqualValues(t, 1, req.StartOffset)
+
+	for key, value := range map[string]string{"a": "0", "b": "1", "c": "2"} {
+		r := <-lc.Read(context.Background(), &proto.ReadRequest{
+			Shard: &shard,
+			Gets:  []*proto.GetRequest{{Key: key, IncludeValue: true}},
+		})
+		assert.NoError(t, r.Err)
+		assert.Equal(t, proto.Status_OK, r.Response.Status)
+		assert.Equal(t, []byte(value), r.Response.Value)
+	}
+
+	// Witnesses can't take over the leadership
+	_, err = lc.PrepareHandover(context.Background(), &proto.PrepareLeaderHandoverRequest{
+		Shard:     shard,
+		Term:      3,
+		NewLeader: "w1",
+	})
+	assert.Equal(t, common.CodeNodeIsNotFollower, status.Code(err))
+
+	assert.NoError(t, lc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
+
 func TestLeaderController_AddFollowerRepeated(t *testing.T) {
 	var shard int64 = 1
 
@@ -1195,20 +1339,227 @@ func TestLeaderController_GetStatus(t *testing.T) {
 			Value: []byte("value-b")}},
 	})
 
+	// Read entry
+	r := <-lc.Read(context.Background(), &proto.ReadRequest{
+		Shard: &shard,
+		Gets:  []*proto.GetRequest{{Key: "a"}},
+	})
+	assert.NoError(t, r.Err)
+
 	res, err := lc.GetStatus(&proto.GetStatusRequest{Shard: shard})
 	assert.NoError(t, err)
-	assert.Equal(t, &proto.GetStatusResponse{
-		Term:         2,
-		Status:       proto.ServingStatus_LEADER,
-		HeadOffset:   1,
-		CommitOffset: 1,
-	}, res)
+	assert.EqualValues(t, 2, res.Term)
+	assert.Equal(t, proto.ServingStatus_LEADER, res.Status)
+	assert.EqualValues(t, 1, res.HeadOffset)
+	assert.EqualValues(t, 1, res.CommitOffset)
+	assert.EqualValues(t, 2, res.WriteOps)
+	assert.EqualValues(t, 1, res.ReadOps)
+	assert.Positive(t, res.DbSize)
+	assert.Positive(t, res.WalSize)
 
 	assert.NoError(t, lc.Close())
 	assert.NoError(t, kvFactory.Close())
 	assert.NoError(t, walFactory.Close())
 }
 
+func TestLeaderController_Quota(t *testing.T) {
+	var shard int64 = 1
+
+	kvFactory, err := kv.NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	walFactory := newTestWalFactory(t)
+
+	lc, err := NewLeaderController(Config{}, common.DefaultNamespace, shard, newMockRpcClient(), walFactory, kvFactory)
+	assert.NoError(t, err)
+	_, err = lc.NewTerm(&proto.NewTermRequest{
+		Shard: shard,
+		Term:  1,
+		Options: &proto.NewTermOptions{
+			EnableNotifications: true,
+			Quota:               &proto.ShardQuota{MaxBytes: 15, MaxKeys: 2, MaxValueSize: 8},
+		},
+	})
+	assert.NoError(t, err)
+	_, err = lc.BecomeLeader(context.Background(), &proto.BecomeLeaderRequest{
+		Shard:             shard,
+		Term:              1,
+		ReplicationFactor: 1,
+		FollowerMaps:      nil,
+	})
+	assert.NoError(t, err)
+
+	putsStatus := func(puts ...*proto.PutRequest) []proto.Status {
+		res, err := lc.Write(context.Background(), &proto.WriteRequest{Shard: &shard, Puts: puts})
+		assert.NoError(t, err)
+		var statuses []proto.Status
+		for _, pr := range res.Puts {
+			statuses = append(statuses, pr.Status)
+		}
+		return statuses
+	}
+
+	assert.Equal(t, []proto.Status{proto.Status_QUOTA_EXCEEDED, proto.Status_OK, proto.Status_OK, proto.Status_QUOTA_EXCEEDED},
+		putsStatus(
+			&proto.PutRequest{Key: "a", Value: []byte("value-too-large")},
+			&proto.PutRequest{Key: "a", Value: []byte("0")},
+			&proto.PutRequest{Key: "b", Value: []byte("0")},
+			// The max keys is reached
+			&proto.PutRequest{Key: "c", Value: []byte("0")},
+		))
+
+	// Overwriting the existing records doesn't add keys
+	assert.Equal(t, []proto.Status{proto.Status_OK}, putsStatus(&proto.PutRequest{Key: "a", Value: []byte("01234567")}))
+	// The max bytes would be exceeded
+	assert.Equal(t, []proto.Status{proto.Status_QUOTA_EXCEEDED}, putsStatus(&proto.PutRequest{Key: "b", Value: []byte("01234567")}))
+
+	res, err := lc.Write(context.Background(), &proto.WriteRequest{
+		Shard:   &shard,
+		Deletes: []*proto.DeleteRequest{{Key: "b"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, proto.Status_OK, res.Deletes[0].Status)
+	assert.Equal(t, []proto.Status{proto.Status_OK}, putsStatus(&proto.PutRequest{Key: "c", Value: []byte("0")}))
+
+	r := <-lc.Read(context.Background(), &proto.ReadRequest{
+		Shard: &shard,
+		Gets:  []*proto.GetRequest{{Key: "a", IncludeValue: true}},
+	})
+	assert.NoError(t, r.Err)
+	assert.Equal(t, []byte("01234567"), r.Response.Value)
+
+	status, err := lc.GetStatus(&proto.GetStatusRequest{Shard: shard})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, status.Keys)
+	assert.EqualValues(t, 11, status.DataSize)
+
+	// The reservations are released once the writes are applied
+	assert.Equal(t, kv.Usage{}, lc.(*leaderController).quota.reserved)
+
+	// The leader replicates the usage of the delete ranges in the log entry
+	res, err = lc.Write(context.Background(), &proto.WriteRequest{
+		Shard:        &shard,
+		DeleteRanges: []*proto.DeleteRangeRequest{{StartInclusive: "b", EndExclusive: "z"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, proto.Status_OK, res.DeleteRanges[0].Status)
+
+	reader, err := lc.(*leaderController).wal.NewReverseReader()
+	assert.NoError(t, err)
+	entry, err := reader.ReadNext()
+	assert.NoError(t, err)
+	assert.NoError(t, reader.Close())
+	logEntryValue := &proto.LogEntryValue{}
+	assert.NoError(t, logEntryValue.UnmarshalVT(entry.Value))
+	if assert.Len(t, logEntryValue.GetRequests().Usages, 1) {
+		AssertProtoEqual(t, &proto.WriteUsage{DeleteRanges: []*proto.RecordsUsage{{Keys: 1, Bytes: 2}}},
+			logEntryValue.GetRequests().Usages[0])
+	}
+
+	status, err = lc.GetStatus(&proto.GetStatusRequest{Shard: shard})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, status.Keys)
+	assert.EqualValues(t, 9, status.DataSize)
+
+	assert.NoError(t, lc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
+
+func TestLeaderController_PrepareHandover(t *testing.T) {
+	var shard int64 = 1
+
+	kvFactory, err := kv.NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	walFactory := newTestWalFactory(t)
+
+	rpc := newMockRpcClient()
+
+	lc, err := NewLeaderController(Config{}, common.DefaultNamespace, shard, rpc, walFactory, kvFactory)
+	assert.NoError(t, err)
+
+	_, err = lc.NewTerm(&proto.NewTermRequest{Shard: shard, Term: 1})
+	assert.NoError(t, err)
+	_, err = lc.BecomeLeader(context.Background(), &proto.BecomeLeaderRequest{
+		Shard:             shard,
+		Term:              1,
+		ReplicationFactor: 2,
+		FollowerMaps: map[string]*proto.EntryId{
+			"f1": InvalidEntryId,
+		},
+	})
+	assert.NoError(t, err)
+
+	// The new leader must be one of the followers
+	_, err = lc.PrepareHandover(context.Background(), &proto.PrepareLeaderHandoverRequest{
+		Shard:     shard,
+		Term:      1,
+		NewLeader: "f2",
+	})
+	assert.Equal(t, common.CodeNodeIsNotFollower, status.Code(err))
+
+	// Start a write that is not acknowledged by the follower yet
+	writeCh := make(chan error, 1)
+	go func() {
+		_, err := lc.Write(context.Background(), &proto.WriteRequest{
+			Shard: &shard,
+			Puts:  []*proto.PutRequest{{Key: "a", Value: []byte("value-a")}},
+		})
+		writeCh <- err
+	}()
+	appendReq := <-rpc.appendReqs
+
+	type handoverResult struct {
+		res *proto.PrepareLeaderHandoverResponse
+		err error
+	}
+	handoverCh := make(chan handoverResult, 1)
+	go func() {
+		res, err := lc.PrepareHandover(context.Background(), &proto.PrepareLeaderHandoverRequest{
+			Shard:     shard,
+			Term:      1,
+			NewLeader: "f1",
+		})
+		handoverCh <- handoverResult{res, err}
+	}()
+
+	// New writes are rejected with a retriable error
+	assert.Eventually(t, func() bool {
+		lc.(*leaderController).RLock()
+		defer lc.(*leaderController).RUnlock()
+		return !lc.(*leaderController).handoverDeadline.IsZero()
+	}, 10*time.Second, 10*time.Millisecond)
+
+	_, err = lc.Write(context.Background(), &proto.WriteRequest{
+		Shard: &shard,
+		Puts:  []*proto.PutRequest{{Key: "b", Value: []byte("value-b")}},
+	})
+	assert.Equal(t, common.CodeNodeIsNotLeader, status.Code(err))
+
+	// The handover waits for the pending write to complete
+	select {
+	case <-handoverCh:
+		assert.Fail(t, "handover should wait for the pending writes")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	rpc.ackResps <- &proto.Ack{Offset: appendReq.Entry.Offset}
+	assert.NoError(t, <-writeCh)
+
+	hr := <-handoverCh
+	assert.NoError(t, hr.err)
+	assert.EqualValues(t, 0, hr.res.HeadOffset)
+
+	// Once fenced, the leader doesn't need to block writes anymore
+	_, err = lc.NewTerm(&proto.NewTermRequest{Shard: shard, Term: 2})
+	assert.NoError(t, err)
+	assert.True(t, lc.(*leaderController).handoverDeadline.IsZero())
+
+	close(rpc.ackResps)
+	assert.NoError(t, lc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
+
 func TestLeaderController_WriteStream(t *testing.T) {
 	var shard int64 = 1
 
@@ -1316,6 +1667,108 @@ func TestLeaderController_WriteStream(t *testing.T) {
 	assert.NoError(t, walFactory.Close())
 }
 
+func TestLeaderController_WriteStreamBackpressure(t *testing.T) {
+	var shard int64 = 1
+
+	kvFactory, err := kv.NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	walFactory := newTestWalFactory(t)
+	rpc := newMockRpcClient()
+
+	config := Config{WriteBackpressure: BackpressureOptions{MaxPendingWrites: 2}}
+	lc, err := NewLeaderController(config, common.DefaultNamespace, shard, rpc, walFactory, kvFactory)
+	assert.NoError(t, err)
+
+	_, err = lc.NewTerm(&proto.NewTermRequest{Shard: shard, Term: 1})
+	assert.NoError(t, err)
+
+	_, err = lc.BecomeLeader(context.Background(), &proto.BecomeLeaderRequest{
+		Shard:             shard,
+		Term:              1,
+		ReplicationFactor: 2,
+		FollowerMaps: map[string]*proto.EntryId{
+			"f1": InvalidEntryId,
+		},
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := newMockWriteStream(ctx)
+	put := func(key string) *proto.WriteRequest {
+		return &proto.WriteRequest{
+			Shard: &shard,
+			Puts:  []*proto.PutRequest{{Key: key, Value: []byte("value-" + key)}},
+		}
+	}
+
+	go func() {
+		err1 := lc.WriteStream(stream)
+		assert.ErrorIs(t, err1, context.Canceled)
+	}()
+
+	// The first 2 writes stay pending until the follower acks them
+	stream.requests <- put("a")
+	stream.requests <- put("b")
+	<-rpc.appendReqs
+	<-rpc.appendReqs
+
+	// The third one is rejected, while the others are in flight
+	stream.requests <- put("c")
+	rpc.ackResps <- &proto.Ack{Offset: 1}
+
+	res := <-stream.response
+	assert.Nil(t, res.Rejection)
+	if assert.Len(t, res.Puts, 1) {
+		assert.Equal(t, proto.Status_OK, res.Puts[0].Status)
+		assert.EqualValues(t, 0, res.Puts[0].Version.VersionId)
+	}
+
+	res = <-stream.response
+	assert.Nil(t, res.Rejection)
+	if assert.Len(t, res.Puts, 1) {
+		assert.Equal(t, proto.Status_OK, res.Puts[0].Status)
+		assert.EqualValues(t, 1, res.Puts[0].Version.VersionId)
+	}
+
+	res = <-stream.response
+	assert.Empty(t, res.Puts)
+	if assert.NotNil(t, res.Rejection) {
+		assert.EqualValues(t, codes.ResourceExhausted, res.Rejection.Code)
+		assert.EqualValues(t, backpressureRetryAfter.Milliseconds(), res.Rejection.RetryAfterMillis)
+	}
+
+	// The stream is still usable once the leader is no longer overloaded
+	assert.Eventually(t, func() bool {
+		return lc.(*leaderController).pendingWrites.Load() == 0
+	}, 10*time.Second, 10*time.Millisecond)
+
+	stream.requests <- put("c")
+	req := <-rpc.appendReqs
+	rpc.ackResps <- &proto.Ack{Offset: req.Entry.Offset}
+
+	res = <-stream.response
+	assert.Nil(t, res.Rejection)
+	if assert.Len(t, res.Puts, 1) {
+		assert.Equal(t, proto.Status_OK, res.Puts[0].Status)
+		assert.EqualValues(t, 2, res.Puts[0].Version.VersionId)
+	}
+
+	cancel()
+
+	r := <-lc.Read(context.Background(), &proto.ReadRequest{
+		Shard: &shard,
+		Gets:  []*proto.GetRequest{{Key: "c", IncludeValue: true}},
+	})
+	assert.NoError(t, r.Err)
+	assert.Equal(t, proto.Status_OK, r.Response.Status)
+	assert.Equal(t, []byte("value-c"), r.Response.Value)
+
+	close(rpc.ackResps)
+	assert.NoError(t, lc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
+
 func TestLeaderController_NotificationsDisabled(t *testing.T) {
 	var shard int64 = 1
 
@@ -1418,3 +1871,281 @@ func TestLeaderController_DuplicateNewTerm_WithSession(t *testing.T) {
 	assert.NoError(t, kvFactory.Close())
 	assert.NoError(t, walFactory.Close())
 }
+
+func TestLeaderController_GetHotKeys(t *testing.T) {
+	var shard int64 = 1
+
+	kvFactory, err := kv.NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	walFactory := newTestWalFactory(t)
+
+	lc, err := NewLeaderController(Config{HotKeysSampleRate: 1}, common.DefaultNamespace, shard, newMockRpcClient(), walFactory, kvFactory)
+	assert.NoError(t, err)
+	_, err = lc.NewTerm(&proto.NewTermRequest{Shard: shard, Term: 1})
+	assert.NoError(t, err)
+	_, err = lc.BecomeLeader(context.Background(), &proto.BecomeLeaderRequest{
+		Shard:             shard,
+		Term:              1,
+		ReplicationFactor: 1,
+		FollowerMaps:      nil,
+	})
+	assert.NoError(t, err)
+
+	_, err = lc.Write(context.Background(), &proto.WriteRequest{
+		Shard: &shard,
+		Puts: []*proto.PutRequest{
+			{Key: "/a/1", Value: []byte("0")},
+			{Key: "/a/2", Value: []byte("0")},
+		},
+	})
+	assert.NoError(t, err)
+	r := <-lc.Read(context.Background(), &proto.ReadRequest{
+		Shard: &shard,
+		Gets:  []*proto.GetRequest{{Key: "/a/1"}},
+	})
+	assert.NoError(t, r.Err)
+
+	res, err := lc.GetHotKeys(&proto.GetHotKeysRequest{Namespace: common.DefaultNamespace, Shard: shard, Limit: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, res.Reads)
+	assert.EqualValues(t, 2, res.Writes)
+	assert.Equal(t, "/a/1", res.Keys[0].Key)
+	assert.Equal(t, "/a/", res.Prefixes[0].Key)
+
+	_, err = lc.GetHotKeys(&proto.GetHotKeysRequest{Namespace: "other", Shard: shard})
+	assert.ErrorIs(t, err, common.ErrorNamespaceNotFound)
+
+	assert.NoError(t, lc.Close())
+
+	// The sampling is disabled
+	lc, err = NewLeaderController(Config{}, common.DefaultNamespace, shard, newMockRpcClient(), walFactory, kvFactory)
+	assert.NoError(t, err)
+	_, err = lc.NewTerm(&proto.NewTermRequest{Shard: shard, Term: 2})
+	assert.NoError(t, err)
+	_, err = lc.BecomeLeader(context.Background(), &proto.BecomeLeaderRequest{
+		Shard:             shard,
+		Term:              2,
+		ReplicationFactor: 1,
+		FollowerMaps:      nil,
+	})
+	assert.NoError(t, err)
+	_, err = lc.GetHotKeys(&proto.GetHotKeysRequest{Namespace: common.DefaultNamespace, Shard: shard})
+	assert.ErrorIs(t, err, common.ErrorHotKeysNotEnabled)
+
+	assert.NoError(t, lc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
+
+func TestLeaderController_GetSlowOperations(t *testing.T) {
+	var shard int64 = 1
+
+	kvFactory, err := kv.NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	walFactory := newTestWalFactory(t)
+
+	lc, err := NewLeaderController(Config{SlowOperationThreshold: time.Nanosecond}, common.DefaultNamespace, shard,
+		newMockRpcClient(), walFactory, kvFactory)
+	assert.NoError(t, err)
+	_, err = lc.NewTerm(&proto.NewTermRequest{Shard: shard, Term: 1})
+	assert.NoError(t, err)
+	_, err = lc.BecomeLeader(context.Background(), &proto.BecomeLeaderRequest{
+		Shard:             shard,
+		Term:              1,
+		ReplicationFactor: 1,
+		FollowerMaps:      nil,
+	})
+	assert.NoError(t, err)
+
+	_, err = lc.Write(context.Background(), &proto.WriteRequest{
+		Shard: &shard,
+		Puts: []*proto.PutRequest{
+			{Key: "/a", Value: []byte("0")},
+			{Key: "/b", Value: []byte("01")},
+		},
+	})
+	assert.NoError(t, err)
+
+	for r := range lc.Read(context.Background(), &proto.ReadRequest{
+		Shard: &shard,
+		Gets:  []*proto.GetRequest{{Key: "/b", IncludeValue: true}, {Key: "/c"}},
+	}) {
+		assert.NoError(t, r.Err)
+	}
+
+	ch, err := lc.List(context.Background(), &proto.ListRequest{Shard: &shard, StartInclusive: "/a", EndExclusive: "/z"})
+	assert.NoError(t, err)
+	for range ch {
+	}
+
+	// The list is recorded after its results are consumed
+	var res *proto.GetSlowOperationsResponse
+	assert.Eventually(t, func() bool {
+		res, err = lc.GetSlowOperations(&proto.GetSlowOperationsRequest{Namespace: common.DefaultNamespace, Shard: shard})
+		return err == nil && len(res.Operations) == 3
+	}, 10*time.Second, 10*time.Millisecond)
+
+	var write, read, list *proto.SlowOperation
+	for _, op := range res.Operations {
+		switch op.Type {
+		case slowOperationWrite:
+			write = op
+		case slowOperationRead:
+			read = op
+		case slowOperationList:
+			list = op
+		}
+	}
+
+	assert.Equal(t, "/a", write.KeyStart)
+	assert.Equal(t, "/b", write.KeyEnd)
+	assert.EqualValues(t, 2, write.Results)
+	assert.EqualValues(t, 3, write.Bytes)
+	assert.GreaterOrEqual(t, write.DurationMicros, write.WalAppendMicros+write.QuorumWaitMicros+write.DbApplyMicros)
+
+	assert.Equal(t, "/b", read.KeyStart)
+	assert.Equal(t, "/c", read.KeyEnd)
+	assert.EqualValues(t, 1, read.Results)
+	assert.EqualValues(t, 2, read.Bytes)
+
+	assert.Equal(t, "/a", list.KeyStart)
+	assert.Equal(t, "/z", list.KeyEnd)
+	assert.EqualValues(t, 2, list.Results)
+
+	_, err = lc.GetSlowOperations(&proto.GetSlowOperationsRequest{Namespace: "other", Shard: shard})
+	assert.ErrorIs(t, err, common.ErrorNamespaceNotFound)
+
+	assert.NoError(t, lc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
+
+func TestLeaderController_GetSlowOperationsFailedWrites(t *testing.T) {
+	var shard int64 = 1
+
+	kvFactory, err := kv.NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	walFactory := newTestWalFactory(t)
+	rpc := newMockRpcClient()
+
+	lc, err := NewLeaderController(Config{SlowOperationThreshold: time.Nanosecond}, common.DefaultNamespace, shard,
+		rpc, walFactory, kvFactory)
+	assert.NoError(t, err)
+	_, err = lc.NewTerm(&proto.NewTermRequest{Shard: shard, Term: 1})
+	assert.NoError(t, err)
+	_, err = lc.BecomeLeader(context.Background(), &proto.BecomeLeaderRequest{
+		Shard:             shard,
+		Term:              1,
+		ReplicationFactor: 2,
+		FollowerMaps: map[string]*proto.EntryId{
+			"f1": InvalidEntryId,
+		},
+	})
+	assert.NoError(t, err)
+
+	// The follower never acks the writes
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = lc.Write(ctx, &proto.WriteRequest{
+		Shard: &shard,
+		Puts:  []*proto.PutRequest{{Key: "/a", Value: []byte("0")}},
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	stream := newMockWriteStream(context.Background())
+	stream.requests <- &proto.WriteRequest{
+		Shard: &shard,
+		Puts:  []*proto.PutRequest{{Key: "/b", Value: []byte("0")}},
+	}
+	streamErr := make(chan error)
+	go func() {
+		streamErr <- lc.WriteStream(stream)
+	}()
+
+	// The pending write of the stream fails when the leader is fenced
+	<-rpc.appendReqs
+	<-rpc.appendReqs
+	_, err = lc.NewTerm(&proto.NewTermRequest{Shard: shard, Term: 2})
+	assert.NoError(t, err)
+	assert.Error(t, <-streamErr)
+
+	// The fenced leader doesn't serve the slow operations anymore
+	ops := lc.(*leaderController).slowOps.last(0)
+	if assert.Len(t, ops, 2) {
+		assert.Equal(t, slowOperationWrite, ops[0].Type)
+		assert.Equal(t, "/b", ops[0].KeyStart)
+		assert.Equal(t, common.ErrorAlreadyClosed.Error(), ops[0].Error)
+
+		assert.Equal(t, slowOperationWrite, ops[1].Type)
+		assert.Equal(t, "/a", ops[1].KeyStart)
+		assert.Equal(t, context.DeadlineExceeded.Error(), ops[1].Error)
+	}
+
+	assert.NoError(t, lc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
+
+func TestLeaderController_WriteBackpressureRecovery(t *testing.T) {
+	var shard int64 = 1
+
+	kvFactory, err := kv.NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	walFactory := newTestWalFactory(t)
+	rpc := newMockRpcClient()
+
+	config := Config{WriteBackpressure: BackpressureOptions{MaxQuorumAckLatency: 100 * time.Millisecond}}
+	lc, err := NewLeaderController(config, common.DefaultNamespace, shard, rpc, walFactory, kvFactory)
+	assert.NoError(t, err)
+
+	becomeLeader := func(term int64, followerMaps map[string]*proto.EntryId) {
+		_, err = lc.NewTerm(&proto.NewTermRequest{Shard: shard, Term: term})
+		assert.NoError(t, err)
+		_, err = lc.BecomeLeader(context.Background(), &proto.BecomeLeaderRequest{
+			Shard:             shard,
+			Term:              term,
+			ReplicationFactor: uint32(len(followerMaps) + 1),
+			FollowerMaps:      followerMaps,
+		})
+		assert.NoError(t, err)
+	}
+	write := func(key string) error {
+		_, err := lc.Write(context.Background(), &proto.WriteRequest{
+			Shard: &shard,
+			Puts:  []*proto.PutRequest{{Key: key, Value: []byte("0")}},
+		})
+		return err
+	}
+
+	// The follower acks the writes slowly
+	go func() {
+		for req := range rpc.appendReqs {
+			time.Sleep(time.Second)
+			rpc.ackResps <- &proto.Ack{Offset: req.Entry.Offset}
+		}
+	}()
+
+	becomeLeader(1, map[string]*proto.EntryId{"f1": InvalidEntryId})
+	assert.NoError(t, write("a"))
+
+	// The next writes are rejected, until the average latency decays
+	// without any write being acknowledged
+	_, ok := common.RetryAfter(write("b"))
+	assert.True(t, ok)
+	assert.Eventually(t, func() bool {
+		return lc.(*leaderController).applyBackpressure(context.Background()) == nil
+	}, 10*time.Second, 50*time.Millisecond)
+
+	assert.NoError(t, write("c"))
+	_, ok = common.RetryAfter(write("d"))
+	assert.True(t, ok)
+
+	// A new term doesn't inherit the load of the previous one
+	becomeLeader(2, nil)
+	assert.NoError(t, lc.(*leaderController).applyBackpressure(context.Background()))
+
+	close(rpc.appendReqs)
+	assert.NoError(t, lc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
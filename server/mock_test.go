@@ -16,6 +16,7 @@ package server
 
 import (
 	"context"
+	"io"
 
 	"google.golang.org/grpc/metadata"
 
@@ -61,6 +62,7 @@ func newMockRpcClient() *mockRpcClient {
 		appendReqs:         make(chan *proto.Append, 1000),
 		ackResps:           make(chan *proto.Ack, 1000),
 		truncateReqs:       make(chan *proto.TruncateRequest, 1000),
+		fetchLogReqs:       make(chan *proto.FetchLogRequest, 1000),
 		truncateResps: make(chan struct {
 			*proto.TruncateResponse
 			error
@@ -78,6 +80,10 @@ type mockRpcClient struct {
 		*proto.TruncateResponse
 		error
 	}
+	fetchLogReqs chan *proto.FetchLogRequest
+
+	// The entries returned by the FetchLog streams
+	fetchLogEntries []*proto.LogEntry
 }
 
 func (m *mockRpcClient) Close() error {
@@ -106,6 +112,10 @@ func (m *mockRpcClient) SendSnapshot(ctx context.Context, follower string, names
 	return m.sendSnapshotStream, nil
 }
 
+func (*mockRpcClient) SendIngestFile(context.Context, string, string, int64, int64) (proto.OxiaLogReplication_SendIngestFileClient, error) {
+	panic("not implemented")
+}
+
 func (m *mockRpcClient) Truncate(follower string, req *proto.TruncateRequest) (*proto.TruncateResponse, error) {
 	m.truncateReqs <- req
 
@@ -115,6 +125,30 @@ func (m *mockRpcClient) Truncate(follower string, req *proto.TruncateRequest) (*
 	return x.TruncateResponse, x.error
 }
 
+func (m *mockRpcClient) FetchLog(_ context.Context, _ string, req *proto.FetchLogRequest) (proto.OxiaLogReplication_FetchLogClient, error) {
+	m.fetchLogReqs <- req
+	return &mockFetchLogClientStream{entries: m.fetchLogEntries}, nil
+}
+
+type mockFetchLogClientStream struct {
+	mockBase
+	entries []*proto.LogEntry
+}
+
+func (m *mockFetchLogClientStream) Recv() (*proto.LogEntry, error) {
+	if len(m.entries) == 0 {
+		return nil, io.EOF
+	}
+
+	entry := m.entries[0]
+	m.entries = m.entries[1:]
+	return entry, nil
+}
+
+func (*mockFetchLogClientStream) CloseSend() error {
+	return nil
+}
+
 func newMockShardAssignmentClientStream() *mockShardAssignmentClientStream {
 	r := &mockShardAssignmentClientStream{
 		responses: make(chan *proto.ShardAssignments, 1000),
@@ -182,6 +216,7 @@ type mockServerSendSnapshotStream struct {
 	mockBase
 	chunks    chan *proto.SnapshotChunk
 	responses chan *proto.SnapshotResponse
+	sendErr   error
 }
 
 func (m *mockServerSendSnapshotStream) AddChunk(chunk *proto.SnapshotChunk) {
@@ -193,6 +228,10 @@ func (m *mockServerSendSnapshotStream) GetResponse() *proto.SnapshotResponse {
 }
 
 func (m *mockServerSendSnapshotStream) SendAndClose(empty *proto.SnapshotResponse) error {
+	if m.sendErr != nil {
+		return m.sendErr
+	}
+
 	m.responses <- empty
 	return nil
 }
@@ -201,6 +240,16 @@ func (m *mockServerSendSnapshotStream) Recv() (*proto.SnapshotChunk, error) {
 	return <-m.chunks, nil
 }
 
+type mockServerFetchLogStream struct {
+	mockBase
+	entries []*proto.LogEntry
+}
+
+func (m *mockServerFetchLogStream) Send(entry *proto.LogEntry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
 type mockGetNotificationsServer struct {
 	mockBase
 	ch chan *proto.NotificationBatch
@@ -333,3 +382,12 @@ func (m *mockWriteStream) CloseSend() error {
 	m.cancel()
 	return nil
 }
+
+type mockFollowerCursor struct {
+	ackOffset int64
+}
+
+func (m *mockFollowerCursor) Close() error      { return nil }
+func (m *mockFollowerCursor) ShardId() int64    { return 1 }
+func (m *mockFollowerCursor) LastPushed() int64 { return m.ackOffset }
+func (m *mockFollowerCursor) AckOffset() int64  { return m.ackOffset }
@@ -158,9 +158,13 @@ func (nd *notificationDispatcher) iterateOverNotifications(startOffsetInclusive
 		)
 
 		for _, n := range notifications {
+			trace := lc.slowOps.start(slowOperationNotifications)
+			trace.addResults(len(n.Notifications), n.SizeVT())
 			if err := nd.stream.Send(n); err != nil {
+				trace.finish(err)
 				return err
 			}
+			trace.finish(nil)
 		}
 
 		offsetInclusive += int64(len(notifications))
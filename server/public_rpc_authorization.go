@@ -0,0 +1,195 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/auth"
+)
+
+// The authorize methods check the requests against the authorization
+// policy, when one is configured.
+
+func (s *publicRpcServer) authorizeWrite(ctx context.Context, namespace string, write *proto.WriteRequest) error {
+	if s.authorizer == nil {
+		return nil
+	}
+
+	user, _ := auth.UserNameFromContext(ctx)
+	for _, put := range write.Puts {
+		if err := s.authorizer.AuthorizeKeys(user, namespace, auth.PermissionWrite, put.Key); err != nil {
+			return err
+		}
+	}
+	for _, del := range write.Deletes {
+		if err := s.authorizer.AuthorizeKeys(user, namespace, auth.PermissionWrite, del.Key); err != nil {
+			return err
+		}
+	}
+	for _, dr := range write.DeleteRanges {
+		if err := s.authorizer.AuthorizeRange(user, namespace, auth.PermissionWrite,
+			dr.StartInclusive, dr.EndExclusive); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *publicRpcServer) authorizeRead(ctx context.Context, namespace string, request *proto.ReadRequest) error {
+	if s.authorizer == nil {
+		return nil
+	}
+
+	user, _ := auth.UserNameFromContext(ctx)
+	for _, get := range request.Gets {
+		// The floor, ceiling, lower and higher lookups can return any key
+		// of the namespace
+		if get.ComparisonType != proto.KeyComparisonType_EQUAL {
+			return s.authorizer.AuthorizeNamespace(user, namespace, auth.PermissionRead)
+		}
+		if err := s.authorizer.AuthorizeKeys(user, namespace, auth.PermissionRead, get.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorizeScan checks the list and range-scan requests. The queries on a
+// secondary index can return any key of the namespace.
+func (s *publicRpcServer) authorizeScan(ctx context.Context, namespace string,
+	start, end string, secondaryIndexName *string) error {
+	if s.authorizer == nil {
+		return nil
+	}
+
+	user, _ := auth.UserNameFromContext(ctx)
+	if secondaryIndexName != nil {
+		return s.authorizer.AuthorizeNamespace(user, namespace, auth.PermissionRead)
+	}
+	return s.authorizer.AuthorizeRange(user, namespace, auth.PermissionRead, start, end)
+}
+
+func (s *publicRpcServer) authorizeNamespace(ctx context.Context, namespace string, permission auth.Permission) error {
+	if s.authorizer == nil {
+		return nil
+	}
+
+	user, _ := auth.UserNameFromContext(ctx)
+	return s.authorizer.AuthorizeNamespace(user, namespace, permission)
+}
+
+// authorizeSession checks the session operations, which are allowed to the
+// users that can write some keys of the namespace.
+func (s *publicRpcServer) authorizeSession(ctx context.Context, namespace string) error {
+	if s.authorizer == nil {
+		return nil
+	}
+
+	user, _ := auth.UserNameFromContext(ctx)
+	return s.authorizer.AuthorizeAnyKey(user, namespace, auth.PermissionWrite)
+}
+
+// authorizeSessionOwner checks that the session was created by the
+// authenticated user, so that the users can't keep alive or close the sessions
+// of the others, and delete their ephemeral records. The sessions that are not
+// found are left to the session operations to report.
+func (s *publicRpcServer) authorizeSessionOwner(ctx context.Context, lc LeaderController, sessionId int64) error {
+	user, ok := auth.UserNameFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	identity, err := lc.SessionIdentity(sessionId)
+	if err != nil {
+		if errors.Is(err, common.ErrorSessionNotFound) {
+			return nil
+		}
+		return err
+	}
+	if identity != user {
+		return status.Errorf(codes.PermissionDenied, "session %d was not created by the user %q", sessionId, user)
+	}
+	return nil
+}
+
+// stampIdentity replaces the client identity chosen by the client with the
+// authenticated user, so that the records and the sessions can be trusted to
+// carry their real author. It returns the identity to use.
+func (s *publicRpcServer) stampIdentity(ctx context.Context, clientIdentity string) (string, error) {
+	user, ok := auth.UserNameFromContext(ctx)
+	if !ok {
+		return clientIdentity, nil
+	}
+
+	if s.rejectIdentityMismatch && clientIdentity != "" && clientIdentity != user {
+		return "", status.Errorf(codes.PermissionDenied,
+			"client identity %q doesn't match the authenticated user %q", clientIdentity, user)
+	}
+	return user, nil
+}
+
+func (s *publicRpcServer) stampWriteIdentity(ctx context.Context, write *proto.WriteRequest) error {
+	if _, ok := auth.UserNameFromContext(ctx); !ok {
+		return nil
+	}
+
+	for _, put := range write.Puts {
+		identity, err := s.stampIdentity(ctx, put.GetClientIdentity())
+		if err != nil {
+			return err
+		}
+		put.ClientIdentity = &identity
+	}
+	return nil
+}
+
+// checkWrite stamps the identity of the authenticated user in the write and
+// checks it against the authorization policy.
+func (s *publicRpcServer) checkWrite(ctx context.Context, namespace string, write *proto.WriteRequest) error {
+	if err := s.stampWriteIdentity(ctx, write); err != nil {
+		return err
+	}
+	return s.authorizeWrite(ctx, namespace, write)
+}
+
+// authenticatedWriteStream checks each of the requests received from the
+// write stream. The requests that are not authorized, or whose client identity
+// doesn't match the user, are rejected on their own, so that the stream and the
+// other in-flight writes are not failed.
+type authenticatedWriteStream struct {
+	proto.OxiaClient_WriteStreamServer
+
+	server    *publicRpcServer
+	namespace string
+}
+
+func (s *authenticatedWriteStream) Recv() (*proto.WriteRequest, error) {
+	write, err := s.OxiaClient_WriteStreamServer.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.server.checkWrite(s.Context(), s.namespace, write); err != nil {
+		return nil, &writeRejectedError{err: err}
+	}
+	return write, nil
+}
@@ -0,0 +1,218 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "google.golang.org/protobuf/proto"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/auth"
+	"github.com/streamnative/oxia/server/kv"
+)
+
+func TestPublicRpcServer_StampWriteIdentity(t *testing.T) {
+	s := &publicRpcServer{}
+	write := &proto.WriteRequest{
+		Puts: []*proto.PutRequest{
+			{Key: "a", ClientIdentity: pb.String("mallory")},
+			{Key: "b"},
+		},
+	}
+
+	// Without authentication the client identity is left as it is
+	assert.NoError(t, s.stampWriteIdentity(context.Background(), write))
+	assert.Equal(t, "mallory", write.Puts[0].GetClientIdentity())
+	assert.Nil(t, write.Puts[1].ClientIdentity)
+
+	ctx := auth.WithUserName(context.Background(), "alice")
+	assert.NoError(t, s.stampWriteIdentity(ctx, write))
+	assert.Equal(t, "alice", write.Puts[0].GetClientIdentity())
+	assert.Equal(t, "alice", write.Puts[1].GetClientIdentity())
+}
+
+func TestPublicRpcServer_RejectIdentityMismatch(t *testing.T) {
+	s := &publicRpcServer{rejectIdentityMismatch: true}
+	ctx := auth.WithUserName(context.Background(), "alice")
+
+	identity, err := s.stampIdentity(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", identity)
+
+	identity, err = s.stampIdentity(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", identity)
+
+	_, err = s.stampIdentity(ctx, "mallory")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestPublicRpcServer_AuthorizedWriteStream(t *testing.T) {
+	var shard int64 = 1
+
+	policy, err := auth.ReadPolicy(strings.NewReader(`
+roles:
+  - name: team-a
+    grants:
+      - namespace: default
+        keyPrefix: /team-a/
+        permissions: [write]
+bindings:
+  - role: team-a
+    users: [alice]
+`))
+	assert.NoError(t, err)
+	s := &publicRpcServer{authorizer: auth.NewAuthorizer(policy), rejectIdentityMismatch: true}
+
+	kvFactory, err := kv.NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	walFactory := newTestWalFactory(t)
+
+	lc, err := NewLeaderController(Config{}, common.DefaultNamespace, shard, newMockRpcClient(), walFactory, kvFactory)
+	assert.NoError(t, err)
+	_, err = lc.NewTerm(&proto.NewTermRequest{Shard: shard, Term: 1})
+	assert.NoError(t, err)
+	_, err = lc.BecomeLeader(context.Background(), &proto.BecomeLeaderRequest{
+		Shard:             shard,
+		Term:              1,
+		ReplicationFactor: 1,
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(auth.WithUserName(context.Background(), "alice"))
+	stream := newMockWriteStream(ctx)
+	for _, key := range []string{"/team-a/a", "/team-b/b", "/team-a/c"} {
+		stream.requests <- &proto.WriteRequest{
+			Shard: &shard,
+			Puts:  []*proto.PutRequest{{Key: key, Value: []byte("0")}},
+		}
+	}
+	stream.requests <- &proto.WriteRequest{
+		Shard: &shard,
+		Puts:  []*proto.PutRequest{{Key: "/team-a/d", Value: []byte("0"), ClientIdentity: pb.String("mallory")}},
+	}
+	stream.requests <- &proto.WriteRequest{
+		Shard: &shard,
+		Puts:  []*proto.PutRequest{{Key: "/team-a/e", Value: []byte("0")}},
+	}
+
+	go func() {
+		err1 := lc.WriteStream(&authenticatedWriteStream{
+			OxiaClient_WriteStreamServer: stream,
+			server:                       s,
+			namespace:                    common.DefaultNamespace,
+		})
+		assert.ErrorIs(t, err1, context.Canceled)
+	}()
+
+	// Only the denied write fails, the stream is kept open for the others
+	res := <-stream.response
+	assert.Nil(t, res.Rejection)
+	assert.Equal(t, proto.Status_OK, res.Puts[0].Status)
+
+	res = <-stream.response
+	if assert.NotNil(t, res.Rejection) {
+		assert.EqualValues(t, codes.PermissionDenied, res.Rejection.Code)
+	}
+
+	res = <-stream.response
+	assert.Nil(t, res.Rejection)
+	assert.Equal(t, proto.Status_OK, res.Puts[0].Status)
+
+	// The same for a write with the identity of another user
+	res = <-stream.response
+	if assert.NotNil(t, res.Rejection) {
+		assert.EqualValues(t, codes.PermissionDenied, res.Rejection.Code)
+		assert.Contains(t, res.Rejection.Message, "mallory")
+	}
+
+	res = <-stream.response
+	assert.Nil(t, res.Rejection)
+	assert.Equal(t, proto.Status_OK, res.Puts[0].Status)
+
+	cancel()
+
+	r := <-lc.Read(context.Background(), &proto.ReadRequest{
+		Shard: &shard,
+		Gets:  []*proto.GetRequest{{Key: "/team-b/b"}},
+	})
+	assert.NoError(t, r.Err)
+	assert.Equal(t, proto.Status_KEY_NOT_FOUND, r.Response.Status)
+
+	assert.NoError(t, lc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
+
+func TestPublicRpcServer_SessionOwner(t *testing.T) {
+	var shard int64 = 1
+
+	kvFactory, err := kv.NewPebbleKVFactory(testKVOptions)
+	assert.NoError(t, err)
+	walFactory := newTestWalFactory(t)
+
+	lc, err := NewLeaderController(Config{}, common.DefaultNamespace, shard, newMockRpcClient(), walFactory, kvFactory)
+	assert.NoError(t, err)
+	_, err = lc.NewTerm(&proto.NewTermRequest{Shard: shard, Term: 1})
+	assert.NoError(t, err)
+	_, err = lc.BecomeLeader(context.Background(), &proto.BecomeLeaderRequest{
+		Shard:             shard,
+		Term:              1,
+		ReplicationFactor: 1,
+	})
+	assert.NoError(t, err)
+
+	s := &publicRpcServer{
+		shardsDirector: &shardsDirector{leaders: map[int64]LeaderController{shard: lc}},
+		log:            slog.Default(),
+	}
+	alice := auth.WithUserName(context.Background(), "alice")
+	bob := auth.WithUserName(context.Background(), "bob")
+
+	res, err := s.CreateSession(alice, &proto.CreateSessionRequest{
+		Shard:            shard,
+		SessionTimeoutMs: uint32((5 * time.Second).Milliseconds()),
+		ClientIdentity:   "mallory",
+	})
+	assert.NoError(t, err)
+
+	// The other users can't keep alive or close the session
+	_, err = s.KeepAlive(bob, &proto.SessionHeartbeat{Shard: shard, SessionId: res.SessionId})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = s.CloseSession(bob, &proto.CloseSessionRequest{Shard: shard, SessionId: res.SessionId})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = s.KeepAlive(alice, &proto.SessionHeartbeat{Shard: shard, SessionId: res.SessionId})
+	assert.NoError(t, err)
+	_, err = s.CloseSession(alice, &proto.CloseSessionRequest{Shard: shard, SessionId: res.SessionId})
+	assert.NoError(t, err)
+
+	// The sessions that are not found are reported by the session operations
+	_, err = s.KeepAlive(bob, &proto.SessionHeartbeat{Shard: shard, SessionId: res.SessionId})
+	assert.Equal(t, common.CodeSessionNotFound, status.Code(err))
+
+	assert.NoError(t, lc.Close())
+	assert.NoError(t, kvFactory.Close())
+	assert.NoError(t, walFactory.Close())
+}
@@ -21,6 +21,7 @@ import (
 	"log/slog"
 
 	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
@@ -40,28 +41,48 @@ const (
 
 type publicRpcServer struct {
 	proto.UnimplementedOxiaClientServer
+	proto.UnimplementedOxiaAdminServer
 
 	shardsDirector       ShardsDirector
 	assignmentDispatcher ShardAssignmentsDispatcher
 	grpcServer           container.GrpcServer
+	authorizer           *auth.Authorizer
+	rateLimiter          *rateLimiter
 	log                  *slog.Logger
+
+	rejectIdentityMismatch bool
 }
 
 func newPublicRpcServer(provider container.GrpcProvider, bindAddress string, shardsDirector ShardsDirector, assignmentDispatcher ShardAssignmentsDispatcher,
-	tlsConf *tls.Config, options *auth.Options) (*publicRpcServer, error) {
+	tlsConf *tls.Config, options *auth.Options, rateLimit RateLimitOptions) (*publicRpcServer, error) {
 	server := &publicRpcServer{
 		shardsDirector:       shardsDirector,
 		assignmentDispatcher: assignmentDispatcher,
+		rateLimiter:          newRateLimiter(rateLimit),
 		log: slog.With(
 			slog.String("component", "public-rpc-server"),
 		),
+		rejectIdentityMismatch: options.RejectIdentityMismatch,
 	}
 
 	var err error
+	if options.PolicyFile != "" {
+		if !options.IsEnabled() {
+			return nil, errors.New("the authorization policy requires the authentication to be enabled")
+		}
+		if server.authorizer, err = auth.NewAuthorizerFromFile(options.PolicyFile); err != nil {
+			return nil, err
+		}
+	}
+
 	server.grpcServer, err = provider.StartGrpcServer("public", bindAddress, func(registrar grpc.ServiceRegistrar) {
 		proto.RegisterOxiaClientServer(registrar, server)
+		proto.RegisterOxiaAdminServer(registrar, server)
 	}, tlsConf, options)
 	if err != nil {
+		if server.authorizer != nil {
+			_ = server.authorizer.Close()
+		}
 		return nil, err
 	}
 
@@ -97,6 +118,14 @@ func (s *publicRpcServer) Write(ctx context.Context, write *proto.WriteRequest)
 		return nil, err
 	}
 
+	if err = s.checkWrite(ctx, lc.Namespace(), write); err != nil {
+		return nil, err
+	}
+
+	if err = s.rateLimiter.allow(ctx, lc.Namespace(), rateLimitWrite, int(writeOpsCount(write))); err != nil {
+		return nil, err
+	}
+
 	wr, err := lc.Write(ctx, write)
 	if err != nil {
 		s.log.Warn(
@@ -139,6 +168,21 @@ func (s *publicRpcServer) WriteStream(stream proto.OxiaClient_WriteStreamServer)
 		return err
 	}
 
+	if _, authenticated := auth.UserNameFromContext(stream.Context()); authenticated {
+		stream = &authenticatedWriteStream{
+			OxiaClient_WriteStreamServer: stream,
+			server:                       s,
+			namespace:                    lc.Namespace(),
+		}
+	}
+	if s.rateLimiter != nil {
+		stream = &rateLimitedWriteStream{
+			OxiaClient_WriteStreamServer: stream,
+			limiter:                      s.rateLimiter,
+			namespace:                    lc.Namespace(),
+		}
+	}
+
 	if err = lc.WriteStream(stream); err != nil {
 		if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
 			log.Info("Write stream has been closed")
@@ -162,11 +206,19 @@ func (s *publicRpcServer) Read(request *proto.ReadRequest, stream proto.OxiaClie
 		slog.Any("req", request),
 	)
 
-	lc, err := s.getLeader(*request.Shard)
+	lc, err := s.getReader(*request.Shard)
 	if err != nil {
 		return err
 	}
 
+	if err = s.authorizeRead(stream.Context(), lc.Namespace(), request); err != nil {
+		return err
+	}
+
+	if err = s.rateLimiter.allow(stream.Context(), lc.Namespace(), rateLimitRead, len(request.Gets)); err != nil {
+		return err
+	}
+
 	ch := lc.Read(stream.Context(), request)
 
 	response := &proto.ReadResponse{}
@@ -210,11 +262,20 @@ func (s *publicRpcServer) List(request *proto.ListRequest, stream proto.OxiaClie
 		slog.Any("req", request),
 	)
 
-	lc, err := s.getLeader(*request.Shard)
+	lc, err := s.getReader(*request.Shard)
 	if err != nil {
 		return err
 	}
 
+	if err = s.authorizeScan(stream.Context(), lc.Namespace(),
+		request.StartInclusive, request.EndExclusive, request.SecondaryIndexName); err != nil {
+		return err
+	}
+
+	if err = s.rateLimiter.allow(stream.Context(), lc.Namespace(), rateLimitRead, 1); err != nil {
+		return err
+	}
+
 	ch, err := lc.List(stream.Context(), request)
 	if err != nil {
 		s.log.Warn(
@@ -261,11 +322,20 @@ func (s *publicRpcServer) RangeScan(request *proto.RangeScanRequest, stream prot
 		slog.Any("req", request),
 	)
 
-	lc, err := s.getLeader(*request.Shard)
+	lc, err := s.getReader(*request.Shard)
 	if err != nil {
 		return err
 	}
 
+	if err = s.authorizeScan(stream.Context(), lc.Namespace(),
+		request.StartInclusive, request.EndExclusive, request.SecondaryIndexName); err != nil {
+		return err
+	}
+
+	if err = s.rateLimiter.allow(stream.Context(), lc.Namespace(), rateLimitRead, 1); err != nil {
+		return err
+	}
+
 	ch, errCh, err := lc.RangeScan(stream.Context(), request)
 	if err != nil {
 		s.log.Warn(
@@ -323,6 +393,14 @@ func (s *publicRpcServer) GetNotifications(req *proto.NotificationsRequest, stre
 		return err
 	}
 
+	if err = s.authorizeNamespace(stream.Context(), lc.Namespace(), auth.PermissionRead); err != nil {
+		return err
+	}
+
+	if err = s.rateLimiter.allow(stream.Context(), lc.Namespace(), rateLimitNotifications, 1); err != nil {
+		return err
+	}
+
 	if err = lc.GetNotifications(req, stream); err != nil && !errors.Is(err, context.Canceled) {
 		s.log.Warn(
 			"Failed to handle notifications request",
@@ -333,6 +411,164 @@ func (s *publicRpcServer) GetNotifications(req *proto.NotificationsRequest, stre
 	return err
 }
 
+func (s *publicRpcServer) Backup(req *proto.BackupRequest, stream proto.OxiaAdmin_BackupServer) error {
+	s.log.Info(
+		"Backup request",
+		slog.String("peer", common.GetPeer(stream.Context())),
+		slog.String("namespace", req.Namespace),
+		slog.Int64("shard", req.Shard),
+	)
+
+	lc, err := s.getLeader(req.Shard)
+	if err != nil {
+		return err
+	}
+
+	if err = s.authorizeNamespace(stream.Context(), lc.Namespace(), auth.PermissionAdmin); err != nil {
+		return err
+	}
+
+	err = lc.Backup(req, stream)
+	if err != nil {
+		s.log.Warn(
+			"Failed to send the backup",
+			slog.Int64("shard", req.Shard),
+			slog.Any("error", err),
+		)
+	}
+	return err
+}
+
+func (s *publicRpcServer) Export(req *proto.ExportRequest, stream proto.OxiaAdmin_ExportServer) error {
+	s.log.Info(
+		"Export request",
+		slog.String("peer", common.GetPeer(stream.Context())),
+		slog.String("namespace", req.Namespace),
+		slog.Int64("shard", req.Shard),
+	)
+
+	lc, err := s.getLeader(req.Shard)
+	if err != nil {
+		return err
+	}
+
+	if err = s.authorizeNamespace(stream.Context(), lc.Namespace(), auth.PermissionAdmin); err != nil {
+		return err
+	}
+
+	err = lc.Export(req, stream)
+	if err != nil {
+		s.log.Warn(
+			"Failed to export the records",
+			slog.Int64("shard", req.Shard),
+			slog.Any("error", err),
+		)
+	}
+	return err
+}
+
+func (s *publicRpcServer) Ingest(stream proto.OxiaAdmin_IngestServer) error {
+	chunk, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	s.log.Info(
+		"Ingest request",
+		slog.String("peer", common.GetPeer(stream.Context())),
+		slog.String("namespace", chunk.Namespace),
+		slog.Int64("shard", chunk.Shard),
+	)
+
+	lc, err := s.getLeader(chunk.Shard)
+	if err != nil {
+		return err
+	}
+
+	if err = s.authorizeNamespace(stream.Context(), lc.Namespace(), auth.PermissionAdmin); err != nil {
+		return err
+	}
+
+	err = lc.Ingest(chunk, stream)
+	if err != nil {
+		s.log.Warn(
+			"Failed to ingest the sstable",
+			slog.Int64("shard", chunk.Shard),
+			slog.Any("error", err),
+		)
+	}
+	return err
+}
+
+func (s *publicRpcServer) GetRecords(ctx context.Context, req *proto.GetRecordsRequest) (*proto.GetRecordsResponse, error) {
+	s.log.Debug(
+		"Get records request",
+		slog.String("peer", common.GetPeer(ctx)),
+		slog.String("namespace", req.Namespace),
+		slog.Int64("shard", req.Shard),
+		slog.Int("keys", len(req.Keys)),
+	)
+
+	lc, err := s.getLeader(req.Shard)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.authorizeNamespace(ctx, lc.Namespace(), auth.PermissionAdmin); err != nil {
+		return nil, err
+	}
+
+	res, err := lc.GetRecords(req)
+	if err != nil {
+		s.log.Warn(
+			"Failed to get the records",
+			slog.Int64("shard", req.Shard),
+			slog.Any("error", err),
+		)
+	}
+	return res, err
+}
+
+func (s *publicRpcServer) GetHotKeys(ctx context.Context, req *proto.GetHotKeysRequest) (*proto.GetHotKeysResponse, error) {
+	s.log.Debug(
+		"Get hot keys request",
+		slog.String("peer", common.GetPeer(ctx)),
+		slog.String("namespace", req.Namespace),
+		slog.Int64("shard", req.Shard),
+	)
+
+	lc, err := s.getLeader(req.Shard)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.authorizeNamespace(ctx, lc.Namespace(), auth.PermissionAdmin); err != nil {
+		return nil, err
+	}
+
+	return lc.GetHotKeys(req)
+}
+
+func (s *publicRpcServer) GetSlowOperations(ctx context.Context, req *proto.GetSlowOperationsRequest) (*proto.GetSlowOperationsResponse, error) {
+	s.log.Debug(
+		"Get slow operations request",
+		slog.String("peer", common.GetPeer(ctx)),
+		slog.String("namespace", req.Namespace),
+		slog.Int64("shard", req.Shard),
+	)
+
+	lc, err := s.getLeader(req.Shard)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.authorizeNamespace(ctx, lc.Namespace(), auth.PermissionAdmin); err != nil {
+		return nil, err
+	}
+
+	return lc.GetSlowOperations(req)
+}
+
 func (s *publicRpcServer) Port() int {
 	return s.grpcServer.Port()
 }
@@ -347,6 +583,12 @@ func (s *publicRpcServer) CreateSession(ctx context.Context, req *proto.CreateSe
 	if err != nil {
 		return nil, err
 	}
+	if err = s.authorizeSession(ctx, lc.Namespace()); err != nil {
+		return nil, err
+	}
+	if req.ClientIdentity, err = s.stampIdentity(ctx, req.ClientIdentity); err != nil {
+		return nil, err
+	}
 	res, err := lc.CreateSession(req)
 	if err != nil {
 		s.log.Warn(
@@ -369,6 +611,12 @@ func (s *publicRpcServer) KeepAlive(ctx context.Context, req *proto.SessionHeart
 	if err != nil {
 		return nil, err
 	}
+	if err = s.authorizeSession(ctx, lc.Namespace()); err != nil {
+		return nil, err
+	}
+	if err = s.authorizeSessionOwner(ctx, lc, req.SessionId); err != nil {
+		return nil, err
+	}
 	err = lc.KeepAlive(req.SessionId)
 	if err != nil {
 		s.log.Warn(
@@ -390,6 +638,12 @@ func (s *publicRpcServer) CloseSession(ctx context.Context, req *proto.CloseSess
 	if err != nil {
 		return nil, err
 	}
+	if err = s.authorizeSession(ctx, lc.Namespace()); err != nil {
+		return nil, err
+	}
+	if err = s.authorizeSessionOwner(ctx, lc, req.SessionId); err != nil {
+		return nil, err
+	}
 	res, err := lc.CloseSession(req)
 	if err != nil {
 		if status.Code(err) != common.CodeSessionNotFound {
@@ -419,6 +673,39 @@ func (s *publicRpcServer) getLeader(shardId int64) (LeaderController, error) {
 	return lc, nil
 }
 
+// shardReader serves the read operations of a shard, either from the
+// leader or, with stale data, from an observer.
+type shardReader interface {
+	Read(ctx context.Context, request *proto.ReadRequest) <-chan GetResult
+	List(ctx context.Context, request *proto.ListRequest) (<-chan string, error)
+	RangeScan(ctx context.Context, request *proto.RangeScanRequest) (<-chan *proto.GetResponse, <-chan error, error)
+	Namespace() string
+}
+
+func (s *publicRpcServer) getReader(shardId int64) (shardReader, error) {
+	lc, err := s.shardsDirector.GetLeader(shardId)
+	if err == nil {
+		return lc, nil
+	}
+
+	if status.Code(err) == common.CodeNodeIsNotLeader {
+		if fc, ferr := s.shardsDirector.GetFollower(shardId); ferr == nil {
+			// The follower rejects the reads, unless it's an observer
+			return fc, nil
+		}
+	} else {
+		s.log.Warn(
+			"Failed to get the leader controller",
+			slog.Any("error", err),
+		)
+	}
+	return nil, err
+}
+
 func (s *publicRpcServer) Close() error {
-	return s.grpcServer.Close()
+	err := s.grpcServer.Close()
+	if s.authorizer != nil {
+		err = multierr.Append(err, s.authorizer.Close())
+	}
+	return err
 }
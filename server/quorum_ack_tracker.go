@@ -92,6 +92,10 @@ type QuorumAckTracker interface {
 	// NewCursorAcker creates a tracker for a new cursor
 	// The `ackOffset` is the previous last-acked position for the cursor
 	NewCursorAcker(ackOffset int64) (CursorAcker, error)
+
+	// NewObserverAcker creates a tracker for the cursor of an observer
+	// The acks from observers are not counted towards the quorum
+	NewObserverAcker(ackOffset int64) (CursorAcker, error)
 }
 
 type quorumAckTracker struct {
@@ -122,6 +126,8 @@ type cursorAcker struct {
 	cursorIdx     int
 }
 
+type observerAcker struct{}
+
 type waitingRequest struct {
 	minOffset int64
 	callback  callback.Callback[any]
@@ -279,6 +285,16 @@ func (q *quorumAckTracker) NewCursorAcker(ackOffset int64) (CursorAcker, error)
 	return qa, nil
 }
 
+func (q *quorumAckTracker) NewObserverAcker(ackOffset int64) (CursorAcker, error) {
+	if ackOffset > q.headOffset.Load() {
+		return nil, ErrInvalidHeadOffset
+	}
+
+	return &observerAcker{}, nil
+}
+
+func (*observerAcker) Ack(int64) {}
+
 func (c *cursorAcker) Ack(offset int64) {
 	c.quorumTracker.Lock()
 	defer c.quorumTracker.Unlock()
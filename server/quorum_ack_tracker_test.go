@@ -268,6 +268,37 @@ func TestQuorumAckTracker_AddingCursors_RF5(t *testing.T) {
 	assert.EqualValues(t, 7, at.CommitOffset())
 }
 
+func TestQuorumAckTracker_Observers(t *testing.T) {
+	at := NewQuorumAckTracker(3, 10, 5)
+
+	o, err := at.NewObserverAcker(11)
+	assert.Nil(t, o)
+	assert.ErrorIs(t, err, ErrInvalidHeadOffset)
+
+	// Observers never advance the commit offset and don't take
+	// the place of a follower
+	o1, err := at.NewObserverAcker(8)
+	assert.NoError(t, err)
+	o2, err := at.NewObserverAcker(wal.InvalidOffset)
+	assert.NoError(t, err)
+	o1.Ack(9)
+	o2.Ack(6)
+	assert.EqualValues(t, 5, at.CommitOffset())
+
+	c1, err := at.NewCursorAcker(wal.InvalidOffset)
+	assert.NoError(t, err)
+	_, err = at.NewCursorAcker(wal.InvalidOffset)
+	assert.NoError(t, err)
+	_, err = at.NewCursorAcker(wal.InvalidOffset)
+	assert.ErrorIs(t, err, ErrTooManyCursors)
+
+	o1.Ack(10)
+	assert.EqualValues(t, 5, at.CommitOffset())
+
+	c1.Ack(6)
+	assert.EqualValues(t, 6, at.CommitOffset())
+}
+
 func TestQuorumAckTracker_ClearPending(t *testing.T) {
 	at := NewQuorumAckTracker(5, 10, 5)
 	asyncRes := make(chan error, 1)
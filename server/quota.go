@@ -0,0 +1,198 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/kv"
+)
+
+// quotaTracker keeps the usage of the writes that were appended to the WAL,
+// but not yet applied to the database. The quota is checked against the
+// usage of the database plus the reserved one, so that the concurrent
+// writes can't exceed it together.
+//
+// The reservation is conservative: the deletes don't free any space until
+// they're applied, and the puts on keys with pending writes are counted
+// against the last applied version of the record.
+type quotaTracker struct {
+	sync.Mutex
+	reserved kv.Usage
+}
+
+// quotaReservation is the usage reserved by a write request, and the indexes
+// of its puts that were rejected for exceeding the quota. It also has the
+// usage of the delete ranges of the request, which is replicated with it.
+type quotaReservation struct {
+	tracker      *quotaTracker
+	usage        kv.Usage
+	rejected     []int
+	deleteRanges *proto.WriteUsage
+}
+
+// check returns the request without the puts that would exceed the quota,
+// and reserves the usage of the others. The request is not modified.
+func (t *quotaTracker) check(db kv.DB, quota *proto.ShardQuota, request *proto.WriteRequest) (*proto.WriteRequest, *quotaReservation) {
+	if quota == nil || (quota.MaxBytes <= 0 && quota.MaxKeys <= 0 && quota.MaxValueSize <= 0) {
+		return request, nil
+	}
+
+	deleteRanges := deleteRangesUsage(db, quota, request)
+	t.Lock()
+	defer t.Unlock()
+
+	usage := db.Usage()
+	usage.Keys += t.reserved.Keys
+	usage.Bytes += t.reserved.Bytes
+
+	reservation := &quotaReservation{tracker: t, deleteRanges: deleteRanges}
+	for i, put := range request.Puts {
+		if strings.HasPrefix(put.Key, common.InternalKeyPrefix) {
+			continue
+		}
+
+		delta := putUsage(db, put)
+		if (quota.MaxValueSize > 0 && int64(len(put.Value)) > quota.MaxValueSize) ||
+			(quota.MaxKeys > 0 && delta.Keys > 0 && usage.Keys+delta.Keys > quota.MaxKeys) ||
+			(quota.MaxBytes > 0 && delta.Bytes > 0 && usage.Bytes+delta.Bytes > quota.MaxBytes) {
+			reservation.rejected = append(reservation.rejected, i)
+			continue
+		}
+
+		// The space freed by the smaller values is only counted once applied
+		delta.Bytes = max(delta.Bytes, 0)
+		usage.Keys += delta.Keys
+		usage.Bytes += delta.Bytes
+		reservation.usage.Keys += delta.Keys
+		reservation.usage.Bytes += delta.Bytes
+	}
+
+	t.reserved.Keys += reservation.usage.Keys
+	t.reserved.Bytes += reservation.usage.Bytes
+
+	if len(reservation.rejected) == 0 {
+		return request, reservation
+	}
+
+	accepted := &proto.WriteRequest{
+		Shard:        request.Shard,
+		Puts:         make([]*proto.PutRequest, 0, len(request.Puts)-len(reservation.rejected)),
+		Deletes:      request.Deletes,
+		DeleteRanges: request.DeleteRanges,
+	}
+	rejected := reservation.rejected
+	for i, put := range request.Puts {
+		if len(rejected) > 0 && rejected[0] == i {
+			rejected = rejected[1:]
+			continue
+		}
+		accepted.Puts = append(accepted.Puts, put)
+	}
+	return accepted, reservation
+}
+
+// The usage of the records in the delete ranges, so that the replicas don't
+// need to scan them when they track their usage. The usage is read from the
+// applied records, so the records written by the pending writes in the ranges
+// are not counted.
+func deleteRangesUsage(db kv.DB, quota *proto.ShardQuota, request *proto.WriteRequest) *proto.WriteUsage {
+	if len(request.DeleteRanges) == 0 || !kv.UsageTracked(quota) {
+		return nil
+	}
+
+	writeUsage := &proto.WriteUsage{DeleteRanges: make([]*proto.RecordsUsage, 0, len(request.DeleteRanges))}
+	for _, dr := range request.DeleteRanges {
+		usage, err := db.RangeUsage(dr.StartInclusive, dr.EndExclusive)
+		if err != nil {
+			// Without the usage, the replicas scan the ranges when applying them
+			return nil
+		}
+		writeUsage.DeleteRanges = append(writeUsage.DeleteRanges, &proto.RecordsUsage{Keys: usage.Keys, Bytes: usage.Bytes})
+	}
+	return writeUsage
+}
+
+// The change in the usage after the put, compared with the last applied
+// version of the record.
+func putUsage(db kv.DB, put *proto.PutRequest) kv.Usage {
+	delta := kv.Usage{Keys: 1, Bytes: kv.RecordSize(put.Key, put.Value)}
+	if len(put.SequenceKeyDelta) > 0 {
+		// The key is always a new one, with a suffix added by the database
+		return delta
+	}
+
+	if se, err := db.ReadRecord(put.Key); err == nil {
+		delta.Keys = 0
+		delta.Bytes -= kv.RecordSize(put.Key, se.Value)
+	}
+	return delta
+}
+
+// writeUsage is the usage of the delete ranges of the request, if the shard
+// tracks its usage.
+func (r *quotaReservation) writeUsage() *proto.WriteUsage {
+	if r == nil {
+		return nil
+	}
+	return r.deleteRanges
+}
+
+// logEntryUsages are the usages replicated with the request in the log entry.
+func (r *quotaReservation) logEntryUsages() []*proto.WriteUsage {
+	if r.writeUsage() == nil {
+		return nil
+	}
+	return []*proto.WriteUsage{r.deleteRanges}
+}
+
+// release returns the reserved usage, once the write was applied to the
+// database or it failed.
+func (r *quotaReservation) release() {
+	if r == nil {
+		return
+	}
+
+	r.tracker.Lock()
+	defer r.tracker.Unlock()
+	r.tracker.reserved.Keys -= r.usage.Keys
+	r.tracker.reserved.Bytes -= r.usage.Bytes
+}
+
+// merge adds the responses of the rejected puts to the response of the
+// accepted ones, at the original positions in the request.
+func (r *quotaReservation) merge(response *proto.WriteResponse) *proto.WriteResponse {
+	if r == nil || len(r.rejected) == 0 || response == nil {
+		return response
+	}
+
+	puts := make([]*proto.PutResponse, 0, len(response.Puts)+len(r.rejected))
+	accepted := response.Puts
+	rejected := r.rejected
+	for i := 0; len(accepted) > 0 || len(rejected) > 0; i++ {
+		if len(rejected) > 0 && rejected[0] == i {
+			puts = append(puts, &proto.PutResponse{Status: proto.Status_QUOTA_EXCEEDED})
+			rejected = rejected[1:]
+			continue
+		}
+		puts = append(puts, accepted[0])
+		accepted = accepted[1:]
+	}
+	response.Puts = puts
+	return response
+}
@@ -0,0 +1,223 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/common/metrics"
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/auth"
+)
+
+const (
+	// The buckets of the clients that didn't make requests for this long are
+	// discarded. They would be full anyway.
+	rateLimitIdleTimeout = time.Minute
+)
+
+// RateLimitOptions are the budgets of each client on each namespace, in
+// operations per second. A client is the authenticated principal or, without
+// authentication, the address of the peer. The bursts are up to one second
+// of operations. The throttled requests are rejected, except the ones on the
+// write streams, which are delayed. The zero values are unlimited.
+type RateLimitOptions struct {
+	// Gets, lists and range-scans
+	ReadsPerSecond float64
+	// Puts, deletes and delete-ranges
+	WritesPerSecond float64
+	// New notifications streams
+	NotificationsPerSecond float64
+}
+
+func (o *RateLimitOptions) IsEnabled() bool {
+	return o != nil && (o.ReadsPerSecond > 0 || o.WritesPerSecond > 0 || o.NotificationsPerSecond > 0)
+}
+
+type rateLimitType int
+
+const (
+	rateLimitRead rateLimitType = iota
+	rateLimitWrite
+	rateLimitNotifications
+)
+
+func (t rateLimitType) String() string {
+	switch t {
+	case rateLimitRead:
+		return "read"
+	case rateLimitWrite:
+		return "write"
+	default:
+		return "notifications"
+	}
+}
+
+type rateLimitKey struct {
+	client    string
+	namespace string
+}
+
+type rateLimitBuckets struct {
+	limiters [3]*rate.Limiter
+	lastUsed time.Time
+}
+
+// rateLimiter keeps a token bucket for each type of operation, for each client
+// and namespace. A nil rate limiter allows all the requests.
+type rateLimiter struct {
+	sync.Mutex
+
+	limits    [3]rate.Limit
+	buckets   map[rateLimitKey]*rateLimitBuckets
+	lastSweep time.Time
+	now       func() time.Time
+	log       *slog.Logger
+
+	throttledCounters [3]metrics.Counter
+}
+
+func newRateLimiter(options RateLimitOptions) *rateLimiter {
+	if !options.IsEnabled() {
+		return nil
+	}
+
+	l := &rateLimiter{
+		buckets: map[rateLimitKey]*rateLimitBuckets{},
+		now:     time.Now,
+		log: slog.With(
+			slog.String("component", "rate-limiter"),
+		),
+	}
+	for t, perSecond := range []float64{options.ReadsPerSecond, options.WritesPerSecond, options.NotificationsPerSecond} {
+		l.limits[t] = rate.Inf
+		if perSecond > 0 {
+			l.limits[t] = rate.Limit(perSecond)
+		}
+		l.throttledCounters[t] = metrics.NewCounter("oxia_server_rate_limited_operations",
+			"The number of operations rejected by the rate limiter", "count",
+			map[string]any{"type": rateLimitType(t).String()})
+	}
+	return l
+}
+
+// allow takes the tokens for the operations of a request, or returns a
+// ResourceExhausted error with the time after which the request can be retried.
+func (l *rateLimiter) allow(ctx context.Context, namespace string, t rateLimitType, operations int) error {
+	if l == nil || l.limits[t] == rate.Inf || operations <= 0 {
+		return nil
+	}
+
+	now := l.now()
+	reservation, delay := l.reserve(ctx, now, namespace, t, operations)
+	if delay > 0 {
+		reservation.CancelAt(now)
+		return common.NewRateLimitedError(t.String(), delay)
+	}
+	return nil
+}
+
+func (l *rateLimiter) reserve(ctx context.Context, now time.Time, namespace string, t rateLimitType,
+	operations int) (*rate.Reservation, time.Duration) {
+	key := rateLimitKey{client: rateLimitClient(ctx), namespace: namespace}
+
+	l.Lock()
+	defer l.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &rateLimitBuckets{}
+		l.buckets[key] = b
+	}
+	b.lastUsed = now
+
+	limiter := b.limiters[t]
+	if limiter == nil {
+		limiter = rate.NewLimiter(l.limits[t], max(int(l.limits[t]), 1))
+		b.limiters[t] = limiter
+	}
+
+	// A request larger than the burst takes the whole bucket
+	reservation := limiter.ReserveN(now, min(operations, limiter.Burst()))
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		l.throttledCounters[t].Add(operations)
+		l.log.Debug(
+			"Request was rate limited",
+			slog.String("client", key.client),
+			slog.String("namespace", namespace),
+			slog.String("type", t.String()),
+			slog.Duration("delay", delay),
+		)
+	}
+	return reservation, delay
+}
+
+// Discard the buckets of the idle clients.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitIdleTimeout {
+		return
+	}
+
+	for key, b := range l.buckets {
+		if now.Sub(b.lastUsed) >= rateLimitIdleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+func rateLimitClient(ctx context.Context) string {
+	if user, ok := auth.UserNameFromContext(ctx); ok {
+		return user
+	}
+
+	peer := common.GetPeer(ctx)
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		return host
+	}
+	return peer
+}
+
+// rateLimitedWriteStream rejects the requests received from the write stream
+// above the rate limit, with the time after which they can be retried. The
+// stream is kept open, so that the in-flight writes are not failed.
+type rateLimitedWriteStream struct {
+	proto.OxiaClient_WriteStreamServer
+
+	limiter   *rateLimiter
+	namespace string
+}
+
+func (s *rateLimitedWriteStream) Recv() (*proto.WriteRequest, error) {
+	write, err := s.OxiaClient_WriteStreamServer.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.limiter.allow(s.Context(), s.namespace, rateLimitWrite, int(writeOpsCount(write))); err != nil {
+		return nil, &writeRejectedError{err: err}
+	}
+	return write, nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/auth"
+)
+
+func newTestRateLimiter(options RateLimitOptions) (*rateLimiter, *time.Time) {
+	now := time.Unix(1000, 0)
+	l := newRateLimiter(options)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	l := newRateLimiter(RateLimitOptions{})
+	assert.Nil(t, l)
+	assert.NoError(t, l.allow(context.Background(), common.DefaultNamespace, rateLimitWrite, 1000))
+}
+
+func TestRateLimiter_Allow(t *testing.T) {
+	l, now := newTestRateLimiter(RateLimitOptions{WritesPerSecond: 10})
+	ctx := auth.WithUserName(context.Background(), "user-1")
+
+	assert.NoError(t, l.allow(ctx, common.DefaultNamespace, rateLimitWrite, 8))
+	assert.NoError(t, l.allow(ctx, common.DefaultNamespace, rateLimitWrite, 2))
+
+	err := l.allow(ctx, common.DefaultNamespace, rateLimitWrite, 5)
+	retryAfter, ok := common.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// The rejected request doesn't take any tokens
+	*now = now.Add(500 * time.Millisecond)
+	assert.NoError(t, l.allow(ctx, common.DefaultNamespace, rateLimitWrite, 5))
+
+	// The reads are not limited
+	assert.NoError(t, l.allow(ctx, common.DefaultNamespace, rateLimitRead, 1000))
+
+	// The requests larger than the burst take the whole bucket
+	*now = now.Add(time.Second)
+	assert.NoError(t, l.allow(ctx, common.DefaultNamespace, rateLimitWrite, 100))
+	assert.Error(t, l.allow(ctx, common.DefaultNamespace, rateLimitWrite, 1))
+}
+
+func TestRateLimiter_Isolation(t *testing.T) {
+	l, _ := newTestRateLimiter(RateLimitOptions{ReadsPerSecond: 1})
+	user1 := auth.WithUserName(context.Background(), "user-1")
+	user2 := auth.WithUserName(context.Background(), "user-2")
+	peer1 := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234},
+	})
+	peer1OtherPort := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5678},
+	})
+
+	assert.NoError(t, l.allow(user1, "ns-1", rateLimitRead, 1))
+	assert.Error(t, l.allow(user1, "ns-1", rateLimitRead, 1))
+
+	assert.NoError(t, l.allow(user1, "ns-2", rateLimitRead, 1))
+	assert.NoError(t, l.allow(user2, "ns-1", rateLimitRead, 1))
+
+	// The unauthenticated clients are identified by their host
+	assert.NoError(t, l.allow(peer1, "ns-1", rateLimitRead, 1))
+	assert.Error(t, l.allow(peer1OtherPort, "ns-1", rateLimitRead, 1))
+}
+
+func TestRateLimiter_Sweep(t *testing.T) {
+	l, now := newTestRateLimiter(RateLimitOptions{NotificationsPerSecond: 1})
+	user1 := auth.WithUserName(context.Background(), "user-1")
+	user2 := auth.WithUserName(context.Background(), "user-2")
+
+	assert.NoError(t, l.allow(user1, common.DefaultNamespace, rateLimitNotifications, 1))
+	*now = now.Add(30 * time.Second)
+	assert.NoError(t, l.allow(user2, common.DefaultNamespace, rateLimitNotifications, 1))
+	assert.Len(t, l.buckets, 2)
+
+	*now = now.Add(45 * time.Second)
+	assert.NoError(t, l.allow(user2, common.DefaultNamespace, rateLimitNotifications, 1))
+	assert.Len(t, l.buckets, 1)
+}
+
+func TestRateLimiter_WriteStream(t *testing.T) {
+	config := NewTestConfig(t.TempDir())
+	config.RateLimit = RateLimitOptions{WritesPerSecond: 2}
+	standalone, err := NewStandalone(config)
+	assert.NoError(t, err)
+	serviceAddress := fmt.Sprintf("localhost:%d", standalone.RpcPort())
+
+	clientPool := common.NewClientPool(nil, nil)
+	rpc, err := clientPool.GetClientRpc(serviceAddress)
+	assert.NoError(t, err)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), common.MetadataNamespace, common.DefaultNamespace)
+	ctx = metadata.AppendToOutgoingContext(ctx, common.MetadataShardId, "0")
+	stream, err := rpc.WriteStream(ctx)
+	assert.NoError(t, err)
+
+	var shard int64
+	put := func(key string) *proto.WriteResponse {
+		assert.NoError(t, stream.Send(&proto.WriteRequest{
+			Shard: &shard,
+			Puts:  []*proto.PutRequest{{Key: key, Value: []byte("value")}},
+		}))
+		res, err := stream.Recv()
+		assert.NoError(t, err)
+		return res
+	}
+
+	// The first writes take the whole burst
+	assert.Nil(t, put("a").Rejection)
+	assert.Nil(t, put("b").Rejection)
+
+	res := put("c")
+	assert.NotNil(t, res.Rejection)
+	assert.Empty(t, res.Puts)
+	retryAfter, ok := common.RetryAfter(common.WriteRejectionError(res.Rejection))
+	assert.True(t, ok)
+	assert.Greater(t, retryAfter, time.Duration(0))
+
+	// The stream can still be used once the suggested delay has passed
+	time.Sleep(retryAfter)
+	res = put("c")
+	assert.Nil(t, res.Rejection)
+	assert.Equal(t, proto.Status_OK, res.Puts[0].Status)
+	assert.NoError(t, stream.CloseSend())
+
+	// The client retries the throttled writes after the suggested delay
+	client, err := oxia.NewSyncClient(serviceAddress, oxia.WithBatchLinger(0))
+	assert.NoError(t, err)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, _, err = client.Put(context.Background(), fmt.Sprintf("key-%d", i), []byte("value"))
+		assert.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+
+	assert.NoError(t, client.Close())
+	assert.NoError(t, clientPool.Close())
+	assert.NoError(t, standalone.Close())
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replicator
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var ErrCheckpointNamespaceMismatch = errors.New("oxia: checkpoint of a different source namespace")
+
+// The offsets of the last notifications applied for each shard of the source
+// namespace.
+type checkpoint struct {
+	sync.Mutex
+	path  string
+	dirty bool
+
+	SourceNamespace string          `json:"sourceNamespace"`
+	Offsets         map[int64]int64 `json:"offsets"`
+}
+
+func readCheckpoint(path string, sourceNamespace string) (*checkpoint, error) {
+	c := &checkpoint{
+		path:            path,
+		SourceNamespace: sourceNamespace,
+		Offsets:         map[int64]int64{},
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the checkpoint %s", path)
+	}
+	if c.SourceNamespace != sourceNamespace {
+		return nil, errors.Wrapf(ErrCheckpointNamespaceMismatch, "namespace %q", c.SourceNamespace)
+	}
+	if c.Offsets == nil {
+		c.Offsets = map[int64]int64{}
+	}
+	return c, nil
+}
+
+func (c *checkpoint) offset(shard int64) (int64, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	offset, ok := c.Offsets[shard]
+	return offset, ok
+}
+
+func (c *checkpoint) update(shard int64, offset int64) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.Offsets[shard] = offset
+	c.dirty = true
+}
+
+// Writes the checkpoint, if it was updated, replacing the previous one
+// atomically.
+func (c *checkpoint) write() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := c.path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		return err
+	}
+
+	c.dirty = false
+	return nil
+}
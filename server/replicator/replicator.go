@@ -0,0 +1,215 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replicator asynchronously replicates a namespace to a namespace of
+// another cluster, to keep a warm standby.
+//
+// The replicator tails the notifications of each shard of the source
+// namespace, which must have the notifications enabled. For each changed key,
+// the current state of the record in the source is applied to the target.
+// The offsets of the applied notifications are checkpointed in a file, so
+// that a restarted replicator resumes from where it stopped. When a shard
+// has no checkpoint, all its records are copied first.
+package replicator
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/common/metrics"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/server/backup"
+)
+
+// ConflictResolution decides which change is kept when a record was modified
+// in the target as well.
+type ConflictResolution string
+
+const (
+	// LastWriterWins keeps the change with the latest modification timestamp.
+	LastWriterWins ConflictResolution = "last-writer-wins"
+	// SourceWins always applies the changes of the source.
+	SourceWins ConflictResolution = "source-wins"
+)
+
+const (
+	DefaultIdentity = "oxia-replicator"
+
+	checkpointInterval = 1 * time.Second
+)
+
+var ErrInvalidConflictResolution = errors.New("oxia: invalid conflict resolution")
+
+type Options struct {
+	SourceAddress   string
+	SourceNamespace string
+	TargetAddress   string
+	TargetNamespace string
+
+	// The file where the applied offsets are stored
+	CheckpointFile     string
+	ConflictResolution ConflictResolution
+
+	// The identity recorded in the records written in the target, to tell
+	// them apart from the ones written by the applications
+	Identity string
+
+	MetricsServiceAddr string
+}
+
+type Replicator struct {
+	options    Options
+	clientPool common.ClientPool
+	source     oxia.SyncClient
+	target     oxia.SyncClient
+	checkpoint *checkpoint
+	shards     []*shardReplicator
+	metrics    *metrics.PrometheusMetrics
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	log    *slog.Logger
+}
+
+// New starts replicating the source namespace into the target one.
+func New(options Options) (*Replicator, error) {
+	if options.ConflictResolution != LastWriterWins && options.ConflictResolution != SourceWins {
+		return nil, errors.Wrapf(ErrInvalidConflictResolution, "%q", options.ConflictResolution)
+	}
+	if options.Identity == "" {
+		options.Identity = DefaultIdentity
+	}
+
+	r := &Replicator{
+		options:    options,
+		clientPool: common.NewClientPool(nil, nil),
+		log: slog.With(
+			slog.String("component", "replicator"),
+			slog.String("source-namespace", options.SourceNamespace),
+			slog.String("target-namespace", options.TargetNamespace),
+		),
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+
+	var err error
+	if r.checkpoint, err = readCheckpoint(options.CheckpointFile, options.SourceNamespace); err != nil {
+		return nil, multierr.Append(err, r.Close())
+	}
+
+	if r.source, err = oxia.NewSyncClient(options.SourceAddress, oxia.WithNamespace(options.SourceNamespace)); err != nil {
+		return nil, multierr.Append(err, r.Close())
+	}
+	if r.target, err = oxia.NewSyncClient(options.TargetAddress, oxia.WithNamespace(options.TargetNamespace),
+		oxia.WithIdentity(options.Identity)); err != nil {
+		return nil, multierr.Append(err, r.Close())
+	}
+
+	if options.MetricsServiceAddr != "" {
+		if r.metrics, err = metrics.Start(options.MetricsServiceAddr); err != nil {
+			return nil, multierr.Append(err, r.Close())
+		}
+	}
+
+	assignments, err := backup.GetShardAssignments(r.ctx, r.clientPool, options.SourceAddress, options.SourceNamespace)
+	if err != nil {
+		return nil, multierr.Append(err, r.Close())
+	}
+
+	for _, assignment := range assignments {
+		sr := newShardReplicator(r, assignment.Shard)
+		r.shards = append(r.shards, sr)
+
+		r.wg.Add(1)
+		go common.DoWithLabels(
+			r.ctx,
+			map[string]string{
+				"oxia":  "replicator",
+				"shard": sr.shardLabel(),
+			},
+			func() {
+				defer r.wg.Done()
+				sr.run()
+			},
+		)
+	}
+
+	r.wg.Add(1)
+	go common.DoWithLabels(
+		r.ctx,
+		map[string]string{
+			"oxia": "replicator-checkpoint",
+		},
+		func() {
+			defer r.wg.Done()
+			r.writeCheckpoints()
+		},
+	)
+
+	r.log.Info(
+		"Started the replicator",
+		slog.Int("shards", len(assignments)),
+		slog.String("conflict-resolution", string(options.ConflictResolution)),
+	)
+	return r, nil
+}
+
+func (r *Replicator) writeCheckpoints() {
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.checkpoint.write(); err != nil {
+				r.log.Warn(
+					"Failed to write the checkpoint",
+					slog.Any("error", err),
+				)
+			}
+		}
+	}
+}
+
+// Close stops the replication and writes the last checkpoint.
+func (r *Replicator) Close() error {
+	r.cancel()
+	r.wg.Wait()
+
+	var err error
+	for _, sr := range r.shards {
+		sr.close()
+	}
+	if r.checkpoint != nil {
+		err = multierr.Append(err, r.checkpoint.write())
+	}
+	if r.source != nil {
+		err = multierr.Append(err, r.source.Close())
+	}
+	if r.target != nil {
+		err = multierr.Append(err, r.target.Close())
+	}
+	if r.metrics != nil {
+		err = multierr.Append(err, r.metrics.Close())
+	}
+	return multierr.Append(err, r.clientPool.Close())
+}
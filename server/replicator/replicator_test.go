@@ -0,0 +1,204 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replicator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/server"
+)
+
+func newStandalone(t *testing.T, shards uint32) (*server.Standalone, string) {
+	t.Helper()
+
+	config := server.NewTestConfig(t.TempDir())
+	config.NumShards = shards
+	s, err := server.NewStandalone(config)
+	require.NoError(t, err)
+	return s, fmt.Sprintf("localhost:%d", s.RpcPort())
+}
+
+func newClient(t *testing.T, serviceAddress string) oxia.SyncClient {
+	t.Helper()
+
+	client, err := oxia.NewSyncClient(serviceAddress)
+	require.NoError(t, err)
+	return client
+}
+
+func assertEventuallyValue(t *testing.T, client oxia.SyncClient, key string, expected string) {
+	t.Helper()
+
+	assert.Eventually(t, func() bool {
+		_, value, _, err := client.Get(context.Background(), key)
+		return err == nil && string(value) == expected
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func assertEventuallyNotFound(t *testing.T, client oxia.SyncClient, key string) {
+	t.Helper()
+
+	assert.Eventually(t, func() bool {
+		_, _, _, err := client.Get(context.Background(), key)
+		return errors.Is(err, oxia.ErrKeyNotFound)
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestReplicator(t *testing.T) {
+	source, sourceAddress := newStandalone(t, 2)
+	defer source.Close()
+	target, targetAddress := newStandalone(t, 3)
+	defer target.Close()
+
+	sourceClient := newClient(t, sourceAddress)
+	defer sourceClient.Close()
+	targetClient := newClient(t, targetAddress)
+	defer targetClient.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		_, _, err := sourceClient.Put(ctx, fmt.Sprintf("key-%d", i), []byte("v0"),
+			oxia.SecondaryIndex("idx", fmt.Sprint(9-i)))
+		assert.NoError(t, err)
+	}
+	_, _, err := sourceClient.Put(ctx, "partitioned", []byte("p0"), oxia.PartitionKey("pk"))
+	assert.NoError(t, err)
+
+	options := Options{
+		SourceAddress:      sourceAddress,
+		SourceNamespace:    oxia.DefaultNamespace,
+		TargetAddress:      targetAddress,
+		TargetNamespace:    oxia.DefaultNamespace,
+		CheckpointFile:     filepath.Join(t.TempDir(), "checkpoint.json"),
+		ConflictResolution: LastWriterWins,
+	}
+	r, err := New(options)
+	require.NoError(t, err)
+
+	// The existing records are copied first
+	for i := 0; i < 10; i++ {
+		assertEventuallyValue(t, targetClient, fmt.Sprintf("key-%d", i), "v0")
+	}
+	assert.Eventually(t, func() bool {
+		_, value, _, err := targetClient.Get(ctx, "partitioned", oxia.PartitionKey("pk"))
+		return err == nil && string(value) == "p0"
+	}, 10*time.Second, 10*time.Millisecond)
+
+	keys, err := targetClient.List(ctx, "0", "3", oxia.UseIndex("idx"))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"key-9", "key-8", "key-7"}, keys)
+
+	// The following changes are applied
+	_, _, err = sourceClient.Put(ctx, "key-0", []byte("v1"))
+	assert.NoError(t, err)
+	assert.NoError(t, sourceClient.Delete(ctx, "key-1"))
+	assert.NoError(t, sourceClient.DeleteRange(ctx, "key-5", "key-7"))
+	_, _, err = sourceClient.Put(ctx, "new-key", []byte("n0"))
+	assert.NoError(t, err)
+
+	assertEventuallyValue(t, targetClient, "key-0", "v1")
+	assertEventuallyValue(t, targetClient, "new-key", "n0")
+	assertEventuallyNotFound(t, targetClient, "key-1")
+	assertEventuallyNotFound(t, targetClient, "key-5")
+	assertEventuallyNotFound(t, targetClient, "key-6")
+	assertEventuallyValue(t, targetClient, "key-7", "v0")
+
+	_, _, version, err := targetClient.Get(ctx, "key-0")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultIdentity, version.ClientIdentity)
+
+	// The replication resumes from the checkpoint after a restart
+	assert.NoError(t, r.Close())
+
+	_, _, err = sourceClient.Put(ctx, "key-2", []byte("v1"))
+	assert.NoError(t, err)
+
+	r, err = New(options)
+	require.NoError(t, err)
+
+	assertEventuallyValue(t, targetClient, "key-2", "v1")
+	assert.NoError(t, r.Close())
+}
+
+func TestReplicator_Conflicts(t *testing.T) {
+	for _, test := range []struct {
+		conflictResolution ConflictResolution
+		expected           string
+	}{
+		{LastWriterWins, "target"},
+		{SourceWins, "source-2"},
+	} {
+		t.Run(string(test.conflictResolution), func(t *testing.T) {
+			source, sourceAddress := newStandalone(t, 1)
+			defer source.Close()
+			target, targetAddress := newStandalone(t, 1)
+			defer target.Close()
+
+			sourceClient := newClient(t, sourceAddress)
+			defer sourceClient.Close()
+			targetClient := newClient(t, targetAddress)
+			defer targetClient.Close()
+
+			ctx := context.Background()
+			_, _, err := sourceClient.Put(ctx, "key", []byte("source-1"))
+			assert.NoError(t, err)
+
+			options := Options{
+				SourceAddress:      sourceAddress,
+				SourceNamespace:    oxia.DefaultNamespace,
+				TargetAddress:      targetAddress,
+				TargetNamespace:    oxia.DefaultNamespace,
+				CheckpointFile:     filepath.Join(t.TempDir(), "checkpoint.json"),
+				ConflictResolution: test.conflictResolution,
+			}
+			r, err := New(options)
+			require.NoError(t, err)
+			assertEventuallyValue(t, targetClient, "key", "source-1")
+			assert.NoError(t, r.Close())
+
+			// The record is modified in the source first, and then by an
+			// application in the target
+			_, _, err = sourceClient.Put(ctx, "key", []byte("source-2"))
+			assert.NoError(t, err)
+			time.Sleep(10 * time.Millisecond)
+			_, _, err = targetClient.Put(ctx, "key", []byte("target"))
+			assert.NoError(t, err)
+
+			// A marker to know when the change has been replicated
+			_, _, err = sourceClient.Put(ctx, "marker", []byte("m"))
+			assert.NoError(t, err)
+
+			r, err = New(options)
+			require.NoError(t, err)
+			assertEventuallyValue(t, targetClient, "marker", "m")
+			assertEventuallyValue(t, targetClient, "key", test.expected)
+			assert.NoError(t, r.Close())
+		})
+	}
+}
+
+func TestReplicator_InvalidConflictResolution(t *testing.T) {
+	_, err := New(Options{ConflictResolution: "invalid"})
+	assert.ErrorIs(t, err, ErrInvalidConflictResolution)
+}
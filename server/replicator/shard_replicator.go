@@ -0,0 +1,345 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replicator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/pkg/errors"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/common/metrics"
+	"github.com/streamnative/oxia/oxia"
+	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/backup"
+)
+
+type shardReplicator struct {
+	*Replicator
+	shard int64
+	log   *slog.Logger
+
+	// The delay between the commit of the last applied notifications in the
+	// source and their application in the target
+	lag    atomic.Int64
+	offset atomic.Int64
+
+	lagGauge      metrics.Gauge
+	offsetGauge   metrics.Gauge
+	appliedCount  metrics.Counter
+	conflictCount metrics.Counter
+}
+
+func newShardReplicator(r *Replicator, shard int64) *shardReplicator {
+	sr := &shardReplicator{
+		Replicator: r,
+		shard:      shard,
+		log:        r.log.With(slog.Int64("shard", shard)),
+	}
+
+	offset, ok := r.checkpoint.offset(shard)
+	if !ok {
+		offset = -1
+	}
+	sr.offset.Store(offset)
+
+	labels := map[string]any{
+		"namespace":        r.options.SourceNamespace,
+		"shard":            shard,
+		"target_namespace": r.options.TargetNamespace,
+	}
+	sr.lagGauge = metrics.NewGauge("oxia_replicator_lag",
+		"The delay between a change in the source and its application in the target", metrics.Milliseconds, labels,
+		sr.lag.Load)
+	sr.offsetGauge = metrics.NewGauge("oxia_replicator_offset",
+		"The offset of the last notifications applied in the target", "count", labels, sr.offset.Load)
+	sr.appliedCount = metrics.NewCounter("oxia_replicator_applied",
+		"The number of changes applied in the target", "count", labels)
+	sr.conflictCount = metrics.NewCounter("oxia_replicator_conflicts",
+		"The number of changes not applied because the record was modified in the target after them", "count", labels)
+	return sr
+}
+
+func (sr *shardReplicator) shardLabel() string {
+	return fmt.Sprintf("%d", sr.shard)
+}
+
+func (sr *shardReplicator) close() {
+	sr.lagGauge.Unregister()
+	sr.offsetGauge.Unregister()
+}
+
+func (sr *shardReplicator) run() {
+	bo := common.NewBackOff(sr.ctx)
+	_ = backoff.RetryNotify(func() error {
+		return sr.replicate(bo)
+	}, bo, func(err error, duration time.Duration) {
+		if sr.ctx.Err() != nil {
+			return
+		}
+		sr.log.Warn(
+			"Failed to replicate the shard",
+			slog.Any("error", err),
+			slog.Duration("retry-after", duration),
+		)
+	})
+}
+
+func (sr *shardReplicator) replicate(bo backoff.BackOff) error {
+	assignments, err := backup.GetShardAssignments(sr.ctx, sr.clientPool, sr.options.SourceAddress,
+		sr.options.SourceNamespace)
+	if err != nil {
+		return err
+	}
+
+	var leader string
+	for _, assignment := range assignments {
+		if assignment.Shard == sr.shard {
+			leader = assignment.Leader
+		}
+	}
+	if leader == "" {
+		return errors.Errorf("no leader for shard %d", sr.shard)
+	}
+
+	rpc, err := sr.clientPool.GetClientRpc(leader)
+	if err != nil {
+		return err
+	}
+	adminRpc, err := sr.clientPool.GetAdminRpc(leader)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(sr.ctx)
+	defer cancel()
+
+	req := &proto.NotificationsRequest{Shard: sr.shard}
+	offset, ok := sr.checkpoint.offset(sr.shard)
+	if ok {
+		req.StartOffsetExclusive = &offset
+	}
+	stream, err := rpc.GetNotifications(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		// The first batch only positions the stream at the current commit
+		// offset, from where the changes that follow the copy are applied
+		nb, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if err := sr.copyRecords(ctx, adminRpc); err != nil {
+			return err
+		}
+		sr.checkpoint.update(sr.shard, nb.Offset)
+		sr.offset.Store(nb.Offset)
+	}
+
+	bo.Reset()
+
+	for {
+		nb, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if err := sr.applyNotifications(ctx, adminRpc, nb); err != nil {
+			return err
+		}
+
+		sr.checkpoint.update(sr.shard, nb.Offset)
+		sr.offset.Store(nb.Offset)
+		sr.lag.Store(time.Now().UnixMilli() - int64(nb.Timestamp))
+	}
+}
+
+func (sr *shardReplicator) copyRecords(ctx context.Context, adminRpc proto.OxiaAdminClient) error {
+	stream, err := adminRpc.Export(ctx, &proto.ExportRequest{Namespace: sr.options.SourceNamespace, Shard: sr.shard})
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	for {
+		res, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		for _, record := range res.Records {
+			if err := sr.applyRecord(ctx, record); err != nil {
+				return err
+			}
+		}
+		count += int64(len(res.Records))
+	}
+
+	sr.log.Info(
+		"Copied the records of the shard",
+		slog.Int64("records", count),
+	)
+	return nil
+}
+
+func (sr *shardReplicator) applyNotifications(ctx context.Context, adminRpc proto.OxiaAdminClient,
+	nb *proto.NotificationBatch) error {
+	var puts, deletes []string
+
+	// The range deletions are applied first, since the other keys of the batch
+	// are read in a state that follows them
+	for key, n := range nb.Notifications {
+		switch n.Type {
+		case proto.NotificationType_KEY_CREATED, proto.NotificationType_KEY_MODIFIED:
+			puts = append(puts, key)
+		case proto.NotificationType_KEY_DELETED:
+			deletes = append(deletes, key)
+		case proto.NotificationType_KEY_RANGE_DELETED:
+			if err := sr.applyDeleteRange(ctx, key, n.GetKeyRangeLast(), nb.Timestamp); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, key := range deletes {
+		if err := sr.applyDeleteRange(ctx, key, key+"\x00", nb.Timestamp); err != nil {
+			return err
+		}
+	}
+
+	if len(puts) == 0 {
+		return nil
+	}
+
+	// The records that don't exist anymore have a deletion in the
+	// notifications that follow
+	res, err := adminRpc.GetRecords(ctx, &proto.GetRecordsRequest{
+		Namespace: sr.options.SourceNamespace,
+		Shard:     sr.shard,
+		Keys:      puts,
+	})
+	if err != nil {
+		return err
+	}
+	for _, record := range res.Records {
+		if err := sr.applyRecord(ctx, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Checks whether the record in the target was modified by an application
+// after a change in the source made at the given time.
+func (sr *shardReplicator) isConflict(version oxia.Version, timestamp uint64) bool {
+	return sr.options.ConflictResolution == LastWriterWins &&
+		version.ClientIdentity != sr.options.Identity &&
+		version.ModifiedTimestamp > timestamp
+}
+
+func (sr *shardReplicator) applyRecord(ctx context.Context, record *proto.ExportedRecord) error {
+	options := []oxia.PutOption{oxia.RecordIdentity()}
+	if record.PartitionKey != nil {
+		options = append(options, oxia.PartitionKey(*record.PartitionKey))
+	}
+	for _, idx := range record.SecondaryIndexes {
+		options = append(options, oxia.SecondaryIndex(idx.IndexName, idx.SecondaryKey))
+	}
+
+	if sr.options.ConflictResolution == SourceWins {
+		if _, _, err := sr.target.Put(ctx, record.Key, record.Value, options...); err != nil {
+			return err
+		}
+		sr.appliedCount.Inc()
+		return nil
+	}
+
+	for {
+		var getOptions []oxia.GetOption
+		if record.PartitionKey != nil {
+			getOptions = append(getOptions, oxia.PartitionKey(*record.PartitionKey))
+		}
+
+		expectedVersion := oxia.ExpectedRecordNotExists()
+		_, _, version, err := sr.target.Get(ctx, record.Key, getOptions...)
+		if err == nil {
+			if sr.isConflict(version, record.ModificationTimestamp) {
+				sr.conflictCount.Inc()
+				return nil
+			}
+			expectedVersion = oxia.ExpectedVersionId(version.VersionId)
+		} else if !errors.Is(err, oxia.ErrKeyNotFound) {
+			return err
+		}
+
+		// The record is checked again if it's modified in the meantime
+		_, _, err = sr.target.Put(ctx, record.Key, record.Value, append(options, expectedVersion)...)
+		if errors.Is(err, oxia.ErrUnexpectedVersionId) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		sr.appliedCount.Inc()
+		return nil
+	}
+}
+
+// Deletes the records of the target in the range that don't exist in the
+// source anymore. Since the shards are replicated independently, a record
+// may have been written in another shard after a range deletion.
+func (sr *shardReplicator) applyDeleteRange(ctx context.Context, minKeyInclusive string, maxKeyExclusive string,
+	timestamp uint64) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sourceKeys, err := sr.source.List(ctx, minKeyInclusive, maxKeyExclusive)
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, key := range sourceKeys {
+		existing[key] = true
+	}
+
+	for res := range sr.target.RangeScan(ctx, minKeyInclusive, maxKeyExclusive) {
+		if res.Err != nil {
+			return res.Err
+		}
+		if existing[res.Key] {
+			continue
+		}
+		if sr.isConflict(res.Version, timestamp) {
+			sr.conflictCount.Inc()
+			continue
+		}
+
+		// The partition key of the record is unknown, so it's deleted from
+		// any shard
+		if err := sr.target.DeleteRange(ctx, res.Key, res.Key+"\x00"); err != nil {
+			return err
+		}
+		sr.appliedCount.Inc()
+	}
+	return nil
+}
@@ -17,32 +17,55 @@ package server
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"sync"
 	"time"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 
 	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/common/compression"
 	"github.com/streamnative/oxia/proto"
 )
 
 const rpcTimeout = 30 * time.Second
 
+// After a follower rejects the compressed streams, they are sent uncompressed
+// for a while before trying again, in case the follower was upgraded.
+const compressionRetryInterval = 5 * time.Minute
+
 type ReplicationRpcProvider interface {
 	io.Closer
 	ReplicateStreamProvider
 
 	Truncate(follower string, req *proto.TruncateRequest) (*proto.TruncateResponse, error)
+
+	FetchLog(ctx context.Context, follower string, req *proto.FetchLogRequest) (proto.OxiaLogReplication_FetchLogClient, error)
 }
 
 type replicationRpcProvider struct {
-	pool common.ClientPool
+	sync.Mutex
+
+	pool        common.ClientPool
+	compression compression.Compression
+
+	// The followers that don't support the compression, with the time
+	// when they rejected it
+	uncompressedFollowers map[string]time.Time
 }
 
-func NewReplicationRpcProvider(tlsConf *tls.Config) ReplicationRpcProvider {
+// NewReplicationRpcProvider creates a provider that compresses the replication and
+// snapshot streams with the given compression. The compression is negotiated with
+// each follower: the streams to followers that don't support it are sent uncompressed.
+func NewReplicationRpcProvider(tlsConf *tls.Config, compression compression.Compression) ReplicationRpcProvider {
 	return &replicationRpcProvider{
-		pool: common.NewClientPool(tlsConf, nil),
+		pool:                  common.NewClientPool(tlsConf, nil),
+		compression:           compression,
+		uncompressedFollowers: map[string]time.Time{},
 	}
 }
 
@@ -57,8 +80,15 @@ func (r *replicationRpcProvider) GetReplicateStream(ctx context.Context, followe
 	ctx = metadata.AppendToOutgoingContext(ctx, common.MetadataShardId, fmt.Sprintf("%d", shard))
 	ctx = metadata.AppendToOutgoingContext(ctx, common.MetadataTerm, fmt.Sprintf("%d", term))
 
-	stream, err := rpc.Replicate(ctx)
-	return stream, err
+	callOptions := r.callOptions(follower)
+	stream, err := rpc.Replicate(ctx, callOptions...)
+	if err != nil || len(callOptions) == 0 {
+		return stream, err
+	}
+	return &compressedReplicateClient{
+		OxiaLogReplication_ReplicateClient: stream,
+		onError:                            func(err error) { r.checkCompressionError(follower, err) },
+	}, nil
 }
 
 func (r *replicationRpcProvider) SendSnapshot(ctx context.Context, follower string, namespace string, shard int64, term int64) (
@@ -72,8 +102,30 @@ func (r *replicationRpcProvider) SendSnapshot(ctx context.Context, follower stri
 	ctx = metadata.AppendToOutgoingContext(ctx, common.MetadataShardId, fmt.Sprintf("%d", shard))
 	ctx = metadata.AppendToOutgoingContext(ctx, common.MetadataTerm, fmt.Sprintf("%d", term))
 
-	stream, err := rpc.SendSnapshot(ctx)
-	return stream, err
+	callOptions := r.callOptions(follower)
+	stream, err := rpc.SendSnapshot(ctx, callOptions...)
+	if err != nil || len(callOptions) == 0 {
+		return stream, err
+	}
+	return &compressedSendSnapshotClient{
+		OxiaLogReplication_SendSnapshotClient: stream,
+		onError:                               func(err error) { r.checkCompressionError(follower, err) },
+	}, nil
+}
+
+func (r *replicationRpcProvider) SendIngestFile(ctx context.Context, follower string, namespace string, shard int64, term int64) (
+	proto.OxiaLogReplication_SendIngestFileClient, error) {
+	rpc, err := r.pool.GetReplicationRpc(follower)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = metadata.AppendToOutgoingContext(ctx, common.MetadataNamespace, namespace)
+	ctx = metadata.AppendToOutgoingContext(ctx, common.MetadataShardId, fmt.Sprintf("%d", shard))
+	ctx = metadata.AppendToOutgoingContext(ctx, common.MetadataTerm, fmt.Sprintf("%d", term))
+
+	// The sstables are already compressed
+	return rpc.SendIngestFile(ctx)
 }
 
 func (r *replicationRpcProvider) Truncate(follower string, req *proto.TruncateRequest) (*proto.TruncateResponse, error) {
@@ -88,6 +140,90 @@ func (r *replicationRpcProvider) Truncate(follower string, req *proto.TruncateRe
 	return rpc.Truncate(ctx, req)
 }
 
+func (r *replicationRpcProvider) FetchLog(ctx context.Context, follower string, req *proto.FetchLogRequest) (
+	proto.OxiaLogReplication_FetchLogClient, error) {
+	rpc, err := r.pool.GetReplicationRpc(follower)
+	if err != nil {
+		return nil, err
+	}
+
+	return rpc.FetchLog(ctx, req)
+}
+
 func (r *replicationRpcProvider) Close() error {
 	return r.pool.Close()
 }
+
+func (r *replicationRpcProvider) callOptions(follower string) []grpc.CallOption {
+	if !r.compression.Enabled() {
+		return nil
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if rejectedAt, ok := r.uncompressedFollowers[follower]; ok {
+		if time.Since(rejectedAt) < compressionRetryInterval {
+			return nil
+		}
+		delete(r.uncompressedFollowers, follower)
+	}
+	return r.compression.CallOptions()
+}
+
+func (r *replicationRpcProvider) checkCompressionError(follower string, err error) {
+	if !compression.IsNotSupported(err) {
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.uncompressedFollowers[follower]; !ok {
+		slog.Warn(
+			"Follower does not support the replication compression, falling back to uncompressed streams",
+			slog.String("follower", follower),
+			slog.Any("compression", r.compression),
+		)
+	}
+	r.uncompressedFollowers[follower] = time.Now()
+}
+
+// The compressed streams report the errors, to detect the followers that
+// cannot decompress them.
+
+type compressedReplicateClient struct {
+	proto.OxiaLogReplication_ReplicateClient
+	onError func(err error)
+}
+
+func (c *compressedReplicateClient) Recv() (*proto.Ack, error) {
+	ack, err := c.OxiaLogReplication_ReplicateClient.Recv()
+	if err != nil {
+		c.onError(err)
+	}
+	return ack, err
+}
+
+type compressedSendSnapshotClient struct {
+	proto.OxiaLogReplication_SendSnapshotClient
+	onError func(err error)
+}
+
+func (c *compressedSendSnapshotClient) Send(chunk *proto.SnapshotChunk) error {
+	err := c.OxiaLogReplication_SendSnapshotClient.Send(chunk)
+	if errors.Is(err, io.EOF) {
+		// The stream was terminated, the status is only available
+		// when receiving
+		c.onError(c.RecvMsg(&proto.SnapshotResponse{}))
+	}
+	return err
+}
+
+func (c *compressedSendSnapshotClient) CloseAndRecv() (*proto.SnapshotResponse, error) {
+	res, err := c.OxiaLogReplication_SendSnapshotClient.CloseAndRecv()
+	if err != nil {
+		c.onError(err)
+	}
+	return res, err
+}
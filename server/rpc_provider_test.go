@@ -0,0 +1,120 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/streamnative/oxia/common/compression"
+	"github.com/streamnative/oxia/common/container"
+	"github.com/streamnative/oxia/proto"
+)
+
+type echoReplicationServer struct {
+	proto.UnimplementedOxiaLogReplicationServer
+}
+
+func (echoReplicationServer) Replicate(stream proto.OxiaLogReplication_ReplicateServer) error {
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&proto.Ack{Offset: req.Entry.Offset}); err != nil {
+			return err
+		}
+	}
+}
+
+func (echoReplicationServer) SendSnapshot(stream proto.OxiaLogReplication_SendSnapshotServer) error {
+	var size int64
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&proto.SnapshotResponse{AckOffset: size})
+		} else if err != nil {
+			return err
+		}
+		size += int64(len(chunk.Content))
+	}
+}
+
+func TestReplicationRpcProvider_Compression(t *testing.T) {
+	grpcServer, err := container.Default.StartGrpcServer("replication", "localhost:0", func(registrar grpc.ServiceRegistrar) {
+		proto.RegisterOxiaLogReplicationServer(registrar, &echoReplicationServer{})
+	}, nil, nil)
+	assert.NoError(t, err)
+	follower := fmt.Sprintf("localhost:%d", grpcServer.Port())
+
+	value := bytes.Repeat([]byte("value-"), 1000)
+
+	for _, c := range []compression.Compression{compression.None, compression.Zstd, compression.Snappy} {
+		t.Run(string(c), func(t *testing.T) {
+			provider := NewReplicationRpcProvider(nil, c)
+
+			stream, err := provider.GetReplicateStream(context.Background(), follower, "default", 1, 1)
+			assert.NoError(t, err)
+			for i := int64(0); i < 10; i++ {
+				assert.NoError(t, stream.Send(&proto.Append{Term: 1, Entry: &proto.LogEntry{Term: 1, Offset: i, Value: value}}))
+				ack, err := stream.Recv()
+				assert.NoError(t, err)
+				assert.Equal(t, i, ack.Offset)
+			}
+			assert.NoError(t, stream.CloseSend())
+
+			snapshot, err := provider.SendSnapshot(context.Background(), follower, "default", 1, 1)
+			assert.NoError(t, err)
+			assert.NoError(t, snapshot.Send(&proto.SnapshotChunk{Term: 1, Name: "000001.sst", Content: value}))
+			res, err := snapshot.CloseAndRecv()
+			assert.NoError(t, err)
+			assert.EqualValues(t, len(value), res.AckOffset)
+
+			assert.NoError(t, provider.Close())
+		})
+	}
+
+	assert.NoError(t, grpcServer.Close())
+}
+
+func TestReplicationRpcProvider_CompressionNotSupported(t *testing.T) {
+	provider := NewReplicationRpcProvider(nil, compression.Zstd).(*replicationRpcProvider)
+	assert.Len(t, provider.callOptions("f1"), 1)
+
+	// Errors unrelated to the compression are ignored
+	provider.checkCompressionError("f1", status.Error(codes.Unavailable, "connection refused"))
+	assert.Len(t, provider.callOptions("f1"), 1)
+
+	provider.checkCompressionError("f1", status.Error(codes.Unimplemented,
+		`grpc: Decompressor is not installed for grpc-encoding "zstd"`))
+	assert.Empty(t, provider.callOptions("f1"))
+	assert.Len(t, provider.callOptions("f2"), 1)
+
+	// The compression is attempted again after a while
+	provider.uncompressedFollowers["f1"] = time.Now().Add(-compressionRetryInterval)
+	assert.Len(t, provider.callOptions("f1"), 1)
+
+	assert.NoError(t, provider.Close())
+}
@@ -22,11 +22,14 @@ import (
 	"go.uber.org/multierr"
 	"google.golang.org/grpc/health"
 
+	"github.com/streamnative/oxia/common/compression"
 	"github.com/streamnative/oxia/common/container"
 	"github.com/streamnative/oxia/common/metrics"
 	"github.com/streamnative/oxia/server/auth"
+	"github.com/streamnative/oxia/server/encryption"
 	"github.com/streamnative/oxia/server/kv"
 	"github.com/streamnative/oxia/server/wal"
+	"github.com/streamnative/oxia/server/wal/codec"
 )
 
 type Config struct {
@@ -39,13 +42,39 @@ type Config struct {
 
 	AuthOptions auth.Options
 
+	// The rate limits of the clients on the public service
+	RateLimit RateLimitOptions
+
+	// The load of a leader beyond which it rejects the new writes
+	WriteBackpressure BackpressureOptions
+
+	// The fraction of the reads and the writes sampled to find the hot keys
+	// of each shard. The sampling is disabled when it's 0
+	HotKeysSampleRate float64
+
+	// The operations of the leaders that take longer than this are logged.
+	// The log is disabled when it's 0
+	SlowOperationThreshold time.Duration
+
 	DataDir string
 	WalDir  string
 
 	WalRetentionTime           time.Duration
 	WalSyncData                bool
+	WalCompression             codec.Compression
 	NotificationsRetentionTime time.Duration
 
+	// The compression for the replication and snapshot streams sent to the followers
+	ReplicationCompression compression.Compression
+
+	// When set, the write-ahead-log and the database files are encrypted with
+	// the keys from this file
+	EncryptionKeyFile string
+
+	// When set, the write-ahead-log segments are offloaded to this directory
+	// before being trimmed
+	WalOffloadDir string
+
 	DbBlockCacheMB int64
 }
 
@@ -63,8 +92,22 @@ type Server struct {
 	healthServer *health.Server
 }
 
+func (c *Config) keyProvider() (encryption.KeyProvider, error) {
+	if c.EncryptionKeyFile == "" {
+		return nil, nil //nolint:nilnil
+	}
+	return encryption.NewFileKeyProvider(c.EncryptionKeyFile)
+}
+
+func (c *Config) walObjectStore() (wal.ObjectStore, error) {
+	if c.WalOffloadDir == "" {
+		return nil, nil //nolint:nilnil
+	}
+	return wal.NewFileObjectStore(c.WalOffloadDir)
+}
+
 func New(config Config) (*Server, error) {
-	return NewWithGrpcProvider(config, container.Default, NewReplicationRpcProvider(config.PeerTLS))
+	return NewWithGrpcProvider(config, container.Default, NewReplicationRpcProvider(config.PeerTLS, config.ReplicationCompression))
 }
 
 func NewWithGrpcProvider(config Config, provider container.GrpcProvider, replicationRpcProvider ReplicationRpcProvider) (*Server, error) {
@@ -73,9 +116,20 @@ func NewWithGrpcProvider(config Config, provider container.GrpcProvider, replica
 		slog.Any("config", config),
 	)
 
+	keyProvider, err := config.keyProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	walObjectStore, err := config.walObjectStore()
+	if err != nil {
+		return nil, err
+	}
+
 	kvFactory, err := kv.NewPebbleKVFactory(&kv.FactoryOptions{
 		DataDir:     config.DataDir,
 		CacheSizeMB: config.DbBlockCacheMB,
+		KeyProvider: keyProvider,
 	})
 	if err != nil {
 		return nil, err
@@ -88,6 +142,9 @@ func NewWithGrpcProvider(config Config, provider container.GrpcProvider, replica
 			Retention:   config.WalRetentionTime,
 			SegmentSize: wal.DefaultFactoryOptions.SegmentSize,
 			SyncData:    true,
+			Compression: config.WalCompression,
+			KeyProvider: keyProvider,
+			ObjectStore: walObjectStore,
 		}),
 		kvFactory:    kvFactory,
 		healthServer: health.NewServer(),
@@ -103,7 +160,7 @@ func NewWithGrpcProvider(config Config, provider container.GrpcProvider, replica
 	}
 
 	s.publicRpcServer, err = newPublicRpcServer(provider, config.PublicServiceAddr, s.shardsDirector,
-		s.shardAssignmentDispatcher, config.ServerTLS, &config.AuthOptions)
+		s.shardAssignmentDispatcher, config.ServerTLS, &config.AuthOptions, config.RateLimit)
 	if err != nil {
 		return nil, err
 	}
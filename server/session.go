@@ -126,17 +126,20 @@ func (s *session) delete() error {
 	deletes = append(deletes, &proto.DeleteRequest{
 		Key: sessionKey,
 	})
-	_, err = s.sm.leaderController.Write(context.Background(), &proto.WriteRequest{
-		Shard:   &s.shardId,
-		Puts:    nil,
-		Deletes: deletes,
-		// Delete the whole index of ephemeral keys for the session
-		DeleteRanges: []*proto.DeleteRangeRequest{
-			{
-				StartInclusive: sessionKey + "/",
-				EndExclusive:   sessionKey + "//",
+	// The cleanup is not subject to the backpressure of the client writes
+	_, _, err = s.sm.leaderController.write(context.Background(), func(_ int64) *proto.WriteRequest {
+		return &proto.WriteRequest{
+			Shard:   &s.shardId,
+			Puts:    nil,
+			Deletes: deletes,
+			// Delete the whole index of ephemeral keys for the session
+			DeleteRanges: []*proto.DeleteRangeRequest{
+				{
+					StartInclusive: sessionKey + "/",
+					EndExclusive:   sessionKey + "//",
+				},
 			},
-		},
+		}
 	})
 	s.log.Info("Session cleanup complete",
 		slog.Int("keys-deleted", len(deletes)))
@@ -166,6 +169,10 @@ func (s *session) waitForHeartbeats() {
 				return
 			}
 			timer.Reset(s.timeout)
+		case <-s.ctx.Done():
+			// The session was closed before we started waiting
+			timer.Stop()
+			return
 		case <-timeoutCh:
 			s.log.Warn("Session expired")
 
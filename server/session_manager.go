@@ -69,6 +69,7 @@ type SessionManager interface {
 	CreateSession(request *proto.CreateSessionRequest) (*proto.CreateSessionResponse, error)
 	KeepAlive(sessionId int64) error
 	CloseSession(request *proto.CloseSessionRequest) (*proto.CloseSessionResponse, error)
+	SessionIdentity(sessionId int64) (string, error)
 	Initialize() error
 }
 
@@ -192,6 +193,16 @@ func (sm *sessionManager) KeepAlive(sessionId int64) error {
 	return nil
 }
 
+func (sm *sessionManager) SessionIdentity(sessionId int64) (string, error) {
+	sm.RLock()
+	s, err := sm.getSession(sessionId)
+	sm.RUnlock()
+	if err != nil {
+		return "", err
+	}
+	return s.clientIdentity, nil
+}
+
 func (sm *sessionManager) CloseSession(request *proto.CloseSessionRequest) (*proto.CloseSessionResponse, error) {
 	sm.Lock()
 	s, err := sm.getSession(request.SessionId)
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"log/slog"
 	"testing"
 	"time"
 
@@ -639,3 +640,29 @@ func TestSession_PutWithExpiredSession(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, closer.Close())
 }
+
+func TestSession_WaitForHeartbeatsAfterClose(t *testing.T) {
+	s := &session{
+		timeout:     1 * time.Hour,
+		heartbeatCh: make(chan bool, 1),
+		log:         slog.Default(),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	// The session is closed before the heartbeats goroutine gets to run,
+	// so it only finds a nil channel. It must still stop right away
+	// instead of waiting for the session to expire.
+	s.Close()
+
+	done := make(chan struct{})
+	go func() {
+		s.waitForHeartbeats()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		assert.Fail(t, "the session kept waiting for heartbeats after being closed")
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/streamnative/oxia/common/metrics"
+	"github.com/streamnative/oxia/proto"
+)
+
+// The number of slow operations retained by each shard.
+const slowOperationLogSize = 100
+
+const (
+	slowOperationRead          = "read"
+	slowOperationWrite         = "write"
+	slowOperationList          = "list"
+	slowOperationRangeScan     = "range-scan"
+	slowOperationNotifications = "notifications"
+)
+
+// slowOperationLog keeps the last operations of a shard that took longer than
+// the threshold, and logs them. A nil log doesn't trace any operation.
+type slowOperationLog struct {
+	sync.Mutex
+
+	threshold  time.Duration
+	operations []*proto.SlowOperation
+	next       int
+	log        *slog.Logger
+
+	counters map[string]metrics.Counter
+}
+
+func newSlowOperationLog(threshold time.Duration, namespace string, shardId int64) *slowOperationLog {
+	if threshold <= 0 {
+		return nil
+	}
+
+	l := &slowOperationLog{
+		threshold:  threshold,
+		operations: make([]*proto.SlowOperation, 0, slowOperationLogSize),
+		log: slog.With(
+			slog.String("component", "slow-operations"),
+			slog.String("namespace", namespace),
+			slog.Int64("shard", shardId),
+		),
+		counters: map[string]metrics.Counter{},
+	}
+	for _, opType := range []string{slowOperationRead, slowOperationWrite, slowOperationList,
+		slowOperationRangeScan, slowOperationNotifications} {
+		labels := metrics.LabelsForShard(namespace, shardId)
+		labels["type"] = opType
+		l.counters[opType] = metrics.NewCounter("oxia_server_slow_operations",
+			"The number of operations that took longer than the slow operation threshold", "count", labels)
+	}
+	return l
+}
+
+// start begins the trace of an operation.
+func (l *slowOperationLog) start(opType string) *operationTrace {
+	if l == nil {
+		return nil
+	}
+
+	now := time.Now()
+	return &operationTrace{
+		log:       l,
+		start:     now,
+		lastStage: now,
+		op: &proto.SlowOperation{
+			Type:      opType,
+			Timestamp: uint64(now.UnixMilli()),
+		},
+	}
+}
+
+func (l *slowOperationLog) add(op *proto.SlowOperation) {
+	l.counters[op.Type].Inc()
+	l.log.Warn(
+		"Slow operation",
+		slog.String("type", op.Type),
+		slog.Duration("duration", time.Duration(op.DurationMicros)*time.Microsecond),
+		slog.String("key-start", op.KeyStart),
+		slog.String("key-end", op.KeyEnd),
+		slog.Int64("results", op.Results),
+		slog.Int64("bytes", op.Bytes),
+		slog.Duration("wal-append", time.Duration(op.WalAppendMicros)*time.Microsecond),
+		slog.Duration("quorum-wait", time.Duration(op.QuorumWaitMicros)*time.Microsecond),
+		slog.Duration("db-apply", time.Duration(op.DbApplyMicros)*time.Microsecond),
+		slog.String("error", op.Error),
+	)
+
+	l.Lock()
+	defer l.Unlock()
+	if len(l.operations) < slowOperationLogSize {
+		l.operations = append(l.operations, op)
+	} else {
+		l.operations[l.next] = op
+	}
+	l.next = (l.next + 1) % slowOperationLogSize
+}
+
+// last returns the most recent slow operations first.
+func (l *slowOperationLog) last(limit int) []*proto.SlowOperation {
+	l.Lock()
+	defer l.Unlock()
+
+	if limit <= 0 || limit > len(l.operations) {
+		limit = len(l.operations)
+	}
+	res := make([]*proto.SlowOperation, 0, limit)
+	for i := 1; i <= limit; i++ {
+		res = append(res, l.operations[(l.next-i+len(l.operations))%len(l.operations)])
+	}
+	return res
+}
+
+// operationTrace measures an operation and the stages of the writes. A nil
+// trace doesn't measure anything.
+type operationTrace struct {
+	log       *slowOperationLog
+	start     time.Time
+	lastStage time.Time
+	op        *proto.SlowOperation
+}
+
+// Extends the key range of the operation to include the key.
+func (t *operationTrace) addKey(key string) {
+	if t.op.KeyStart == "" || key < t.op.KeyStart {
+		t.op.KeyStart = key
+	}
+	if key > t.op.KeyEnd {
+		t.op.KeyEnd = key
+	}
+}
+
+func (t *operationTrace) readKeys(request *proto.ReadRequest) {
+	if t == nil {
+		return
+	}
+
+	for _, get := range request.Gets {
+		t.addKey(get.Key)
+	}
+}
+
+func (t *operationTrace) writeKeys(request *proto.WriteRequest) {
+	if t == nil {
+		return
+	}
+
+	for _, put := range request.Puts {
+		t.addKey(put.Key)
+		t.op.Results++
+		t.op.Bytes += int64(len(put.Value))
+	}
+	for _, del := range request.Deletes {
+		t.addKey(del.Key)
+		t.op.Results++
+	}
+	for _, dr := range request.DeleteRanges {
+		t.addKey(dr.StartInclusive)
+		t.addKey(dr.EndExclusive)
+		t.op.Results++
+	}
+}
+
+func (t *operationTrace) keyRange(startInclusive string, endExclusive string) {
+	if t == nil {
+		return
+	}
+
+	t.op.KeyStart = startInclusive
+	t.op.KeyEnd = endExclusive
+}
+
+func (t *operationTrace) addResults(count int, bytes int) {
+	if t == nil {
+		return
+	}
+
+	t.op.Results += int64(count)
+	t.op.Bytes += int64(bytes)
+}
+
+// Returns the time since the previous stage.
+func (t *operationTrace) stage() int64 {
+	now := time.Now()
+	elapsed := now.Sub(t.lastStage)
+	t.lastStage = now
+	return elapsed.Microseconds()
+}
+
+func (t *operationTrace) walAppended() {
+	if t == nil {
+		return
+	}
+	t.op.WalAppendMicros = t.stage()
+}
+
+func (t *operationTrace) quorumAcked() {
+	if t == nil {
+		return
+	}
+	t.op.QuorumWaitMicros = t.stage()
+}
+
+func (t *operationTrace) dbApplied() {
+	if t == nil {
+		return
+	}
+	t.op.DbApplyMicros = t.stage()
+}
+
+// finish adds the operation to the log when it took longer than the threshold,
+// with the error of the operation, if it failed.
+func (t *operationTrace) finish(err error) {
+	if t == nil {
+		return
+	}
+
+	duration := time.Since(t.start)
+	if duration < t.log.threshold {
+		return
+	}
+	t.op.DurationMicros = duration.Microseconds()
+	if err != nil {
+		t.op.Error = err.Error()
+	}
+	t.log.add(t.op)
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/proto"
+)
+
+func TestSlowOperationLog_Disabled(t *testing.T) {
+	l := newSlowOperationLog(0, common.DefaultNamespace, 1)
+	assert.Nil(t, l)
+
+	trace := l.start(slowOperationWrite)
+	assert.Nil(t, trace)
+	trace.writeKeys(&proto.WriteRequest{Puts: []*proto.PutRequest{{Key: "a"}}})
+	trace.walAppended()
+	trace.finish(nil)
+}
+
+func TestSlowOperationLog_Threshold(t *testing.T) {
+	l := newSlowOperationLog(time.Hour, common.DefaultNamespace, 1)
+	l.start(slowOperationRead).finish(nil)
+	assert.Empty(t, l.last(0))
+
+	l.threshold = time.Nanosecond
+	trace := l.start(slowOperationWrite)
+	trace.writeKeys(&proto.WriteRequest{
+		Puts:         []*proto.PutRequest{{Key: "/b", Value: []byte("value")}},
+		Deletes:      []*proto.DeleteRequest{{Key: "/c"}},
+		DeleteRanges: []*proto.DeleteRangeRequest{{StartInclusive: "/a/", EndExclusive: "/a//"}},
+	})
+	time.Sleep(time.Millisecond)
+	trace.walAppended()
+	trace.quorumAcked()
+	trace.dbApplied()
+	trace.finish(nil)
+
+	ops := l.last(0)
+	assert.Len(t, ops, 1)
+	op := ops[0]
+	assert.Equal(t, slowOperationWrite, op.Type)
+	assert.Equal(t, "/a/", op.KeyStart)
+	assert.Equal(t, "/c", op.KeyEnd)
+	assert.EqualValues(t, 3, op.Results)
+	assert.EqualValues(t, 5, op.Bytes)
+	assert.GreaterOrEqual(t, op.WalAppendMicros, int64(1000))
+	assert.GreaterOrEqual(t, op.DurationMicros, op.WalAppendMicros+op.QuorumWaitMicros+op.DbApplyMicros)
+}
+
+func TestSlowOperationLog_Last(t *testing.T) {
+	l := newSlowOperationLog(time.Nanosecond, common.DefaultNamespace, 1)
+	for i := 0; i < slowOperationLogSize+10; i++ {
+		trace := l.start(slowOperationRead)
+		trace.readKeys(&proto.ReadRequest{Gets: []*proto.GetRequest{{Key: fmt.Sprintf("%03d", i)}}})
+		trace.finish(nil)
+	}
+
+	ops := l.last(0)
+	assert.Len(t, ops, slowOperationLogSize)
+	assert.Equal(t, fmt.Sprintf("%03d", slowOperationLogSize+9), ops[0].KeyStart)
+	assert.Equal(t, "010", ops[slowOperationLogSize-1].KeyStart)
+
+	ops = l.last(2)
+	assert.Len(t, ops, 2)
+	assert.Equal(t, fmt.Sprintf("%03d", slowOperationLogSize+8), ops[1].KeyEnd)
+}
+
+func TestSlowOperationLog_Error(t *testing.T) {
+	l := newSlowOperationLog(time.Nanosecond, common.DefaultNamespace, 1)
+	l.start(slowOperationRead).finish(nil)
+	l.start(slowOperationWrite).finish(context.DeadlineExceeded)
+
+	ops := l.last(0)
+	if assert.Len(t, ops, 2) {
+		assert.Equal(t, context.DeadlineExceeded.Error(), ops[0].Error)
+		assert.Empty(t, ops[1].Error)
+	}
+}
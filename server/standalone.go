@@ -70,14 +70,26 @@ func NewStandalone(config StandaloneConfig) (*Standalone, error) {
 
 	s := &Standalone{config: config}
 
-	kvOptions := kv.FactoryOptions{DataDir: config.DataDir}
+	keyProvider, err := config.keyProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	walObjectStore, err := config.walObjectStore()
+	if err != nil {
+		return nil, err
+	}
+
+	kvOptions := kv.FactoryOptions{DataDir: config.DataDir, KeyProvider: keyProvider}
 	s.walFactory = wal.NewWalFactory(&wal.FactoryOptions{
 		BaseWalDir:  config.WalDir,
 		Retention:   config.WalRetentionTime,
 		SegmentSize: wal.DefaultFactoryOptions.SegmentSize,
 		SyncData:    config.WalSyncData,
+		Compression: config.WalCompression,
+		KeyProvider: keyProvider,
+		ObjectStore: walObjectStore,
 	})
-	var err error
 	if s.kvFactory, err = kv.NewPebbleKVFactory(&kvOptions); err != nil {
 		return nil, err
 	}
@@ -89,7 +101,7 @@ func NewStandalone(config StandaloneConfig) (*Standalone, error) {
 	}
 
 	s.rpc, err = newPublicRpcServer(container.Default, config.PublicServiceAddr, s.shardsDirector,
-		nil, config.ServerTLS, &auth.Disabled)
+		nil, config.ServerTLS, &auth.Disabled, config.RateLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -175,10 +187,18 @@ func (noOpReplicationRpcProvider) SendSnapshot(context.Context, string, string,
 	panic("not implemented")
 }
 
+func (noOpReplicationRpcProvider) SendIngestFile(context.Context, string, string, int64, int64) (proto.OxiaLogReplication_SendIngestFileClient, error) {
+	panic("not implemented")
+}
+
 func (noOpReplicationRpcProvider) Truncate(string, *proto.TruncateRequest) (*proto.TruncateResponse, error) {
 	panic("not implemented")
 }
 
+func (noOpReplicationRpcProvider) FetchLog(context.Context, string, *proto.FetchLogRequest) (proto.OxiaLogReplication_FetchLogClient, error) {
+	panic("not implemented")
+}
+
 func newNoOpReplicationRpcProvider() ReplicationRpcProvider {
 	return &noOpReplicationRpcProvider{}
 }
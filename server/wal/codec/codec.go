@@ -20,12 +20,16 @@ import (
 	"sync"
 
 	"github.com/pkg/errors"
+
+	"github.com/streamnative/oxia/server/encryption"
 )
 
 var (
 	ErrOffsetOutOfBounds = errors.New("oxia: offset out of bounds")
 	ErrEmptyPayload      = errors.New("oxia: empty payload")
 	ErrDataCorrupted     = errors.New("oxia: data corrupted")
+
+	ErrMissingEncryptionKey = errors.New("oxia: the segment is encrypted, but the encryption is not configured")
 )
 
 type Metadata struct {
@@ -129,35 +133,70 @@ type Codec interface {
 		lastCrc uint32, newFileOffset uint32, lastEntryOffset int64, err error)
 }
 
-// The latest codec.
-var latestCodec = v2
-var SupportedCodecs = []Codec{latestCodec, v1} // the latest codec should be always first element
+var SupportedCodecs = []Codec{v4, v3, v2, v1} // the latest codec should be always first element
+
+// Options are the settings for the records written in the segments.
+type Options struct {
+	// The compression for the records
+	Compression Compression
+
+	// When set, the records are encrypted with the active key of the cipher
+	Cipher *encryption.Cipher
+}
 
 // GetOrCreate checks if a file with the specified extension exists at the basePath to support compatible with
 // the old codec versions.
-func GetOrCreate(basePath string) (_codec Codec, exist bool, err error) {
-	_codec = latestCodec
-	fullPath := basePath + _codec.GetTxnExtension()
-	candidateCodecs := SupportedCodecs[1:] // pop the latest version
-	for {
-		if _, err := os.Stat(fullPath); err != nil {
+//
+// New segments are written with the latest codec when the encryption is enabled, or with V3 when only the
+// compression is enabled. Otherwise, they keep using V2, so that they can still be read after a downgrade.
+// The existing segments keep their codec, while the new records use the compression in the options.
+func GetOrCreate(basePath string, options Options) (_codec Codec, exist bool, err error) {
+	for _, candidate := range SupportedCodecs {
+		if _, err := os.Stat(basePath + candidate.GetTxnExtension()); err != nil {
 			if !os.IsNotExist(err) {
 				// unexpected behaviour
 				return nil, false, nil
 			}
-			if len(candidateCodecs) == 0 {
-				// complete recursive check, go back to the latest txn extension
-				return latestCodec, false, nil
-			}
 			// fallback to previousVersion and check again.
-			_codec = candidateCodecs[0]
-			// pop
-			candidateCodecs = candidateCodecs[1:]
-			fullPath = basePath + _codec.GetTxnExtension()
 			continue
 		}
-		return _codec, true, nil
+
+		switch candidate {
+		case v4:
+			if options.Cipher == nil {
+				return nil, false, errors.Wrapf(ErrMissingEncryptionKey, "segment %s", basePath)
+			}
+			_codec, err = newV4(compressionOrNone(options.Compression), options.Cipher)
+			return _codec, true, err
+		case v3:
+			return v3Codecs[compressionOrNone(options.Compression)], true, nil
+		default:
+			return candidate, true, nil
+		}
+	}
+
+	// complete recursive check, go back to the codec for new segments
+	_codec, err = newSegmentCodec(options)
+	return _codec, false, err
+}
+
+func newSegmentCodec(options Options) (Codec, error) {
+	compression := compressionOrNone(options.Compression)
+	switch {
+	case options.Cipher != nil:
+		return newV4(compression, options.Cipher)
+	case compression != CompressionNone:
+		return v3Codecs[compression], nil
+	default:
+		return v2, nil
+	}
+}
+
+func compressionOrNone(compression Compression) Compression {
+	if _, ok := v3Codecs[compression]; !ok {
+		return CompressionNone
 	}
+	return compression
 }
 
 // ReadInt read unsigned int from buf with big endian.
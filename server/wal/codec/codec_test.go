@@ -27,23 +27,75 @@ func TestCodec_GetOrCreate(t *testing.T) {
 	nonExistFileName := "0"
 	v1FileName := "1"
 	v2FileName := "2"
+	v3FileName := "3"
 	_, err := os.Create(path.Join(baseDir, v1FileName+v1.GetTxnExtension()))
 	assert.NoError(t, err)
 	_, err = os.Create(path.Join(baseDir, v2FileName+v2.GetTxnExtension()))
 	assert.NoError(t, err)
+	_, err = os.Create(path.Join(baseDir, v3FileName+v3.GetTxnExtension()))
+	assert.NoError(t, err)
 
-	codec, exist, err := GetOrCreate(path.Join(baseDir, nonExistFileName))
+	codec, exist, err := GetOrCreate(path.Join(baseDir, nonExistFileName), Options{})
 	assert.NoError(t, err)
 	assert.EqualValues(t, v2, codec)
 	assert.EqualValues(t, false, exist)
 
-	codec, exist, err = GetOrCreate(path.Join(baseDir, v1FileName))
+	codec, exist, err = GetOrCreate(path.Join(baseDir, v1FileName), Options{Compression: CompressionZstd})
 	assert.NoError(t, err)
 	assert.EqualValues(t, v1, codec)
 	assert.EqualValues(t, true, exist)
 
-	codec, exist, err = GetOrCreate(path.Join(baseDir, v2FileName))
+	codec, exist, err = GetOrCreate(path.Join(baseDir, v2FileName), Options{Compression: CompressionZstd})
 	assert.NoError(t, err)
 	assert.EqualValues(t, v2, codec)
 	assert.EqualValues(t, true, exist)
+
+	// New segments are compressed only when the compression is enabled
+	codec, exist, err = GetOrCreate(path.Join(baseDir, nonExistFileName), Options{Compression: CompressionZstd})
+	assert.NoError(t, err)
+	assert.EqualValues(t, v3Codecs[CompressionZstd], codec)
+	assert.EqualValues(t, false, exist)
+
+	codec, exist, err = GetOrCreate(path.Join(baseDir, v3FileName), Options{Compression: CompressionNone})
+	assert.NoError(t, err)
+	assert.EqualValues(t, v3, codec)
+	assert.EqualValues(t, true, exist)
+
+	codec, exist, err = GetOrCreate(path.Join(baseDir, v3FileName), Options{Compression: CompressionSnappy})
+	assert.NoError(t, err)
+	assert.EqualValues(t, v3Codecs[CompressionSnappy], codec)
+	assert.EqualValues(t, true, exist)
+}
+
+func TestCodec_GetOrCreateEncrypted(t *testing.T) {
+	baseDir := t.TempDir()
+	v3FileName := "3"
+	v4FileName := "4"
+	_, err := os.Create(path.Join(baseDir, v3FileName+v3.GetTxnExtension()))
+	assert.NoError(t, err)
+	_, err = os.Create(path.Join(baseDir, v4FileName+v4.GetTxnExtension()))
+	assert.NoError(t, err)
+
+	options := Options{Compression: CompressionZstd, Cipher: newTestCipher(t)}
+
+	// New segments are encrypted, while the existing ones keep their codec
+	codec, exist, err := GetOrCreate(path.Join(baseDir, "0"), options)
+	assert.NoError(t, err)
+	assert.IsType(t, &V4{}, codec)
+	assert.Equal(t, v4TxnExtension, codec.GetTxnExtension())
+	assert.EqualValues(t, false, exist)
+
+	codec, exist, err = GetOrCreate(path.Join(baseDir, v3FileName), options)
+	assert.NoError(t, err)
+	assert.EqualValues(t, v3Codecs[CompressionZstd], codec)
+	assert.EqualValues(t, true, exist)
+
+	codec, exist, err = GetOrCreate(path.Join(baseDir, v4FileName), options)
+	assert.NoError(t, err)
+	assert.IsType(t, &V4{}, codec)
+	assert.EqualValues(t, true, exist)
+
+	// Encrypted segments cannot be opened without the keys
+	_, _, err = GetOrCreate(path.Join(baseDir, v4FileName), Options{})
+	assert.ErrorIs(t, err, ErrMissingEncryptionKey)
 }
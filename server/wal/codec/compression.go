@@ -0,0 +1,102 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+var ErrUnknownCompression = errors.New("oxia: unknown compression")
+
+// Compression is the algorithm used to compress the payload of the records.
+type Compression string
+
+const (
+	CompressionNone   Compression = "none"
+	CompressionZstd   Compression = "zstd"
+	CompressionSnappy Compression = "snappy"
+)
+
+func (c *Compression) String() string {
+	return string(*c)
+}
+
+func (c *Compression) Set(s string) error {
+	switch Compression(s) {
+	case CompressionNone, CompressionZstd, CompressionSnappy:
+		*c = Compression(s)
+		return nil
+	default:
+		return errors.New(`must be one of "none", "zstd" or "snappy"`)
+	}
+}
+
+func (*Compression) Type() string {
+	return "Compression"
+}
+
+// The identifier of the compression, as stored in the record header.
+type compressionType byte
+
+const (
+	compressionTypeNone compressionType = iota
+	compressionTypeZstd
+	compressionTypeSnappy
+)
+
+func (c Compression) compressionType() compressionType {
+	switch c {
+	case CompressionZstd:
+		return compressionTypeZstd
+	case CompressionSnappy:
+		return compressionTypeSnappy
+	default:
+		return compressionTypeNone
+	}
+}
+
+// The encoder and decoder are safe for concurrent use with EncodeAll and DecodeAll.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+)
+
+func compress(t compressionType, payload []byte) []byte {
+	switch t {
+	case compressionTypeZstd:
+		return zstdEncoder.EncodeAll(payload, make([]byte, 0, len(payload)))
+	case compressionTypeSnappy:
+		return s2.EncodeSnappy(nil, payload)
+	default:
+		return payload
+	}
+}
+
+func decompress(t compressionType, data []byte) ([]byte, error) {
+	switch t {
+	case compressionTypeNone:
+		payload := make([]byte, len(data))
+		copy(payload, data)
+		return payload, nil
+	case compressionTypeZstd:
+		return zstdDecoder.DecodeAll(data, nil)
+	case compressionTypeSnappy:
+		return s2.Decode(nil, data)
+	default:
+		return nil, errors.Wrapf(ErrUnknownCompression, "compression type: %d", t)
+	}
+}
@@ -197,6 +197,14 @@ func (v *V2) ReadIndex(path string) ([]byte, error) {
 }
 
 func (v *V2) RecoverIndex(buf []byte, startFileOffset uint32, baseEntryOffset int64,
+	commitOffset *int64) (index []byte, lastCrc uint32,
+	newFileOffset uint32, lastEntryOffset int64, err error) {
+	return recoverIndex(v, buf, startFileOffset, baseEntryOffset, commitOffset)
+}
+
+// Rebuild the index by scanning the records, for the codecs that store the payload
+// size and the CRCs in the header.
+func recoverIndex(c Codec, buf []byte, startFileOffset uint32, baseEntryOffset int64,
 	commitOffset *int64) (index []byte, lastCrc uint32,
 	newFileOffset uint32, lastEntryOffset int64, err error) {
 	maxSize := uint32(len(buf))
@@ -205,11 +213,11 @@ func (v *V2) RecoverIndex(buf []byte, startFileOffset uint32, baseEntryOffset in
 
 	index = BorrowEmptyIndexBuf()
 
-	for newFileOffset+v.HeaderSize <= maxSize {
+	for newFileOffset+c.GetHeaderSize() <= maxSize {
 		var payloadSize uint32
 		var payloadCrc uint32
 		var err error
-		if payloadSize, _, payloadCrc, err = v.ReadHeaderWithValidation(buf, newFileOffset); err != nil {
+		if payloadSize, _, payloadCrc, err = c.ReadHeaderWithValidation(buf, newFileOffset); err != nil {
 			if errors.Is(err, ErrEmptyPayload) {
 				// we might read the end of the segment.
 				break
@@ -228,7 +236,7 @@ func (v *V2) RecoverIndex(buf []byte, startFileOffset uint32, baseEntryOffset in
 		}
 		lastCrc = payloadCrc
 		index = binary.BigEndian.AppendUint32(index, newFileOffset)
-		newFileOffset += v.GetHeaderSize() + payloadSize
+		newFileOffset += c.GetHeaderSize() + payloadSize
 		currentEntryOffset++
 	}
 	return index, lastCrc, newFileOffset, currentEntryOffset - 1, nil
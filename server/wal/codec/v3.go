@@ -0,0 +1,169 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"github.com/streamnative/oxia/server/util/crc"
+)
+
+// Txn File:
+// +--------------+---------------------+-------------+---------------------+--------------+
+// | Size(4Bytes) | PreviousCRC(4Bytes) | CRC(4Bytes) | Compression(1Byte)  | Payload(...) |
+// +--------------+---------------------+-------------+---------------------+--------------+
+// Size: 			Length of the stored payload data, after compression
+// PreviousCRC: 	32bit hash computed over the previous payload using CRC.
+// CRC:				32bit hash computed over the previous CRC, the compression and the stored payload.
+// Compression:		The algorithm used to compress the payload. The payload is stored uncompressed when
+//					the compression doesn't make it smaller.
+// Payload: 		Byte stream as long as specified by the payload size.
+
+// Idx File:
+// Same as V2.
+var _ Codec = &V3{}
+
+const v3CompressionLen uint32 = 1
+
+const v3TxnExtension = ".txn3"
+const v3IdxExtension = ".idx3"
+
+var v3Metadata = Metadata{
+	TxnExtension:  v3TxnExtension,
+	IdxExtension:  v3IdxExtension,
+	HeaderSize:    v2PayloadSizeLen + v2PreviousCrcLen + v2PayloadCrcLen + v3CompressionLen,
+	IdxHeaderSize: v2IndexCrcLen,
+}
+
+var v3 = newV3(CompressionNone)
+
+var v3Codecs = map[Compression]*V3{
+	CompressionNone:   v3,
+	CompressionZstd:   newV3(CompressionZstd),
+	CompressionSnappy: newV3(CompressionSnappy),
+}
+
+// V3 compresses the payload of each record. The compression is recorded in each
+// record header, so it can be read regardless of the compression used to write it.
+type V3 struct {
+	V2
+	compression compressionType
+}
+
+func newV3(compression Compression) *V3 {
+	return &V3{
+		V2:          V2{v3Metadata},
+		compression: compression.compressionType(),
+	}
+}
+
+func (v *V3) GetRecordSize(buf []byte, startFileOffset uint32) (uint32, error) {
+	var payloadSize uint32
+	var err error
+	if payloadSize, _, _, err = v.ReadHeaderWithValidation(buf, startFileOffset); err != nil {
+		return 0, err
+	}
+	return v.HeaderSize + payloadSize, nil
+}
+
+func (v *V3) ReadRecordWithValidation(buf []byte, startFileOffset uint32) (payload []byte, err error) {
+	var payloadSize uint32
+	if payloadSize, _, _, err = v.ReadHeaderWithValidation(buf, startFileOffset); err != nil {
+		return nil, err
+	}
+	compression := compressionType(buf[startFileOffset+v.HeaderSize-v3CompressionLen])
+	payloadStartFileOffset := startFileOffset + v.HeaderSize
+	return decompress(compression, buf[payloadStartFileOffset:payloadStartFileOffset+payloadSize])
+}
+
+func (v *V3) ReadHeaderWithValidation(buf []byte, startFileOffset uint32) (payloadSize uint32, previousCrc uint32, payloadCrc uint32, err error) {
+	bufSize := uint32(len(buf))
+	if startFileOffset >= bufSize {
+		return payloadSize, previousCrc, payloadCrc,
+			errors.Wrapf(ErrOffsetOutOfBounds, "expected payload size: %d. actual buf size: %d ",
+				startFileOffset+v2PayloadSizeLen, bufSize)
+	}
+
+	var headerOffset uint32
+	payloadSize = ReadInt(buf, startFileOffset)
+	headerOffset += v2PayloadSizeLen
+
+	// It shouldn't happen when normal reading
+	if payloadSize == 0 {
+		return payloadSize, previousCrc, payloadCrc, errors.Wrapf(ErrEmptyPayload, "unexpected empty payload")
+	}
+
+	expectSize := payloadSize + v.HeaderSize
+	// overflow checking
+	actualBufSize := bufSize - startFileOffset
+	if expectSize > actualBufSize {
+		return payloadSize, previousCrc, payloadCrc,
+			errors.Wrapf(ErrOffsetOutOfBounds, "expected payload size: %d. actual buf size: %d ", expectSize, bufSize)
+	}
+
+	previousCrc = ReadInt(buf, startFileOffset+headerOffset)
+	headerOffset += v2PreviousCrcLen
+	payloadCrc = ReadInt(buf, startFileOffset+headerOffset)
+	headerOffset += v2PayloadCrcLen
+
+	// The CRC covers the rest of the header too
+	headerSlice := buf[startFileOffset+headerOffset : startFileOffset+v.HeaderSize]
+
+	payloadStartFileOffset := startFileOffset + v.HeaderSize
+	payloadSlice := buf[payloadStartFileOffset : payloadStartFileOffset+payloadSize]
+
+	if expectedCrc := crc.Checksum(previousCrc).Update(headerSlice).Update(payloadSlice).Value(); expectedCrc != payloadCrc {
+		return payloadSize, previousCrc, payloadCrc, errors.Wrapf(ErrDataCorrupted,
+			" expected crc: %d; actual crc: %d", expectedCrc, payloadCrc)
+	}
+
+	return payloadSize, previousCrc, payloadCrc, nil
+}
+
+func (v *V3) WriteRecord(buf []byte, startOffset uint32, previousCrc uint32, payload []byte) (recordSize uint32, payloadCrc uint32) {
+	compression := v.compression
+	data := compress(compression, payload)
+	if len(data) >= len(payload) {
+		// Not worth it, keep the record uncompressed. This also ensures
+		// that the record never exceeds the space reserved for it.
+		compression = compressionTypeNone
+		data = payload
+	}
+	payloadSize := uint32(len(data))
+
+	var headerOffset uint32
+	binary.BigEndian.PutUint32(buf[startOffset:], payloadSize)
+	headerOffset += v2PayloadSizeLen
+
+	binary.BigEndian.PutUint32(buf[startOffset+headerOffset:], previousCrc)
+	headerOffset += v2PreviousCrcLen
+	compressionSlice := []byte{byte(compression)}
+	payloadCrc = crc.Checksum(previousCrc).Update(compressionSlice).Update(data).Value()
+	binary.BigEndian.PutUint32(buf[startOffset+headerOffset:], payloadCrc)
+	headerOffset += v2PayloadCrcLen
+	buf[startOffset+headerOffset] = compressionSlice[0]
+	headerOffset += v3CompressionLen
+
+	copy(buf[startOffset+headerOffset:], data)
+	return headerOffset + payloadSize, payloadCrc
+}
+
+func (v *V3) RecoverIndex(buf []byte, startFileOffset uint32, baseEntryOffset int64,
+	commitOffset *int64) (index []byte, lastCrc uint32,
+	newFileOffset uint32, lastEntryOffset int64, err error) {
+	return recoverIndex(v, buf, startFileOffset, baseEntryOffset, commitOffset)
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV3_GetHeaderSize(t *testing.T) {
+	assert.EqualValues(t, v3.GetHeaderSize(), 13)
+}
+
+func TestV3_Codec(t *testing.T) {
+	compressible := bytes.Repeat([]byte(`{"key":"value"}`), 100)
+
+	for _, compression := range []Compression{CompressionNone, CompressionZstd, CompressionSnappy} {
+		t.Run(string(compression), func(t *testing.T) {
+			c := v3Codecs[compression]
+			buf := make([]byte, 4096)
+
+			recordSize, _ := c.WriteRecord(buf, 0, 0, compressible)
+			if compression == CompressionNone {
+				assert.EqualValues(t, v3.GetHeaderSize()+uint32(len(compressible)), recordSize)
+			} else {
+				assert.Less(t, recordSize, uint32(len(compressible)))
+			}
+
+			getRecordSize, err := c.GetRecordSize(buf, 0)
+			assert.NoError(t, err)
+			assert.EqualValues(t, recordSize, getRecordSize)
+
+			// The record can be read by any V3 codec
+			for _, reader := range v3Codecs {
+				payload, err := reader.ReadRecordWithValidation(buf, 0)
+				assert.NoError(t, err)
+				assert.Equal(t, compressible, payload)
+			}
+
+			// Payloads that don't compress are stored as they are
+			incompressible := []byte{1}
+			recordSize, _ = c.WriteRecord(buf, getRecordSize, 0, incompressible)
+			assert.EqualValues(t, v3.GetHeaderSize()+1, recordSize)
+			payload, err := c.ReadRecordWithValidation(buf, getRecordSize)
+			assert.NoError(t, err)
+			assert.Equal(t, incompressible, payload)
+		})
+	}
+}
+
+func TestV3_DataCorrupted(t *testing.T) {
+	c := v3Codecs[CompressionZstd]
+	buf := make([]byte, 4096)
+	recordSize, _ := c.WriteRecord(buf, 0, 0, bytes.Repeat([]byte("a"), 100))
+
+	// Flip the compression in the header
+	buf[v3.GetHeaderSize()-1] = byte(compressionTypeSnappy)
+	_, err := c.ReadRecordWithValidation(buf, 0)
+	assert.ErrorIs(t, err, ErrDataCorrupted)
+
+	buf[v3.GetHeaderSize()-1] = byte(compressionTypeZstd)
+	buf[recordSize-1]++
+	_, err = c.ReadRecordWithValidation(buf, 0)
+	assert.ErrorIs(t, err, ErrDataCorrupted)
+}
+
+func TestV3_RecoverIndex(t *testing.T) {
+	c := v3Codecs[CompressionSnappy]
+	buf := make([]byte, 16*1024)
+
+	var fOffset uint32
+	var previousCrc uint32
+	for i := 0; i < 10; i++ {
+		payload := []byte(fmt.Sprintf("%s-%d", bytes.Repeat([]byte("value"), i*10), i))
+		var recordSize uint32
+		recordSize, previousCrc = c.WriteRecord(buf, fOffset, previousCrc, payload)
+		fOffset += recordSize
+	}
+
+	index, lastCrc, newFileOffset, lastEntryOffset, err := c.RecoverIndex(buf, 0, 0, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, previousCrc, lastCrc)
+	assert.EqualValues(t, fOffset, newFileOffset)
+	assert.EqualValues(t, 9, lastEntryOffset)
+
+	for i := 0; i < 10; i++ {
+		payload, err := c.ReadRecordWithValidation(buf, ReadInt(index, uint32(i*4)))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(fmt.Sprintf("%s-%d", bytes.Repeat([]byte("value"), i*10), i)), payload)
+	}
+	ReturnIndexBuf(&index)
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"github.com/streamnative/oxia/server/encryption"
+	"github.com/streamnative/oxia/server/util/crc"
+)
+
+// Txn File:
+// +--------------+---------------------+-------------+--------------------+---------------+----------------+--------------+--------------+
+// | Size(4Bytes) | PreviousCRC(4Bytes) | CRC(4Bytes) | Compression(1Byte) | KeyId(4Bytes) | Nonce(12Bytes) | Tag(16Bytes) | Payload(...) |
+// +--------------+---------------------+-------------+--------------------+---------------+----------------+--------------+--------------+
+// Size: 			Length of the stored payload data, after compression and encryption
+// PreviousCRC: 	32bit hash computed over the previous payload using CRC.
+// CRC:				32bit hash computed over the previous CRC, the rest of the header and the stored payload.
+// Compression:		The algorithm used to compress the payload, before encrypting it.
+// KeyId:			The id of the key used to encrypt the payload.
+// Nonce:			The random nonce used to encrypt the payload.
+// Tag:				The AES-GCM authentication tag, which also covers the compression and the key id.
+// Payload: 		Byte stream as long as specified by the payload size.
+
+// Idx File:
+// Same as V2.
+var _ Codec = &V4{}
+
+const v4KeyIdLen uint32 = 4
+const v4NonceLen uint32 = 12
+const v4TagLen uint32 = 16
+
+const v4TxnExtension = ".txn4"
+const v4IdxExtension = ".idx4"
+
+var v4Metadata = Metadata{
+	TxnExtension:  v4TxnExtension,
+	IdxExtension:  v4IdxExtension,
+	HeaderSize:    v3Metadata.HeaderSize + v4KeyIdLen + v4NonceLen + v4TagLen,
+	IdxHeaderSize: v2IndexCrcLen,
+}
+
+// The v4 codec used to recognize the segments, which cannot read or write
+// records without a cipher.
+var v4 = &V4{V3: V3{V2: V2{v4Metadata}}}
+
+// V4 encrypts the payload of each record with AES-GCM, after compressing it.
+// Each record keeps the id of its key, so that the segments can still be
+// read after the active key is rotated.
+type V4 struct {
+	V3
+	cipher *encryption.Cipher
+
+	activeKeyId encryption.KeyId
+	activeAEAD  cipher.AEAD
+}
+
+func newV4(compression Compression, c *encryption.Cipher) (*V4, error) {
+	keyId, aead, err := c.ActiveAEAD()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the active encryption key")
+	}
+
+	return &V4{
+		V3: V3{
+			V2:          V2{v4Metadata},
+			compression: compression.compressionType(),
+		},
+		cipher:      c,
+		activeKeyId: keyId,
+		activeAEAD:  aead,
+	}, nil
+}
+
+func (v *V4) ReadRecordWithValidation(buf []byte, startFileOffset uint32) (payload []byte, err error) {
+	var payloadSize uint32
+	if payloadSize, _, _, err = v.ReadHeaderWithValidation(buf, startFileOffset); err != nil {
+		return nil, err
+	}
+
+	additionalDataOffset := startFileOffset + v3Metadata.HeaderSize - v3CompressionLen
+	additionalData := buf[additionalDataOffset : additionalDataOffset+v3CompressionLen+v4KeyIdLen]
+	compression := compressionType(additionalData[0])
+	keyId := encryption.KeyId(ReadInt(additionalData, v3CompressionLen))
+
+	nonceOffset := additionalDataOffset + v3CompressionLen + v4KeyIdLen
+	nonce := buf[nonceOffset : nonceOffset+v4NonceLen]
+	tag := buf[nonceOffset+v4NonceLen : nonceOffset+v4NonceLen+v4TagLen]
+
+	aead, err := v.cipher.AEAD(keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadStartFileOffset := startFileOffset + v.HeaderSize
+	sealed := make([]byte, 0, payloadSize+v4TagLen)
+	sealed = append(sealed, buf[payloadStartFileOffset:payloadStartFileOffset+payloadSize]...)
+	sealed = append(sealed, tag...)
+
+	data, err := aead.Open(sealed[:0], nonce, sealed, additionalData)
+	if err != nil {
+		return nil, errors.Wrapf(ErrDataCorrupted, "failed to decrypt record: %v", err)
+	}
+	return decompress(compression, data)
+}
+
+func (v *V4) WriteRecord(buf []byte, startOffset uint32, previousCrc uint32, payload []byte) (recordSize uint32, payloadCrc uint32) {
+	compression := v.compression
+	data := compress(compression, payload)
+	if len(data) >= len(payload) {
+		compression = compressionTypeNone
+		data = payload
+	}
+	payloadSize := uint32(len(data))
+
+	binary.BigEndian.PutUint32(buf[startOffset:], payloadSize)
+	binary.BigEndian.PutUint32(buf[startOffset+v2PayloadSizeLen:], previousCrc)
+
+	headerOffset := startOffset + v2PayloadSizeLen + v2PreviousCrcLen + v2PayloadCrcLen
+	additionalData := buf[headerOffset : headerOffset+v3CompressionLen+v4KeyIdLen]
+	additionalData[0] = byte(compression)
+	binary.BigEndian.PutUint32(additionalData[v3CompressionLen:], uint32(v.activeKeyId))
+	headerOffset += v3CompressionLen + v4KeyIdLen
+
+	nonce := buf[headerOffset : headerOffset+v4NonceLen]
+	if _, err := rand.Read(nonce); err != nil {
+		panic(errors.Wrap(err, "failed to generate the encryption nonce"))
+	}
+	headerOffset += v4NonceLen
+
+	// The ciphertext has the same size as the plaintext, followed by the tag
+	sealed := v.activeAEAD.Seal(nil, nonce, data, additionalData)
+	copy(buf[headerOffset:], sealed[payloadSize:])
+	headerOffset += v4TagLen
+	copy(buf[headerOffset:], sealed[:payloadSize])
+
+	payloadCrc = crc.Checksum(previousCrc).
+		Update(buf[startOffset+v2PayloadSizeLen+v2PreviousCrcLen+v2PayloadCrcLen : headerOffset]).
+		Update(buf[headerOffset : headerOffset+payloadSize]).
+		Value()
+	binary.BigEndian.PutUint32(buf[startOffset+v2PayloadSizeLen+v2PreviousCrcLen:], payloadCrc)
+	return headerOffset - startOffset + payloadSize, payloadCrc
+}
+
+func (v *V4) RecoverIndex(buf []byte, startFileOffset uint32, baseEntryOffset int64,
+	commitOffset *int64) (index []byte, lastCrc uint32,
+	newFileOffset uint32, lastEntryOffset int64, err error) {
+	return recoverIndex(v, buf, startFileOffset, baseEntryOffset, commitOffset)
+}
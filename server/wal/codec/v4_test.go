@@ -0,0 +1,156 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/streamnative/oxia/server/encryption"
+	"github.com/streamnative/oxia/server/util/crc"
+)
+
+var (
+	testKey1 = bytes.Repeat([]byte{1}, encryption.KeySize)
+	testKey2 = bytes.Repeat([]byte{2}, encryption.KeySize)
+)
+
+func newTestCipher(t *testing.T, keys ...[]byte) *encryption.Cipher {
+	t.Helper()
+
+	if len(keys) == 0 {
+		keys = [][]byte{testKey1}
+	}
+	provider, err := encryption.NewStaticKeyProvider(keys...)
+	assert.NoError(t, err)
+	return encryption.NewCipher(provider)
+}
+
+func TestV4_GetHeaderSize(t *testing.T) {
+	assert.EqualValues(t, v4.GetHeaderSize(), 45)
+}
+
+func TestV4_Codec(t *testing.T) {
+	compressible := bytes.Repeat([]byte(`{"key":"value"}`), 100)
+
+	for _, compression := range []Compression{CompressionNone, CompressionZstd, CompressionSnappy} {
+		t.Run(string(compression), func(t *testing.T) {
+			c, err := newV4(compression, newTestCipher(t))
+			assert.NoError(t, err)
+			buf := make([]byte, 4096)
+
+			recordSize, _ := c.WriteRecord(buf, 0, 0, compressible)
+			if compression == CompressionNone {
+				assert.EqualValues(t, v4.GetHeaderSize()+uint32(len(compressible)), recordSize)
+			} else {
+				assert.Less(t, recordSize, uint32(len(compressible)))
+			}
+			assert.False(t, bytes.Contains(buf, []byte(`{"key":"value"}`)))
+
+			getRecordSize, err := c.GetRecordSize(buf, 0)
+			assert.NoError(t, err)
+			assert.EqualValues(t, recordSize, getRecordSize)
+
+			payload, err := c.ReadRecordWithValidation(buf, 0)
+			assert.NoError(t, err)
+			assert.Equal(t, compressible, payload)
+
+			// The encryption doesn't change the size of the payload
+			incompressible := []byte{1}
+			recordSize, _ = c.WriteRecord(buf, getRecordSize, 0, incompressible)
+			assert.EqualValues(t, v4.GetHeaderSize()+1, recordSize)
+			payload, err = c.ReadRecordWithValidation(buf, getRecordSize)
+			assert.NoError(t, err)
+			assert.Equal(t, incompressible, payload)
+		})
+	}
+}
+
+func TestV4_KeyRotation(t *testing.T) {
+	c1, err := newV4(CompressionNone, newTestCipher(t, testKey1))
+	assert.NoError(t, err)
+	buf := make([]byte, 4096)
+	recordSize, previousCrc := c1.WriteRecord(buf, 0, 0, []byte("value-1"))
+
+	// The new key is the active one, while the previous one is kept to read
+	// the existing records
+	c2, err := newV4(CompressionNone, newTestCipher(t, testKey2, testKey1))
+	assert.NoError(t, err)
+	c2.WriteRecord(buf, recordSize, previousCrc, []byte("value-2"))
+
+	payload, err := c2.ReadRecordWithValidation(buf, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value-1"), payload)
+	payload, err = c2.ReadRecordWithValidation(buf, recordSize)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value-2"), payload)
+
+	// Without the key, the record cannot be read
+	_, err = c1.ReadRecordWithValidation(buf, recordSize)
+	assert.ErrorIs(t, err, encryption.ErrKeyNotFound)
+}
+
+func TestV4_DataCorrupted(t *testing.T) {
+	c, err := newV4(CompressionZstd, newTestCipher(t))
+	assert.NoError(t, err)
+	buf := make([]byte, 4096)
+	recordSize, _ := c.WriteRecord(buf, 0, 0, bytes.Repeat([]byte("a"), 100))
+
+	buf[recordSize-1]++
+	_, err = c.ReadRecordWithValidation(buf, 0)
+	assert.ErrorIs(t, err, ErrDataCorrupted)
+	buf[recordSize-1]--
+
+	// Tampering with both the payload and the CRC is detected by the
+	// authentication tag
+	buf[recordSize-1]++
+	header := v2PayloadSizeLen + v2PreviousCrcLen + v2PayloadCrcLen
+	payloadCrc := crc.Checksum(0).Update(buf[header:recordSize]).Value()
+	binary.BigEndian.PutUint32(buf[v2PayloadSizeLen+v2PreviousCrcLen:], payloadCrc)
+	_, err = c.ReadRecordWithValidation(buf, 0)
+	assert.ErrorIs(t, err, ErrDataCorrupted)
+}
+
+func TestV4_RecoverIndex(t *testing.T) {
+	c, err := newV4(CompressionSnappy, newTestCipher(t))
+	assert.NoError(t, err)
+	buf := make([]byte, 16*1024)
+
+	var fOffset uint32
+	var previousCrc uint32
+	for i := 0; i < 10; i++ {
+		payload := []byte(fmt.Sprintf("%s-%d", bytes.Repeat([]byte("value"), i*10), i))
+		var recordSize uint32
+		recordSize, previousCrc = c.WriteRecord(buf, fOffset, previousCrc, payload)
+		fOffset += recordSize
+	}
+
+	index, lastCrc, newFileOffset, lastEntryOffset, err := c.RecoverIndex(buf, 0, 0, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, previousCrc, lastCrc)
+	assert.EqualValues(t, fOffset, newFileOffset)
+	assert.EqualValues(t, 9, lastEntryOffset)
+
+	for i := 0; i < 10; i++ {
+		payload, err := c.ReadRecordWithValidation(buf, ReadInt(index, uint32(i*4)))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(fmt.Sprintf("%s-%d", bytes.Repeat([]byte("value"), i*10), i)), payload)
+	}
+	ReturnIndexBuf(&index)
+}
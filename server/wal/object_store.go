@@ -0,0 +1,147 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+var (
+	ErrObjectNotFound   = errors.New("oxia: object not found")
+	ErrInvalidObjectKey = errors.New("oxia: invalid object key")
+)
+
+// ObjectStore keeps the sealed wal segments after they are trimmed from the
+// local disk. The keys are slash separated paths.
+type ObjectStore interface {
+	io.Closer
+
+	// Put stores the content of the reader with the given key, replacing the
+	// existing object, if any.
+	Put(key string, r io.Reader) error
+
+	// Get opens the object with the given key, or fails with [ErrObjectNotFound].
+	Get(key string) (io.ReadCloser, error)
+
+	// List returns the keys of all the objects starting with the given prefix.
+	List(prefix string) ([]string, error)
+}
+
+type fileObjectStore struct {
+	dir string
+}
+
+// NewFileObjectStore creates an ObjectStore that keeps the objects as files in
+// a local directory, which can be a mount of a network file system.
+func NewFileObjectStore(dir string) (ObjectStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create the object store directory %s", dir)
+	}
+	return &fileObjectStore{dir: dir}, nil
+}
+
+func (s *fileObjectStore) path(key string) (string, error) {
+	p := filepath.FromSlash(key)
+	if !filepath.IsLocal(p) {
+		return "", errors.Wrapf(ErrInvalidObjectKey, "key: %s", key)
+	}
+	return filepath.Join(s.dir, p), nil
+}
+
+func (s *fileObjectStore) Put(key string, r io.Reader) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	// The object is written to a temporary file first, so that a failed upload
+	// never leaves a partial object behind
+	f, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		return multierr.Combine(err, f.Close(), os.Remove(f.Name()))
+	}
+	if err := multierr.Combine(f.Sync(), f.Close()); err != nil {
+		return multierr.Append(err, os.Remove(f.Name()))
+	}
+	if err := os.Rename(f.Name(), p); err != nil {
+		return multierr.Append(err, os.Remove(f.Name()))
+	}
+	return nil
+}
+
+func (s *fileObjectStore) Get(key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errors.Wrapf(ErrObjectNotFound, "key: %s", key)
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *fileObjectStore) List(prefix string) ([]string, error) {
+	// Only the directory containing the prefix needs to be walked
+	root, err := s.path(path.Dir(prefix + "_"))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	return keys, err
+}
+
+func (*fileObjectStore) Close() error {
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileObjectStore(t *testing.T) {
+	store, err := NewFileObjectStore(t.TempDir())
+	assert.NoError(t, err)
+
+	keys, err := store.List("ns/")
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	assert.NoError(t, store.Put("ns/shard-1/0.txn", bytes.NewReader([]byte("old"))))
+	assert.NoError(t, store.Put("ns/shard-1/0.txn", bytes.NewReader([]byte("txn"))))
+	assert.NoError(t, store.Put("ns/shard-1/0.idx", bytes.NewReader([]byte("idx"))))
+	assert.NoError(t, store.Put("ns/shard-10/0.txn", bytes.NewReader([]byte("other"))))
+
+	r, err := store.Get("ns/shard-1/0.txn")
+	assert.NoError(t, err)
+	content, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "txn", string(content))
+	assert.NoError(t, r.Close())
+
+	keys, err = store.List("ns/shard-1/")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ns/shard-1/0.txn", "ns/shard-1/0.idx"}, keys)
+
+	keys, err = store.List("ns/shard-1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ns/shard-1/0.txn", "ns/shard-1/0.idx", "ns/shard-10/0.txn"}, keys)
+
+	_, err = store.Get("ns/shard-2/0.txn")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	assert.ErrorIs(t, store.Put("../outside", bytes.NewReader(nil)), ErrInvalidObjectKey)
+	_, err = store.Get("/etc/passwd")
+	assert.ErrorIs(t, err, ErrInvalidObjectKey)
+
+	assert.NoError(t, store.Close())
+}
@@ -0,0 +1,268 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/server/wal/codec"
+)
+
+// The directory, inside the wal directory, where the offloaded segments are
+// downloaded to be read.
+const offloadedSegmentsCacheDir = "offloaded"
+
+// segmentOffloader uploads the sealed segments before they are trimmed.
+type segmentOffloader interface {
+	Offload(c *segmentConfig) error
+}
+
+// offloadedSegmentsGroup keeps track of the segments of a shard that were
+// uploaded to the object store. The segments are uploaded as they are on the
+// disk, so they keep their codec, compression and encryption.
+type offloadedSegmentsGroup struct {
+	sync.Mutex
+
+	store        ObjectStore
+	prefix       string
+	cachePath    string
+	codecOptions codec.Options
+
+	// The codec of each offloaded segment, by base offset
+	segments     *treeMap[int64, codec.Codec]
+	openSegments *treeMap[int64, common.RefCount[ReadOnlySegment]]
+}
+
+func offloadedSegmentsPrefix(namespace string, shard int64) string {
+	return path.Join(namespace, fmt.Sprint("shard-", shard)) + "/"
+}
+
+func newOffloadedSegmentsGroup(store ObjectStore, namespace string, shard int64, walPath string,
+	codecOptions codec.Options) (*offloadedSegmentsGroup, error) {
+	g := &offloadedSegmentsGroup{
+		store:        store,
+		prefix:       offloadedSegmentsPrefix(namespace, shard),
+		cachePath:    filepath.Join(walPath, offloadedSegmentsCacheDir),
+		codecOptions: codecOptions,
+		segments:     newInt64TreeMap[codec.Codec](),
+		openSegments: newInt64TreeMap[common.RefCount[ReadOnlySegment]](),
+	}
+
+	// Discard the segments downloaded before a restart
+	if err := os.RemoveAll(g.cachePath); err != nil {
+		return nil, err
+	}
+
+	keys, err := store.List(g.prefix)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the offloaded segments in %s", g.prefix)
+	}
+
+	// The txn file is uploaded after the idx file, so a segment is complete
+	// once its txn file is there
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, g.prefix)
+		for _, _codec := range codec.SupportedCodecs {
+			var baseOffset int64
+			if n, _ := fmt.Sscanf(name, "%d"+_codec.GetTxnExtension(), &baseOffset); n == 1 &&
+				name == fmt.Sprint(baseOffset)+_codec.GetTxnExtension() {
+				g.segments.Put(baseOffset, _codec)
+				break
+			}
+		}
+	}
+	return g, nil
+}
+
+// FirstOffset returns the base offset of the first offloaded segment, or
+// InvalidOffset if there are none.
+func (g *offloadedSegmentsGroup) FirstOffset() int64 {
+	g.Lock()
+	defer g.Unlock()
+
+	if g.segments.Empty() {
+		return InvalidOffset
+	}
+	firstOffset, _ := g.segments.Min()
+	return firstOffset
+}
+
+func (g *offloadedSegmentsGroup) Offload(c *segmentConfig) error {
+	g.Lock()
+	_, offloaded := g.segments.Get(c.baseOffset)
+	g.Unlock()
+
+	// The segment was already uploaded by a trim attempt that failed later on
+	if offloaded {
+		return nil
+	}
+
+	if err := g.upload(c.idxPath); err != nil {
+		return err
+	}
+	if err := g.upload(c.txnPath); err != nil {
+		return err
+	}
+
+	g.Lock()
+	defer g.Unlock()
+	g.segments.Put(c.baseOffset, c.codec)
+	return nil
+}
+
+func (g *offloadedSegmentsGroup) upload(filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+
+	key := g.prefix + filepath.Base(filePath)
+	if err := g.store.Put(key, f); err != nil {
+		return multierr.Combine(errors.Wrapf(err, "failed to upload %s", key), f.Close())
+	}
+	return f.Close()
+}
+
+// Downloads the files of a segment in a new directory, since the files of a
+// previous download might still be mapped by a reader.
+func (g *offloadedSegmentsGroup) download(baseOffset int64, _codec codec.Codec) (dir string, err error) {
+	if err = os.MkdirAll(g.cachePath, 0755); err != nil {
+		return "", err
+	}
+	if dir, err = os.MkdirTemp(g.cachePath, fmt.Sprint(baseOffset, "-")); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprint(baseOffset)
+	for _, extension := range []string{_codec.GetIdxExtension(), _codec.GetTxnExtension()} {
+		if err = g.downloadFile(dir, name+extension); err != nil {
+			return "", multierr.Append(err, os.RemoveAll(dir))
+		}
+	}
+	return dir, nil
+}
+
+func (g *offloadedSegmentsGroup) downloadFile(dir string, name string) error {
+	key := g.prefix + name
+	r, err := g.store.Get(key)
+	if err != nil {
+		return errors.Wrapf(err, "failed to download %s", key)
+	}
+
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return multierr.Combine(err, r.Close())
+	}
+
+	_, err = io.Copy(f, r)
+	return multierr.Combine(err, f.Close(), r.Close())
+}
+
+// Get returns the offloaded segment containing the offset, downloading it
+// if it's not in the local cache.
+func (g *offloadedSegmentsGroup) Get(offset int64) (common.RefCount[ReadOnlySegment], error) {
+	g.Lock()
+	defer g.Unlock()
+
+	_, segment := g.openSegments.Floor(offset)
+	if segment != nil && offset <= segment.Get().LastOffset() {
+		return segment.Acquire(), nil
+	}
+
+	baseOffset, _codec := g.segments.Floor(offset)
+	if _codec == nil {
+		return nil, codec.ErrOffsetOutOfBounds
+	}
+
+	dir, err := g.download(baseOffset, _codec)
+	if err != nil {
+		return nil, err
+	}
+
+	roSegment, err := newReadOnlySegment(dir, baseOffset, g.codecOptions)
+	if err != nil {
+		return nil, multierr.Append(err, os.RemoveAll(dir))
+	}
+
+	rc := common.NewRefCount[ReadOnlySegment](&offloadedSegment{ReadOnlySegment: roSegment, dir: dir})
+	res := rc.Acquire()
+
+	g.openSegments.Put(baseOffset, rc)
+	if err := g.cleanSegmentsCache(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (g *offloadedSegmentsGroup) cleanSegmentsCache() error {
+	var err error
+	g.openSegments.Each(func(k int64, v common.RefCount[ReadOnlySegment]) bool {
+		if time.Since(v.Get().OpenTimestamp()) > maxReadOnlySegmentsInCacheTime {
+			err = multierr.Append(err, v.Close())
+			g.openSegments.Remove(k)
+		}
+		return true
+	})
+
+	g.openSegments.Each(func(k int64, v common.RefCount[ReadOnlySegment]) bool {
+		if g.openSegments.Size() > maxReadOnlySegmentsInCacheCount {
+			err = multierr.Append(err, v.Close())
+			g.openSegments.Remove(k)
+			return true
+		}
+		return false
+	})
+	return err
+}
+
+// Close releases the downloaded segments, while the offloaded ones are
+// still tracked.
+func (g *offloadedSegmentsGroup) Close() error {
+	g.Lock()
+	defer g.Unlock()
+
+	var err error
+	g.openSegments.Each(func(_ int64, segment common.RefCount[ReadOnlySegment]) bool {
+		err = multierr.Append(err, segment.Close())
+		return true
+	})
+	g.openSegments.Clear()
+	return err
+}
+
+// offloadedSegment removes the downloaded files when the segment is no
+// longer in use.
+type offloadedSegment struct {
+	ReadOnlySegment
+	dir string
+}
+
+func (s *offloadedSegment) Close() error {
+	return multierr.Combine(
+		s.ReadOnlySegment.Close(),
+		os.RemoveAll(s.dir),
+	)
+}
@@ -67,8 +67,8 @@ type readOnlySegment struct {
 	openTimestamp time.Time
 }
 
-func newReadOnlySegment(basePath string, baseOffset int64) (ReadOnlySegment, error) {
-	c, err := newSegmentConfig(basePath, baseOffset)
+func newReadOnlySegment(basePath string, baseOffset int64, options codec.Options) (ReadOnlySegment, error) {
+	c, err := newSegmentConfig(basePath, baseOffset, options)
 	if err != nil {
 		return nil, err
 	}
@@ -28,14 +28,14 @@ import (
 func TestReadOnlySegment(t *testing.T) {
 	path := t.TempDir()
 
-	rw, err := newReadWriteSegment(path, 0, 128*1024, 0, nil)
+	rw, err := newReadWriteSegment(path, 0, 128*1024, 0, nil, codec.Options{})
 	assert.NoError(t, err)
 	for i := int64(0); i < 10; i++ {
 		assert.NoError(t, rw.Append(i, []byte(fmt.Sprintf("entry-%d", i))))
 	}
 	assert.NoError(t, rw.Close())
 
-	ro, err := newReadOnlySegment(path, 0)
+	ro, err := newReadOnlySegment(path, 0, codec.Options{})
 	assert.NoError(t, err)
 	assert.EqualValues(t, 0, ro.BaseOffset())
 	assert.EqualValues(t, 9, ro.LastOffset())
@@ -60,7 +60,7 @@ func TestReadOnlySegment(t *testing.T) {
 func TestRO_auto_recover_broken_index(t *testing.T) {
 	path := t.TempDir()
 
-	rw, err := newReadWriteSegment(path, 0, 128*1024, 0, nil)
+	rw, err := newReadWriteSegment(path, 0, 128*1024, 0, nil, codec.Options{})
 	assert.NoError(t, err)
 	for i := int64(0); i < 10; i++ {
 		assert.NoError(t, rw.Append(i, []byte(fmt.Sprintf("entry-%d", i))))
@@ -78,7 +78,7 @@ func TestRO_auto_recover_broken_index(t *testing.T) {
 	_, err = file.WriteAt(faultData, 0)
 	assert.NoError(t, err)
 
-	ro, err := newReadOnlySegment(path, 0)
+	ro, err := newReadOnlySegment(path, 0, codec.Options{})
 	assert.NoError(t, err)
 	assert.EqualValues(t, 0, ro.BaseOffset())
 	assert.EqualValues(t, 9, ro.LastOffset())
@@ -19,6 +19,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/pkg/errors"
 	"go.uber.org/multierr"
 
 	"github.com/streamnative/oxia/common"
@@ -44,13 +45,20 @@ type readOnlySegmentsGroup struct {
 	sync.Mutex
 
 	basePath     string
+	codecOptions codec.Options
+	offloader    segmentOffloader
 	allSegments  *treeMap[int64, bool]
 	openSegments *treeMap[int64, common.RefCount[ReadOnlySegment]]
 }
 
-func newReadOnlySegmentsGroup(basePath string) (ReadOnlySegmentsGroup, error) {
+// The offloader is optional. When set, the segments are offloaded before
+// being trimmed.
+func newReadOnlySegmentsGroup(basePath string, codecOptions codec.Options,
+	offloader segmentOffloader) (ReadOnlySegmentsGroup, error) {
 	g := &readOnlySegmentsGroup{
 		basePath:     basePath,
+		codecOptions: codecOptions,
+		offloader:    offloader,
 		allSegments:  newInt64TreeMap[bool](),
 		openSegments: newInt64TreeMap[common.RefCount[ReadOnlySegment]](),
 	}
@@ -119,7 +127,7 @@ func (r *readOnlySegmentsGroup) Get(offset int64) (common.RefCount[ReadOnlySegme
 		return nil, codec.ErrOffsetOutOfBounds
 	}
 
-	rosegment, err := newReadOnlySegment(r.basePath, baseOffset)
+	rosegment, err := newReadOnlySegment(r.basePath, baseOffset, r.codecOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -135,26 +143,22 @@ func (r *readOnlySegmentsGroup) Get(offset int64) (common.RefCount[ReadOnlySegme
 }
 
 func (r *readOnlySegmentsGroup) TrimSegments(offset int64) error {
-	r.Lock()
-	defer r.Unlock()
-
-	// Find the segment that ends before the trim offset
-	segmentToKeep, found := r.allSegments.Floor(offset)
-	if !found {
-		segmentToKeep = offset
+	segments := r.segmentsToTrim(offset)
+	if len(segments) == 0 {
+		return nil
 	}
 
-	cutoffSegment, found := r.allSegments.Floor(segmentToKeep - 1)
-	if !found {
-		return nil
+	// The lock is not held while uploading the segments, to not block the
+	// readers. Nothing is deleted unless all the segments were offloaded.
+	if err := r.offload(segments); err != nil {
+		return err
 	}
 
-	var err error
-	for _, s := range r.allSegments.Keys() {
-		if s > cutoffSegment {
-			break
-		}
+	r.Lock()
+	defer r.Unlock()
 
+	var err error
+	for _, s := range segments {
 		r.allSegments.Remove(s)
 		if segment, ok := r.openSegments.Get(s); ok {
 			err = multierr.Append(err, segment.Get().Delete())
@@ -162,7 +166,7 @@ func (r *readOnlySegmentsGroup) TrimSegments(offset int64) error {
 			continue
 		}
 
-		c, err2 := newSegmentConfig(r.basePath, s)
+		c, err2 := newSegmentConfig(r.basePath, s, r.codecOptions)
 		if err2 != nil {
 			err = multierr.Append(err, err2)
 			continue
@@ -180,6 +184,49 @@ func (r *readOnlySegmentsGroup) TrimSegments(offset int64) error {
 	return err
 }
 
+// Returns the segments that end before the trim offset.
+func (r *readOnlySegmentsGroup) segmentsToTrim(offset int64) []int64 {
+	r.Lock()
+	defer r.Unlock()
+
+	// Find the segment that ends before the trim offset
+	segmentToKeep, found := r.allSegments.Floor(offset)
+	if !found {
+		segmentToKeep = offset
+	}
+
+	cutoffSegment, found := r.allSegments.Floor(segmentToKeep - 1)
+	if !found {
+		return nil
+	}
+
+	var segments []int64
+	for _, s := range r.allSegments.Keys() {
+		if s > cutoffSegment {
+			break
+		}
+		segments = append(segments, s)
+	}
+	return segments
+}
+
+func (r *readOnlySegmentsGroup) offload(segments []int64) error {
+	if r.offloader == nil {
+		return nil
+	}
+
+	for _, s := range segments {
+		c, err := newSegmentConfig(r.basePath, s, r.codecOptions)
+		if err != nil {
+			return err
+		}
+		if err := r.offloader.Offload(c); err != nil {
+			return errors.Wrapf(err, "failed to offload segment %d", s)
+		}
+	}
+	return nil
+}
+
 func (r *readOnlySegmentsGroup) PollHighestSegment() (common.RefCount[ReadOnlySegment], error) {
 	r.Lock()
 	defer r.Unlock()
@@ -195,7 +242,7 @@ func (r *readOnlySegmentsGroup) PollHighestSegment() (common.RefCount[ReadOnlySe
 		return segment.Acquire(), nil
 	}
 
-	roSegment, err := newReadOnlySegment(r.basePath, offset)
+	roSegment, err := newReadOnlySegment(r.basePath, offset, r.codecOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/wal/codec"
 )
 
 func TestReadOnlySegmentsGroupTrimSegments(t *testing.T) {
@@ -44,7 +45,7 @@ func TestReadOnlySegmentsGroupTrimSegments(t *testing.T) {
 			}))
 		}
 		walBasePath := w.(*wal).walPath
-		readOnlySegments, err := newReadOnlySegmentsGroup(walBasePath)
+		readOnlySegments, err := newReadOnlySegmentsGroup(walBasePath, codec.Options{}, nil)
 		assert.NoError(t, err)
 
 		// Ensure newReadOnlySegment will return an NotExists error
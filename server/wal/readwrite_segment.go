@@ -56,7 +56,7 @@ type readWriteSegment struct {
 }
 
 func newReadWriteSegment(basePath string, baseOffset int64, segmentSize uint32, lastCrc uint32,
-	commitOffsetProvider CommitOffsetProvider) (ReadWriteSegment, error) {
+	commitOffsetProvider CommitOffsetProvider, options codec.Options) (ReadWriteSegment, error) {
 	var err error
 	if _, err = os.Stat(basePath); os.IsNotExist(err) {
 		if err = os.MkdirAll(basePath, 0755); err != nil {
@@ -64,7 +64,7 @@ func newReadWriteSegment(basePath string, baseOffset int64, segmentSize uint32,
 		}
 	}
 
-	c, err := newSegmentConfig(basePath, baseOffset)
+	c, err := newSegmentConfig(basePath, baseOffset, options)
 	if err != nil {
 		return nil, err
 	}
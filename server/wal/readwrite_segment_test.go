@@ -27,7 +27,7 @@ import (
 func TestReadWriteSegment(t *testing.T) {
 	path := t.TempDir()
 
-	rw, err := newReadWriteSegment(path, 0, 128*1024, 0, nil)
+	rw, err := newReadWriteSegment(path, 0, 128*1024, 0, nil, codec.Options{})
 	assert.NoError(t, err)
 
 	assert.EqualValues(t, 0, rw.BaseOffset())
@@ -46,7 +46,7 @@ func TestReadWriteSegment(t *testing.T) {
 	assert.NoError(t, rw.Close())
 
 	// Re-open and recover the segment
-	rw, err = newReadWriteSegment(path, 0, 128*1024, 0, nil)
+	rw, err = newReadWriteSegment(path, 0, 128*1024, 0, nil, codec.Options{})
 	assert.NoError(t, err)
 	assert.EqualValues(t, 0, rw.BaseOffset())
 	assert.EqualValues(t, 1, rw.LastOffset())
@@ -65,7 +65,7 @@ func TestReadWriteSegment(t *testing.T) {
 func TestReadWriteSegment_NonZero(t *testing.T) {
 	path := t.TempDir()
 
-	rw, err := newReadWriteSegment(path, 5, 128*1024, 0, nil)
+	rw, err := newReadWriteSegment(path, 5, 128*1024, 0, nil, codec.Options{})
 	assert.NoError(t, err)
 
 	assert.EqualValues(t, 5, rw.BaseOffset())
@@ -92,14 +92,14 @@ func TestReadWriteSegment_NonZero(t *testing.T) {
 	assert.NoError(t, rw.Close())
 
 	// Re-open and recover the segment
-	rw, err = newReadWriteSegment(path, 5, 128*1024, 0, nil)
+	rw, err = newReadWriteSegment(path, 5, 128*1024, 0, nil, codec.Options{})
 	assert.NoError(t, err)
 	assert.EqualValues(t, 5, rw.BaseOffset())
 	assert.EqualValues(t, 6, rw.LastOffset())
 }
 
 func TestReadWriteSegment_HasSpace(t *testing.T) {
-	rw, err := newReadWriteSegment(t.TempDir(), 0, 1024, 0, nil)
+	rw, err := newReadWriteSegment(t.TempDir(), 0, 1024, 0, nil, codec.Options{})
 	assert.NoError(t, err)
 	segment := rw.(*readWriteSegment)
 	headerSize := int(segment.c.codec.GetHeaderSize())
@@ -129,7 +129,7 @@ func TestReadWriteSegment_BrokenUncommittedData_ErrOffsetOutOfBounds(t *testing.
 
 	dir := t.TempDir()
 	// basic functionality test
-	rw, err := newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider)
+	rw, err := newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider, codec.Options{})
 	assert.NoError(t, err)
 	payload1 := []byte("entry-0")
 	assert.NoError(t, rw.Append(0, payload1))
@@ -159,7 +159,7 @@ func TestReadWriteSegment_BrokenUncommittedData_ErrOffsetOutOfBounds(t *testing.
 	rwSegment.Close()
 
 	// recover the rw segment
-	rw, err = newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider)
+	rw, err = newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider, codec.Options{})
 	assert.NoError(t, err)
 	assert.EqualValues(t, 1, rw.LastOffset())
 
@@ -177,7 +177,7 @@ func TestReadWriteSegment_BrokenCommittedData_ErrOffsetOutOfBounds(t *testing.T)
 
 	dir := t.TempDir()
 	// basic functionality test
-	rw, err := newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider)
+	rw, err := newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider, codec.Options{})
 	assert.NoError(t, err)
 	payload1 := []byte("entry-0")
 	assert.NoError(t, rw.Append(0, payload1))
@@ -207,7 +207,7 @@ func TestReadWriteSegment_BrokenCommittedData_ErrOffsetOutOfBounds(t *testing.T)
 	rwSegment.Close()
 
 	// recover the rw segment
-	_, err = newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider)
+	_, err = newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider, codec.Options{})
 	assert.ErrorIs(t, err, codec.ErrOffsetOutOfBounds)
 }
 
@@ -216,7 +216,7 @@ func TestReadWriteSegment_BrokenUncommittedData_ErrDataCorrupted(t *testing.T) {
 
 	dir := t.TempDir()
 	// basic functionality test
-	rw, err := newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider)
+	rw, err := newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider, codec.Options{})
 	assert.NoError(t, err)
 	payload1 := []byte("entry-0")
 	assert.NoError(t, rw.Append(0, payload1))
@@ -246,7 +246,7 @@ func TestReadWriteSegment_BrokenUncommittedData_ErrDataCorrupted(t *testing.T) {
 	rwSegment.Close()
 
 	// recover the rw segment
-	rw, err = newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider)
+	rw, err = newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider, codec.Options{})
 	assert.NoError(t, err)
 	assert.EqualValues(t, 1, rw.LastOffset())
 
@@ -264,7 +264,7 @@ func TestReadWriteSegment_BrokenCommittedData_ErrDataCorrupted(t *testing.T) {
 
 	dir := t.TempDir()
 	// basic functionality test
-	rw, err := newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider)
+	rw, err := newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider, codec.Options{})
 	assert.NoError(t, err)
 	payload1 := []byte("entry-0")
 	assert.NoError(t, rw.Append(0, payload1))
@@ -294,13 +294,13 @@ func TestReadWriteSegment_BrokenCommittedData_ErrDataCorrupted(t *testing.T) {
 	rwSegment.Close()
 
 	// recover the rw segment
-	_, err = newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider)
+	_, err = newReadWriteSegment(dir, 0, 1024, 0, commitOffsetProvider, codec.Options{})
 	assert.ErrorIs(t, err, codec.ErrDataCorrupted)
 }
 
 func TestSegmentAppendShouldNotPanic(t *testing.T) {
 	basePath := t.TempDir()
-	rw, err := newReadWriteSegment(basePath, 0, 1024, 0, nil)
+	rw, err := newReadWriteSegment(basePath, 0, 1024, 0, nil, codec.Options{})
 	assert.NoError(t, err)
 	for i := int64(0); i < 51; i++ {
 		err := rw.Append(i, fmt.Appendf(nil, "entry-%d", i))
@@ -26,8 +26,8 @@ type segmentConfig struct {
 	baseOffset    int64
 }
 
-func newSegmentConfig(basePath string, baseOffset int64) (*segmentConfig, error) {
-	_codec, segmentExists, err := codec.GetOrCreate(segmentPath(basePath, baseOffset))
+func newSegmentConfig(basePath string, baseOffset int64, options codec.Options) (*segmentConfig, error) {
+	_codec, segmentExists, err := codec.GetOrCreate(segmentPath(basePath, baseOffset), options)
 	if err != nil {
 		return nil, err
 	}
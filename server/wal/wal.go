@@ -22,6 +22,8 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/encryption"
+	"github.com/streamnative/oxia/server/wal/codec"
 )
 
 var (
@@ -39,6 +41,18 @@ type FactoryOptions struct {
 	Retention   time.Duration
 	SegmentSize int32
 	SyncData    bool
+
+	// The compression for the records of new segments
+	Compression codec.Compression
+
+	// When set, the records of new segments are encrypted with the keys
+	// from the provider
+	KeyProvider encryption.KeyProvider
+
+	// When set, the segments are uploaded to the store before being trimmed,
+	// and the readers can still read them from there. The store is closed
+	// with the factory.
+	ObjectStore ObjectStore
 }
 
 var DefaultFactoryOptions = &FactoryOptions{
@@ -98,6 +112,9 @@ type Wal interface {
 	// Return InvalidOffset if the WAL is empty
 	FirstOffset() int64
 
+	// DiskUsage returns the disk space used by the WAL segments, in bytes
+	DiskUsage() int64
+
 	// Clear removes all the entries in the WAL
 	Clear() error
 
@@ -31,6 +31,7 @@ import (
 	"github.com/streamnative/oxia/common"
 	"github.com/streamnative/oxia/common/metrics"
 	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/encryption"
 	"github.com/streamnative/oxia/server/wal/codec"
 )
 
@@ -44,26 +45,39 @@ func NewWalFactory(options *FactoryOptions) Factory {
 	}
 }
 
+func newCodecOptions(options *FactoryOptions) codec.Options {
+	codecOptions := codec.Options{Compression: options.Compression}
+	if options.KeyProvider != nil {
+		codecOptions.Cipher = encryption.NewCipher(options.KeyProvider)
+	}
+	return codecOptions
+}
+
 func (f *walFactory) NewWal(namespace string, shard int64, commitOffsetProvider CommitOffsetProvider) (Wal, error) {
 	impl, err := newWal(namespace, shard, f.options, commitOffsetProvider, common.SystemClock, DefaultCheckInterval)
 	return impl, err
 }
 
-func (*walFactory) Close() error {
+func (f *walFactory) Close() error {
+	if f.options.ObjectStore != nil {
+		return f.options.ObjectStore.Close()
+	}
 	return nil
 }
 
 type wal struct {
 	sync.RWMutex
-	walPath     string
-	namespace   string
-	shard       int64
-	firstOffset atomic.Int64
-	segmentSize uint32
-	syncData    bool
+	walPath      string
+	namespace    string
+	shard        int64
+	firstOffset  atomic.Int64
+	segmentSize  uint32
+	syncData     bool
+	codecOptions codec.Options
 
 	currentSegment       ReadWriteSegment
 	readOnlySegments     ReadOnlySegmentsGroup
+	offloadedSegments    *offloadedSegmentsGroup
 	commitOffsetProvider CommitOffsetProvider
 
 	// The last offset appended to the Wal. It might not yet be synced
@@ -106,6 +120,7 @@ func newWal(namespace string, shard int64, options *FactoryOptions, commitOffset
 		shard:                shard,
 		segmentSize:          uint32(options.SegmentSize),
 		syncData:             options.SyncData,
+		codecOptions:         newCodecOptions(options),
 		commitOffsetProvider: commitOffsetProvider,
 
 		appendLatency: metrics.NewLatencyHistogram("oxia_server_wal_append_latency",
@@ -127,7 +142,14 @@ func newWal(namespace string, shard int64, options *FactoryOptions, commitOffset
 	}
 
 	var err error
-	if w.readOnlySegments, err = newReadOnlySegmentsGroup(w.walPath); err != nil {
+	if options.ObjectStore != nil {
+		if w.offloadedSegments, err = newOffloadedSegmentsGroup(options.ObjectStore, namespace, shard,
+			w.walPath, w.codecOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	if w.readOnlySegments, err = newReadOnlySegmentsGroup(w.walPath, w.codecOptions, w.offloader()); err != nil {
 		return nil, err
 	}
 
@@ -160,13 +182,41 @@ func newWal(namespace string, shard int64, options *FactoryOptions, commitOffset
 	return w, nil
 }
 
-func (t *wal) readAtIndex(index int64) (*proto.LogEntry, error) {
-	t.RLock()
-	defer t.RUnlock()
+func (t *wal) offloader() segmentOffloader {
+	if t.offloadedSegments == nil {
+		return nil
+	}
+	return t.offloadedSegments
+}
 
+func (t *wal) readAtIndex(index int64) (*proto.LogEntry, error) {
 	timer := t.readLatency.Timer()
 	defer timer.Done()
 
+	entry, err := t.readLocalAtIndex(index)
+	if errors.Is(err, codec.ErrOffsetOutOfBounds) && t.offloadedSegments != nil && index < t.FirstOffset() {
+		// The entry was trimmed from the local disk. The wal lock is not held
+		// while reading it, since the segment might need to be downloaded
+		return t.readOffloadedAtIndex(index)
+	}
+	return entry, err
+}
+
+func (t *wal) readOffloadedAtIndex(index int64) (*proto.LogEntry, error) {
+	rc, err := t.offloadedSegments.Get(index)
+	if err != nil {
+		t.readErrors.Inc()
+		return nil, err
+	}
+
+	entry, err := t.readFromSegment(rc.Get(), index)
+	return entry, multierr.Append(err, rc.Close())
+}
+
+func (t *wal) readLocalAtIndex(index int64) (*proto.LogEntry, error) {
+	t.RLock()
+	defer t.RUnlock()
+
 	var err error
 	var rc common.RefCount[ReadOnlySegment]
 	var segment ReadOnlySegment
@@ -184,8 +234,12 @@ func (t *wal) readAtIndex(index int64) (*proto.LogEntry, error) {
 		segment = rc.Get()
 	}
 
-	var val []byte
-	if val, err = segment.Read(index); err != nil {
+	return t.readFromSegment(segment, index)
+}
+
+func (t *wal) readFromSegment(segment ReadOnlySegment, index int64) (*proto.LogEntry, error) {
+	val, err := segment.Read(index)
+	if err != nil {
 		t.readErrors.Inc()
 		return nil, err
 	}
@@ -196,7 +250,7 @@ func (t *wal) readAtIndex(index int64) (*proto.LogEntry, error) {
 		return nil, err
 	}
 	t.readBytes.Add(len(val))
-	return entry, err
+	return entry, nil
 }
 
 func (t *wal) LastOffset() int64 {
@@ -207,6 +261,33 @@ func (t *wal) FirstOffset() int64 {
 	return t.firstOffset.Load()
 }
 
+// Tells whether the offset is before the first offset but can still be read
+// from the offloaded segments.
+func (t *wal) isOffloaded(offset int64) bool {
+	if t.offloadedSegments == nil {
+		return false
+	}
+	firstOffloadedOffset := t.offloadedSegments.FirstOffset()
+	return firstOffloadedOffset != InvalidOffset && offset >= firstOffloadedOffset
+}
+
+func (t *wal) DiskUsage() int64 {
+	var size int64
+	// Segments can get trimmed while we're walking the directory,
+	// so we're ignoring the files that are not there anymore
+	_ = filepath.WalkDir(t.walPath, func(_ string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil //nolint:nilerr
+		}
+
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
 func (t *wal) trim(firstOffset int64) error {
 	if firstOffset <= t.firstOffset.Load() {
 		return nil
@@ -240,10 +321,14 @@ func (t *wal) closeWithoutLock() error {
 		t.cancel()
 		t.activeEntries.Unregister()
 
-		return multierr.Combine(
+		err := multierr.Combine(
 			t.currentSegment.Close(),
 			t.readOnlySegments.Close(),
 		)
+		if t.offloadedSegments != nil {
+			err = multierr.Append(err, t.offloadedSegments.Close())
+		}
+		return err
 	}
 }
 
@@ -281,7 +366,7 @@ func (t *wal) AppendAsync(entry *proto.LogEntry) error {
 		}
 
 		if t.currentSegment, err = newReadWriteSegment(t.walPath, entry.Offset, t.segmentSize,
-			0, t.commitOffsetProvider); err != nil {
+			0, t.commitOffsetProvider, t.codecOptions); err != nil {
 			t.writeErrors.Inc()
 			return err
 		}
@@ -329,7 +414,7 @@ func (t *wal) rolloverSegment() error {
 	t.readOnlySegments.AddedNewSegment(t.currentSegment.BaseOffset())
 
 	if t.currentSegment, err = newReadWriteSegment(t.walPath, t.lastAppendedOffset.Load()+1, t.segmentSize,
-		lastCrc, t.commitOffsetProvider); err != nil {
+		lastCrc, t.commitOffsetProvider, t.codecOptions); err != nil {
 		return err
 	}
 
@@ -434,8 +519,12 @@ func (t *wal) Clear() error {
 	err := multierr.Combine(
 		t.currentSegment.Close(),
 		t.readOnlySegments.Close(),
-		os.RemoveAll(t.walPath),
 	)
+	if t.offloadedSegments != nil {
+		// The offloaded segments are kept, since they only have committed entries
+		err = multierr.Append(err, t.offloadedSegments.Close())
+	}
+	err = multierr.Append(err, os.RemoveAll(t.walPath))
 
 	if err != nil {
 		t.writeErrors.Inc()
@@ -443,11 +532,11 @@ func (t *wal) Clear() error {
 	}
 
 	if t.currentSegment, err = newReadWriteSegment(t.walPath, 0, t.segmentSize,
-		0, t.commitOffsetProvider); err != nil {
+		0, t.commitOffsetProvider, t.codecOptions); err != nil {
 		return err
 	}
 
-	if t.readOnlySegments, err = newReadOnlySegmentsGroup(t.walPath); err != nil {
+	if t.readOnlySegments, err = newReadOnlySegmentsGroup(t.walPath, t.codecOptions, t.offloader()); err != nil {
 		return err
 	}
 
@@ -519,7 +608,7 @@ func (t *wal) TruncateLog(lastSafeOffset int64) (int64, error) { //nolint:revive
 					return InvalidOffset, err
 				}
 				if t.currentSegment, err = newReadWriteSegment(t.walPath, segment.Get().BaseOffset(),
-					t.segmentSize, segment.Get().LastCrc(), t.commitOffsetProvider); err != nil {
+					t.segmentSize, segment.Get().LastCrc(), t.commitOffsetProvider, t.codecOptions); err != nil {
 					err = multierr.Append(err, segment.Close())
 					return InvalidOffset, err
 				}
@@ -572,7 +661,7 @@ func (t *wal) recoverWal() error {
 	}
 
 	if t.currentSegment, err = newReadWriteSegment(t.walPath, lastSegment, t.segmentSize,
-		lastCrc, t.commitOffsetProvider); err != nil {
+		lastCrc, t.commitOffsetProvider, t.codecOptions); err != nil {
 		return err
 	}
 
@@ -23,7 +23,7 @@ import (
 func (t *wal) NewReader(after int64) (Reader, error) {
 	firstOffset := after + 1
 
-	if firstOffset < t.FirstOffset() {
+	if firstOffset < t.FirstOffset() && !t.isOffloaded(firstOffset) {
 		return nil, ErrEntryNotFound
 	}
 
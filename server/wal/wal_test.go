@@ -15,8 +15,12 @@
 package wal
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -25,6 +29,7 @@ import (
 
 	"github.com/streamnative/oxia/common"
 	"github.com/streamnative/oxia/proto"
+	"github.com/streamnative/oxia/server/encryption"
 	"github.com/streamnative/oxia/server/wal/codec"
 )
 
@@ -544,3 +549,225 @@ func TestReaderReadNext(t *testing.T) {
 	assert.NoError(t, reader.Close())
 	assert.NoError(t, f.Close())
 }
+
+func TestCompression(t *testing.T) {
+	dir := t.TempDir()
+	newFactory := func(compression codec.Compression) Factory {
+		return NewWalFactory(&FactoryOptions{
+			BaseWalDir:  dir,
+			Retention:   1 * time.Hour,
+			SegmentSize: 128 * 1024,
+			SyncData:    true,
+			Compression: compression,
+		})
+	}
+
+	entryValue := func(i int) []byte {
+		value := make([]byte, 1024)
+		copy(value, fmt.Sprintf("entry-%d", i))
+		return value
+	}
+
+	appendEntries := func(f Factory, first, last int) {
+		w, err := f.NewWal(common.DefaultNamespace, shard, nil)
+		assert.NoError(t, err)
+		for i := first; i < last; i++ {
+			assert.NoError(t, w.Append(&proto.LogEntry{
+				Term:   1,
+				Offset: int64(i),
+				Value:  entryValue(i),
+			}))
+		}
+		assert.NoError(t, w.Close())
+		assert.NoError(t, f.Close())
+	}
+
+	// The segments written before enabling the compression are still readable
+	appendEntries(newFactory(codec.CompressionNone), 0, 200)
+	appendEntries(newFactory(codec.CompressionZstd), 200, 1000)
+	appendEntries(newFactory(codec.CompressionSnappy), 1000, 1500)
+
+	f := newFactory(codec.CompressionNone)
+	w, err := f.NewWal(common.DefaultNamespace, shard, nil)
+	assert.NoError(t, err)
+
+	fr, err := w.NewReader(InvalidOffset)
+	assert.NoError(t, err)
+	for i := 0; i < 1500; i++ {
+		assert.True(t, fr.HasNext())
+		entry, err := fr.ReadNext()
+		assert.NoError(t, err)
+		assert.EqualValues(t, i, entry.Offset)
+		assert.Equal(t, entryValue(i), entry.Value)
+	}
+	assert.False(t, fr.HasNext())
+	assert.NoError(t, fr.Close())
+
+	// Without compression, 1500 entries would need at least 12 segments
+	segments, err := filepath.Glob(filepath.Join(walPath(dir, common.DefaultNamespace, shard), "*.txn*"))
+	assert.NoError(t, err)
+	assert.Less(t, len(segments), 6)
+
+	assert.NoError(t, w.Close())
+	assert.NoError(t, f.Close())
+}
+
+func TestEncryption(t *testing.T) {
+	dir := t.TempDir()
+	keyProvider, err := encryption.NewStaticKeyProvider(bytes.Repeat([]byte{1}, encryption.KeySize))
+	assert.NoError(t, err)
+
+	newFactory := func(keyProvider encryption.KeyProvider) Factory {
+		return NewWalFactory(&FactoryOptions{
+			BaseWalDir:  dir,
+			Retention:   1 * time.Hour,
+			SegmentSize: 16 * 1024,
+			SyncData:    true,
+			Compression: codec.CompressionZstd,
+			KeyProvider: keyProvider,
+		})
+	}
+
+	appendEntries := func(f Factory, first, last int) {
+		w, err := f.NewWal(common.DefaultNamespace, shard, nil)
+		assert.NoError(t, err)
+		for i := first; i < last; i++ {
+			assert.NoError(t, w.Append(&proto.LogEntry{
+				Term:   1,
+				Offset: int64(i),
+				Value:  []byte(fmt.Sprintf("secret-value-%d", i)),
+			}))
+		}
+		assert.NoError(t, w.Close())
+		assert.NoError(t, f.Close())
+	}
+
+	// The segments written before enabling the encryption are still readable
+	appendEntries(newFactory(nil), 0, 500)
+	appendEntries(newFactory(keyProvider), 500, 1500)
+
+	f := newFactory(keyProvider)
+	w, err := f.NewWal(common.DefaultNamespace, shard, nil)
+	assert.NoError(t, err)
+
+	fr, err := w.NewReader(InvalidOffset)
+	assert.NoError(t, err)
+	for i := 0; i < 1500; i++ {
+		assert.True(t, fr.HasNext())
+		entry, err := fr.ReadNext()
+		assert.NoError(t, err)
+		assert.EqualValues(t, i, entry.Offset)
+		assert.Equal(t, []byte(fmt.Sprintf("secret-value-%d", i)), entry.Value)
+	}
+	assert.False(t, fr.HasNext())
+	assert.NoError(t, fr.Close())
+	assert.NoError(t, w.Close())
+	assert.NoError(t, f.Close())
+
+	// The new segments don't contain the values in clear
+	segments, err := filepath.Glob(filepath.Join(walPath(dir, common.DefaultNamespace, shard), "*.txn4"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, segments)
+	for _, segment := range segments {
+		content, err := os.ReadFile(segment)
+		assert.NoError(t, err)
+		assert.False(t, bytes.Contains(content, []byte("secret-value")))
+	}
+
+	// The encrypted segments cannot be opened without the keys
+	f = newFactory(nil)
+	_, err = f.NewWal(common.DefaultNamespace, shard, nil)
+	assert.ErrorIs(t, err, codec.ErrMissingEncryptionKey)
+	assert.NoError(t, f.Close())
+}
+
+type failingObjectStore struct {
+	ObjectStore
+}
+
+func (*failingObjectStore) Put(string, io.Reader) error {
+	return errors.New("upload failed")
+}
+
+func TestOffload(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileObjectStore(t.TempDir())
+	assert.NoError(t, err)
+
+	newFactory := func(store ObjectStore) Factory {
+		return NewWalFactory(&FactoryOptions{
+			BaseWalDir:  dir,
+			Retention:   1 * time.Hour,
+			SegmentSize: 16 * 1024,
+			SyncData:    true,
+			ObjectStore: store,
+		})
+	}
+
+	assertReadAll := func(w Wal, after int64) {
+		r, err := w.NewReader(after)
+		assert.NoError(t, err)
+		for i := after + 1; i < 1500; i++ {
+			assert.True(t, r.HasNext())
+			entry, err := r.ReadNext()
+			assert.NoError(t, err)
+			assert.EqualValues(t, i, entry.Offset)
+			assert.Equal(t, []byte(fmt.Sprintf("value-%d", i)), entry.Value)
+		}
+		assert.False(t, r.HasNext())
+		assert.NoError(t, r.Close())
+	}
+
+	f := newFactory(store)
+	w, err := f.NewWal(common.DefaultNamespace, shard, nil)
+	assert.NoError(t, err)
+	for i := 0; i < 1500; i++ {
+		assert.NoError(t, w.Append(&proto.LogEntry{
+			Term:   1,
+			Offset: int64(i),
+			Value:  []byte(fmt.Sprintf("value-%d", i)),
+		}))
+	}
+
+	// Nothing is trimmed if the segments cannot be offloaded
+	w.(*wal).offloadedSegments.store = &failingObjectStore{}
+	assert.Error(t, w.(*wal).trim(1000))
+	assert.EqualValues(t, 0, w.FirstOffset())
+	assertReadAll(w, InvalidOffset)
+
+	w.(*wal).offloadedSegments.store = store
+	assert.NoError(t, w.(*wal).trim(1000))
+	assert.EqualValues(t, 1000, w.FirstOffset())
+
+	segments, err := listAllSegments(walPath(dir, common.DefaultNamespace, shard))
+	assert.NoError(t, err)
+	assert.Less(t, int64(0), segments[0])
+
+	keys, err := store.List(offloadedSegmentsPrefix(common.DefaultNamespace, shard))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, keys)
+
+	// The trimmed entries are read from the offloaded segments
+	assertReadAll(w, InvalidOffset)
+	assertReadAll(w, 500)
+	assert.NoError(t, w.Close())
+	assert.NoError(t, f.Close())
+
+	// The offloaded segments are still there after a restart
+	f = newFactory(store)
+	w, err = f.NewWal(common.DefaultNamespace, shard, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, segments[0], w.FirstOffset())
+	assertReadAll(w, InvalidOffset)
+	assert.NoError(t, w.Close())
+	assert.NoError(t, f.Close())
+
+	// Without the store, the trimmed entries are gone
+	f = newFactory(nil)
+	w, err = f.NewWal(common.DefaultNamespace, shard, nil)
+	assert.NoError(t, err)
+	_, err = w.NewReader(InvalidOffset)
+	assert.ErrorIs(t, err, ErrEntryNotFound)
+	assert.NoError(t, w.Close())
+	assert.NoError(t, f.Close())
+}
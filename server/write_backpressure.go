@@ -0,0 +1,204 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/streamnative/oxia/common"
+	"github.com/streamnative/oxia/common/metrics"
+)
+
+const (
+	// Beyond this fraction of any of the thresholds, the new writes are delayed
+	backpressureSlowDownLevel = 0.5
+
+	// The delay of the writes just below the thresholds. It grows linearly
+	// from the slow-down level
+	backpressureMaxDelay = 100 * time.Millisecond
+
+	// The retry delay suggested to the clients of the rejected writes
+	backpressureRetryAfter = 500 * time.Millisecond
+
+	// The weight of each write in the average quorum ack latency
+	ackLatencyWeight = 0.1
+
+	// The average quorum ack latency is halved every this time without new
+	// writes, so that the leader accepts the writes again when they stop
+	// completing because all of them are rejected
+	ackLatencyHalfLife = time.Second
+)
+
+// BackpressureOptions are the thresholds of the load of a leader beyond which
+// the new writes are rejected. The writes are delayed more and more as the
+// load approaches them. The zero values disable each threshold.
+type BackpressureOptions struct {
+	// The writes appended to the WAL whose outcome was not returned yet
+	MaxPendingWrites int64
+
+	// The entries that the followers needed for the quorum are behind the
+	// head offset. A follower that is down doesn't stop the writes, as long
+	// as the others keep up
+	MaxFollowerLag int64
+
+	// The average time for the writes to be acknowledged by the quorum, after
+	// being synced in the WAL of the leader. The average decays while no
+	// writes are acknowledged
+	MaxQuorumAckLatency time.Duration
+}
+
+func (o *BackpressureOptions) IsEnabled() bool {
+	return o != nil && (o.MaxPendingWrites > 0 || o.MaxFollowerLag > 0 || o.MaxQuorumAckLatency > 0)
+}
+
+// writeBackpressure slows down and rejects the new writes of a leader when the
+// WAL or the followers can't keep up with them, so that the pending writes
+// don't grow without bounds. A nil backpressure accepts all the writes.
+type writeBackpressure struct {
+	sync.Mutex
+
+	options    BackpressureOptions
+	ackLatency time.Duration
+	lastSample time.Time
+	now        func() time.Time
+	log        *slog.Logger
+
+	delayedCounter  metrics.Counter
+	rejectedCounter metrics.Counter
+}
+
+func newWriteBackpressure(options BackpressureOptions, namespace string, shardId int64) *writeBackpressure {
+	if !options.IsEnabled() {
+		return nil
+	}
+
+	labels := metrics.LabelsForShard(namespace, shardId)
+	delayedLabels := map[string]any{"action": "delayed"}
+	rejectedLabels := map[string]any{"action": "rejected"}
+	for k, v := range labels {
+		delayedLabels[k] = v
+		rejectedLabels[k] = v
+	}
+
+	return &writeBackpressure{
+		options: options,
+		now:     time.Now,
+		log: slog.With(
+			slog.String("component", "write-backpressure"),
+			slog.String("namespace", namespace),
+			slog.Int64("shard", shardId),
+		),
+		delayedCounter: metrics.NewCounter("oxia_server_leader_write_backpressure",
+			"The number of writes delayed or rejected because the leader was overloaded", "count", delayedLabels),
+		rejectedCounter: metrics.NewCounter("oxia_server_leader_write_backpressure",
+			"The number of writes delayed or rejected because the leader was overloaded", "count", rejectedLabels),
+	}
+}
+
+// observeAckLatency adds the time a write waited for the quorum to the average.
+func (b *writeBackpressure) observeAckLatency(latency time.Duration) {
+	if b == nil {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	now := b.now()
+	if b.ackLatency == 0 {
+		b.ackLatency = latency
+	} else {
+		b.ackLatency = b.decayedAckLatency(now)
+		b.ackLatency += time.Duration(ackLatencyWeight * float64(latency-b.ackLatency))
+	}
+	b.lastSample = now
+}
+
+// The average quorum ack latency, decayed by the time since the last write
+// was acknowledged. It must be called with the lock.
+func (b *writeBackpressure) decayedAckLatency(now time.Time) time.Duration {
+	elapsed := now.Sub(b.lastSample)
+	if elapsed <= 0 {
+		return b.ackLatency
+	}
+	return time.Duration(float64(b.ackLatency) * math.Exp2(-float64(elapsed)/float64(ackLatencyHalfLife)))
+}
+
+// reset discards the load observed in the previous terms.
+func (b *writeBackpressure) reset() {
+	if b == nil {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	b.ackLatency = 0
+	b.lastSample = time.Time{}
+}
+
+// level is the highest ratio of the load to its threshold. The follower lag
+// is only computed when its threshold is set.
+func (b *writeBackpressure) level(pendingWrites int64, followerLag func() int64) float64 {
+	var level float64
+	if b.options.MaxPendingWrites > 0 {
+		level = max(level, float64(pendingWrites)/float64(b.options.MaxPendingWrites))
+	}
+	if b.options.MaxFollowerLag > 0 {
+		level = max(level, float64(followerLag())/float64(b.options.MaxFollowerLag))
+	}
+	if b.options.MaxQuorumAckLatency > 0 {
+		b.Lock()
+		ackLatency := b.decayedAckLatency(b.now())
+		b.Unlock()
+		level = max(level, float64(ackLatency)/float64(b.options.MaxQuorumAckLatency))
+	}
+	return level
+}
+
+// throttle delays a new write in proportion to the load of the leader, or
+// rejects it with a ResourceExhausted error when the load is beyond any of
+// the thresholds.
+func (b *writeBackpressure) throttle(ctx context.Context, pendingWrites int64, followerLag func() int64) error {
+	if b == nil {
+		return nil
+	}
+
+	level := b.level(pendingWrites, followerLag)
+	if level >= 1 {
+		b.rejectedCounter.Inc()
+		b.log.Debug(
+			"Rejected write, the leader is overloaded",
+			slog.Float64("level", level),
+		)
+		return common.NewOverloadedError(backpressureRetryAfter)
+	}
+	if level <= backpressureSlowDownLevel {
+		return nil
+	}
+
+	b.delayedCounter.Inc()
+	delay := time.Duration((level - backpressureSlowDownLevel) / (1 - backpressureSlowDownLevel) * float64(backpressureMaxDelay))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/streamnative/oxia/common"
+)
+
+func noFollowerLag() int64 {
+	return 0
+}
+
+func TestWriteBackpressure_Disabled(t *testing.T) {
+	b := newWriteBackpressure(BackpressureOptions{}, common.DefaultNamespace, 1)
+	assert.Nil(t, b)
+
+	b.observeAckLatency(time.Hour)
+	assert.NoError(t, b.throttle(context.Background(), 1_000_000, noFollowerLag))
+}
+
+func TestWriteBackpressure_PendingWrites(t *testing.T) {
+	b := newWriteBackpressure(BackpressureOptions{MaxPendingWrites: 100}, common.DefaultNamespace, 1)
+
+	start := time.Now()
+	assert.NoError(t, b.throttle(context.Background(), 10, noFollowerLag))
+	assert.Less(t, time.Since(start), backpressureMaxDelay/2)
+
+	// Approaching the threshold, the writes are delayed
+	start = time.Now()
+	assert.NoError(t, b.throttle(context.Background(), 90, noFollowerLag))
+	assert.GreaterOrEqual(t, time.Since(start), backpressureMaxDelay/2)
+
+	err := b.throttle(context.Background(), 100, noFollowerLag)
+	retryAfter, ok := common.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, backpressureRetryAfter, retryAfter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, b.throttle(ctx, 99, noFollowerLag), context.Canceled)
+}
+
+func TestWriteBackpressure_FollowerLag(t *testing.T) {
+	b := newWriteBackpressure(BackpressureOptions{MaxFollowerLag: 1000}, common.DefaultNamespace, 1)
+
+	assert.NoError(t, b.throttle(context.Background(), 1_000_000, func() int64 { return 10 }))
+	assert.Error(t, b.throttle(context.Background(), 0, func() int64 { return 1000 }))
+}
+
+func TestWriteBackpressure_QuorumAckLatency(t *testing.T) {
+	b := newWriteBackpressure(BackpressureOptions{MaxQuorumAckLatency: 100 * time.Millisecond}, common.DefaultNamespace, 1)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.observeAckLatency(10 * time.Millisecond)
+	assert.NoError(t, b.throttle(context.Background(), 0, noFollowerLag))
+
+	// A single slow write doesn't reject the next ones
+	b.observeAckLatency(time.Second)
+	assert.Equal(t, 109*time.Millisecond, b.ackLatency)
+	assert.Error(t, b.throttle(context.Background(), 0, noFollowerLag))
+
+	for i := 0; i < 50; i++ {
+		b.observeAckLatency(10 * time.Millisecond)
+	}
+	assert.NoError(t, b.throttle(context.Background(), 0, noFollowerLag))
+}
+
+func TestWriteBackpressure_QuorumAckLatencyDecay(t *testing.T) {
+	b := newWriteBackpressure(BackpressureOptions{MaxQuorumAckLatency: 100 * time.Millisecond}, common.DefaultNamespace, 1)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.observeAckLatency(time.Second)
+	assert.Error(t, b.throttle(context.Background(), 0, noFollowerLag))
+
+	// No write completes while all of them are rejected, and the leader
+	// accepts them again once the average decays
+	now = now.Add(ackLatencyHalfLife)
+	assert.Equal(t, 500*time.Millisecond, b.decayedAckLatency(now))
+	assert.Error(t, b.throttle(context.Background(), 0, noFollowerLag))
+
+	now = now.Add(4 * ackLatencyHalfLife)
+	start := time.Now()
+	assert.NoError(t, b.throttle(context.Background(), 0, noFollowerLag))
+	assert.Less(t, time.Since(start), backpressureMaxDelay/2)
+
+	// The new samples are averaged with the decayed latency
+	b.observeAckLatency(10 * time.Millisecond)
+	assert.Equal(t, 29125*time.Microsecond, b.ackLatency)
+}
+
+func TestWriteBackpressure_Reset(t *testing.T) {
+	b := newWriteBackpressure(BackpressureOptions{MaxQuorumAckLatency: 100 * time.Millisecond}, common.DefaultNamespace, 1)
+	b.observeAckLatency(time.Second)
+	assert.Error(t, b.throttle(context.Background(), 0, noFollowerLag))
+
+	b.reset()
+	assert.NoError(t, b.throttle(context.Background(), 0, noFollowerLag))
+
+	var disabled *writeBackpressure
+	disabled.reset()
+}
+
+func TestLeaderController_QuorumFollowerLag(t *testing.T) {
+	lc := &leaderController{
+		replicationFactor: 5,
+		quorumAckTracker:  NewQuorumAckTracker(5, 100, 0),
+		followers: map[string]FollowerCursor{
+			"f1": &mockFollowerCursor{ackOffset: 0},
+			"f2": &mockFollowerCursor{ackOffset: 90},
+			"f3": &mockFollowerCursor{ackOffset: 50},
+			"o1": &mockFollowerCursor{ackOffset: 100},
+		},
+		observers: map[string]bool{"o1": true},
+	}
+
+	// The second follower is needed for the quorum, after the observer
+	assert.EqualValues(t, 50, lc.quorumFollowerLag())
+
+	// Without enough followers, the lag is not counted
+	delete(lc.followers, "f2")
+	delete(lc.followers, "f3")
+	assert.EqualValues(t, 0, lc.quorumFollowerLag())
+
+	lc.replicationFactor = 1
+	assert.EqualValues(t, 0, lc.quorumFollowerLag())
+}
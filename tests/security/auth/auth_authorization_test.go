@@ -0,0 +1,114 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/oauth2-proxy/mockoidc"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/streamnative/oxia/oxia"
+	clientauth "github.com/streamnative/oxia/oxia/auth"
+)
+
+const authorizationPolicy = `
+roles:
+  - name: team-a-writer
+    grants:
+      - namespace: default
+        keyPrefix: /team-a/
+        permissions: [read, write]
+  - name: team-a-reader
+    grants:
+      - namespace: default
+        keyPrefix: /team-a/
+        permissions: [read]
+bindings:
+  - role: team-a-writer
+    users: [alice]
+  - role: team-a-reader
+    users: [bob]
+`
+
+func TestAuthorization(t *testing.T) {
+	mockOIDC, err := mockoidc.Run()
+	assert.NoError(t, err)
+	defer func(mockOIDC *mockoidc.MockOIDC) {
+		_ = mockOIDC.Shutdown()
+	}(mockOIDC)
+
+	audience := generateRandomStr(t)
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	assert.NoError(t, os.WriteFile(policyFile, []byte(authorizationPolicy), 0600))
+
+	authOptions := newOIDCAuthOptions(t, mockOIDC.Issuer(), audience)
+	authOptions.PolicyFile = policyFile
+	address, clusterCloseFunc := newOxiaCluster(t, authOptions)
+	defer clusterCloseFunc()
+
+	connect := func(subject string) oxia.SyncClient {
+		token, err := mockOIDC.Keypair.SignJWT(&jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(1) * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    mockOIDC.Issuer(),
+			Subject:   subject,
+		})
+		assert.NoError(t, err)
+		client, err := oxia.NewSyncClient(address,
+			oxia.WithAuthentication(clientauth.NewTokenAuthenticationWithToken(token, false)))
+		assert.NoError(t, err)
+		return client
+	}
+
+	ctx := context.Background()
+	alice := connect("alice")
+	defer alice.Close()
+
+	_, _, err = alice.Put(ctx, "/team-a/key", []byte("value"))
+	assert.NoError(t, err)
+	_, _, err = alice.Put(ctx, "/team-b/key", []byte("value"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	bob := connect("bob")
+	defer bob.Close()
+
+	_, value, _, err := bob.Get(ctx, "/team-a/key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", string(value))
+	keys, err := bob.List(ctx, "/team-a/", "/team-a//")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/team-a/key"}, keys)
+
+	_, _, err = bob.Put(ctx, "/team-a/key", []byte("other"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, codes.PermissionDenied, status.Code(bob.DeleteRange(ctx, "/team-a/", "/team-a//")))
+	_, err = bob.List(ctx, "", "~")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// The users without any role are denied everything
+	carol := connect("carol")
+	defer carol.Close()
+	_, _, _, err = carol.Get(ctx, "/team-a/key")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/streamnative/oxia/oxia"
+	clientauth "github.com/streamnative/oxia/oxia/auth"
+	"github.com/streamnative/oxia/server/auth"
+)
+
+func TestJWTWithLocalKeys(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "key.pem")
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	params, err := json.Marshal(auth.JWTOptions{
+		PublicKeyFiles:   keyFile,
+		AllowedAudiences: "oxia",
+	})
+	assert.NoError(t, err)
+	addr, clusterCloseFunc := newOxiaCluster(t, auth.Options{
+		ProviderName:   auth.ProviderJWT,
+		ProviderParams: string(params),
+	})
+	defer clusterCloseFunc()
+
+	signedToken, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+		Audience:  jwt.ClaimStrings{"oxia"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		Subject:   "alice",
+	}).SignedString(key)
+	assert.NoError(t, err)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(tokenFile, []byte("wrong-token"), 0600))
+	authentication, err := clientauth.NewTokenAuthenticationWithFile(tokenFile, time.Minute, false)
+	assert.NoError(t, err)
+	_, err = oxia.NewSyncClient(addr, oxia.WithAuthentication(authentication))
+	assert.Equal(t, codes.Unauthenticated, status.Code(errors.Unwrap(err)))
+
+	assert.NoError(t, os.WriteFile(tokenFile, []byte(signedToken), 0600))
+	authentication, err = clientauth.NewTokenAuthenticationWithFile(tokenFile, time.Minute, false)
+	assert.NoError(t, err)
+	client, err := oxia.NewSyncClient(addr, oxia.WithAuthentication(authentication))
+	assert.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	_, _, err = client.Put(ctx, "key", []byte("value"))
+	assert.NoError(t, err)
+	_, value, _, err := client.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", string(value))
+}
+
+func TestStaticTokens(t *testing.T) {
+	addr, clusterCloseFunc := newOxiaCluster(t, auth.Options{
+		ProviderName:   auth.ProviderStatic,
+		ProviderParams: `{"tokens":{"dev-token":"dev"}}`,
+	})
+	defer clusterCloseFunc()
+
+	_, err := oxia.NewSyncClient(addr,
+		oxia.WithAuthentication(clientauth.NewTokenAuthenticationWithToken("wrong-token", false)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(errors.Unwrap(err)))
+
+	client, err := oxia.NewSyncClient(addr,
+		oxia.WithAuthentication(clientauth.NewTokenAuthenticationWithToken("dev-token", false)))
+	assert.NoError(t, err)
+	defer client.Close()
+
+	_, _, err = client.Put(context.Background(), "key", []byte("value"))
+	assert.NoError(t, err)
+}
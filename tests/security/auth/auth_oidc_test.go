@@ -39,6 +39,11 @@ import (
 )
 
 func newOxiaClusterWithAuth(t *testing.T, issueURL string, audiences string) (address string, closeFunc func()) {
+	t.Helper()
+	return newOxiaCluster(t, newOIDCAuthOptions(t, issueURL, audiences))
+}
+
+func newOIDCAuthOptions(t *testing.T, issueURL string, audiences string) auth.Options {
 	t.Helper()
 	options := auth.OIDCOptions{
 		AllowedIssueURLs: issueURL,
@@ -46,10 +51,14 @@ func newOxiaClusterWithAuth(t *testing.T, issueURL string, audiences string) (ad
 	}
 	jsonParams, err := json.Marshal(options)
 	assert.NoError(t, err)
-	authParams := auth.Options{
+	return auth.Options{
 		ProviderName:   auth.ProviderOIDC,
 		ProviderParams: string(jsonParams),
 	}
+}
+
+func newOxiaCluster(t *testing.T, authParams auth.Options) (address string, closeFunc func()) {
+	t.Helper()
 	s1, err := server.New(server.Config{
 		PublicServiceAddr:          "localhost:0",
 		InternalServiceAddr:        "localhost:0",
@@ -107,7 +116,7 @@ func newOxiaClusterWithAuth(t *testing.T, issueURL string, audiences string) (ad
 
 	coordinator, err := impl.NewCoordinator(metadataProvider,
 		func() (model.ClusterConfig, error) { return clusterConfig, nil },
-		nil, impl.NewRpcProvider(clientPool))
+		nil, impl.NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 
 	return s1Addr.Public, func() {
@@ -15,6 +15,7 @@
 package tls
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -22,6 +23,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/streamnative/oxia/common"
 	"github.com/streamnative/oxia/common/security"
@@ -29,6 +32,7 @@ import (
 	"github.com/streamnative/oxia/coordinator/model"
 	"github.com/streamnative/oxia/oxia"
 	"github.com/streamnative/oxia/server"
+	"github.com/streamnative/oxia/server/auth"
 )
 
 func getPeerTLSOption() (*security.TLSOption, error) {
@@ -135,7 +139,7 @@ func TestClusterHandshakeSuccess(t *testing.T) {
 	clientPool := common.NewClientPool(tlsConf, nil)
 	defer clientPool.Close()
 
-	coordinator, err := impl.NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, impl.NewRpcProvider(clientPool))
+	coordinator, err := impl.NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, impl.NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 	defer coordinator.Close()
 }
@@ -165,7 +169,7 @@ func TestClientHandshakeFailByNoTlsConfig(t *testing.T) {
 	clientPool := common.NewClientPool(tlsConf, nil)
 	defer clientPool.Close()
 
-	coordinator, err := impl.NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, impl.NewRpcProvider(clientPool))
+	coordinator, err := impl.NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, impl.NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 	defer coordinator.Close()
 
@@ -199,7 +203,7 @@ func TestClientHandshakeByAuthFail(t *testing.T) {
 	clientPool := common.NewClientPool(tlsConf, nil)
 	defer clientPool.Close()
 
-	coordinator, err := impl.NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, impl.NewRpcProvider(clientPool))
+	coordinator, err := impl.NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, impl.NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 	defer coordinator.Close()
 
@@ -239,7 +243,7 @@ func TestClientHandshakeWithInsecure(t *testing.T) {
 	clientPool := common.NewClientPool(tlsConf, nil)
 	defer clientPool.Close()
 
-	coordinator, err := impl.NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, impl.NewRpcProvider(clientPool))
+	coordinator, err := impl.NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, impl.NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 	defer coordinator.Close()
 
@@ -280,7 +284,7 @@ func TestClientHandshakeSuccess(t *testing.T) {
 	clientPool := common.NewClientPool(tlsConf, nil)
 	defer clientPool.Close()
 
-	coordinator, err := impl.NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, impl.NewRpcProvider(clientPool))
+	coordinator, err := impl.NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, impl.NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 	defer coordinator.Close()
 
@@ -317,7 +321,7 @@ func TestOnlyEnablePublicTls(t *testing.T) {
 	clientPool := common.NewClientPool(nil, nil)
 	defer clientPool.Close()
 
-	coordinator, err := impl.NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, impl.NewRpcProvider(clientPool))
+	coordinator, err := impl.NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, impl.NewRpcProvider(clientPool), nil)
 	assert.NoError(t, err)
 	defer coordinator.Close()
 
@@ -336,3 +340,79 @@ func TestOnlyEnablePublicTls(t *testing.T) {
 	assert.NoError(t, err)
 	client.Close()
 }
+
+func TestClientAuthenticationWithMTLS(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	assert.NoError(t, os.WriteFile(policyFile, []byte(`
+roles:
+  - name: client
+    grants:
+      - namespace: default
+        keyPrefix: /client/
+        permissions: [read, write]
+bindings:
+  - role: client
+    users: [oxia-client]
+`), 0600))
+
+	withMTLS := func(config *server.Config) {
+		option, err := getPeerTLSOption()
+		assert.NoError(t, err)
+		option.ClientAuth = true
+		config.ServerTLS, err = option.MakeServerTLSConf()
+		assert.NoError(t, err)
+		config.AuthOptions = auth.Options{
+			ProviderName: auth.ProviderMTLS,
+			PolicyFile:   policyFile,
+		}
+	}
+	s1, sa1 := newTLSServerWithInterceptor(t, withMTLS)
+	defer s1.Close()
+	s2, sa2 := newTLSServerWithInterceptor(t, withMTLS)
+	defer s2.Close()
+	s3, sa3 := newTLSServerWithInterceptor(t, withMTLS)
+	defer s3.Close()
+
+	metadataProvider := impl.NewMetadataProviderMemory()
+	clusterConfig := model.ClusterConfig{
+		Namespaces: []model.NamespaceConfig{{
+			Name:              common.DefaultNamespace,
+			ReplicationFactor: 3,
+			InitialShardCount: 1,
+		}},
+		Servers: []model.Server{sa1, sa2, sa3},
+	}
+	option, err := getPeerTLSOption()
+	assert.NoError(t, err)
+	peerTLSConf, err := option.MakeClientTLSConf()
+	assert.NoError(t, err)
+
+	clientPool := common.NewClientPool(peerTLSConf, nil)
+	defer clientPool.Close()
+
+	coordinator, err := impl.NewCoordinator(metadataProvider, func() (model.ClusterConfig, error) { return clusterConfig, nil }, nil, impl.NewRpcProvider(clientPool), nil)
+	assert.NoError(t, err)
+	defer coordinator.Close()
+
+	// The user name is the common name of the client certificate
+	clientOption, err := getClientTLSOption()
+	assert.NoError(t, err)
+	clientTLSConf, err := clientOption.MakeClientTLSConf()
+	assert.NoError(t, err)
+	client, err := oxia.NewSyncClient(sa1.Public, oxia.WithTLS(clientTLSConf))
+	assert.NoError(t, err)
+	defer client.Close()
+
+	ctx := context.Background()
+	_, _, err = client.Put(ctx, "/client/key", []byte("value"))
+	assert.NoError(t, err)
+	_, _, err = client.Put(ctx, "/other/key", []byte("value"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// The peer certificate is a different user, without any role
+	peerClient, err := oxia.NewSyncClient(sa1.Public, oxia.WithTLS(peerTLSConf))
+	assert.NoError(t, err)
+	defer peerClient.Close()
+	_, _, _, err = peerClient.Get(ctx, "/client/key")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}